@@ -0,0 +1,43 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	results := []struct {
+		Attempts int
+		Backoff  time.Duration
+	}{
+		{Attempts: 0, Backoff: time.Second},
+		{Attempts: 1, Backoff: time.Second},
+		{Attempts: 2, Backoff: 2 * time.Second},
+		{Attempts: 4, Backoff: 8 * time.Second},
+		{Attempts: 5, Backoff: 10 * time.Second},
+		{Attempts: 1000, Backoff: 10 * time.Second},
+	}
+
+	for _, result := range results {
+		assert.Equal(t, result.Backoff, blamewarrior.ExponentialBackoff(result.Attempts, time.Second, 10*time.Second), "attempts: %d", result.Attempts)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultBranch is a snapshot of repository default branch and its protection settings.
+type DefaultBranch struct {
+	Name      string `json:"name"`
+	Protected bool   `json:"protected"`
+	// RequiredReviews is set if pull requests need an approving review before merging
+	RequiredReviews bool `json:"required_reviews"`
+	// RequireCodeOwnerReviews is set if pull requests need an approval of code owners
+	RequireCodeOwnerReviews bool `json:"require_code_owner_reviews"`
+	// DismissStaleReviews is set if approvals are dismissed when new commits are pushed
+	DismissStaleReviews bool      `json:"dismiss_stale_reviews"`
+	RefreshedAt         time.Time `json:"refreshed_at"`
+}
+
+// Equal reports whether both snapshots describe the same branch settings regardless of
+// when they were taken.
+func (b *DefaultBranch) Equal(other *DefaultBranch) bool {
+	if b == nil || other == nil {
+		return b == other
+	}
+
+	x, y := *b, *other
+	x.RefreshedAt, y.RefreshedAt = time.Time{}, time.Time{}
+
+	return x == y
+}
+
+// UpdateRepositoryDefaultBranch stores default branch snapshot of repository. Snapshots that differ
+// from the stored one only by refresh time do not count as repository change.
+func UpdateRepositoryDefaultBranch(runner SQLRunner, repo *Repository, branch *DefaultBranch, changes ...*Change) error {
+	doc, err := json.Marshal(branch)
+	if err != nil {
+		return fmt.Errorf("failed to update repository default branch: %s", err)
+	}
+
+	if branch.Equal(repo.DefaultBranch) {
+		if _, err := runner.Exec(RefreshRepositoryDefaultBranchQuery, repo.ID, string(doc)); err != nil {
+			return fmt.Errorf("failed to update repository default branch: %s", err)
+		}
+
+		repo.DefaultBranch = branch
+
+		return nil
+	}
+
+	before := *repo
+
+	if err := runner.QueryRow(UpdateRepositoryDefaultBranchQuery, repo.ID, string(doc)).Scan(&repo.Version); err != nil {
+		return fmt.Errorf("failed to update repository default branch: %s", err)
+	}
+
+	repo.DefaultBranch = branch
+
+	return recordEvent(runner, EventUpdated, &before, repo, changes...)
+}
+
+const (
+	UpdateRepositoryDefaultBranchQuery  = `UPDATE repositories SET default_branch=$2, version=version+1 WHERE id=$1 AND deleted_at IS NULL RETURNING version`
+	RefreshRepositoryDefaultBranchQuery = `UPDATE repositories SET default_branch=$2 WHERE id=$1 AND deleted_at IS NULL`
+)
@@ -0,0 +1,83 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultBranch_Equal(t *testing.T) {
+	branch := &blamewarrior.DefaultBranch{Name: "master", Protected: true, RefreshedAt: time.Now()}
+
+	examples := []struct {
+		Other *blamewarrior.DefaultBranch
+		Equal bool
+	}{
+		{&blamewarrior.DefaultBranch{Name: "master", Protected: true}, true},
+		{&blamewarrior.DefaultBranch{Name: "master", Protected: true, RequiredReviews: true}, false},
+		{&blamewarrior.DefaultBranch{Name: "develop", Protected: true}, false},
+		{nil, false},
+	}
+
+	for _, example := range examples {
+		assert.Equal(t, example.Equal, branch.Equal(example.Other), "%+v", example.Other)
+	}
+
+	var none *blamewarrior.DefaultBranch
+	assert.True(t, none.Equal(nil))
+}
+
+func TestUpdateRepositoryDefaultBranch(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events CASCADE;")
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
+
+	repo, err = blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	assert.Nil(t, repo.DefaultBranch)
+
+	version := repo.Version
+
+	branch := &blamewarrior.DefaultBranch{Name: "master", Protected: true, RequiredReviews: true, RefreshedAt: time.Now()}
+	require.NoError(t, blamewarrior.UpdateRepositoryDefaultBranch(db, repo, branch))
+	assert.Equal(t, version+1, repo.Version)
+
+	// refreshing an unchanged snapshot is not a change of repository
+	unchanged := *branch
+	unchanged.RefreshedAt = time.Now().Add(time.Hour)
+	require.NoError(t, blamewarrior.UpdateRepositoryDefaultBranch(db, repo, &unchanged))
+	assert.Equal(t, version+1, repo.Version)
+
+	repo, err = blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	require.NotNil(t, repo.DefaultBranch)
+	assert.True(t, branch.Equal(repo.DefaultBranch))
+	assert.WithinDuration(t, unchanged.RefreshedAt, repo.DefaultBranch.RefreshedAt, time.Second)
+
+	history, err := blamewarrior.GetRepositoryHistory(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+}
@@ -0,0 +1,133 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package cache implements in-memory caches.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is a cache of limited size that evicts least recently used entries once it is full.
+// Entries also expire after TTL. LRU is safe for concurrent use.
+type LRU struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries *list.List
+	items   map[string]*list.Element
+
+	// now returns current time, it is replaced in tests
+	now func() time.Time
+}
+
+type entry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// NewLRU returns cache holding up to size entries for ttl. Entries do not expire if ttl is zero.
+func NewLRU(size int, ttl time.Duration) *LRU {
+	return &LRU{
+		size:    size,
+		ttl:     ttl,
+		entries: list.New(),
+		items:   make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// Get returns value stored under key unless it has expired.
+func (c *LRU) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	e := el.Value.(*entry)
+	if c.ttl > 0 && !c.now().Before(e.expiresAt) {
+		c.remove(el)
+		return nil, false
+	}
+
+	c.entries.MoveToFront(el)
+
+	return e.value, true
+}
+
+// Add stores value under key evicting least recently used entry if cache is full. It reports
+// whether an entry has been evicted.
+func (c *LRU) Add(key string, value interface{}) (evicted bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry)
+		e.value, e.expiresAt = value, expiresAt
+		c.entries.MoveToFront(el)
+
+		return false
+	}
+
+	c.items[key] = c.entries.PushFront(&entry{key: key, value: value, expiresAt: expiresAt})
+
+	if c.entries.Len() > c.size {
+		c.remove(c.entries.Back())
+		return true
+	}
+
+	return false
+}
+
+// Remove deletes value stored under key.
+func (c *LRU) Remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+}
+
+// Purge deletes all entries.
+func (c *LRU) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+// Len returns the number of entries including expired ones that have not been evicted yet.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.entries.Len()
+}
+
+func (c *LRU) remove(el *list.Element) {
+	c.entries.Remove(el)
+	delete(c.items, el.Value.(*entry).key)
+}
@@ -0,0 +1,89 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU_Eviction(t *testing.T) {
+	c := NewLRU(2, 0)
+
+	assert.False(t, c.Add("a", 1))
+	assert.False(t, c.Add("b", 2))
+
+	// a becomes the most recently used entry
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	assert.True(t, c.Add("c", 3))
+
+	_, ok = c.Get("b")
+	assert.False(t, ok, "least recently used entry should be evicted")
+
+	for key, expected := range map[string]int{"a": 1, "c": 3} {
+		v, ok := c.Get(key)
+		assert.True(t, ok, key)
+		assert.Equal(t, expected, v, key)
+	}
+
+	assert.False(t, c.Add("a", 4), "replacing value should not evict entries")
+	v, _ = c.Get("a")
+	assert.Equal(t, 4, v)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestLRU_Expiration(t *testing.T) {
+	now := time.Date(2017, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	c := NewLRU(10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Add("a", 1)
+
+	now = now.Add(59 * time.Second)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok = c.Get("a")
+	assert.False(t, ok, "entry should expire after TTL")
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRU_Remove(t *testing.T) {
+	c := NewLRU(10, 0)
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+
+	c.Remove("a")
+	c.Remove("missing")
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, c.Len())
+
+	c.Purge()
+
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
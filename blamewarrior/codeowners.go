@@ -0,0 +1,256 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CodeOwnersPaths are the locations of CODEOWNERS file in the order GitHub looks them up.
+var CodeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwners is a parsed CODEOWNERS file of repository.
+type CodeOwners struct {
+	// Path is the location of CODEOWNERS file, it is empty if repository has none
+	Path string `json:"path"`
+	// CommitSHA is the commit CODEOWNERS file was read from
+	CommitSHA string           `json:"commit_sha"`
+	Rules     []CodeOwnersRule `json:"rules"`
+	// Errors contains descriptions of lines that were skipped because they could not be parsed
+	Errors      []string  `json:"errors,omitempty"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+}
+
+// CodeOwnersRule is a single line of CODEOWNERS file.
+type CodeOwnersRule struct {
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+	Line    int      `json:"line"`
+
+	re *regexp.Regexp
+}
+
+// ParseCodeOwners parses contents of CODEOWNERS file. Lines that cannot be parsed are skipped
+// and reported in returned errors the same way GitHub does.
+func ParseCodeOwners(content string) (rules []CodeOwnersRule, errs []string) {
+	for i, line := range strings.Split(content, "\n") {
+		line = stripCodeOwnersComment(line)
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		rule, err := NewCodeOwnersRule(strings.Replace(fields[0], `\#`, "#", -1), fields[1:], i+1)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("line %d: %s", i+1, err))
+			continue
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, errs
+}
+
+func stripCodeOwnersComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '#':
+			return line[:i]
+		}
+	}
+
+	return line
+}
+
+var codeOwnerRe = regexp.MustCompile(`^(@[A-Za-z0-9][A-Za-z0-9\-]*(/[A-Za-z0-9_\.\-]+)?|[^@\s]+@[^@\s]+)$`)
+
+// NewCodeOwnersRule returns rule that assigns owners to files matching pattern. Patterns follow
+// CODEOWNERS syntax: gitignore-like globs without negation and character ranges.
+func NewCodeOwnersRule(pattern string, owners []string, line int) (CodeOwnersRule, error) {
+	if strings.HasPrefix(pattern, "!") {
+		return CodeOwnersRule{}, fmt.Errorf("negated pattern %s is not supported", pattern)
+	}
+
+	if strings.ContainsAny(pattern, "[]") {
+		return CodeOwnersRule{}, fmt.Errorf("character ranges in pattern %s are not supported", pattern)
+	}
+
+	for _, owner := range owners {
+		if !codeOwnerRe.MatchString(owner) {
+			return CodeOwnersRule{}, fmt.Errorf("invalid owner %s", owner)
+		}
+	}
+
+	re, err := compileCodeOwnersPattern(pattern)
+	if err != nil {
+		return CodeOwnersRule{}, err
+	}
+
+	if owners == nil {
+		owners = []string{}
+	}
+
+	return CodeOwnersRule{Pattern: pattern, Owners: owners, Line: line, re: re}, nil
+}
+
+// Matches reports whether file path relative to repository root matches rule pattern.
+func (rule *CodeOwnersRule) Matches(path string) bool {
+	if rule.re == nil {
+		re, err := compileCodeOwnersPattern(rule.Pattern)
+		if err != nil {
+			return false
+		}
+		rule.re = re
+	}
+
+	return rule.re.MatchString(strings.TrimPrefix(path, "/"))
+}
+
+// Match returns the rule that defines owners of file at path, which is the last matching one.
+// It returns nil if no rule matches.
+func (owners *CodeOwners) Match(path string) *CodeOwnersRule {
+	for i := len(owners.Rules) - 1; i >= 0; i-- {
+		if owners.Rules[i].Matches(path) {
+			return &owners.Rules[i]
+		}
+	}
+
+	return nil
+}
+
+// compileCodeOwnersPattern converts CODEOWNERS pattern into regular expression:
+//
+//   - a pattern containing a slash anywhere but at the end is relative to repository root,
+//     otherwise it matches at any depth
+//   - "*" matches anything except a slash, "?" matches any single character except a slash
+//   - leading "**/" matches in all directories, trailing "/**" matches everything inside
+//     and "/**/" matches zero or more directories
+//   - a pattern which last segment has no wildcards matches a directory with all its contents,
+//     and a trailing slash makes it match only directories
+func compileCodeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	p := pattern
+
+	anchored := strings.HasPrefix(p, "/") || strings.Contains(strings.TrimSuffix(p, "/"), "/")
+	dirOnly := strings.HasSuffix(p, "/")
+
+	p = strings.TrimSuffix(strings.TrimPrefix(p, "/"), "/")
+	if p == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	var expr strings.Builder
+
+	if anchored {
+		expr.WriteString(`^`)
+	} else {
+		expr.WriteString(`^(?:.*/)?`)
+	}
+
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/") && (i == 0 || p[i-1] == '/'):
+			expr.WriteString(`(?:.*/)?`)
+			i += 2
+		case strings.HasPrefix(p[i:], "**") && i+2 == len(p) && (i == 0 || p[i-1] == '/'):
+			expr.WriteString(`.*`)
+			i++
+		case p[i] == '*':
+			expr.WriteString(`[^/]*`)
+		case p[i] == '?':
+			expr.WriteString(`[^/]`)
+		default:
+			expr.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+
+	lastSegment := p[strings.LastIndex(p, "/")+1:]
+
+	switch {
+	case dirOnly:
+		expr.WriteString(`/.*$`)
+	case !strings.ContainsAny(lastSegment, "*?"):
+		expr.WriteString(`(?:/.*)?$`)
+	default:
+		expr.WriteString(`$`)
+	}
+
+	return regexp.Compile(expr.String())
+}
+
+// GetCodeOwners returns stored CODEOWNERS of repository with given ID or nil if they have
+// never been loaded.
+func GetCodeOwners(runner SQLRunner, repositoryID int) (*CodeOwners, error) {
+	var (
+		owners      CodeOwners
+		rules, errs []byte
+	)
+
+	err := runner.QueryRow(GetCodeOwnersQuery, repositoryID).Scan(&owners.Path, &owners.CommitSHA, &rules, &errs, &owners.RefreshedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch code owners: %s", err)
+	}
+
+	if err := json.Unmarshal(rules, &owners.Rules); err != nil {
+		return nil, fmt.Errorf("failed to fetch code owners: %s", err)
+	}
+
+	if err := json.Unmarshal(errs, &owners.Errors); err != nil {
+		return nil, fmt.Errorf("failed to fetch code owners: %s", err)
+	}
+
+	return &owners, nil
+}
+
+// UpdateCodeOwners replaces stored CODEOWNERS of repository with given ID.
+func UpdateCodeOwners(runner SQLRunner, repositoryID int, owners *CodeOwners) error {
+	rules, err := json.Marshal(owners.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to update code owners: %s", err)
+	}
+
+	errs, err := json.Marshal(owners.Errors)
+	if err != nil {
+		return fmt.Errorf("failed to update code owners: %s", err)
+	}
+
+	_, err = runner.Exec(UpdateCodeOwnersQuery, repositoryID, owners.Path, owners.CommitSHA, string(rules), string(errs), owners.RefreshedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update code owners: %s", err)
+	}
+
+	return nil
+}
+
+const (
+	GetCodeOwnersQuery    = `SELECT path, commit_sha, rules, errors, refreshed_at FROM code_owners WHERE repository_id=$1`
+	UpdateCodeOwnersQuery = `INSERT INTO code_owners (repository_id, path, commit_sha, rules, errors, refreshed_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (repository_id) DO UPDATE SET path=EXCLUDED.path, commit_sha=EXCLUDED.commit_sha, rules=EXCLUDED.rules, errors=EXCLUDED.errors, refreshed_at=EXCLUDED.refreshed_at`
+)
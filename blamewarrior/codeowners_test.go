@@ -0,0 +1,237 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeOwnersRule_Matches(t *testing.T) {
+	examples := []struct {
+		Pattern string
+		Matches []string
+		Misses  []string
+	}{
+		{
+			Pattern: "*",
+			Matches: []string{"README.md", "src/main.go", ".github/CODEOWNERS"},
+		},
+		{
+			Pattern: "*.js",
+			Matches: []string{"app.js", "src/app.js", "src/lib/app.js"},
+			Misses:  []string{"app.jsx", "app.js.map", "js"},
+		},
+		{
+			Pattern: "*.go",
+			Matches: []string{"main.go", "/main.go"},
+			Misses:  []string{"main.go.orig", "go"},
+		},
+		{
+			Pattern: "README.md",
+			Matches: []string{"README.md", "docs/README.md"},
+			Misses:  []string{"README.markdown", "docs/README.md.bak"},
+		},
+		{
+			Pattern: "/README.md",
+			Matches: []string{"README.md"},
+			Misses:  []string{"docs/README.md"},
+		},
+		{
+			Pattern: "docs",
+			Matches: []string{"docs", "docs/index.md", "src/docs/api/index.md"},
+			Misses:  []string{"documents/index.md", "mydocs/index.md"},
+		},
+		{
+			Pattern: "/build/logs/",
+			Matches: []string{"build/logs/a.log", "build/logs/2017/a.log"},
+			Misses:  []string{"build/logs", "src/build/logs/a.log", "build/logs.txt"},
+		},
+		{
+			Pattern: "apps/",
+			Matches: []string{"apps/main.go", "src/apps/main.go", "apps/cmd/main.go"},
+			Misses:  []string{"apps", "myapps/main.go"},
+		},
+		{
+			Pattern: "docs/*",
+			Matches: []string{"docs/getting-started.md"},
+			Misses:  []string{"docs/build-app/troubleshooting.md", "src/docs/index.md", "docs"},
+		},
+		{
+			Pattern: "docs/**",
+			Matches: []string{"docs/index.md", "docs/build-app/troubleshooting.md"},
+			Misses:  []string{"docs", "src/docs/index.md"},
+		},
+		{
+			Pattern: "**/logs",
+			Matches: []string{"logs", "logs/a.log", "build/logs/a.log", "deeply/nested/logs"},
+			Misses:  []string{"build/logs.txt", "mylogs/a.log"},
+		},
+		{
+			Pattern: "src/**/test",
+			Matches: []string{"src/test", "src/test/a_test.go", "src/pkg/test/a_test.go", "src/a/b/test/c"},
+			Misses:  []string{"test", "lib/src/test", "src/testing"},
+		},
+		{
+			Pattern: "src/*.go",
+			Matches: []string{"src/main.go"},
+			Misses:  []string{"src/pkg/main.go", "main.go"},
+		},
+		{
+			Pattern: "file?.txt",
+			Matches: []string{"file1.txt", "dir/fileA.txt"},
+			Misses:  []string{"file.txt", "file10.txt", "file/.txt"},
+		},
+		{
+			Pattern: "foo.bar",
+			Matches: []string{"foo.bar"},
+			Misses:  []string{"fooxbar"},
+		},
+		{
+			Pattern: "*.min.*",
+			Matches: []string{"app.min.js", "static/style.min.css"},
+			Misses:  []string{"app.js", "min.js"},
+		},
+	}
+
+	for _, example := range examples {
+		t.Run(example.Pattern, func(t *testing.T) {
+			rule, err := blamewarrior.NewCodeOwnersRule(example.Pattern, []string{"@octocat"}, 1)
+			require.NoError(t, err)
+
+			for _, path := range example.Matches {
+				assert.True(t, rule.Matches(path), "%s should match %s", example.Pattern, path)
+			}
+
+			for _, path := range example.Misses {
+				assert.False(t, rule.Matches(path), "%s should not match %s", example.Pattern, path)
+			}
+		})
+	}
+}
+
+func TestParseCodeOwners(t *testing.T) {
+	rules, errs := blamewarrior.ParseCodeOwners(`# This is a comment
+*       @global-owner1 @global-owner2
+
+*.js    @js-owner # inline comment
+*.go docs@example.com
+/build/logs/ @doctocat
+\#file_with_pound.rb @ruby-owner
+/apps/github
+!negated @octocat
+[Rr]eadme.md @octocat
+docs/* not-an-owner
+/teams/ @blamewarrior/core-team
+`)
+
+	assert.Equal(t, []string{
+		"line 9: negated pattern !negated is not supported",
+		"line 10: character ranges in pattern [Rr]eadme.md are not supported",
+		"line 11: invalid owner not-an-owner",
+	}, errs)
+
+	expected := []struct {
+		Pattern string
+		Owners  []string
+		Line    int
+	}{
+		{"*", []string{"@global-owner1", "@global-owner2"}, 2},
+		{"*.js", []string{"@js-owner"}, 4},
+		{"*.go", []string{"docs@example.com"}, 5},
+		{"/build/logs/", []string{"@doctocat"}, 6},
+		{"#file_with_pound.rb", []string{"@ruby-owner"}, 7},
+		{"/apps/github", []string{}, 8},
+		{"/teams/", []string{"@blamewarrior/core-team"}, 12},
+	}
+
+	require.Len(t, rules, len(expected))
+
+	for i, rule := range rules {
+		assert.Equal(t, expected[i].Pattern, rule.Pattern)
+		assert.Equal(t, expected[i].Owners, rule.Owners)
+		assert.Equal(t, expected[i].Line, rule.Line)
+	}
+}
+
+func TestCodeOwners_Match(t *testing.T) {
+	rules, errs := blamewarrior.ParseCodeOwners(`
+*           @global-owner
+*.js        @js-owner
+/apps/      @apps-owner
+/apps/github
+`)
+	require.Empty(t, errs)
+
+	owners := &blamewarrior.CodeOwners{Rules: rules}
+
+	examples := []struct {
+		Path   string
+		Owners []string
+	}{
+		{"README.md", []string{"@global-owner"}},
+		{"src/index.js", []string{"@js-owner"}},
+		{"apps/index.js", []string{"@apps-owner"}},
+		{"apps/github/index.js", []string{}},
+	}
+
+	for _, example := range examples {
+		rule := owners.Match(example.Path)
+		require.NotNil(t, rule, example.Path)
+		assert.Equal(t, example.Owners, rule.Owners, example.Path)
+	}
+
+	assert.Nil(t, (&blamewarrior.CodeOwners{}).Match("README.md"))
+}
+
+func TestUpdateCodeOwners(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, code_owners CASCADE;")
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
+
+	owners, err := blamewarrior.GetCodeOwners(db, repo.ID)
+	require.NoError(t, err)
+	assert.Nil(t, owners)
+
+	rules, errs := blamewarrior.ParseCodeOwners("*.go @gopher\n!x @octocat\n")
+
+	err = blamewarrior.UpdateCodeOwners(db, repo.ID, &blamewarrior.CodeOwners{
+		Path:        ".github/CODEOWNERS",
+		CommitSHA:   "abc123",
+		Rules:       rules,
+		Errors:      errs,
+		RefreshedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	owners, err = blamewarrior.GetCodeOwners(db, repo.ID)
+	require.NoError(t, err)
+	require.NotNil(t, owners)
+
+	assert.Equal(t, ".github/CODEOWNERS", owners.Path)
+	assert.Equal(t, errs, owners.Errors)
+	require.NotNil(t, owners.Match("cmd/main.go"))
+	assert.Equal(t, []string{"@gopher"}, owners.Match("cmd/main.go").Owners)
+}
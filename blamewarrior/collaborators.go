@@ -0,0 +1,119 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"fmt"
+	"time"
+)
+
+// Repository permission levels in ascending order
+const (
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+	PermissionAdmin = "admin"
+)
+
+var permissionLevels = map[string]int{
+	PermissionRead:  1,
+	PermissionWrite: 2,
+	PermissionAdmin: 3,
+}
+
+// Collaborator types
+const (
+	CollaboratorUser = "user"
+	CollaboratorTeam = "team"
+)
+
+// Collaborator is a user or a team that has access to repository.
+type Collaborator struct {
+	// Login is user login or team slug
+	Login      string    `json:"login"`
+	Type       string    `json:"type"`
+	Permission string    `json:"permission"`
+	SyncedAt   time.Time `json:"synced_at"`
+}
+
+// ValidPermission reports whether permission is one of known permission levels.
+func ValidPermission(permission string) bool {
+	_, ok := permissionLevels[permission]
+	return ok
+}
+
+// HasPermission reports whether collaborator has at least given permission level.
+func (c *Collaborator) HasPermission(permission string) bool {
+	return permissionLevels[c.Permission] >= permissionLevels[permission]
+}
+
+// GetCollaborators returns collaborators of repository with given ID that have at least given
+// permission level. All collaborators are returned if permission is empty.
+func GetCollaborators(runner SQLRunner, repositoryID int, permission string) (collaborators []Collaborator, err error) {
+	if permission != "" && !ValidPermission(permission) {
+		return nil, fmt.Errorf("unknown permission %s", permission)
+	}
+
+	rows, err := runner.Query(GetCollaboratorsQuery, repositoryID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch collaborators: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c Collaborator
+
+		if err := rows.Scan(&c.Login, &c.Type, &c.Permission, &c.SyncedAt); err != nil {
+			return nil, fmt.Errorf("failed to fetch collaborators: %s", err)
+		}
+
+		if c.HasPermission(permission) {
+			collaborators = append(collaborators, c)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch collaborators: %s", err)
+	}
+
+	return collaborators, nil
+}
+
+// ReplaceCollaborators replaces stored collaborators of repository with given ID. It is expected
+// to be run within a transaction so that readers never see a partially synced list.
+func ReplaceCollaborators(runner SQLRunner, repositoryID int, collaborators []Collaborator) error {
+	if _, err := runner.Exec(DeleteCollaboratorsQuery, repositoryID); err != nil {
+		return fmt.Errorf("failed to replace collaborators: %s", err)
+	}
+
+	for _, c := range collaborators {
+		if !ValidPermission(c.Permission) {
+			return fmt.Errorf("failed to replace collaborators: unknown permission %s of %s", c.Permission, c.Login)
+		}
+
+		if _, err := runner.Exec(CreateCollaboratorQuery, repositoryID, c.Login, c.Type, c.Permission); err != nil {
+			return fmt.Errorf("failed to replace collaborators: %s", err)
+		}
+	}
+
+	return nil
+}
+
+const (
+	GetCollaboratorsQuery    = `SELECT login, type, permission, synced_at FROM repository_collaborators WHERE repository_id=$1 ORDER BY type DESC, login`
+	DeleteCollaboratorsQuery = `DELETE FROM repository_collaborators WHERE repository_id=$1`
+	CreateCollaboratorQuery  = `INSERT INTO repository_collaborators (repository_id, login, type, permission) VALUES ($1, $2, $3, $4)`
+)
@@ -0,0 +1,97 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"testing"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollaborator_HasPermission(t *testing.T) {
+	examples := []struct {
+		Permission string
+		Required   string
+		Result     bool
+	}{
+		{blamewarrior.PermissionAdmin, "", true},
+		{blamewarrior.PermissionAdmin, blamewarrior.PermissionWrite, true},
+		{blamewarrior.PermissionWrite, blamewarrior.PermissionWrite, true},
+		{blamewarrior.PermissionRead, blamewarrior.PermissionWrite, false},
+		{blamewarrior.PermissionWrite, blamewarrior.PermissionAdmin, false},
+		{blamewarrior.PermissionRead, blamewarrior.PermissionRead, true},
+	}
+
+	for _, example := range examples {
+		c := &blamewarrior.Collaborator{Permission: example.Permission}
+		assert.Equal(t, example.Result, c.HasPermission(example.Required), "%s >= %s", example.Permission, example.Required)
+	}
+}
+
+func TestReplaceCollaborators(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_collaborators CASCADE;")
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
+
+	err = blamewarrior.ReplaceCollaborators(db, repo.ID, []blamewarrior.Collaborator{
+		{Login: "user1", Type: blamewarrior.CollaboratorUser, Permission: blamewarrior.PermissionAdmin},
+		{Login: "user2", Type: blamewarrior.CollaboratorUser, Permission: blamewarrior.PermissionRead},
+	})
+	require.NoError(t, err)
+
+	err = blamewarrior.ReplaceCollaborators(db, repo.ID, []blamewarrior.Collaborator{
+		{Login: "user2", Type: blamewarrior.CollaboratorUser, Permission: blamewarrior.PermissionWrite},
+		{Login: "user3", Type: blamewarrior.CollaboratorUser, Permission: blamewarrior.PermissionRead},
+		{Login: "core", Type: blamewarrior.CollaboratorTeam, Permission: blamewarrior.PermissionWrite},
+	})
+	require.NoError(t, err)
+
+	results := []struct {
+		Permission string
+		Logins     []string
+	}{
+		{"", []string{"user2", "user3", "core"}},
+		{blamewarrior.PermissionWrite, []string{"user2", "core"}},
+		{blamewarrior.PermissionAdmin, nil},
+	}
+
+	for _, result := range results {
+		collaborators, err := blamewarrior.GetCollaborators(db, repo.ID, result.Permission)
+		require.NoError(t, err)
+
+		var logins []string
+		for _, c := range collaborators {
+			logins = append(logins, c.Login)
+		}
+
+		assert.Equal(t, result.Logins, logins, result.Permission)
+	}
+
+	_, err = blamewarrior.GetCollaborators(db, repo.ID, "owner")
+	assert.Error(t, err)
+
+	err = blamewarrior.ReplaceCollaborators(db, repo.ID, []blamewarrior.Collaborator{
+		{Login: "user1", Type: blamewarrior.CollaboratorUser, Permission: "maintain"},
+	})
+	assert.Error(t, err)
+}
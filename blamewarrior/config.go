@@ -0,0 +1,99 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigFileName is the name of BlameWarrior configuration file kept in repository.
+const ConfigFileName = ".blamewarrior.yml"
+
+// RepositoryConfig is BlameWarrior configuration loaded from the configuration file on the
+// default branch of repository.
+type RepositoryConfig struct {
+	// Settings is parsed configuration file, it is empty if repository has no such file
+	Settings json.RawMessage `json:"settings,omitempty"`
+	// CommitSHA is the commit configuration was read from
+	CommitSHA string `json:"commit_sha"`
+	// Error is the reason configuration file from CommitSHA could not be used
+	Error       string    `json:"error,omitempty"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+}
+
+// ParseConfigFile parses YAML content of configuration file into settings document and checks
+// that it is valid. Duplicate keys are rejected.
+func ParseConfigFile(content []byte) (json.RawMessage, error) {
+	var doc interface{}
+	if err := yaml.UnmarshalStrict(content, &doc); err != nil {
+		return nil, err
+	}
+
+	settings, err := json.Marshal(jsonValue(doc))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateSettings(settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// jsonValue converts mappings decoded from YAML, which may have keys of any type, into
+// JSON objects.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			obj[fmt.Sprint(k)] = jsonValue(item)
+		}
+
+		return obj
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonValue(item)
+		}
+	}
+
+	return v
+}
+
+// UpdateRepositoryConfig stores configuration loaded from repository.
+func UpdateRepositoryConfig(runner SQLRunner, repo *Repository, config *RepositoryConfig, changes ...*Change) error {
+	before := *repo
+
+	err := runner.QueryRow(
+		UpdateRepositoryConfigQuery,
+		repo.ID, nullableJSON(config.Settings), config.CommitSHA, config.Error, config.RefreshedAt,
+	).Scan(&repo.Version)
+
+	if err != nil {
+		return fmt.Errorf("failed to update repository config: %s", err)
+	}
+
+	repo.Config = config
+
+	return recordEvent(runner, EventUpdated, &before, repo, changes...)
+}
+
+const UpdateRepositoryConfigQuery = `UPDATE repositories SET config=$2, config_sha=$3, config_error=$4, config_refreshed_at=$5, version=version+1 WHERE id=$1 AND deleted_at IS NULL RETURNING version`
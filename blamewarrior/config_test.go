@@ -0,0 +1,118 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateRepositoryConfig(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{
+		Owner:    "blamewarrior",
+		Name:     "repos",
+		Settings: json.RawMessage(`{"version":1,"reviewers":{"count":2},"ignored_paths":["docs/"]}`),
+	}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
+
+	repo, err = blamewarrior.GetRepositoryByFullName(db, repo.FullName())
+	require.NoError(t, err)
+	assert.Nil(t, repo.Config)
+
+	config := &blamewarrior.RepositoryConfig{
+		Settings:    json.RawMessage(`{"version":1,"ignored_paths":["vendor/"]}`),
+		CommitSHA:   "abc123",
+		RefreshedAt: time.Now(),
+	}
+	require.NoError(t, blamewarrior.UpdateRepositoryConfig(db, repo, config))
+
+	stored, err := blamewarrior.GetRepositoryByFullName(db, repo.FullName())
+	require.NoError(t, err)
+	require.NotNil(t, stored.Config)
+	assert.Equal(t, "abc123", stored.Config.CommitSHA)
+	assert.Empty(t, stored.Config.Error)
+	assert.Equal(t, repo.Version, stored.Version)
+
+	settings, err := blamewarrior.GetRepositorySettings(db, repo.FullName())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":1,"reviewers":{"count":2},"ignored_paths":["vendor/"]}`, string(settings))
+}
+
+func TestParseConfigFile(t *testing.T) {
+	examples := map[string]struct {
+		YAML, Settings, Err string
+	}{
+		"settings": {
+			YAML:     "version: 1 # comment\nignored_paths:\n  - vendor/\n  - \"docs/*.md\"\n",
+			Settings: `{"version":1,"ignored_paths":["vendor/","docs/*.md"]}`,
+		},
+		"document marker": {
+			YAML:     "---\nversion: 1\nignored_paths: [vendor/]\n",
+			Settings: `{"version":1,"ignored_paths":["vendor/"]}`,
+		},
+		"nested mappings": {
+			YAML:     "version: 1\nstaleness:\n  action: untrack\n  untrack_after_days: 7\n",
+			Settings: `{"version":1,"staleness":{"action":"untrack","untrack_after_days":7}}`,
+		},
+		"malformed": {
+			YAML: "version: 1\n  ignored_paths: []\n",
+			Err:  "yaml: line 2: mapping values are not allowed in this context",
+		},
+		"duplicate key": {
+			YAML: "version: 1\nversion: 2\n",
+			Err:  `yaml: unmarshal errors:` + "\n" + `  line 2: key "version" already set in map`,
+		},
+		"empty": {
+			YAML: "",
+			Err:  "settings version must be specified",
+		},
+		"not a mapping": {
+			YAML: "- version\n",
+			Err:  "settings must be a JSON object",
+		},
+		"invalid settings": {
+			YAML: "version: 1\nreviewers: 2\n",
+			Err:  "invalid settings: reviewers must be object",
+		},
+	}
+
+	for name, example := range examples {
+		t.Run(name, func(t *testing.T) {
+			settings, err := blamewarrior.ParseConfigFile([]byte(example.YAML))
+
+			if example.Err != "" {
+				require.Error(t, err)
+				assert.Equal(t, example.Err, err.Error())
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.JSONEq(t, example.Settings, string(settings))
+		})
+	}
+}
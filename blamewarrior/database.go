@@ -23,6 +23,8 @@ import (
 	"database/sql"
 	"fmt"
 	"strconv"
+	"sync/atomic"
+	"time"
 
 	_ "github.com/lib/pq"
 )
@@ -37,6 +39,10 @@ type DatabaseOptions struct {
 	User string
 	// Password is connection password to authenticate with
 	Password string
+	// Replicas is a list of connection strings for read-only replicas. Each of them is
+	// appended to the primary connection string, so it only needs to contain the options
+	// that differ, e.g. "host=db-replica-1".
+	Replicas []string
 }
 
 // ConnectionString returns a connection string suitable to for sql.DB().
@@ -65,17 +71,122 @@ func (opts *DatabaseOptions) ConnectionString() string {
 	return connStr
 }
 
-func ConnectDatabase(dbName string, opts ...*DatabaseOptions) (*sql.DB, error) {
-	connStr := "sslmode=disable dbname=" + dbName
+// DB is a connection to the primary database that routes read-only queries
+// issued through it to a healthy replica.
+type DB struct {
+	*sql.DB
+	*Router
+
+	replicas []*sql.DB
+}
+
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.Router.Query(query, args...)
+}
+
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.Router.QueryRow(query, args...)
+}
+
+func (db *DB) Prepare(query string) (*sql.Stmt, error) {
+	return db.Router.Prepare(query)
+}
+
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.Router.Exec(query, args...)
+}
+
+// Conn is a runner that can start transactions, such as DB or Session.
+type Conn interface {
+	SQLRunner
+
+	Begin() (*sql.Tx, error)
+}
+
+// Session returns a connection for a single request, see Router.Session.
+func (db *DB) Session() *Session {
+	return &Session{session: &session{router: db.Router}, db: db.DB}
+}
+
+// Session is a connection for a single request. Transactions begun through it
+// pin the session to the primary, so that reads issued after commit see the
+// changes made within the request.
+type Session struct {
+	*session
+
+	db *sql.DB
+}
+
+// Begin starts a transaction on the primary.
+func (s *Session) Begin() (*sql.Tx, error) {
+	atomic.StoreInt32(&s.pinned, 1)
+	return s.db.Begin()
+}
+
+// MonitorReplicas checks replicas health every interval until stop is closed.
+func (db *DB) MonitorReplicas(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			db.CheckReplicas()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Close closes connections to the primary and all replicas.
+func (db *DB) Close() error {
+	for _, replica := range db.replicas {
+		replica.Close()
+	}
+
+	return db.DB.Close()
+}
 
+func ConnectDatabase(dbName string, opts ...*DatabaseOptions) (*DB, error) {
+	connStr := primaryConnectionString(dbName, opts...)
+
+	var replicaConnStrs []string
 	if len(opts) > 0 && opts[0] != nil {
-		connStr += " " + opts[0].ConnectionString()
+		replicaConnStrs = opts[0].Replicas
 	}
 
-	db, err := sql.Open("postgres", connStr)
+	primary, err := sql.Open("postgres", connStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to db: %s", err)
 	}
 
-	return db, db.Ping()
+	db := &DB{DB: primary}
+
+	var replicas []Replica
+	for _, replicaConnStr := range replicaConnStrs {
+		replica, err := sql.Open("postgres", connStr+" "+replicaConnStr)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to connect to replica: %s", err)
+		}
+
+		db.replicas = append(db.replicas, replica)
+		replicas = append(replicas, replica)
+	}
+
+	db.Router = NewRouter(primary, replicas...)
+	db.CheckReplicas()
+
+	return db, primary.Ping()
+}
+
+// primaryConnectionString returns a connection string of the primary database.
+func primaryConnectionString(dbName string, opts ...*DatabaseOptions) string {
+	connStr := "sslmode=disable dbname=" + dbName
+
+	if len(opts) > 0 && opts[0] != nil {
+		connStr += " " + opts[0].ConnectionString()
+	}
+
+	return connStr
 }
@@ -0,0 +1,201 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Repository event actions
+const (
+	EventCreated  = "created"
+	EventDeleted  = "deleted"
+	EventUpdated  = "updated"
+	EventRestored = "restored"
+	EventPurged   = "purged"
+)
+
+// Sources of repository mutations
+const (
+	SourceAPI        = "api"
+	SourceWebhook    = "webhook"
+	SourceReconciler = "reconciler"
+	SourceImport     = "import"
+	SourceJob        = "job"
+	SourceRetention  = "retention"
+)
+
+// Change describes who caused a repository mutation and through which channel.
+type Change struct {
+	Actor     string
+	RequestID string
+	Source    string
+}
+
+// RepositoryEvent is an audit log entry for a single repository mutation.
+type RepositoryEvent struct {
+	ID           int64           `json:"id"`
+	RepositoryID int             `json:"repository_id"`
+	Provider     string          `json:"provider"`
+	Owner        string          `json:"owner"`
+	Name         string          `json:"name"`
+	Action       string          `json:"action"`
+	Actor        string          `json:"actor"`
+	RequestID    string          `json:"request_id"`
+	Source       string          `json:"source"`
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// GetRepositoryHistory returns audit log entries of repository with given full name in
+// chronological order. ErrRepositoryNotFound is returned if repository has neither been
+// tracked nor has any history.
+func GetRepositoryHistory(runner SQLRunner, fullName string) (events []RepositoryEvent, err error) {
+	provider, owner, name, err := parseFullName(fullName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if events, err = queryEvents(runner, GetRepositoryHistoryQuery, provider, owner, name); err != nil || len(events) > 0 {
+		return events, err
+	}
+
+	if _, err = GetRepositoryByFullName(runner, fullName, &ListOptions{IncludeDeleted: true}); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// GetEvents returns up to limit audit log entries created since given time across all owners.
+// Entries are ordered by ID, and after is the ID of the last entry of previous page.
+func GetEvents(runner SQLRunner, since time.Time, after int64, limit int) (events []RepositoryEvent, err error) {
+	return queryEvents(runner, GetEventsQuery, since, after, limit)
+}
+
+// GetLastEventID returns ID of the latest audit log entry or 0 if the log is empty.
+func GetLastEventID(runner SQLRunner) (id int64, err error) {
+	if err = runner.QueryRow(GetLastEventIDQuery).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to fetch repository events: %s", err)
+	}
+
+	return id, nil
+}
+
+func queryEvents(runner SQLRunner, query string, args ...interface{}) (events []RepositoryEvent, err error) {
+	rows, err := runner.Query(query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repository events: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			event         RepositoryEvent
+			before, after []byte
+		)
+
+		err := rows.Scan(
+			&event.ID, &event.RepositoryID, &event.Provider, &event.Owner, &event.Name, &event.Action,
+			&event.Actor, &event.RequestID, &event.Source, &before, &after, &event.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch repository events: %s", err)
+		}
+
+		event.Before, event.After = before, after
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch repository events: %s", err)
+	}
+
+	return events, nil
+}
+
+// recordEvent appends an audit log entry and puts it into the outbox to be published.
+// Mutations are expected to be run within a transaction so that the entry is written
+// atomically with the change itself.
+func recordEvent(runner SQLRunner, action string, before, after *Repository, changes ...*Change) error {
+	change := &Change{}
+	if len(changes) > 0 && changes[0] != nil {
+		change = changes[0]
+	}
+
+	repo := after
+	if repo == nil {
+		repo = before
+	}
+
+	beforeJSON, err := marshalSnapshot(before)
+	if err != nil {
+		return fmt.Errorf("failed to record repository event: %s", err)
+	}
+
+	afterJSON, err := marshalSnapshot(after)
+	if err != nil {
+		return fmt.Errorf("failed to record repository event: %s", err)
+	}
+
+	var id int64
+
+	err = runner.QueryRow(
+		CreateEventQuery,
+		repo.ID, repo.Provider, repo.Owner, repo.Name, action, change.Actor, change.RequestID, change.Source, beforeJSON, afterJSON,
+	).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("failed to record repository event: %s", err)
+	}
+
+	if _, err = runner.Exec(CreateOutboxEntryQuery, id); err != nil {
+		return fmt.Errorf("failed to record repository event: %s", err)
+	}
+
+	return nil
+}
+
+// marshalSnapshot returns JSON representation of repository state suitable
+// to be stored in a nullable JSONB column.
+func marshalSnapshot(repo *Repository) (interface{}, error) {
+	if repo == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return nullableJSON(b), nil
+}
+
+const eventColumns = `id, repository_id, provider, owner, name, action, actor, request_id, source, before, after, created_at`
+
+const (
+	GetRepositoryHistoryQuery = `SELECT ` + eventColumns + ` FROM repository_events WHERE provider=$1 AND lower(owner)=lower($2) AND lower(name)=lower($3) ORDER BY id`
+	GetEventsQuery            = `SELECT ` + eventColumns + ` FROM repository_events WHERE created_at >= $1 AND id > $2 ORDER BY id LIMIT $3`
+	GetLastEventIDQuery       = `SELECT COALESCE(max(id), 0) FROM repository_events`
+	CreateEventQuery          = `INSERT INTO repository_events (repository_id, provider, owner, name, action, actor, request_id, source, before, after) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+)
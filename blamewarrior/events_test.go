@@ -0,0 +1,94 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRepositoryHistory(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events CASCADE;")
+	require.NoError(t, err)
+
+	change := &blamewarrior.Change{Actor: "octocat", RequestID: "req-1", Source: blamewarrior.SourceAPI}
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos", Private: true}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo, change))
+	require.NoError(t, blamewarrior.DeleteRepository(db, repo.FullName(), change))
+	require.NoError(t, blamewarrior.RestoreRepository(db, repo.FullName()))
+
+	events, err := blamewarrior.GetRepositoryHistory(db, repo.FullName())
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	assert.Equal(t, blamewarrior.EventCreated, events[0].Action)
+	assert.Equal(t, "octocat", events[0].Actor)
+	assert.Equal(t, "req-1", events[0].RequestID)
+	assert.Equal(t, blamewarrior.SourceAPI, events[0].Source)
+	assert.Equal(t, repo.ID, events[0].RepositoryID)
+	assert.Equal(t, blamewarrior.ProviderGithub, events[0].Provider)
+	assert.Nil(t, events[0].Before)
+	assert.JSONEq(t, `{"full_name":"blamewarrior/repos","owner":"blamewarrior","name":"repos","private":true,"provider":"github","state":"active"}`, string(events[0].After))
+
+	assert.Equal(t, blamewarrior.EventDeleted, events[1].Action)
+	assert.JSONEq(t, string(events[0].After), string(events[1].Before))
+
+	assert.Equal(t, blamewarrior.EventRestored, events[2].Action)
+	assert.Empty(t, events[2].Actor)
+	assert.JSONEq(t, string(events[1].After), string(events[2].Before))
+
+	_, err = blamewarrior.GetRepositoryHistory(db, "blamewarrior/unknown")
+	assert.Equal(t, blamewarrior.ErrRepositoryNotFound, err)
+}
+
+func TestGetEvents(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events CASCADE;")
+	require.NoError(t, err)
+
+	for _, repo := range []*blamewarrior.Repository{
+		{Owner: "blamewarrior", Name: "repos"},
+		{Owner: "blamewarrior", Name: "hooks"},
+		{Owner: "octocat", Name: "hello-world"},
+	} {
+		require.NoError(t, blamewarrior.CreateRepository(db, repo))
+	}
+
+	page, err := blamewarrior.GetEvents(db, time.Time{}, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, "repos", page[0].Name)
+	assert.Equal(t, "hooks", page[1].Name)
+
+	page, err = blamewarrior.GetEvents(db, time.Time{}, page[1].ID, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "octocat", page[0].Owner)
+
+	page, err = blamewarrior.GetEvents(db, time.Now().Add(time.Hour), 0, 2)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+}
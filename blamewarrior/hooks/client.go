@@ -20,64 +20,89 @@
 package hooks
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/blamewarrior/repos/proto/hooksv1"
 )
 
+// requestTimeout limits the time a single call to hooks service may take
+const requestTimeout = 10 * time.Second
+
 type Client interface {
 	CreateHook(repositoryName string) error
 	DeleteHook(repositoryName string) error
+	UpdateHook(oldRepositoryName, newRepositoryName string) error
+	SetHookActive(repositoryName string, active bool) error
 }
 
+// HooksClient calls hooks service over gRPC using client generated from proto/hooks.proto.
 type HooksClient struct {
-	BaseURL string
-	c       *http.Client
+	api hooksv1.HookServiceClient
 }
 
 func (client *HooksClient) CreateHook(repositoryName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
 
-	payload := []byte(fmt.Sprintf(`{"full_name":"%s"}`, repositoryName))
+	if _, err := client.api.CreateHook(ctx, &hooksv1.CreateHookRequest{FullName: repositoryName}); err != nil {
+		return fmt.Errorf("Impossible to create hook for %s: %s", repositoryName, err)
+	}
 
-	response, err := client.c.Post(client.BaseURL+"/repositories", "application/json", bytes.NewBuffer(payload))
+	return nil
+}
 
-	if err != nil {
-		return err
-	}
+func (client *HooksClient) DeleteHook(repositoryName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
 
-	if response.StatusCode != http.StatusCreated {
-		return fmt.Errorf("Impossible to create hook for %s", repositoryName)
+	if _, err := client.api.DeleteHook(ctx, &hooksv1.DeleteHookRequest{FullName: repositoryName}); err != nil {
+		return fmt.Errorf("Impossible to delete hook for %s: %s", repositoryName, err)
 	}
 
 	return nil
-
 }
 
-func (client *HooksClient) DeleteHook(repositoryName string) error {
-	url := client.BaseURL + "/repositories/" + repositoryName
+func (client *HooksClient) UpdateHook(oldRepositoryName, newRepositoryName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
 
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return err
+	req := &hooksv1.UpdateHookRequest{FullName: oldRepositoryName, NewFullName: newRepositoryName}
+	if _, err := client.api.UpdateHook(ctx, req); err != nil {
+		return fmt.Errorf("Impossible to update hook for %s: %s", oldRepositoryName, err)
 	}
 
-	response, err := client.c.Do(req)
+	return nil
+}
 
-	if err != nil {
-		return err
-	}
+// SetHookActive enables or disables delivery of events from repository hook without removing it.
+func (client *HooksClient) SetHookActive(repositoryName string, active bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
 
-	if response.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("Impossible to delete hook for %s", repositoryName)
+	req := &hooksv1.SetHookActiveRequest{FullName: repositoryName, Active: active}
+	if _, err := client.api.SetHookActive(ctx, req); err != nil {
+		return fmt.Errorf("Impossible to update hook for %s: %s", repositoryName, err)
 	}
+
 	return nil
 }
 
-func NewHooksClient(baseURL string) *HooksClient {
-	client := &HooksClient{
-		BaseURL: baseURL,
-		c:       http.DefaultClient,
+// NewHooksClient returns client of hooks service listening at gRPC address addr. Connection is
+// established in background, so the service does not need to be up when client is created.
+func NewHooksClient(addr string) (*HooksClient, error) {
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to hooks service at %s: %s", addr, err)
 	}
 
-	return client
+	return NewHooksClientConn(conn), nil
+}
+
+// NewHooksClientConn returns client of hooks service using existing connection.
+func NewHooksClientConn(conn *grpc.ClientConn) *HooksClient {
+	return &HooksClient{api: hooksv1.NewHookServiceClient(conn)}
 }
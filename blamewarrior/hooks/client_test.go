@@ -20,74 +20,166 @@
 package hooks_test
 
 import (
+	"context"
 	"errors"
-	"net/http"
-	"net/http/httptest"
+	"net"
 	"testing"
+	"time"
 
-	"github.com/blamewarrior/repos/blamewarrior/hooks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/blamewarrior/repos/blamewarrior/hooks"
+	"github.com/blamewarrior/repos/proto/hooksv1"
 )
 
 func TestCreateHook(t *testing.T) {
-
 	results := []struct {
-		ResponseStatus int
-		ResponseError  error
+		ResponseError error
+		Err           error
 	}{
-		{ResponseStatus: http.StatusCreated, ResponseError: nil},
-		{ResponseStatus: http.StatusNotFound, ResponseError: errors.New("Impossible to create hook for blamewarrior/test_repo")},
+		{ResponseError: nil, Err: nil},
+		{
+			ResponseError: status.Error(codes.NotFound, "repository not found"),
+			Err:           errors.New("Impossible to create hook for blamewarrior/test_repo: rpc error: code = NotFound desc = repository not found"),
+		},
 	}
 
 	for _, result := range results {
-		testAPIEndpoint, mux, teardown := setup()
-
-		mux.HandleFunc("/repositories", func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(result.ResponseStatus)
-		})
-
-		client := hooks.NewHooksClient("http://test.blamewarrior.com/hooks")
-		client.BaseURL = testAPIEndpoint
+		service := new(hookServiceMock)
+		service.On("CreateHook", &hooksv1.CreateHookRequest{FullName: "blamewarrior/test_repo"}).Return(&hooksv1.Hook{}, result.ResponseError)
 
-		err := client.CreateHook("blamewarrior/test_repo")
+		client, teardown := setup(t, service)
 
-		assert.Equal(t, result.ResponseError, err)
+		assert.Equal(t, result.Err, client.CreateHook("blamewarrior/test_repo"))
+		service.AssertExpectations(t)
 
 		teardown()
 	}
 }
 
 func TestDeleteHook(t *testing.T) {
+	results := []struct {
+		ResponseError error
+		Err           error
+	}{
+		{ResponseError: nil, Err: nil},
+		{
+			ResponseError: status.Error(codes.NotFound, "repository not found"),
+			Err:           errors.New("Impossible to delete hook for blamewarrior/test_repo: rpc error: code = NotFound desc = repository not found"),
+		},
+	}
+
+	for _, result := range results {
+		service := new(hookServiceMock)
+		service.On("DeleteHook", &hooksv1.DeleteHookRequest{FullName: "blamewarrior/test_repo"}).Return(&hooksv1.DeleteHookResponse{}, result.ResponseError)
 
+		client, teardown := setup(t, service)
+
+		assert.Equal(t, result.Err, client.DeleteHook("blamewarrior/test_repo"))
+		service.AssertExpectations(t)
+
+		teardown()
+	}
+}
+
+func TestUpdateHook(t *testing.T) {
 	results := []struct {
-		ResponseStatus int
-		ResponseError  error
+		ResponseError error
+		Err           error
 	}{
-		{ResponseStatus: http.StatusNoContent, ResponseError: nil},
-		{ResponseStatus: http.StatusNotFound, ResponseError: errors.New("Impossible to delete hook for blamewarrior/test_repo")},
+		{ResponseError: nil, Err: nil},
+		{
+			ResponseError: status.Error(codes.Unavailable, "github is unavailable"),
+			Err:           errors.New("Impossible to update hook for blamewarrior/test_repo: rpc error: code = Unavailable desc = github is unavailable"),
+		},
 	}
 
 	for _, result := range results {
-		testAPIEndpoint, mux, teardown := setup()
+		service := new(hookServiceMock)
+		service.On("UpdateHook", &hooksv1.UpdateHookRequest{FullName: "blamewarrior/test_repo", NewFullName: "blamewarrior/repos"}).Return(&hooksv1.Hook{}, result.ResponseError)
 
-		mux.HandleFunc("/repositories/blamewarrior/test_repo", func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(result.ResponseStatus)
-		})
+		client, teardown := setup(t, service)
 
-		client := hooks.NewHooksClient("http://test.blamewarrior.com/hooks")
-		client.BaseURL = testAPIEndpoint
+		assert.Equal(t, result.Err, client.UpdateHook("blamewarrior/test_repo", "blamewarrior/repos"))
+		service.AssertExpectations(t)
 
-		err := client.DeleteHook("blamewarrior/test_repo")
+		teardown()
+	}
+}
 
-		assert.Equal(t, result.ResponseError, err)
+func TestSetHookActive(t *testing.T) {
+	results := []struct {
+		Active        bool
+		ResponseError error
+		Err           error
+	}{
+		{Active: true, ResponseError: nil, Err: nil},
+		{Active: false, ResponseError: nil, Err: nil},
+		{
+			Active:        false,
+			ResponseError: status.Error(codes.NotFound, "repository not found"),
+			Err:           errors.New("Impossible to update hook for blamewarrior/test_repo: rpc error: code = NotFound desc = repository not found"),
+		},
+	}
+
+	for _, result := range results {
+		service := new(hookServiceMock)
+		service.On("SetHookActive", &hooksv1.SetHookActiveRequest{FullName: "blamewarrior/test_repo", Active: result.Active}).Return(&hooksv1.Hook{}, result.ResponseError)
+
+		client, teardown := setup(t, service)
+
+		assert.Equal(t, result.Err, client.SetHookActive("blamewarrior/test_repo", result.Active))
+		service.AssertExpectations(t)
 
 		teardown()
 	}
 }
 
-func setup() (baseURL string, mux *http.ServeMux, teardown func()) {
-	mux = http.NewServeMux()
-	server := httptest.NewServer(mux)
+type hookServiceMock struct {
+	mock.Mock
+}
+
+func (m *hookServiceMock) CreateHook(ctx context.Context, req *hooksv1.CreateHookRequest) (*hooksv1.Hook, error) {
+	args := m.Called(req)
+	return args.Get(0).(*hooksv1.Hook), args.Error(1)
+}
+
+func (m *hookServiceMock) DeleteHook(ctx context.Context, req *hooksv1.DeleteHookRequest) (*hooksv1.DeleteHookResponse, error) {
+	args := m.Called(req)
+	return args.Get(0).(*hooksv1.DeleteHookResponse), args.Error(1)
+}
+
+func (m *hookServiceMock) UpdateHook(ctx context.Context, req *hooksv1.UpdateHookRequest) (*hooksv1.Hook, error) {
+	args := m.Called(req)
+	return args.Get(0).(*hooksv1.Hook), args.Error(1)
+}
+
+func (m *hookServiceMock) SetHookActive(ctx context.Context, req *hooksv1.SetHookActiveRequest) (*hooksv1.Hook, error) {
+	args := m.Called(req)
+	return args.Get(0).(*hooksv1.Hook), args.Error(1)
+}
 
-	return server.URL, mux, server.Close
+func setup(t *testing.T, service hooksv1.HookServiceServer) (client *hooks.HooksClient, teardown func()) {
+	listener := bufconn.Listen(1024 * 1024)
+
+	server := grpc.NewServer()
+	hooksv1.RegisterHookServiceServer(server, service)
+
+	go server.Serve(listener)
+
+	conn, err := grpc.Dial("bufconn", grpc.WithInsecure(), grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	require.NoError(t, err)
+
+	return hooks.NewHooksClientConn(conn), func() {
+		conn.Close()
+		server.Stop()
+	}
 }
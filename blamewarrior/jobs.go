@@ -0,0 +1,348 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Job states
+const (
+	// JobPending jobs wait for a worker, either for the first time or to be retried
+	JobPending = "pending"
+	// JobRunning jobs are leased by a worker until their lease expires
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	// JobDead jobs have failed all their attempts and are only run again if retried manually
+	JobDead      = "dead"
+	JobCancelled = "cancelled"
+)
+
+// DefaultJobMaxAttempts is the number of attempts made to run a job unless specified otherwise.
+const DefaultJobMaxAttempts = 5
+
+// errJobLeaseExpired is recorded as the last error of jobs that ran out of attempts because
+// their workers failed to finish them in time.
+var errJobLeaseExpired = errors.New("job lease expired")
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobState is returned when job can not be retried or cancelled in its current state
+	ErrJobState = errors.New("operation is not allowed in current job state")
+)
+
+// Job is a unit of background work processed by job workers.
+type Job struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+	// Key deduplicates jobs: a job is not enqueued while another job of the same type and key
+	// is pending or running
+	Key string `json:"key,omitempty"`
+	// Payload is the JSON document of job arguments that is passed to job handler
+	Payload     json.RawMessage `json:"payload"`
+	State       string          `json:"state"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	// LastError is the error returned by the latest failed attempt
+	LastError string `json:"last_error,omitempty"`
+	// RunAt is the time job is due to run at
+	RunAt time.Time `json:"run_at"`
+	// LockedUntil is the time the lease of running job expires at, after that it is
+	// picked up by another worker
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// NewJob returns pending job of given type with payload encoded as JSON.
+func NewJob(jobType string, payload interface{}) (*Job, error) {
+	doc, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s job payload: %s", jobType, err)
+	}
+
+	return &Job{Type: jobType, Payload: doc, State: JobPending, MaxAttempts: DefaultJobMaxAttempts}, nil
+}
+
+// DecodePayload unmarshals job payload into v.
+func (job *Job) DecodePayload(v interface{}) error {
+	if err := json.Unmarshal(job.Payload, v); err != nil {
+		return fmt.Errorf("malformed %s job payload: %s", job.Type, err)
+	}
+
+	return nil
+}
+
+// EnqueueJob stores pending job. Job is due immediately unless RunAt is set. If job has a key
+// and there is a pending or running job of the same type and key already, nothing is stored
+// and job is filled with the existing one instead.
+func EnqueueJob(runner SQLRunner, job *Job) error {
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = DefaultJobMaxAttempts
+	}
+
+	if len(job.Payload) == 0 {
+		job.Payload = json.RawMessage("{}")
+	}
+
+	var runAt interface{}
+	if !job.RunAt.IsZero() {
+		runAt = job.RunAt
+	}
+
+	// existing job that prevented insertion may not be visible to the statement yet if it has
+	// been enqueued concurrently, so the statement is repeated once
+	var err error
+	for i := 0; i < 2; i++ {
+		err = scanJob(runner.QueryRow(EnqueueJobQuery, job.Type, job.Key, string(job.Payload), job.MaxAttempts, runAt), job)
+		if err != sql.ErrNoRows {
+			break
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to enqueue job: %s", err)
+	}
+
+	return nil
+}
+
+// ExpireJobs moves jobs whose lease has expired by given time after they have used up all of
+// their attempts to dead jobs and returns them.
+func ExpireJobs(runner SQLRunner, now time.Time) (expired []Job, err error) {
+	rows, err := runner.Query(ExpireJobsQuery, errJobLeaseExpired.Error(), now)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire jobs: %s", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var job Job
+
+		if err = scanJob(rows, &job); err != nil {
+			return nil, fmt.Errorf("failed to expire jobs: %s", err)
+		}
+
+		expired = append(expired, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to expire jobs: %s", err)
+	}
+
+	return expired, nil
+}
+
+// ClaimJob leases the earliest job that is due at given time to the caller for lease duration
+// and counts the attempt. Jobs of workers that failed to finish them before their lease
+// expired are claimed again unless they have used up all of their attempts, such jobs are
+// left for ExpireJobs. Nil is returned if there are no due jobs.
+func ClaimJob(runner SQLRunner, now time.Time, lease time.Duration) (*Job, error) {
+	job := &Job{}
+
+	err := scanJob(runner.QueryRow(ClaimJobQuery, now, now.Add(lease)), job)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %s", err)
+	}
+
+	return job, nil
+}
+
+// CompleteJob marks running job succeeded. Jobs that have been cancelled meanwhile stay cancelled.
+func CompleteJob(runner SQLRunner, job *Job, now time.Time) error {
+	return finishJob(runner, job, CompleteJobQuery, now)
+}
+
+// FailJob records failed attempt of running job and reschedules it to retryAt, or moves it
+// to dead jobs if retryAt is zero.
+func FailJob(runner SQLRunner, job *Job, jobErr error, retryAt time.Time, now time.Time) error {
+	if retryAt.IsZero() {
+		return finishJob(runner, job, KillJobQuery, jobErr.Error(), now)
+	}
+
+	return finishJob(runner, job, RescheduleJobQuery, jobErr.Error(), retryAt)
+}
+
+// finishJob updates job claimed by the caller. Update only applies to the attempt the job has
+// been claimed for: attempts and lease expiration time are compared with the claimed ones,
+// so that outcome of an attempt whose lease expired does not override a later one.
+func finishJob(runner SQLRunner, job *Job, query string, args ...interface{}) error {
+	args = append([]interface{}{job.ID, job.Attempts, job.LockedUntil}, args...)
+
+	err := scanJob(runner.QueryRow(query, args...), job)
+
+	if err == sql.ErrNoRows {
+		// job has been cancelled or claimed by another worker while running
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update job: %s", err)
+	}
+
+	return nil
+}
+
+// GetJob returns job with given ID.
+func GetJob(runner SQLRunner, id int64) (*Job, error) {
+	job := &Job{}
+
+	err := scanJob(runner.QueryRow(GetJobQuery, id), job)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrJobNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job: %s", err)
+	}
+
+	return job, nil
+}
+
+// GetJobs returns up to limit jobs with IDs greater than after in ID order, optionally
+// narrowed down to jobs of given state and type.
+func GetJobs(runner SQLRunner, state, jobType string, after int64, limit int) (jobs []Job, err error) {
+	rows, err := runner.Query(GetJobsQuery, state, jobType, after, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jobs: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var job Job
+
+		if err := scanJob(rows, &job); err != nil {
+			return nil, fmt.Errorf("failed to fetch jobs: %s", err)
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch jobs: %s", err)
+	}
+
+	return jobs, nil
+}
+
+// RetryJob makes dead or cancelled job pending again with all of its attempts available.
+func RetryJob(runner SQLRunner, id int64) (*Job, error) {
+	return changeJobState(runner, RetryJobQuery, id)
+}
+
+// CancelJob stops pending or running job from being run again. Running attempt is not
+// interrupted, but its outcome is discarded.
+func CancelJob(runner SQLRunner, id int64) (*Job, error) {
+	return changeJobState(runner, CancelJobQuery, id)
+}
+
+func changeJobState(runner SQLRunner, query string, id int64) (*Job, error) {
+	job := &Job{}
+
+	err := scanJob(runner.QueryRow(query, id), job)
+
+	if err == sql.ErrNoRows {
+		if _, err = GetJob(runner, id); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrJobState
+	}
+
+	// job can not be retried while another job of the same key is pending
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return nil, ErrJobState
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to update job: %s", err)
+	}
+
+	return job, nil
+}
+
+func scanJob(row rowScanner, job *Job) error {
+	var (
+		key         sql.NullString
+		payload     []byte
+		lockedUntil pq.NullTime
+		finishedAt  pq.NullTime
+	)
+
+	err := row.Scan(
+		&job.ID, &job.Type, &key, &payload, &job.State, &job.Attempts, &job.MaxAttempts, &job.LastError,
+		&job.RunAt, &lockedUntil, &job.CreatedAt, &finishedAt,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	job.Key, job.Payload = key.String, payload
+	job.LockedUntil, job.FinishedAt = nil, nil
+
+	if lockedUntil.Valid {
+		job.LockedUntil = &lockedUntil.Time
+	}
+
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+
+	return nil
+}
+
+const jobColumns = `id, type, key, payload, state, attempts, max_attempts, last_error, run_at, locked_until, created_at, finished_at`
+
+const (
+	EnqueueJobQuery = `WITH inserted AS (
+		INSERT INTO jobs (type, key, payload, max_attempts, run_at) VALUES ($1, NULLIF($2, ''), $3, $4, COALESCE($5::timestamptz, now()))
+		ON CONFLICT (type, key) WHERE state IN ('pending', 'running') DO NOTHING
+		RETURNING ` + jobColumns + `
+	)
+	SELECT ` + jobColumns + ` FROM inserted
+	UNION ALL
+	SELECT ` + jobColumns + ` FROM jobs WHERE type=$1 AND key=NULLIF($2, '') AND state IN ('pending', 'running')
+	LIMIT 1`
+	ClaimJobQuery = `UPDATE jobs SET state='running', attempts=attempts+1, locked_until=$2 WHERE id = (
+		SELECT id FROM jobs
+		WHERE (state='pending' AND run_at <= $1) OR (state='running' AND locked_until <= $1 AND attempts < max_attempts)
+		ORDER BY run_at, id LIMIT 1 FOR UPDATE SKIP LOCKED
+	) RETURNING ` + jobColumns
+	ExpireJobsQuery    = `UPDATE jobs SET state='dead', locked_until=NULL, last_error=$1, finished_at=$2 WHERE state='running' AND locked_until <= $2 AND attempts >= max_attempts RETURNING ` + jobColumns
+	CompleteJobQuery   = `UPDATE jobs SET state='succeeded', locked_until=NULL, finished_at=$4 WHERE id=$1 AND state='running' AND attempts=$2 AND locked_until=$3 RETURNING ` + jobColumns
+	RescheduleJobQuery = `UPDATE jobs SET state='pending', locked_until=NULL, last_error=$4, run_at=$5 WHERE id=$1 AND state='running' AND attempts=$2 AND locked_until=$3 RETURNING ` + jobColumns
+	KillJobQuery       = `UPDATE jobs SET state='dead', locked_until=NULL, last_error=$4, finished_at=$5 WHERE id=$1 AND state='running' AND attempts=$2 AND locked_until=$3 RETURNING ` + jobColumns
+	GetJobQuery        = `SELECT ` + jobColumns + ` FROM jobs WHERE id=$1`
+	GetJobsQuery       = `SELECT ` + jobColumns + ` FROM jobs WHERE ($1 = '' OR state=$1) AND ($2 = '' OR type=$2) AND id > $3 ORDER BY id LIMIT $4`
+	RetryJobQuery      = `UPDATE jobs SET state='pending', attempts=0, run_at=now(), finished_at=NULL WHERE id=$1 AND state IN ('dead', 'cancelled') RETURNING ` + jobColumns
+	CancelJobQuery     = `UPDATE jobs SET state='cancelled', locked_until=NULL, finished_at=now() WHERE id=$1 AND state IN ('pending', 'running') RETURNING ` + jobColumns
+)
@@ -0,0 +1,90 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// InProcess runs jobs synchronously as they are enqueued without storing them in the
+// database. Failed attempts are retried right away. It is meant to be used in tests.
+type InProcess struct {
+	// Dead are handlers called by job type once job has been moved to dead jobs
+	Dead map[string]DeadHandler
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	jobs     []blamewarrior.Job
+}
+
+func NewInProcess(handlers map[string]Handler) *InProcess {
+	return &InProcess{handlers: handlers}
+}
+
+// Enqueue runs job until it succeeds or runs out of attempts. Failure of job is not returned,
+// the outcome is recorded in job instead.
+func (r *InProcess) Enqueue(ctx context.Context, job *blamewarrior.Job) error {
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = blamewarrior.DefaultJobMaxAttempts
+	}
+
+	r.mu.Lock()
+	job.ID = int64(len(r.jobs) + 1)
+	r.jobs = append(r.jobs, *job)
+	r.mu.Unlock()
+
+	now := time.Now()
+	job.CreatedAt, job.RunAt = now, now
+
+	for job.State = blamewarrior.JobRunning; job.State == blamewarrior.JobRunning; {
+		job.Attempts++
+
+		err := run(ctx, r.handlers, job)
+		switch {
+		case err == nil:
+			job.State = blamewarrior.JobSucceeded
+		case job.Attempts >= job.MaxAttempts || IsPermanent(err):
+			job.State, job.LastError = blamewarrior.JobDead, err.Error()
+		default:
+			job.LastError = err.Error()
+		}
+	}
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+
+	if job.State == blamewarrior.JobDead {
+		dead(ctx, r.Dead, job)
+	}
+
+	r.mu.Lock()
+	r.jobs[job.ID-1] = *job
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Jobs returns jobs that have been enqueued so far in their order.
+func (r *InProcess) Jobs() []blamewarrior.Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]blamewarrior.Job(nil), r.jobs...)
+}
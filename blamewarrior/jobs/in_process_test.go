@@ -0,0 +1,106 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/jobs"
+)
+
+func TestInProcess(t *testing.T) {
+	var attempts int
+
+	runner := jobs.NewInProcess(map[string]jobs.Handler{
+		"succeed": func(ctx context.Context, job *blamewarrior.Job) error {
+			var payload struct{ Name string }
+			if err := job.DecodePayload(&payload); err != nil {
+				return jobs.Permanent(err)
+			}
+
+			assert.Equal(t, "repos", payload.Name)
+
+			return nil
+		},
+		"flaky": func(ctx context.Context, job *blamewarrior.Job) error {
+			if attempts++; attempts < 3 {
+				return errors.New("temporary failure")
+			}
+
+			return nil
+		},
+		"fail": func(ctx context.Context, job *blamewarrior.Job) error {
+			return errors.New("failure")
+		},
+		"reject": func(ctx context.Context, job *blamewarrior.Job) error {
+			return jobs.Permanent(errors.New("rejected"))
+		},
+		"panic": func(ctx context.Context, job *blamewarrior.Job) error {
+			panic("boom")
+		},
+	})
+
+	var dead []string
+
+	cleanUp := func(ctx context.Context, job *blamewarrior.Job) error {
+		dead = append(dead, job.Type)
+		return nil
+	}
+
+	runner.Dead = map[string]jobs.DeadHandler{"succeed": cleanUp, "fail": cleanUp, "reject": cleanUp, "panic": cleanUp}
+
+	examples := []struct {
+		Type      string
+		State     string
+		Attempts  int
+		LastError string
+	}{
+		{"succeed", blamewarrior.JobSucceeded, 1, ""},
+		{"flaky", blamewarrior.JobSucceeded, 3, "temporary failure"},
+		{"fail", blamewarrior.JobDead, blamewarrior.DefaultJobMaxAttempts, "failure"},
+		{"reject", blamewarrior.JobDead, 1, "rejected"},
+		{"panic", blamewarrior.JobDead, blamewarrior.DefaultJobMaxAttempts, "panic job handler panicked: boom"},
+		{"unknown", blamewarrior.JobDead, 1, "no handler registered for unknown jobs"},
+	}
+
+	for _, example := range examples {
+		job, err := blamewarrior.NewJob(example.Type, map[string]string{"name": "repos"})
+		require.NoError(t, err)
+
+		require.NoError(t, runner.Enqueue(context.Background(), job))
+
+		assert.Equal(t, example.State, job.State, example.Type)
+		assert.Equal(t, example.Attempts, job.Attempts, example.Type)
+		assert.Equal(t, example.LastError, job.LastError, example.Type)
+		assert.NotNil(t, job.FinishedAt, example.Type)
+	}
+
+	enqueued := runner.Jobs()
+	require.Len(t, enqueued, len(examples))
+
+	for i, job := range enqueued {
+		assert.Equal(t, int64(i+1), job.ID)
+		assert.Equal(t, examples[i].State, job.State)
+	}
+
+	assert.Equal(t, []string{"fail", "reject", "panic"}, dead)
+}
@@ -0,0 +1,100 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package jobs runs background jobs stored in the jobs table by handlers registered for
+// their types.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+const (
+	initialBackoff = 10 * time.Second
+	maxBackoff     = time.Hour
+)
+
+// Handler runs a single attempt of job. Failed attempts are retried unless handler returns
+// error wrapped with Permanent.
+type Handler func(ctx context.Context, job *blamewarrior.Job) error
+
+// DeadHandler cleans up after job that has been moved to dead jobs, either because it has
+// used up all of its attempts or because it failed permanently.
+type DeadHandler func(ctx context.Context, job *blamewarrior.Job) error
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Permanent marks err as not worth retrying, job that failed with it is moved to dead jobs
+// right away.
+func Permanent(err error) error {
+	return permanentError{err}
+}
+
+// IsPermanent reports whether err has been returned by Permanent.
+func IsPermanent(err error) bool {
+	_, ok := err.(permanentError)
+	return ok
+}
+
+// Backoff returns the delay before the next attempt of job after given number of attempts.
+func Backoff(attempts int) time.Duration {
+	return blamewarrior.ExponentialBackoff(attempts, initialBackoff, maxBackoff)
+}
+
+// run passes job to its handler recovering from panics.
+func run(ctx context.Context, handlers map[string]Handler, job *blamewarrior.Job) (err error) {
+	handler, ok := handlers[job.Type]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler registered for %s jobs", job.Type))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s job handler panicked: %v", job.Type, r)
+		}
+	}()
+
+	return handler(ctx, job)
+}
+
+// dead passes job that has been moved to dead jobs to its dead handler if there is one. Failure
+// is only logged since dead job is not run again.
+func dead(ctx context.Context, handlers map[string]DeadHandler, job *blamewarrior.Job) {
+	handler, ok := handlers[job.Type]
+	if !ok {
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("%s dead job handler panicked: %v", job.Type, r)
+		}
+	}()
+
+	if err := handler(ctx, job); err != nil {
+		log.Printf("failed to clean up after dead %s job %d: %s", job.Type, job.ID, err)
+	}
+}
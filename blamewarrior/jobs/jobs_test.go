@@ -0,0 +1,51 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package jobs_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/blamewarrior/repos/blamewarrior/jobs"
+)
+
+func TestBackoff(t *testing.T) {
+	examples := []struct {
+		Attempts int
+		Expected time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{4, 80 * time.Second},
+		{10, time.Hour},
+		{100, time.Hour},
+	}
+
+	for _, example := range examples {
+		assert.Equal(t, example.Expected, jobs.Backoff(example.Attempts), "attempts: %d", example.Attempts)
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	err := errors.New("malformed payload")
+
+	assert.True(t, jobs.IsPermanent(jobs.Permanent(err)))
+	assert.Equal(t, err.Error(), jobs.Permanent(err).Error())
+	assert.False(t, jobs.IsPermanent(err))
+}
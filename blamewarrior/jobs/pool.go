@@ -0,0 +1,151 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+const (
+	DefaultWorkers      = 4
+	DefaultPollInterval = time.Second
+	DefaultLease        = 5 * time.Minute
+)
+
+// Pool is a number of workers that run jobs from the jobs table. Workers of several pools
+// may share the same table, each job is leased to one of them at a time.
+type Pool struct {
+	// Workers is the number of jobs run concurrently
+	Workers int
+	// PollInterval is the delay before a worker checks for due jobs again after it found none
+	PollInterval time.Duration
+	// Lease is the time given to handler to run a job, after that handler context is cancelled
+	// and job is claimed by another worker
+	Lease time.Duration
+	// Backoff returns the delay before the next attempt of failed job
+	Backoff func(attempts int) time.Duration
+	// Dead are handlers called by job type once job has been moved to dead jobs
+	Dead map[string]DeadHandler
+
+	db       blamewarrior.SQLRunner
+	handlers map[string]Handler
+}
+
+func NewPool(db blamewarrior.SQLRunner, handlers map[string]Handler) *Pool {
+	return &Pool{
+		Workers:      DefaultWorkers,
+		PollInterval: DefaultPollInterval,
+		Lease:        DefaultLease,
+		Backoff:      Backoff,
+		db:           db,
+		handlers:     handlers,
+	}
+}
+
+// Enqueue stores job to be run by one of the workers.
+func (pool *Pool) Enqueue(ctx context.Context, job *blamewarrior.Job) error {
+	return blamewarrior.EnqueueJob(pool.db, job)
+}
+
+// Run starts workers and blocks until ctx is cancelled and workers finish their current jobs.
+func (pool *Pool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < pool.Workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			pool.work(ctx)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (pool *Pool) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		ran, err := pool.RunOnce(ctx)
+		if err != nil {
+			log.Printf("failed to run job: %s", err)
+		}
+
+		if ran && err == nil {
+			continue
+		}
+
+		select {
+		case <-time.After(pool.PollInterval):
+		case <-ctx.Done():
+		}
+	}
+}
+
+// RunOnce claims a single due job and runs it. It reports whether there was a job to run.
+// Jobs of workers that have not finished their last attempt in time are moved to dead jobs
+// beforehand.
+func (pool *Pool) RunOnce(ctx context.Context) (bool, error) {
+	expired, err := blamewarrior.ExpireJobs(pool.db, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	for i := range expired {
+		dead(ctx, pool.Dead, &expired[i])
+	}
+
+	job, err := blamewarrior.ClaimJob(pool.db, time.Now(), pool.Lease)
+	if err != nil {
+		return false, err
+	}
+
+	if job == nil {
+		return false, nil
+	}
+
+	jobCtx, cancel := context.WithTimeout(ctx, pool.Lease)
+	jobErr := run(jobCtx, pool.handlers, job)
+	cancel()
+
+	now := time.Now()
+
+	if jobErr == nil {
+		return true, blamewarrior.CompleteJob(pool.db, job, now)
+	}
+
+	log.Printf("%s job %d failed on attempt %d of %d: %s", job.Type, job.ID, job.Attempts, job.MaxAttempts, jobErr)
+
+	var retryAt time.Time
+	if job.Attempts < job.MaxAttempts && !IsPermanent(jobErr) {
+		retryAt = now.Add(pool.Backoff(job.Attempts))
+	}
+
+	if err = blamewarrior.FailJob(pool.db, job, jobErr, retryAt, now); err != nil {
+		return true, err
+	}
+
+	// job is left running if it has been cancelled or claimed by another worker meanwhile
+	if job.State == blamewarrior.JobDead {
+		dead(ctx, pool.Dead, job)
+	}
+
+	return true, nil
+}
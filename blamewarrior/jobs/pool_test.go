@@ -0,0 +1,127 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package jobs_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/jobs"
+)
+
+func TestPool_RunOnce(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE jobs;")
+	require.NoError(t, err)
+
+	var ran []string
+
+	pool := jobs.NewPool(db, map[string]jobs.Handler{
+		"succeed": func(ctx context.Context, job *blamewarrior.Job) error {
+			ran = append(ran, job.Type)
+			return nil
+		},
+		"fail": func(ctx context.Context, job *blamewarrior.Job) error {
+			ran = append(ran, job.Type)
+			return errors.New("failure")
+		},
+	})
+	pool.Backoff = func(int) time.Duration { return -time.Second }
+
+	var dead []int
+
+	pool.Dead = map[string]jobs.DeadHandler{
+		"fail": func(ctx context.Context, job *blamewarrior.Job) error {
+			dead = append(dead, job.Attempts)
+			return nil
+		},
+	}
+
+	for _, jobType := range []string{"succeed", "fail"} {
+		job, err := blamewarrior.NewJob(jobType, nil)
+		require.NoError(t, err)
+
+		job.MaxAttempts = 2
+		require.NoError(t, pool.Enqueue(context.Background(), job))
+	}
+
+	for i := 0; i < 3; i++ {
+		ok, err := pool.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	ok, err := pool.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.False(t, ok, "dead job should not be run again")
+
+	assert.Equal(t, []string{"succeed", "fail", "fail"}, ran)
+	assert.Equal(t, []int{2}, dead, "dead handler should be called once job used up its attempts")
+
+	results, err := blamewarrior.GetJobs(db, "", "", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, blamewarrior.JobSucceeded, results[0].State)
+	assert.Equal(t, 1, results[0].Attempts)
+
+	assert.Equal(t, blamewarrior.JobDead, results[1].State)
+	assert.Equal(t, 2, results[1].Attempts)
+	assert.Equal(t, "failure", results[1].LastError)
+}
+
+func setup() (tx *sql.Tx, teardownFn func()) {
+	dbName := os.Getenv("DB_NAME")
+	if dbName == "" {
+		log.Fatal("missing test database name (expected to be passed via ENV['DB_NAME'])")
+	}
+
+	opts := &blamewarrior.DatabaseOptions{
+		Host:     os.Getenv("DB_HOST"),
+		Port:     os.Getenv("DB_PORT"),
+		User:     os.Getenv("DB_USER"),
+		Password: os.Getenv("DB_PASSWORD"),
+	}
+
+	db, err := blamewarrior.ConnectDatabase(dbName, opts)
+	if err != nil {
+		log.Fatalf("failed to establish connection with test db %s using connection string %s: %s", dbName, opts.ConnectionString(), err)
+	}
+
+	tx, err = db.Begin()
+
+	if err != nil {
+		log.Fatalf("failed to create transaction, %s", err)
+	}
+
+	return tx, func() {
+		tx.Rollback()
+		if err := db.Close(); err != nil {
+			log.Printf("failed to close database connection: %s", err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimJob(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE jobs;")
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	later, err := blamewarrior.NewJob("later", nil)
+	require.NoError(t, err)
+
+	later.RunAt = now.Add(time.Hour)
+	require.NoError(t, blamewarrior.EnqueueJob(db, later))
+
+	job, err := blamewarrior.NewJob("reconcile_repository", map[string]string{"repository": "blamewarrior/repos"})
+	require.NoError(t, err)
+	require.NoError(t, blamewarrior.EnqueueJob(db, job))
+
+	assert.Equal(t, blamewarrior.JobPending, job.State)
+	assert.Equal(t, blamewarrior.DefaultJobMaxAttempts, job.MaxAttempts)
+
+	expired, err := blamewarrior.ClaimJob(db, now, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, expired)
+
+	assert.Equal(t, job.ID, expired.ID)
+	assert.Equal(t, blamewarrior.JobRunning, expired.State)
+	assert.Equal(t, 1, expired.Attempts)
+	assert.JSONEq(t, `{"repository": "blamewarrior/repos"}`, string(expired.Payload))
+	require.NotNil(t, expired.LockedUntil)
+
+	claimed, err := blamewarrior.ClaimJob(db, now, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, claimed, "leased job should not be claimed again until its lease expires")
+
+	claimed, err = blamewarrior.ClaimJob(db, now.Add(2*time.Minute), time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, job.ID, claimed.ID)
+	assert.Equal(t, 2, claimed.Attempts)
+
+	// outcome of the attempt whose lease has expired is discarded
+	require.NoError(t, blamewarrior.FailJob(db, expired, errors.New("timeout"), time.Time{}, now))
+	assert.Equal(t, blamewarrior.JobRunning, expired.State)
+
+	stored, err := blamewarrior.GetJob(db, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.JobRunning, stored.State)
+	assert.Equal(t, 2, stored.Attempts)
+
+	require.NoError(t, blamewarrior.CompleteJob(db, claimed, now))
+	assert.Equal(t, blamewarrior.JobSucceeded, claimed.State)
+	assert.Nil(t, claimed.LockedUntil)
+	assert.NotNil(t, claimed.FinishedAt)
+}
+
+func TestFailJob(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE jobs;")
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	job := &blamewarrior.Job{Type: "create_hook", MaxAttempts: 2}
+	require.NoError(t, blamewarrior.EnqueueJob(db, job))
+
+	claimed, err := blamewarrior.ClaimJob(db, now, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+
+	require.NoError(t, blamewarrior.FailJob(db, claimed, errors.New("hooks service unavailable"), now.Add(time.Minute), now))
+	assert.Equal(t, blamewarrior.JobPending, claimed.State)
+	assert.Equal(t, "hooks service unavailable", claimed.LastError)
+
+	claimed, err = blamewarrior.ClaimJob(db, now, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, claimed, "failed job should not be claimed before retry time")
+
+	claimed, err = blamewarrior.ClaimJob(db, now.Add(time.Minute), time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+
+	require.NoError(t, blamewarrior.FailJob(db, claimed, errors.New("hook already exists"), time.Time{}, now))
+	assert.Equal(t, blamewarrior.JobDead, claimed.State)
+	assert.Equal(t, 2, claimed.Attempts)
+
+	results, err := blamewarrior.GetJobs(db, blamewarrior.JobDead, "", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "hook already exists", results[0].LastError)
+
+	results, err = blamewarrior.GetJobs(db, "", "reconcile_repository", 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestEnqueueJob_Key(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE jobs;")
+	require.NoError(t, err)
+
+	first := &blamewarrior.Job{Type: "reconcile_repository", Key: "blamewarrior/repos"}
+	require.NoError(t, blamewarrior.EnqueueJob(db, first))
+
+	duplicate := &blamewarrior.Job{Type: "reconcile_repository", Key: "blamewarrior/repos"}
+	require.NoError(t, blamewarrior.EnqueueJob(db, duplicate))
+	assert.Equal(t, first.ID, duplicate.ID, "pending job of the same key should be returned instead")
+
+	other := &blamewarrior.Job{Type: "create_hook", Key: "blamewarrior/repos"}
+	require.NoError(t, blamewarrior.EnqueueJob(db, other))
+	assert.NotEqual(t, first.ID, other.ID)
+
+	unkeyed := []*blamewarrior.Job{{Type: "reconcile_repository"}, {Type: "reconcile_repository"}}
+	for _, job := range unkeyed {
+		require.NoError(t, blamewarrior.EnqueueJob(db, job))
+		assert.Empty(t, job.Key)
+	}
+	assert.NotEqual(t, unkeyed[0].ID, unkeyed[1].ID)
+
+	claimed, err := blamewarrior.ClaimJob(db, time.Now(), time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, first.ID, claimed.ID)
+
+	require.NoError(t, blamewarrior.CompleteJob(db, claimed, time.Now()))
+
+	next := &blamewarrior.Job{Type: "reconcile_repository", Key: "blamewarrior/repos"}
+	require.NoError(t, blamewarrior.EnqueueJob(db, next))
+	assert.NotEqual(t, first.ID, next.ID, "job should be enqueued again once the previous one finished")
+}
+
+func TestClaimJob_LeaseExpired(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE jobs;")
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	job := &blamewarrior.Job{Type: "create_hook", MaxAttempts: 1}
+	require.NoError(t, blamewarrior.EnqueueJob(db, job))
+
+	claimed, err := blamewarrior.ClaimJob(db, now, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+
+	claimed, err = blamewarrior.ClaimJob(db, now.Add(2*time.Minute), time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, claimed, "job that has used up its attempts should not be claimed again")
+
+	expired, err := blamewarrior.ExpireJobs(db, now)
+	require.NoError(t, err)
+	assert.Empty(t, expired, "job should not be expired before its lease expires")
+
+	expired, err = blamewarrior.ExpireJobs(db, now.Add(2*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, job.ID, expired[0].ID)
+
+	stored, err := blamewarrior.GetJob(db, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.JobDead, stored.State)
+	assert.Equal(t, 1, stored.Attempts)
+	assert.Equal(t, "job lease expired", stored.LastError)
+	assert.Nil(t, stored.LockedUntil)
+	assert.NotNil(t, stored.FinishedAt)
+}
+
+func TestRetryAndCancelJob(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE jobs;")
+	require.NoError(t, err)
+
+	job := &blamewarrior.Job{Type: "create_hook"}
+	require.NoError(t, blamewarrior.EnqueueJob(db, job))
+
+	_, err = blamewarrior.RetryJob(db, job.ID)
+	assert.Equal(t, blamewarrior.ErrJobState, err)
+
+	cancelled, err := blamewarrior.CancelJob(db, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.JobCancelled, cancelled.State)
+
+	_, err = blamewarrior.CancelJob(db, job.ID)
+	assert.Equal(t, blamewarrior.ErrJobState, err)
+
+	claimed, err := blamewarrior.ClaimJob(db, time.Now(), time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, claimed, "cancelled job should not be claimed")
+
+	retried, err := blamewarrior.RetryJob(db, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.JobPending, retried.State)
+	assert.Equal(t, 0, retried.Attempts)
+	assert.Nil(t, retried.FinishedAt)
+
+	_, err = blamewarrior.CancelJob(db, job.ID+1)
+	assert.Equal(t, blamewarrior.ErrJobNotFound, err)
+
+	_, err = blamewarrior.GetJob(db, job.ID+1)
+	assert.Equal(t, blamewarrior.ErrJobNotFound, err)
+}
@@ -0,0 +1,190 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// JSONSchema is a JSON Schema document. Only a subset of draft 4 keywords is supported:
+// type, enum, properties, required, additionalProperties, items, minimum, maximum,
+// minLength, maxLength, maxItems and pattern.
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+}
+
+// ParseJSONSchema parses JSON Schema document.
+func ParseJSONSchema(doc string) (*JSONSchema, error) {
+	schema := &JSONSchema{}
+	if err := json.Unmarshal([]byte(doc), schema); err != nil {
+		return nil, fmt.Errorf("malformed JSON schema: %s", err)
+	}
+
+	return schema, nil
+}
+
+// Validate checks that JSON document conforms the schema.
+func (schema *JSONSchema) Validate(doc []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(doc, &v); err != nil {
+		return fmt.Errorf("malformed JSON: %s", err)
+	}
+
+	return schema.validate("", v)
+}
+
+func (schema *JSONSchema) validate(path string, v interface{}) error {
+	if schema.Type != "" && !isOfJSONType(v, schema.Type) {
+		return fmt.Errorf("%s must be %s", pathName(path), schema.Type)
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, v) {
+		return fmt.Errorf("%s has unexpected value", pathName(path))
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s is required", pathName(path+"."+name))
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			property, ok := schema.Properties[k]
+			if !ok {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					return fmt.Errorf("%s is not allowed", pathName(path+"."+k))
+				}
+				continue
+			}
+
+			if err := property.validate(path+"."+k, v[k]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if schema.MaxItems != nil && len(v) > *schema.MaxItems {
+			return fmt.Errorf("%s must contain at most %d items", pathName(path), *schema.MaxItems)
+		}
+
+		if schema.Items != nil {
+			for i, item := range v {
+				if err := schema.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	case float64:
+		if schema.Minimum != nil && v < *schema.Minimum {
+			return fmt.Errorf("%s must be greater than or equal to %v", pathName(path), *schema.Minimum)
+		}
+
+		if schema.Maximum != nil && v > *schema.Maximum {
+			return fmt.Errorf("%s must be less than or equal to %v", pathName(path), *schema.Maximum)
+		}
+	case string:
+		if schema.MinLength != nil && utf8.RuneCountInString(v) < *schema.MinLength {
+			return fmt.Errorf("%s must be at least %d characters long", pathName(path), *schema.MinLength)
+		}
+
+		if schema.MaxLength != nil && utf8.RuneCountInString(v) > *schema.MaxLength {
+			return fmt.Errorf("%s must be at most %d characters long", pathName(path), *schema.MaxLength)
+		}
+
+		if schema.Pattern != "" {
+			matched, err := regexp.MatchString(schema.Pattern, v)
+			if err != nil {
+				return fmt.Errorf("malformed pattern for %s: %s", pathName(path), err)
+			}
+
+			if !matched {
+				return fmt.Errorf("%s does not match %s", pathName(path), schema.Pattern)
+			}
+		}
+	}
+
+	return nil
+}
+
+func isOfJSONType(v interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		n, ok := v.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	}
+
+	return false
+}
+
+func enumContains(enum []interface{}, v interface{}) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func pathName(path string) string {
+	if path == "" {
+		return "document"
+	}
+
+	return strings.TrimPrefix(path, ".")
+}
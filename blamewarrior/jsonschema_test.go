@@ -0,0 +1,68 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema_Validate(t *testing.T) {
+	schema, err := blamewarrior.ParseJSONSchema(`{
+		"type": "object",
+		"required": ["name"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string", "minLength": 1, "maxLength": 8, "pattern": "^[a-z]+$"},
+			"count": {"type": "integer", "minimum": 1, "maximum": 3},
+			"kind": {"enum": ["a", "b"]},
+			"tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}},
+			"nested": {"type": "object", "properties": {"flag": {"type": "boolean"}}}
+		}
+	}`)
+	require.NoError(t, err)
+
+	examples := map[string]struct {
+		Doc string
+		Err error
+	}{
+		"valid":               {`{"name":"repos","count":2,"kind":"a","tags":["x"],"nested":{"flag":true,"other":1}}`, nil},
+		"not an object":       {`[]`, errors.New("document must be object")},
+		"missing required":    {`{"count":1}`, errors.New("name is required")},
+		"additional property": {`{"name":"repos","extra":1}`, errors.New("extra is not allowed")},
+		"wrong type":          {`{"name":1}`, errors.New("name must be string")},
+		"too short":           {`{"name":""}`, errors.New("name must be at least 1 characters long")},
+		"too long":            {`{"name":"repositories"}`, errors.New("name must be at most 8 characters long")},
+		"pattern mismatch":    {`{"name":"Repos"}`, errors.New("name does not match ^[a-z]+$")},
+		"not an integer":      {`{"name":"repos","count":1.5}`, errors.New("count must be integer")},
+		"below minimum":       {`{"name":"repos","count":0}`, errors.New("count must be greater than or equal to 1")},
+		"above maximum":       {`{"name":"repos","count":4}`, errors.New("count must be less than or equal to 3")},
+		"not in enum":         {`{"name":"repos","kind":"c"}`, errors.New("kind has unexpected value")},
+		"too many items":      {`{"name":"repos","tags":["a","b","c"]}`, errors.New("tags must contain at most 2 items")},
+		"wrong item type":     {`{"name":"repos","tags":["a",1]}`, errors.New("tags[1] must be string")},
+		"nested wrong type":   {`{"name":"repos","nested":{"flag":"yes"}}`, errors.New("nested.flag must be boolean")},
+	}
+
+	for name, example := range examples {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, example.Err, schema.Validate([]byte(example.Doc)))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// MaxLookupItems is the number of repositories that can be looked up at once.
+const MaxLookupItems = 100
+
+// LookupResult is the outcome of looking up a single repository either by its full name or
+// by its ID at provider.
+type LookupResult struct {
+	FullName string `json:"full_name,omitempty"`
+	ID       int64  `json:"id,omitempty"`
+	// Found is false if repository is not tracked, in which case Repository is nil
+	Found      bool        `json:"found"`
+	Repository *Repository `json:"repository,omitempty"`
+}
+
+// LookupRepositories returns tracked repositories with given full or qualified names and
+// IDs at provider using a single query. Results follow the order of full names and then IDs,
+// repositories that are not tracked are marked as not found. IDs are only known for
+// repositories which metadata has been fetched.
+func LookupRepositories(runner SQLRunner, provider string, fullNames []string, ids []int64) ([]LookupResult, error) {
+	var providers, owners, names []string
+
+	for _, fullName := range fullNames {
+		p, owner, name, err := parseFullName(fullName)
+		if err != nil {
+			return nil, err
+		}
+
+		providers, owners, names = append(providers, p), append(owners, owner), append(names, name)
+	}
+
+	results := make([]LookupResult, 0, len(fullNames)+len(ids))
+	if len(fullNames) == 0 && len(ids) == 0 {
+		return results, nil
+	}
+
+	repositories, err := queryRepositories(runner, LookupRepositoriesQuery, pq.Array(providers), pq.Array(owners), pq.Array(names), provider, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repositories: %s", err)
+	}
+
+	byName, byID := make(map[string]*Repository), make(map[int64]*Repository)
+	for i := range repositories {
+		repo := &repositories[i]
+		byName[lookupKey(repo.Provider, repo.Owner, repo.Name)] = repo
+
+		if repo.Provider == provider && repo.Metadata != nil {
+			byID[repo.Metadata.ProviderID] = repo
+		}
+	}
+
+	for i, fullName := range fullNames {
+		repo := byName[lookupKey(providers[i], owners[i], names[i])]
+		results = append(results, LookupResult{FullName: fullName, Found: repo != nil, Repository: repo})
+	}
+
+	for _, id := range ids {
+		repo := byID[id]
+		results = append(results, LookupResult{ID: id, Found: repo != nil, Repository: repo})
+	}
+
+	return results, nil
+}
+
+// lookupKey identifies repository the same way database does, i.e. owner and name are
+// case-insensitive.
+func lookupKey(provider, owner, name string) string {
+	return provider + ":" + strings.ToLower(owner) + "/" + strings.ToLower(name)
+}
+
+const LookupRepositoriesQuery = `SELECT ` + repositoryColumns + ` FROM repositories
+	WHERE (
+		(provider, lower(owner), lower(name)) IN (
+			SELECT p, lower(o), lower(n) FROM unnest($1::varchar[], $2::varchar[], $3::varchar[]) AS t (p, o, n)
+		) OR (provider=$4 AND provider_id = ANY($5::bigint[]))
+	) AND deleted_at IS NULL`
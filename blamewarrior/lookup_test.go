@@ -0,0 +1,85 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupRepositories(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	for _, repo := range []*blamewarrior.Repository{
+		{Owner: "blamewarrior", Name: "repos"},
+		{Owner: "blamewarrior", Name: "hooks"},
+		{Provider: blamewarrior.ProviderGitlab, Owner: "blamewarrior/backend", Name: "repos"},
+	} {
+		require.NoError(t, blamewarrior.CreateRepository(db, repo))
+	}
+
+	require.NoError(t, blamewarrior.DeleteRepository(db, "blamewarrior/hooks"))
+
+	repo, err := blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	require.NoError(t, blamewarrior.UpdateRepositoryMetadata(db, repo, &blamewarrior.RepositoryMetadata{ProviderID: 42, RefreshedAt: time.Now()}))
+
+	gitlabRepo, err := blamewarrior.GetRepositoryByFullName(db, "gitlab:blamewarrior/backend/repos")
+	require.NoError(t, err)
+	require.NoError(t, blamewarrior.UpdateRepositoryMetadata(db, gitlabRepo, &blamewarrior.RepositoryMetadata{ProviderID: 7, RefreshedAt: time.Now()}))
+
+	results, err := blamewarrior.LookupRepositories(db, blamewarrior.ProviderGithub, []string{
+		"BlameWarrior/Repos",
+		"blamewarrior/hooks",
+		"gitlab:blamewarrior/backend/repos",
+		"blamewarrior/missing",
+		"blamewarrior/repos",
+	}, []int64{7, 42})
+	require.NoError(t, err)
+	require.Len(t, results, 7)
+
+	assert.Equal(t, "BlameWarrior/Repos", results[0].FullName)
+	require.True(t, results[0].Found)
+	assert.Equal(t, "blamewarrior/repos", results[0].Repository.FullName())
+	assert.False(t, results[1].Found, "deleted repository should not be found")
+	assert.Nil(t, results[1].Repository)
+	require.True(t, results[2].Found)
+	assert.Equal(t, blamewarrior.ProviderGitlab, results[2].Repository.Provider)
+	assert.False(t, results[3].Found)
+	assert.Equal(t, results[0].Repository, results[4].Repository)
+
+	assert.Equal(t, int64(7), results[5].ID)
+	assert.False(t, results[5].Found, "repositories hosted at other providers should not be found")
+	assert.Equal(t, int64(42), results[6].ID)
+	require.True(t, results[6].Found)
+	assert.Equal(t, results[0].Repository, results[6].Repository)
+
+	_, err = blamewarrior.LookupRepositories(db, blamewarrior.ProviderGithub, []string{"blamewarrior/repos", "blamewarrior"}, nil)
+	assert.Equal(t, blamewarrior.IncorrectFullName, err)
+
+	results, err = blamewarrior.LookupRepositories(db, blamewarrior.ProviderGithub, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
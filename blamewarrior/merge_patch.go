@@ -0,0 +1,95 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MergePatch applies JSON Merge Patch (RFC 7396) to doc and returns the resulting document.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+
+	if len(doc) > 0 {
+		if err := json.Unmarshal(doc, &target); err != nil {
+			return nil, fmt.Errorf("malformed document: %s", err)
+		}
+	}
+
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("malformed merge patch: %s", err)
+	}
+
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+			continue
+		}
+
+		targetObj[k] = mergePatch(targetObj[k], v)
+	}
+
+	return targetObj
+}
+
+// ApplyMergePatch returns a copy of repo with JSON Merge Patch applied. Fields that are not
+// exposed in JSON representation of repository, as well as read-only ones, are not affected.
+func (repo *Repository) ApplyMergePatch(patch []byte) (*Repository, error) {
+	doc, err := json.Marshal(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc, err = MergePatch(doc, patch); err != nil {
+		return nil, err
+	}
+
+	patched := &Repository{}
+	if err := json.Unmarshal(doc, patched); err != nil {
+		return nil, fmt.Errorf("malformed merge patch: %s", err)
+	}
+
+	patched.ID, patched.Provider, patched.Version, patched.DeletedAt = repo.ID, repo.Provider, repo.Version, repo.DeletedAt
+	patched.UpdatedAt = repo.UpdatedAt
+	patched.State, patched.InactiveSince = repo.State, repo.InactiveSince
+	patched.Config, patched.DefaultBranch, patched.Metadata = repo.Config, repo.DefaultBranch, repo.Metadata
+
+	return patched, nil
+}
+
+// ETag returns entity tag of current repository state. Update time is a part of it since
+// refreshes of informational fields change repository representation but not its version.
+func (repo *Repository) ETag() string {
+	updatedAt := repo.UpdatedAt.Unix()*int64(time.Second/time.Microsecond) + int64(repo.UpdatedAt.Nanosecond())/int64(time.Microsecond)
+
+	return fmt.Sprintf(`"%d.%d.%d"`, repo.ID, repo.Version, updatedAt)
+}
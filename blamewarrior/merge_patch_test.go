@@ -0,0 +1,93 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatch(t *testing.T) {
+	// Examples from https://tools.ietf.org/html/rfc7396#appendix-A
+	examples := []struct {
+		Doc, Patch, Result string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, example := range examples {
+		result, err := blamewarrior.MergePatch([]byte(example.Doc), []byte(example.Patch))
+		require.NoError(t, err)
+		assert.JSONEq(t, example.Result, string(result), "applying %s to %s", example.Patch, example.Doc)
+	}
+
+	_, err := blamewarrior.MergePatch([]byte(`{}`), []byte(`{`))
+	assert.Error(t, err)
+}
+
+func TestRepository_ApplyMergePatch(t *testing.T) {
+	repo := &blamewarrior.Repository{ID: 1, Owner: "blamewarrior", Name: "repos", Version: 3}
+
+	patched, err := repo.ApplyMergePatch([]byte(`{"name":"repositories","private":true,"full_name":"octocat/test"}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, &blamewarrior.Repository{ID: 1, Owner: "blamewarrior", Name: "repositories", Private: true, Version: 3}, patched)
+	assert.Equal(t, "repos", repo.Name)
+
+	patched, err = repo.ApplyMergePatch([]byte(`{"name":null}`))
+	require.NoError(t, err)
+	assert.Error(t, patched.Validate())
+
+	repo.DefaultBranch = &blamewarrior.DefaultBranch{Name: "master", Protected: true}
+
+	patched, err = repo.ApplyMergePatch([]byte(`{"default_branch":{"name":"develop","protected":false}}`))
+	require.NoError(t, err)
+	assert.Equal(t, repo.DefaultBranch, patched.DefaultBranch)
+}
+
+func TestRepository_ETag(t *testing.T) {
+	updatedAt := time.Date(2017, 5, 1, 12, 0, 0, 1500, time.UTC)
+	repo := &blamewarrior.Repository{ID: 1, Version: 3, UpdatedAt: updatedAt}
+
+	assert.Equal(t, `"1.3.1493640000000001"`, repo.ETag())
+
+	refreshed := *repo
+	refreshed.UpdatedAt = updatedAt.Add(time.Microsecond)
+	assert.NotEqual(t, repo.ETag(), refreshed.ETag())
+
+	// sub-microsecond precision is not stored by database
+	truncated := *repo
+	truncated.UpdatedAt = updatedAt.Truncate(time.Microsecond)
+	assert.Equal(t, repo.ETag(), truncated.ETag())
+}
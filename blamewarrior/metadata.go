@@ -0,0 +1,73 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RepositoryMetadata is descriptive information about repository fetched from GitHub.
+type RepositoryMetadata struct {
+	// ProviderID is the ID of repository at provider, it does not change when repository is renamed
+	ProviderID  int64    `json:"provider_id,omitempty"`
+	Description string   `json:"description"`
+	Language    string   `json:"language"`
+	Topics      []string `json:"topics"`
+	// Size is repository size in kilobytes
+	Size        int        `json:"size"`
+	Stars       int        `json:"stars"`
+	PushedAt    *time.Time `json:"pushed_at,omitempty"`
+	Archived    bool       `json:"archived"`
+	RefreshedAt time.Time  `json:"refreshed_at"`
+}
+
+// UpdateRepositoryMetadata stores metadata of repository. Metadata is informational and changes
+// often, so its refresh is not recorded as a repository change.
+func UpdateRepositoryMetadata(runner SQLRunner, repo *Repository, metadata *RepositoryMetadata) error {
+	topics := metadata.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+
+	_, err := runner.Exec(
+		UpdateRepositoryMetadataQuery,
+		repo.ID, metadata.Description, metadata.Language, pq.Array(topics), metadata.Size, metadata.Stars,
+		metadata.PushedAt, metadata.Archived, metadata.RefreshedAt, nullableID(metadata.ProviderID),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update repository metadata: %s", err)
+	}
+
+	repo.Metadata = metadata
+
+	return nil
+}
+
+// nullableID converts ID into a value suitable to be stored in a nullable column, zero IDs are
+// considered unknown.
+func nullableID(id int64) interface{} {
+	if id == 0 {
+		return nil
+	}
+
+	return id
+}
+
+const UpdateRepositoryMetadataQuery = `UPDATE repositories SET description=$2, language=$3, topics=$4, size=$5, stars=$6, pushed_at=$7, archived=$8, metadata_refreshed_at=$9, provider_id=COALESCE($10, provider_id) WHERE id=$1 AND deleted_at IS NULL`
@@ -0,0 +1,131 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// nameRules are restrictions code hosting service puts on repository names and namespaces
+// repositories belong to.
+type nameRules struct {
+	Namespace *regexp.Regexp
+	Name      *regexp.Regexp
+	// MaxNamespaces is the number of slash separated namespaces owner may consist of
+	MaxNamespaces int
+	// MaxOwnerLength and MaxNameLength limit length of owner and name in bytes
+	MaxOwnerLength, MaxNameLength int
+	// ReservedNames can not be used as either namespace or name
+	ReservedNames []string
+	// ReservedSuffixes can not end either namespace or name
+	ReservedSuffixes []string
+}
+
+var providerNameRules = map[string]*nameRules{
+	// GitHub logins consist of alphanumerics and hyphens, repository names may also contain
+	// dots and underscores
+	ProviderGithub: {
+		Namespace:      regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9\-]*$`),
+		Name:           regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`),
+		MaxNamespaces:  1,
+		MaxOwnerLength: 39,
+		MaxNameLength:  100,
+		ReservedNames:  []string{".", ".."},
+	},
+	// GitLab paths of groups, subgroups and projects share the same rules, groups can be
+	// nested up to 20 levels deep
+	ProviderGitlab: {
+		Namespace:        regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.\-]*$`),
+		Name:             regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.\-]*$`),
+		MaxNamespaces:    20,
+		MaxOwnerLength:   255,
+		MaxNameLength:    255,
+		ReservedSuffixes: []string{".", ".git", ".atom"},
+	},
+}
+
+// ValidateName checks that owner and name of repository follow naming rules of provider.
+// Owner may consist of several namespaces separated with slashes if provider supports nested
+// namespaces, e.g. group/subgroup.
+func ValidateName(provider, owner, name string) error {
+	if provider == "" {
+		provider = DefaultProvider
+	}
+
+	rules, ok := providerNameRules[provider]
+	if !ok {
+		return fmt.Errorf("unsupported provider %s", provider)
+	}
+
+	if owner == "" {
+		return fmt.Errorf("owner must not be empty")
+	}
+
+	if name == "" {
+		return fmt.Errorf("name must not be empty")
+	}
+
+	if len(owner) > rules.MaxOwnerLength {
+		return fmt.Errorf("owner must not be longer than %d characters", rules.MaxOwnerLength)
+	}
+
+	if len(name) > rules.MaxNameLength {
+		return fmt.Errorf("name must not be longer than %d characters", rules.MaxNameLength)
+	}
+
+	namespaces := strings.Split(owner, "/")
+	if len(namespaces) > rules.MaxNamespaces {
+		if rules.MaxNamespaces == 1 {
+			return fmt.Errorf("owner %s must not contain slashes", owner)
+		}
+
+		return fmt.Errorf("owner %s must not be nested more than %d levels deep", owner, rules.MaxNamespaces)
+	}
+
+	for _, ns := range namespaces {
+		if !rules.valid(rules.Namespace, ns) {
+			return fmt.Errorf("invalid owner %s", owner)
+		}
+	}
+
+	if !rules.valid(rules.Name, name) {
+		return fmt.Errorf("invalid name %s", name)
+	}
+
+	return nil
+}
+
+func (rules *nameRules) valid(re *regexp.Regexp, s string) bool {
+	if !re.MatchString(s) {
+		return false
+	}
+
+	for _, reserved := range rules.ReservedNames {
+		if s == reserved {
+			return false
+		}
+	}
+
+	for _, suffix := range rules.ReservedSuffixes {
+		if strings.HasSuffix(s, suffix) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,56 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestValidateName(t *testing.T) {
+	results := []struct {
+		Provider, Owner, Name string
+		Err                   error
+	}{
+		{"", "blamewarrior", "repos", nil},
+		{"github", "BlameWarrior", "My.Repo_1", nil},
+		{"github", "blame-warrior", "repos", nil},
+		{"github", "-blamewarrior", "repos", errors.New("invalid owner -blamewarrior")},
+		{"github", "blame.warrior", "repos", errors.New("invalid owner blame.warrior")},
+		{"github", "blamewarrior/backend", "repos", errors.New("owner blamewarrior/backend must not contain slashes")},
+		{"github", "blamewarrior", "..", errors.New("invalid name ..")},
+		{"github", "blamewarrior", "re pos", errors.New("invalid name re pos")},
+		{"github", strings.Repeat("a", 40), "repos", errors.New("owner must not be longer than 39 characters")},
+		{"github", "blamewarrior", strings.Repeat("a", 101), errors.New("name must not be longer than 100 characters")},
+		{"gitlab", "blamewarrior/backend/api", "my.repo", nil},
+		{"gitlab", "blamewarrior//api", "repos", errors.New("invalid owner blamewarrior//api")},
+		{"gitlab", "blamewarrior", "repos.git", errors.New("invalid name repos.git")},
+		{"gitlab", "blamewarrior.atom", "repos", errors.New("invalid owner blamewarrior.atom")},
+		{"gitlab", "blamewarrior", ".repos", errors.New("invalid name .repos")},
+		{"gitlab", strings.Repeat("a/", 20) + "a", "repos", errors.New("owner " + strings.Repeat("a/", 20) + "a must not be nested more than 20 levels deep")},
+		{"gitlab", "", "repos", errors.New("owner must not be empty")},
+		{"bitbucket", "blamewarrior", "repos", errors.New("unsupported provider bitbucket")},
+	}
+
+	for _, result := range results {
+		assert.Equal(t, result.Err, blamewarrior.ValidateName(result.Provider, result.Owner, result.Name), result.Provider+":"+result.Owner+"/"+result.Name)
+	}
+}
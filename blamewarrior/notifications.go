@@ -0,0 +1,80 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RepositoryChangesChannel is a PostgreSQL notification channel a trigger on repositories
+// table notifies whenever a repository is created, updated or deleted.
+const RepositoryChangesChannel = "repository_changes"
+
+const (
+	listenerMinReconnectInterval = time.Second
+	listenerMaxReconnectInterval = time.Minute
+)
+
+// ListenRepositoryChanges returns a listener of the primary database subscribed to
+// RepositoryChangesChannel. The listener reconnects on connection loss and sends nil to its
+// Notify channel once reconnected since notifications sent meanwhile are lost.
+func ListenRepositoryChanges(dbName string, opts ...*DatabaseOptions) (*pq.Listener, error) {
+	listener := pq.NewListener(primaryConnectionString(dbName, opts...), listenerMinReconnectInterval, listenerMaxReconnectInterval, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("repository changes listener: %s", err)
+		}
+	})
+
+	if err := listener.Listen(RepositoryChangesChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen to %s: %s", RepositoryChangesChannel, err)
+	}
+
+	return listener, nil
+}
+
+// RepositoryNotification identifies a changed repository. Renamed repositories are notified
+// about twice, under the old and the new name.
+type RepositoryNotification struct {
+	Provider string `json:"provider"`
+	Owner    string `json:"owner"`
+	Name     string `json:"name"`
+}
+
+// ParseRepositoryNotification decodes payload of a notification sent to RepositoryChangesChannel.
+func ParseRepositoryNotification(payload string) (*RepositoryNotification, error) {
+	n := &RepositoryNotification{}
+
+	if err := json.Unmarshal([]byte(payload), n); err != nil {
+		return nil, fmt.Errorf("failed to parse repository notification %q: %s", payload, err)
+	}
+
+	if n.Provider == "" || n.Owner == "" || n.Name == "" {
+		return nil, fmt.Errorf("incomplete repository notification %q", payload)
+	}
+
+	return n, nil
+}
+
+// QualifiedName returns name of changed repository prefixed with its provider unless it is GitHub.
+func (n *RepositoryNotification) QualifiedName() string {
+	return QualifiedName(n.Provider, n.Owner, n.Name)
+}
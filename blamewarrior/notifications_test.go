@@ -0,0 +1,51 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestParseRepositoryNotification(t *testing.T) {
+	n, err := blamewarrior.ParseRepositoryNotification(`{"provider":"gitlab","owner":"group/subgroup","name":"repos"}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, &blamewarrior.RepositoryNotification{
+		Provider: blamewarrior.ProviderGitlab,
+		Owner:    "group/subgroup",
+		Name:     "repos",
+	}, n)
+	assert.Equal(t, "gitlab:group/subgroup/repos", n.QualifiedName())
+
+	results := []struct {
+		Payload string
+	}{
+		{""},
+		{"repos"},
+		{`{"provider":"github","owner":"blamewarrior"}`},
+		{`{"owner":"blamewarrior","name":"repos"}`},
+	}
+
+	for _, result := range results {
+		_, err := blamewarrior.ParseRepositoryNotification(result.Payload)
+		assert.Error(t, err, result.Payload)
+	}
+}
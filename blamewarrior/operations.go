@@ -0,0 +1,85 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Operation statuses
+const (
+	OperationRunning   = "running"
+	OperationSucceeded = "succeeded"
+	OperationFailed    = "failed"
+)
+
+// Operation is the progress of a request that is processed in the background, such as
+// asynchronous repository creation. Operations are backed by jobs and share their IDs.
+type Operation struct {
+	ID     int64  `json:"id"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	// Repository is the qualified name of repository operation is performed on
+	Repository string `json:"repository,omitempty"`
+	// RepositoryState is the current state of repository, e.g. StateFailed if operation
+	// failed to set it up
+	RepositoryState string `json:"repository_state,omitempty"`
+	Attempts        int    `json:"attempts"`
+	MaxAttempts     int    `json:"max_attempts"`
+	// Error describes why operation failed or why its latest attempt failed if it is still
+	// running
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// NewOperation returns operation processed by job. Jobs that are dead or have been
+// cancelled are reported as failed operations.
+func NewOperation(job *Job) *Operation {
+	op := &Operation{
+		ID:          job.ID,
+		Type:        job.Type,
+		Status:      OperationRunning,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		Error:       job.LastError,
+		CreatedAt:   job.CreatedAt,
+		FinishedAt:  job.FinishedAt,
+	}
+
+	switch job.State {
+	case JobSucceeded:
+		op.Status, op.Error = OperationSucceeded, ""
+	case JobDead:
+		op.Status = OperationFailed
+	case JobCancelled:
+		op.Status = OperationFailed
+		if op.Error == "" {
+			op.Error = "operation has been cancelled"
+		}
+	}
+
+	var payload struct {
+		Repository string `json:"repository"`
+	}
+
+	if json.Unmarshal(job.Payload, &payload) == nil {
+		op.Repository = payload.Repository
+	}
+
+	return op
+}
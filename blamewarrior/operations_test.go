@@ -0,0 +1,67 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestNewOperation(t *testing.T) {
+	payload := json.RawMessage(`{"repository": "gitlab:blamewarrior/backend/repos"}`)
+
+	examples := []struct {
+		Job    blamewarrior.Job
+		Status string
+		Error  string
+	}{
+		{
+			Job:    blamewarrior.Job{State: blamewarrior.JobPending, Payload: payload},
+			Status: blamewarrior.OperationRunning,
+		},
+		{
+			Job:    blamewarrior.Job{State: blamewarrior.JobRunning, Payload: payload, LastError: "hooks service unavailable"},
+			Status: blamewarrior.OperationRunning,
+			Error:  "hooks service unavailable",
+		},
+		{
+			Job:    blamewarrior.Job{State: blamewarrior.JobSucceeded, Payload: payload, LastError: "hooks service unavailable"},
+			Status: blamewarrior.OperationSucceeded,
+		},
+		{
+			Job:    blamewarrior.Job{State: blamewarrior.JobDead, Payload: payload, LastError: "hook already exists"},
+			Status: blamewarrior.OperationFailed,
+			Error:  "hook already exists",
+		},
+		{
+			Job:    blamewarrior.Job{State: blamewarrior.JobCancelled, Payload: payload},
+			Status: blamewarrior.OperationFailed,
+			Error:  "operation has been cancelled",
+		},
+	}
+
+	for _, example := range examples {
+		op := blamewarrior.NewOperation(&example.Job)
+
+		assert.Equal(t, example.Status, op.Status, example.Job.State)
+		assert.Equal(t, example.Error, op.Error, example.Job.State)
+		assert.Equal(t, "gitlab:blamewarrior/backend/repos", op.Repository, example.Job.State)
+	}
+}
@@ -0,0 +1,48 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// ClaimOutboxEvents returns up to limit repository events that have not been published yet in
+// the order they were recorded. Claimed events are locked until the end of transaction, so that
+// concurrent publishers skip them. Events have to be released with DeleteOutboxEvents once
+// published.
+func ClaimOutboxEvents(runner SQLRunner, limit int) (events []RepositoryEvent, err error) {
+	return queryEvents(runner, ClaimOutboxEventsQuery, limit)
+}
+
+// DeleteOutboxEvents removes published events from the outbox. Events themselves are kept in
+// the audit log.
+func DeleteOutboxEvents(runner SQLRunner, events []RepositoryEvent) error {
+	if _, err := runner.Exec(DeleteOutboxEventsQuery, pq.Array(eventIDs(events))); err != nil {
+		return fmt.Errorf("failed to delete outbox events: %s", err)
+	}
+
+	return nil
+}
+
+const (
+	CreateOutboxEntryQuery = `INSERT INTO event_outbox (event_id) VALUES ($1)`
+	ClaimOutboxEventsQuery = `SELECT ` + eventColumns + ` FROM repository_events WHERE id IN (
+		SELECT event_id FROM event_outbox ORDER BY event_id LIMIT $1 FOR UPDATE SKIP LOCKED
+	) ORDER BY id`
+	DeleteOutboxEventsQuery = `DELETE FROM event_outbox WHERE event_id = ANY($1)`
+)
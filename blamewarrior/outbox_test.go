@@ -0,0 +1,57 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"testing"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutbox(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events, event_outbox CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"}))
+	require.NoError(t, blamewarrior.DeleteRepository(db, "blamewarrior/repos"))
+
+	events, err := blamewarrior.ClaimOutboxEvents(db, 2)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, blamewarrior.EventCreated, events[0].Action)
+	assert.Equal(t, "repos", events[0].Name)
+	assert.Equal(t, blamewarrior.EventCreated, events[1].Action)
+	assert.Equal(t, "hooks", events[1].Name)
+
+	require.NoError(t, blamewarrior.DeleteOutboxEvents(db, events))
+
+	events, err = blamewarrior.ClaimOutboxEvents(db, 100)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, blamewarrior.EventDeleted, events[0].Action)
+
+	// published events are kept in the audit log
+	history, err := blamewarrior.GetRepositoryHistory(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+}
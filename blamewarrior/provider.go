@@ -0,0 +1,81 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Code hosting services repositories can be tracked at
+const (
+	ProviderGithub = "github"
+	ProviderGitlab = "gitlab"
+)
+
+// DefaultProvider is assumed for repositories and full names that do not specify one.
+const DefaultProvider = ProviderGithub
+
+var providers = map[string]bool{
+	ProviderGithub: true,
+	ProviderGitlab: true,
+}
+
+// Errors returned by providers
+var (
+	ErrRateLimitReached = errors.New("provider API request rate limit reached")
+	ErrNoSuchUser       = errors.New("no such user")
+	ErrNotFound         = errors.New("not found")
+)
+
+// Provider is a client of code hosting service repositories are stored at.
+type Provider interface {
+	// UserRepositories returns repositories that belong to user or group.
+	UserRepositories(ctx context.Context, owner string) ([]Repository, error)
+	// RepositoryMetadata looks up repository and returns its descriptive information.
+	RepositoryMetadata(ctx context.Context, owner, name string) (*RepositoryMetadata, error)
+	// Collaborators returns users and teams that have access to repository.
+	Collaborators(ctx context.Context, owner, name string) ([]Collaborator, error)
+	// Permission returns permission level user has for repository or an empty string if
+	// user has no access to it.
+	Permission(ctx context.Context, owner, name, login string) (string, error)
+}
+
+// ValidProvider reports whether provider is a supported code hosting service.
+func ValidProvider(provider string) bool {
+	return providers[provider]
+}
+
+// QualifiedName returns full name of repository prefixed with its provider, e.g. gitlab:owner/name.
+// Repositories hosted at DefaultProvider are identified by their full names only.
+func QualifiedName(provider, owner, name string) string {
+	if provider == "" || provider == DefaultProvider {
+		return owner + "/" + name
+	}
+
+	return provider + ":" + owner + "/" + name
+}
+
+// splitProvider splits provider prefix off qualified repository name.
+func splitProvider(qualifiedName string) (provider, fullName string) {
+	sep := strings.IndexByte(qualifiedName, ':')
+	if sep < 0 {
+		return DefaultProvider, qualifiedName
+	}
+
+	return qualifiedName[:sep], qualifiedName[sep+1:]
+}
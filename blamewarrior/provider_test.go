@@ -0,0 +1,95 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestQualifiedName(t *testing.T) {
+	examples := []struct {
+		Provider string
+		Expected string
+	}{
+		{"", "blamewarrior/repos"},
+		{blamewarrior.ProviderGithub, "blamewarrior/repos"},
+		{blamewarrior.ProviderGitlab, "gitlab:blamewarrior/repos"},
+	}
+
+	for _, example := range examples {
+		repo := &blamewarrior.Repository{Provider: example.Provider, Owner: "blamewarrior", Name: "repos"}
+
+		assert.Equal(t, example.Expected, repo.QualifiedName(), example.Provider)
+		assert.Equal(t, "blamewarrior/repos", repo.FullName(), example.Provider)
+	}
+}
+
+func TestValidProvider(t *testing.T) {
+	assert.True(t, blamewarrior.ValidProvider(blamewarrior.ProviderGithub))
+	assert.True(t, blamewarrior.ValidProvider(blamewarrior.ProviderGitlab))
+	assert.False(t, blamewarrior.ValidProvider("bitbucket"))
+	assert.False(t, blamewarrior.ValidProvider(""))
+}
+
+func TestRepositoriesOfDifferentProviders(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	github := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}
+	require.NoError(t, blamewarrior.CreateRepository(db, github))
+	assert.Equal(t, blamewarrior.ProviderGithub, github.Provider)
+
+	gitlab := &blamewarrior.Repository{Provider: blamewarrior.ProviderGitlab, Owner: "blamewarrior", Name: "repos", Private: true}
+	require.NoError(t, blamewarrior.CreateRepository(db, gitlab))
+
+	repo, err := blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	assert.Equal(t, github.ID, repo.ID)
+
+	repo, err = blamewarrior.GetRepositoryByFullName(db, "gitlab:blamewarrior/repos")
+	require.NoError(t, err)
+	assert.Equal(t, gitlab.ID, repo.ID)
+	assert.True(t, repo.Private)
+
+	_, err = blamewarrior.GetRepositoryByFullName(db, "bitbucket:blamewarrior/repos")
+	assert.Equal(t, blamewarrior.IncorrectFullName, err)
+
+	repositories, err := blamewarrior.GetListRepositoryByOwner(db, "blamewarrior", &blamewarrior.ListOptions{Provider: blamewarrior.ProviderGitlab})
+	require.NoError(t, err)
+	require.Len(t, repositories, 1)
+	assert.Equal(t, gitlab.ID, repositories[0].ID)
+
+	require.NoError(t, blamewarrior.DeleteRepository(db, gitlab.QualifiedName()))
+
+	_, err = blamewarrior.GetRepositoryByFullName(db, gitlab.QualifiedName())
+	assert.Equal(t, blamewarrior.ErrRepositoryNotFound, err)
+
+	_, err = blamewarrior.GetRepositoryByFullName(db, github.QualifiedName())
+	assert.NoError(t, err)
+
+	events, err := blamewarrior.GetRepositoryHistory(db, gitlab.QualifiedName())
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, blamewarrior.ProviderGitlab, events[0].Provider)
+}
@@ -20,18 +20,119 @@
 package blamewarrior
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type Repository struct {
-	ID      int    `json:"-"`
-	Owner   string `json:"owner"`
-	Name    string `json:"name"`
-	Private bool   `json:"private"`
+	ID        int        `json:"-"`
+	Owner     string     `json:"owner"`
+	Name      string     `json:"name"`
+	Private   bool       `json:"private"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Provider is the code hosting service repository is stored at, DefaultProvider if empty
+	Provider string `json:"provider,omitempty"`
+	// State is StatePending until repository webhook is set up, then either StateActive or
+	// StateInactive, see StalenessPolicy. It is StateFailed if webhook could not be set up
+	State         string     `json:"state,omitempty"`
+	InactiveSince *time.Time `json:"inactive_since,omitempty"`
+	// Settings is BlameWarrior configuration of repository, see ValidateSettings
+	Settings json.RawMessage `json:"settings,omitempty"`
+	// Config is BlameWarrior configuration loaded from repository itself
+	Config *RepositoryConfig `json:"config,omitempty"`
+	// DefaultBranch is the snapshot of default branch taken during the last reconciliation
+	DefaultBranch *DefaultBranch `json:"default_branch,omitempty"`
+	// Metadata is descriptive information fetched from provider
+	Metadata *RepositoryMetadata `json:"metadata,omitempty"`
+	// Version is incremented on every change of repository and is used for optimistic locking
+	Version int `json:"-"`
+	// UpdatedAt is the time repository row has been updated at, including refreshes of
+	// informational fields that do not change Version
+	UpdatedAt time.Time `json:"-"`
+}
+
+// Repository listing sort orders
+const (
+	SortByName   = "name"
+	SortByPushed = "pushed"
+	SortByStars  = "stars"
+)
+
+var listSortOrders = map[string]string{
+	SortByName:   "name, id",
+	SortByPushed: "pushed_at DESC NULLS LAST, name, id",
+	SortByStars:  "stars DESC NULLS LAST, name, id",
+}
+
+// defaultSortOrder keeps listing order stable when no sort order is requested, so that
+// listing ETag does not depend on the plan chosen by the database.
+const defaultSortOrder = "id"
+
+// ListOptions specifies optional parameters for repositories listing.
+type ListOptions struct {
+	// Provider limits listing to repositories hosted at given provider
+	Provider string
+	// IncludeDeleted makes listing include soft deleted repositories
+	IncludeDeleted bool
+	// Language limits listing to repositories written primarily in given language
+	Language string
+	// Topic limits listing to repositories tagged with given topic
+	Topic string
+	// PushedSince limits listing to repositories pushed to after given time
+	PushedSince time.Time
+	// State limits listing to repositories in given state
+	State string
+	// Sort is one of SortByName, SortByPushed or SortByStars
+	Sort string
+}
+
+// ValidSortOrder reports whether sort is a known repository listing sort order.
+func ValidSortOrder(sort string) bool {
+	_, ok := listSortOrders[sort]
+	return ok
+}
+
+// filter appends conditions and ordering specified by options to the query.
+func (opts *ListOptions) filter(query string, args []interface{}) (string, []interface{}) {
+	if opts.Provider != "" {
+		args = append(args, opts.Provider)
+		query += fmt.Sprintf(" AND provider=$%d", len(args))
+	}
+
+	if opts.State != "" {
+		args = append(args, opts.State)
+		query += fmt.Sprintf(" AND state=$%d", len(args))
+	}
+
+	if opts.Language != "" {
+		args = append(args, opts.Language)
+		query += fmt.Sprintf(" AND lower(language)=lower($%d)", len(args))
+	}
+
+	if opts.Topic != "" {
+		args = append(args, opts.Topic)
+		query += fmt.Sprintf(" AND $%d=ANY(topics)", len(args))
+	}
+
+	if !opts.PushedSince.IsZero() {
+		args = append(args, opts.PushedSince)
+		query += fmt.Sprintf(" AND pushed_at >= $%d", len(args))
+	}
+
+	order, ok := listSortOrders[opts.Sort]
+	if !ok {
+		order = defaultSortOrder
+	}
+
+	query += " ORDER BY " + order
+
+	return query, args
 }
 
 func (repo *Repository) MarshalJSON() ([]byte, error) {
@@ -45,26 +146,61 @@ func (repo *Repository) MarshalJSON() ([]byte, error) {
 	})
 }
 
-var IncorrectFullName = fmt.Errorf("incorrect full name for repository")
+var (
+	IncorrectFullName     = fmt.Errorf("incorrect full name for repository")
+	ErrRepositoryNotFound = errors.New("repository not found")
+	ErrRepositoryExists   = errors.New("repository is already tracked")
+	ErrVersionMismatch    = errors.New("repository has been modified concurrently")
+)
+
+// uniqueViolation is PostgreSQL error code for unique constraint violation
+const uniqueViolation = "23505"
 
 func (repo *Repository) FullName() string {
 	return fmt.Sprintf("%s/%s", repo.Owner, repo.Name)
 }
 
+// QualifiedName returns full name of repository prefixed with its provider unless it is
+// hosted at DefaultProvider. Functions that look repositories up by full name accept it.
+func (repo *Repository) QualifiedName() string {
+	return QualifiedName(repo.Provider, repo.Owner, repo.Name)
+}
+
 func (repo *Repository) Validate() error {
-	if repo.Owner == "" {
-		return fmt.Errorf("owner must not be empty")
+	if err := ValidateName(repo.Provider, repo.Owner, repo.Name); err != nil {
+		return err
 	}
 
-	if repo.Name == "" {
-		return fmt.Errorf("name must not be empty")
+	if len(repo.Settings) > 0 {
+		return ValidateSettings(repo.Settings)
 	}
 
 	return nil
 }
 
-func GetListRepositoryByOwner(runner SQLRunner, owner string) (repositories []Repository, err error) {
-	rows, err := runner.Query(GetListRepositoryByOwnerQuery, owner)
+func GetListRepositoryByOwner(runner SQLRunner, owner string, opts ...*ListOptions) (repositories []Repository, err error) {
+	opt := &ListOptions{}
+	if len(opts) > 0 && opts[0] != nil {
+		opt = opts[0]
+	}
+
+	query := GetListRepositoryByOwnerQuery
+	if opt.IncludeDeleted {
+		query = GetListRepositoryByOwnerWithDeletedQuery
+	}
+
+	query, args := opt.filter(query, []interface{}{owner})
+
+	return queryRepositories(runner, query, args...)
+}
+
+// GetRepositories returns all tracked repositories.
+func GetRepositories(runner SQLRunner) (repositories []Repository, err error) {
+	return queryRepositories(runner, GetRepositoriesQuery)
+}
+
+func queryRepositories(runner SQLRunner, query string, args ...interface{}) (repositories []Repository, err error) {
+	rows, err := runner.Query(query, args...)
 
 	if err != nil {
 		return nil, err
@@ -74,7 +210,7 @@ func GetListRepositoryByOwner(runner SQLRunner, owner string) (repositories []Re
 	for rows.Next() {
 		var repo Repository
 
-		if err := rows.Scan(&repo.Owner, &repo.Name, &repo.Private); err != nil {
+		if err := scanRepository(rows, &repo); err != nil {
 			return nil, err
 		}
 
@@ -88,17 +224,26 @@ func GetListRepositoryByOwner(runner SQLRunner, owner string) (repositories []Re
 	return repositories, nil
 }
 
-func GetRepositoryByFullName(runner SQLRunner, fullName string) (*Repository, error) {
+func GetRepositoryByFullName(runner SQLRunner, fullName string, opts ...*ListOptions) (*Repository, error) {
 
 	repo := &Repository{}
 
-	owner, name, err := parseFullName(fullName)
+	provider, owner, name, err := parseFullName(fullName)
 
 	if err != nil {
 		return nil, err
 	}
 
-	err = runner.QueryRow(GetRepositoryQuery, owner, name).Scan(&repo.Owner, &repo.Name, &repo.Private)
+	query := GetRepositoryQuery
+	if len(opts) > 0 && opts[0] != nil && opts[0].IncludeDeleted {
+		query = GetRepositoryWithDeletedQuery
+	}
+
+	err = scanRepository(runner.QueryRow(query, provider, owner, name), repo)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrRepositoryNotFound
+	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch repositories: %s", err)
@@ -108,51 +253,247 @@ func GetRepositoryByFullName(runner SQLRunner, fullName string) (*Repository, er
 
 }
 
-func CreateRepository(runner SQLRunner, repo *Repository) (err error) {
-	err = runner.QueryRow(CreateRepositoryQuery, repo.Owner, repo.Name, repo.Private).Scan(&repo.ID)
+func CreateRepository(runner SQLRunner, repo *Repository, changes ...*Change) (err error) {
+	if repo.Provider == "" {
+		repo.Provider = DefaultProvider
+	}
+
+	if repo.State == "" {
+		repo.State = StateActive
+	}
+
+	err = runner.QueryRow(CreateRepositoryQuery, repo.Provider, repo.Owner, repo.Name, repo.Private, nullableJSON(repo.Settings), repo.State).Scan(&repo.ID, &repo.State, &repo.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create repository: %s", err)
 	}
 
-	return err
+	return recordEvent(runner, EventCreated, nil, repo, changes...)
 }
 
-func DeleteRepository(runner SQLRunner, fullName string) (err error) {
-	owner, name, err := parseFullName(fullName)
+// DeleteRepository soft deletes repository with given full name. It returns ErrRepositoryNotFound
+// if the repository is not tracked or has already been deleted.
+func DeleteRepository(runner SQLRunner, fullName string, changes ...*Change) (err error) {
+	provider, owner, name, err := parseFullName(fullName)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = runner.Exec(DeleteRepositoryQuery, owner, name)
+	after := &Repository{}
+	err = scanRepository(runner.QueryRow(DeleteRepositoryQuery, provider, owner, name), after)
+
+	if err == sql.ErrNoRows {
+		return ErrRepositoryNotFound
+	}
 
 	if err != nil {
 		return fmt.Errorf("failed to delete repository: %s", err)
 	}
 
-	return err
+	before := *after
+	before.DeletedAt = nil
+
+	return recordEvent(runner, EventDeleted, &before, after, changes...)
+}
+
+// RestoreRepository brings back soft deleted repository.
+func RestoreRepository(runner SQLRunner, fullName string, changes ...*Change) (err error) {
+	provider, owner, name, err := parseFullName(fullName)
+
+	if err != nil {
+		return err
+	}
+
+	before := &Repository{}
+	err = scanRepository(runner.QueryRow(GetDeletedRepositoryQuery, provider, owner, name), before)
+
+	if err == sql.ErrNoRows {
+		return ErrRepositoryNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to restore repository: %s", err)
+	}
+
+	after := &Repository{}
+	err = scanRepository(runner.QueryRow(RestoreRepositoryQuery, before.ID), after)
+
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return ErrRepositoryExists
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to restore repository: %s", err)
+	}
+
+	return recordEvent(runner, EventRestored, before, after, changes...)
 }
 
-func parseFullName(fullName string) (owner string, name string, err error) {
-	parameters := strings.Split(fullName, "/")
-	if len(parameters) != 2 {
-		return "", "", IncorrectFullName
+// UpdateRepository stores changes made to repo. The change is rejected with ErrVersionMismatch
+// if the stored repository version differs from repo.Version. On success repo.Version is set
+// to the new version.
+func UpdateRepository(runner SQLRunner, repo *Repository, changes ...*Change) (err error) {
+	before := &Repository{}
+	err = scanRepository(runner.QueryRow(GetRepositoryByIDQuery, repo.ID), before)
+
+	if err == sql.ErrNoRows {
+		return ErrRepositoryNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update repository: %s", err)
+	}
+
+	err = runner.QueryRow(
+		UpdateRepositoryQuery,
+		repo.ID, repo.Version, repo.Owner, repo.Name, repo.Private, nullableJSON(repo.Settings),
+	).Scan(&repo.Version, &repo.UpdatedAt)
+
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return ErrRepositoryExists
 	}
 
-	owner, name = parameters[0], parameters[1]
+	if err == sql.ErrNoRows {
+		return ErrVersionMismatch
+	}
 
-	if owner == "" || name == "" {
-		return "", "", IncorrectFullName
+	if err != nil {
+		return fmt.Errorf("failed to update repository: %s", err)
 	}
 
-	return owner, name, nil
+	return recordEvent(runner, EventUpdated, before, repo, changes...)
+}
+
+// PurgeDeletedRepositories removes repositories that were soft deleted more than retention ago
+// and returns the number of removed rows. Each removal is recorded in the audit log.
+func PurgeDeletedRepositories(runner SQLRunner, retention time.Duration, changes ...*Change) (int64, error) {
+	purged, err := queryRepositories(runner, PurgeDeletedRepositoriesQuery, time.Now().Add(-retention))
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted repositories: %s", err)
+	}
 
+	for i := range purged {
+		if err = recordEvent(runner, EventPurged, &purged[i], nil, changes...); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(purged)), nil
 }
 
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRepository(row rowScanner, repo *Repository) error {
+	var (
+		settings, config       []byte
+		defaultBranch          []byte
+		description, language  sql.NullString
+		topics                 pq.StringArray
+		size, stars            sql.NullInt64
+		providerID             sql.NullInt64
+		archived               sql.NullBool
+		pushedAt               *time.Time
+		metadataRefreshedAt    pq.NullTime
+		configSHA, configError sql.NullString
+		configRefreshedAt      pq.NullTime
+	)
+
+	err := row.Scan(
+		&repo.ID, &repo.Provider, &repo.Owner, &repo.Name, &repo.Private, &repo.DeletedAt, &repo.Version, &settings,
+		&config, &configSHA, &configError, &configRefreshedAt, &defaultBranch,
+		&description, &language, &topics, &size, &stars, &pushedAt, &archived, &metadataRefreshedAt,
+		&repo.State, &repo.InactiveSince, &providerID, &repo.UpdatedAt,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	repo.Settings = settings
+
+	repo.Config = nil
+	if configRefreshedAt.Valid {
+		repo.Config = &RepositoryConfig{
+			Settings:    config,
+			CommitSHA:   configSHA.String,
+			Error:       configError.String,
+			RefreshedAt: configRefreshedAt.Time,
+		}
+	}
+
+	repo.Metadata = nil
+	if metadataRefreshedAt.Valid {
+		repo.Metadata = &RepositoryMetadata{
+			ProviderID:  providerID.Int64,
+			Description: description.String,
+			Language:    language.String,
+			Topics:      []string(topics),
+			Size:        int(size.Int64),
+			Stars:       int(stars.Int64),
+			PushedAt:    pushedAt,
+			Archived:    archived.Bool,
+			RefreshedAt: metadataRefreshedAt.Time,
+		}
+	}
+
+	repo.DefaultBranch = nil
+	if len(defaultBranch) > 0 {
+		repo.DefaultBranch = &DefaultBranch{}
+		if err := json.Unmarshal(defaultBranch, repo.DefaultBranch); err != nil {
+			return fmt.Errorf("malformed default branch snapshot: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// nullableJSON converts JSON document into a value suitable to be stored in a nullable JSONB column.
+func nullableJSON(doc json.RawMessage) interface{} {
+	if len(doc) == 0 {
+		return nil
+	}
+
+	return string(doc)
+}
+
+// parseFullName splits either full or qualified repository name into its parts. Name is the
+// last path segment and owner is everything before it, so that nested namespaces are supported
+// by providers that have them.
+func parseFullName(fullName string) (provider, owner, name string, err error) {
+	provider, fullName = splitProvider(fullName)
+
+	sep := strings.LastIndexByte(fullName, '/')
+	if sep < 0 {
+		return "", "", "", IncorrectFullName
+	}
+
+	owner, name = fullName[:sep], fullName[sep+1:]
+
+	if ValidateName(provider, owner, name) != nil {
+		return "", "", "", IncorrectFullName
+	}
+
+	return provider, owner, name, nil
+}
+
+const repositoryColumns = `id, provider, owner, name, private, deleted_at, version, settings, config, config_sha, config_error, config_refreshed_at, default_branch,
+	description, language, topics, size, stars, pushed_at, archived, metadata_refreshed_at, state, inactive_since, provider_id, updated_at`
+
 const (
-	GetListRepositoryByOwnerQuery = `SELECT owner, name, private FROM repositories WHERE owner=$1`
-	GetRepositoryQuery            = `SELECT owner, name, private FROM repositories WHERE owner=$1 AND name=$2`
-	CreateRepositoryQuery         = `INSERT INTO repositories (owner, name, private) VALUES ($1, $2, $3) RETURNING id`
-	DeleteRepositoryQuery         = `DELETE FROM repositories WHERE owner=$1 and name=$2`
+	GetListRepositoryByOwnerQuery            = `SELECT ` + repositoryColumns + ` FROM repositories WHERE lower(owner)=lower($1) AND deleted_at IS NULL`
+	GetListRepositoryByOwnerWithDeletedQuery = `SELECT ` + repositoryColumns + ` FROM repositories WHERE lower(owner)=lower($1)`
+	GetRepositoriesQuery                     = `SELECT ` + repositoryColumns + ` FROM repositories WHERE deleted_at IS NULL ORDER BY id`
+	GetRepositoryQuery                       = `SELECT ` + repositoryColumns + ` FROM repositories WHERE provider=$1 AND lower(owner)=lower($2) AND lower(name)=lower($3) AND deleted_at IS NULL`
+	GetRepositoryWithDeletedQuery            = `SELECT ` + repositoryColumns + ` FROM repositories WHERE provider=$1 AND lower(owner)=lower($2) AND lower(name)=lower($3) ORDER BY deleted_at IS NULL DESC, deleted_at DESC LIMIT 1`
+	CreateRepositoryQuery                    = `INSERT INTO repositories (provider, owner, name, private, settings, state) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, state, updated_at`
+	DeleteRepositoryQuery                    = `UPDATE repositories SET deleted_at=now(), version=version+1 WHERE provider=$1 AND lower(owner)=lower($2) AND lower(name)=lower($3) AND deleted_at IS NULL RETURNING ` + repositoryColumns
+	GetDeletedRepositoryQuery                = `SELECT ` + repositoryColumns + ` FROM repositories WHERE provider=$1 AND lower(owner)=lower($2) AND lower(name)=lower($3) AND deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT 1 FOR UPDATE`
+	RestoreRepositoryQuery                   = `UPDATE repositories SET deleted_at=NULL, version=version+1 WHERE id=$1 RETURNING ` + repositoryColumns
+	GetRepositoryByIDQuery                   = `SELECT ` + repositoryColumns + ` FROM repositories WHERE id=$1 AND deleted_at IS NULL FOR UPDATE`
+	UpdateRepositoryQuery                    = `UPDATE repositories SET owner=$3, name=$4, private=$5, settings=$6, version=version+1 WHERE id=$1 AND version=$2 AND deleted_at IS NULL RETURNING version, updated_at`
+	PurgeDeletedRepositoriesQuery            = `DELETE FROM repositories WHERE deleted_at < $1 RETURNING ` + repositoryColumns
 )
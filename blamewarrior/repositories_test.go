@@ -25,6 +25,7 @@ import (
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/blamewarrior/repos/blamewarrior"
 
@@ -36,7 +37,7 @@ func TestRepositoryValidation(t *testing.T) {
 	db, teardown := setup()
 	defer teardown()
 
-	_, err := db.Exec("TRUNCATE repositories;")
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
 
 	require.NoError(t, err)
 
@@ -65,9 +66,9 @@ func TestGetRepositoryByFullName(t *testing.T) {
 	db, teardown := setup()
 	defer teardown()
 
-	_, err := db.Exec("TRUNCATE repositories;")
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
 
-	_, err = db.Exec(blamewarrior.CreateRepositoryQuery, "blamewarrior", "repos", true)
+	_, err = db.Exec(blamewarrior.CreateRepositoryQuery, blamewarrior.ProviderGithub, "blamewarrior", "repos", true, nil, blamewarrior.StateActive)
 
 	require.NoError(t, err)
 
@@ -75,15 +76,23 @@ func TestGetRepositoryByFullName(t *testing.T) {
 
 	require.NoError(t, err)
 	require.NotEmpty(t, results)
+
+	results, err = blamewarrior.GetRepositoryByFullName(db, "BlameWarrior/Repos")
+
+	require.NoError(t, err)
+	assert.Equal(t, "repos", results.Name)
+
+	_, err = blamewarrior.GetRepositoryByFullName(db, "blamewarrior/backend/repos")
+	assert.Equal(t, blamewarrior.IncorrectFullName, err)
 }
 
 func TestGetListRepositoryByOwner(t *testing.T) {
 	db, teardown := setup()
 	defer teardown()
 
-	_, err := db.Exec("TRUNCATE repositories;")
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
 
-	_, err = db.Exec(blamewarrior.CreateRepositoryQuery, "blamewarrior", "repos", true)
+	_, err = db.Exec(blamewarrior.CreateRepositoryQuery, blamewarrior.ProviderGithub, "blamewarrior", "repos", true, nil, blamewarrior.StateActive)
 
 	require.NoError(t, err)
 
@@ -104,6 +113,14 @@ func TestCreateRepository(t *testing.T) {
 			Repo: &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos", Private: true},
 			Err:  nil,
 		},
+		{
+			Repo: &blamewarrior.Repository{Owner: "BlameWarrior", Name: "my.repo"},
+			Err:  nil,
+		},
+		{
+			Repo: &blamewarrior.Repository{Provider: blamewarrior.ProviderGitlab, Owner: "blamewarrior/backend", Name: "repos"},
+			Err:  nil,
+		},
 		{
 			Repo: &blamewarrior.Repository{Owner: "blamewarrior&*()", Name: "repos", Private: true},
 			Err:  errors.New(`failed to create repository: pq: new row for relation "repositories" violates check constraint "proper_owner"`),
@@ -117,7 +134,7 @@ func TestCreateRepository(t *testing.T) {
 	for _, result := range results {
 		db, teardown := setup()
 
-		_, err := db.Exec("TRUNCATE repositories;")
+		_, err := db.Exec("TRUNCATE repositories CASCADE;")
 
 		require.NoError(t, err)
 
@@ -133,7 +150,44 @@ func TestDeleteRepository(t *testing.T) {
 	db, teardown := setup()
 	defer teardown()
 
-	_, err := db.Exec("TRUNCATE repositories;")
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos", Private: true}
+	err = blamewarrior.CreateRepository(db, repo)
+	require.NoError(t, err)
+
+	err = blamewarrior.DeleteRepository(db, repo.FullName())
+
+	require.NoError(t, err)
+
+	err = blamewarrior.DeleteRepository(db, repo.FullName())
+	assert.Equal(t, blamewarrior.ErrRepositoryNotFound, err)
+
+	recreated := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos", Private: false}
+	err = blamewarrior.CreateRepository(db, recreated)
+	require.NoError(t, err)
+
+	found, err := blamewarrior.GetRepositoryByFullName(db, repo.FullName(), &blamewarrior.ListOptions{IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.Equal(t, recreated.ID, found.ID)
+	assert.Nil(t, found.DeletedAt)
+
+	err = blamewarrior.DeleteRepository(db, recreated.FullName())
+	require.NoError(t, err)
+
+	found, err = blamewarrior.GetRepositoryByFullName(db, repo.FullName(), &blamewarrior.ListOptions{IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.Equal(t, recreated.ID, found.ID)
+	assert.NotNil(t, found.DeletedAt)
+}
+
+func TestRestoreRepository(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
 
 	require.NoError(t, err)
 
@@ -141,9 +195,100 @@ func TestDeleteRepository(t *testing.T) {
 	err = blamewarrior.CreateRepository(db, repo)
 	require.NoError(t, err)
 
+	err = blamewarrior.RestoreRepository(db, repo.FullName())
+	assert.Equal(t, blamewarrior.ErrRepositoryNotFound, err)
+
 	err = blamewarrior.DeleteRepository(db, repo.FullName())
+	require.NoError(t, err)
 
+	_, err = blamewarrior.GetRepositoryByFullName(db, repo.FullName())
+	assert.Equal(t, blamewarrior.ErrRepositoryNotFound, err)
+
+	results, err := blamewarrior.GetListRepositoryByOwner(db, "blamewarrior")
 	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	results, err = blamewarrior.GetListRepositoryByOwner(db, "blamewarrior", &blamewarrior.ListOptions{IncludeDeleted: true})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.NotNil(t, results[0].DeletedAt)
+
+	err = blamewarrior.RestoreRepository(db, repo.FullName())
+	require.NoError(t, err)
+
+	restored, err := blamewarrior.GetRepositoryByFullName(db, repo.FullName())
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+}
+
+func TestUpdateRepository(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"}))
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
+
+	repo, err = blamewarrior.GetRepositoryByFullName(db, repo.FullName())
+	require.NoError(t, err)
+
+	stale := *repo
+
+	repo.Name, repo.Private = "repositories", true
+	require.NoError(t, blamewarrior.UpdateRepository(db, repo))
+	assert.Equal(t, stale.Version+1, repo.Version)
+
+	updated, err := blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repositories")
+	require.NoError(t, err)
+	assert.Equal(t, repo, updated)
+
+	stale.Private = true
+	assert.Equal(t, blamewarrior.ErrVersionMismatch, blamewarrior.UpdateRepository(db, &stale))
+
+	repo.Name = "hooks"
+	assert.Equal(t, blamewarrior.ErrRepositoryExists, blamewarrior.UpdateRepository(db, repo))
+}
+
+func TestPurgeDeletedRepositories(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+
+	require.NoError(t, err)
+
+	for _, name := range []string{"old", "recent", "active"} {
+		err = blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: name})
+		require.NoError(t, err)
+	}
+
+	_, err = db.Exec("UPDATE repositories SET deleted_at = now() - interval '60 days' WHERE name = 'old'")
+	require.NoError(t, err)
+
+	err = blamewarrior.DeleteRepository(db, "blamewarrior/recent")
+	require.NoError(t, err)
+
+	purged, err := blamewarrior.PurgeDeletedRepositories(db, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, purged)
+
+	results, err := blamewarrior.GetListRepositoryByOwner(db, "blamewarrior", &blamewarrior.ListOptions{IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	events, err := blamewarrior.GetRepositoryHistory(db, "blamewarrior/old")
+	require.NoError(t, err)
+	require.NotEmpty(t, events)
+
+	purge := events[len(events)-1]
+	assert.Equal(t, blamewarrior.EventPurged, purge.Action)
+	assert.NotEmpty(t, purge.Before)
+	assert.Nil(t, purge.After)
 }
 
 func setup() (tx *sql.Tx, teardownFn func()) {
@@ -0,0 +1,164 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"database/sql"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Replica is a read-only database connection the Router may send queries to.
+type Replica interface {
+	SQLRunner
+	Ping() error
+}
+
+// Router is an SQLRunner that sends read-only queries to a healthy replica
+// and everything else to the primary.
+type Router struct {
+	primary  SQLRunner
+	replicas []Replica
+
+	mu      sync.RWMutex
+	healthy []Replica
+	next    uint32
+}
+
+// NewRouter returns a router over primary and replicas. All replicas are
+// considered healthy until CheckReplicas says otherwise.
+func NewRouter(primary SQLRunner, replicas ...Replica) *Router {
+	return &Router{
+		primary:  primary,
+		replicas: replicas,
+		healthy:  append([]Replica(nil), replicas...),
+	}
+}
+
+// CheckReplicas pings every replica and takes the ones that fail out of rotation.
+// Replicas that respond again are put back.
+func (r *Router) CheckReplicas() {
+	var healthy []Replica
+	for _, replica := range r.replicas {
+		if err := replica.Ping(); err == nil {
+			healthy = append(healthy, replica)
+		}
+	}
+
+	r.mu.Lock()
+	r.healthy = healthy
+	r.mu.Unlock()
+}
+
+// Primary returns the runner all writes go to.
+func (r *Router) Primary() SQLRunner {
+	return r.primary
+}
+
+// Session returns a runner for a single request. It reads from replicas until the
+// first write, after which all queries go to the primary so that the request sees
+// its own writes.
+func (r *Router) Session() SQLRunner {
+	return &session{router: r}
+}
+
+func (r *Router) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return r.runnerFor(query).Query(query, args...)
+}
+
+func (r *Router) QueryRow(query string, args ...interface{}) *sql.Row {
+	return r.runnerFor(query).QueryRow(query, args...)
+}
+
+func (r *Router) Prepare(query string) (*sql.Stmt, error) {
+	return r.primary.Prepare(query)
+}
+
+func (r *Router) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return r.primary.Exec(query, args...)
+}
+
+func (r *Router) runnerFor(query string) SQLRunner {
+	if !isReadOnlyQuery(query) {
+		return r.primary
+	}
+
+	return r.replica()
+}
+
+// replica picks the next healthy replica in round-robin order falling back to the
+// primary if there is none.
+func (r *Router) replica() SQLRunner {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.healthy) == 0 {
+		return r.primary
+	}
+
+	n := atomic.AddUint32(&r.next, 1)
+
+	return r.healthy[int(n-1)%len(r.healthy)]
+}
+
+type session struct {
+	router *Router
+	pinned int32
+}
+
+func (s *session) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return s.runnerFor(query).Query(query, args...)
+}
+
+func (s *session) QueryRow(query string, args ...interface{}) *sql.Row {
+	return s.runnerFor(query).QueryRow(query, args...)
+}
+
+func (s *session) Prepare(query string) (*sql.Stmt, error) {
+	atomic.StoreInt32(&s.pinned, 1)
+	return s.router.primary.Prepare(query)
+}
+
+func (s *session) Exec(query string, args ...interface{}) (sql.Result, error) {
+	atomic.StoreInt32(&s.pinned, 1)
+	return s.router.primary.Exec(query, args...)
+}
+
+func (s *session) runnerFor(query string) SQLRunner {
+	if atomic.LoadInt32(&s.pinned) == 1 {
+		return s.router.primary
+	}
+
+	if !isReadOnlyQuery(query) {
+		atomic.StoreInt32(&s.pinned, 1)
+		return s.router.primary
+	}
+
+	return s.router.replica()
+}
+
+// isReadOnlyQuery reports whether query can be safely sent to a replica. Only plain
+// SELECT statements qualify, since INSERT ... RETURNING is run with QueryRow as well.
+func isReadOnlyQuery(query string) bool {
+	q := strings.ToLower(strings.TrimSpace(query))
+
+	if !strings.HasPrefix(q, "select") {
+		return false
+	}
+
+	return !strings.Contains(q, " for update") && !strings.Contains(q, " for share")
+}
@@ -0,0 +1,115 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRunner struct {
+	queries []string
+	pingErr error
+}
+
+func (r *fakeRunner) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	r.queries = append(r.queries, query)
+	return nil, nil
+}
+
+func (r *fakeRunner) QueryRow(query string, args ...interface{}) *sql.Row {
+	r.queries = append(r.queries, query)
+	return nil
+}
+
+func (r *fakeRunner) Prepare(query string) (*sql.Stmt, error) {
+	r.queries = append(r.queries, query)
+	return nil, nil
+}
+
+func (r *fakeRunner) Exec(query string, args ...interface{}) (sql.Result, error) {
+	r.queries = append(r.queries, query)
+	return nil, nil
+}
+
+func (r *fakeRunner) Ping() error {
+	return r.pingErr
+}
+
+func TestRouter_RoutesReadsToReplicas(t *testing.T) {
+	primary, replica1, replica2 := new(fakeRunner), new(fakeRunner), new(fakeRunner)
+
+	router := blamewarrior.NewRouter(primary, replica1, replica2)
+
+	router.Query(blamewarrior.GetListRepositoryByOwnerQuery, "blamewarrior")
+	router.QueryRow(blamewarrior.GetRepositoryQuery, "blamewarrior", "repos")
+	router.QueryRow(blamewarrior.CreateRepositoryQuery, "blamewarrior", "repos", false)
+	router.Exec(blamewarrior.DeleteRepositoryQuery, "blamewarrior", "repos")
+	router.Query("SELECT id FROM repositories FOR UPDATE")
+
+	assert.Equal(t, []string{
+		blamewarrior.CreateRepositoryQuery,
+		blamewarrior.DeleteRepositoryQuery,
+		"SELECT id FROM repositories FOR UPDATE",
+	}, primary.queries)
+	assert.Equal(t, []string{blamewarrior.GetListRepositoryByOwnerQuery}, replica1.queries)
+	assert.Equal(t, []string{blamewarrior.GetRepositoryQuery}, replica2.queries)
+}
+
+func TestRouter_CheckReplicas(t *testing.T) {
+	primary, replica1, replica2 := new(fakeRunner), new(fakeRunner), new(fakeRunner)
+	replica1.pingErr = errors.New("connection refused")
+
+	router := blamewarrior.NewRouter(primary, replica1, replica2)
+	router.CheckReplicas()
+
+	router.Query(blamewarrior.GetListRepositoryByOwnerQuery, "blamewarrior")
+	router.Query(blamewarrior.GetListRepositoryByOwnerQuery, "blamewarrior")
+
+	assert.Empty(t, replica1.queries)
+	assert.Len(t, replica2.queries, 2)
+
+	replica2.pingErr = errors.New("connection refused")
+	router.CheckReplicas()
+
+	router.Query(blamewarrior.GetListRepositoryByOwnerQuery, "blamewarrior")
+
+	assert.Len(t, primary.queries, 1)
+
+	replica1.pingErr = nil
+	router.CheckReplicas()
+
+	router.Query(blamewarrior.GetListRepositoryByOwnerQuery, "blamewarrior")
+
+	assert.Len(t, replica1.queries, 1)
+}
+
+func TestRouter_Session(t *testing.T) {
+	primary, replica := new(fakeRunner), new(fakeRunner)
+
+	session := blamewarrior.NewRouter(primary, replica).Session()
+
+	session.QueryRow(blamewarrior.GetRepositoryQuery, "blamewarrior", "repos")
+	session.QueryRow(blamewarrior.CreateRepositoryQuery, "blamewarrior", "repos", false)
+	session.QueryRow(blamewarrior.GetRepositoryQuery, "blamewarrior", "repos")
+
+	assert.Equal(t, []string{blamewarrior.GetRepositoryQuery}, replica.queries)
+	assert.Equal(t, []string{blamewarrior.CreateRepositoryQuery, blamewarrior.GetRepositoryQuery}, primary.queries)
+}
@@ -0,0 +1,210 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// settingsSchemas contains JSON schemas for every supported version of BlameWarrior settings.
+var settingsSchemas = map[int]string{
+	1: `{
+		"type": "object",
+		"required": ["version"],
+		"additionalProperties": false,
+		"properties": {
+			"version": {"type": "integer", "enum": [1]},
+			"ignored_paths": {
+				"type": "array",
+				"items": {"type": "string", "minLength": 1}
+			},
+			"reviewers": {
+				"type": "object",
+				"additionalProperties": false,
+				"properties": {
+					"count": {"type": "integer", "minimum": 0, "maximum": 10},
+					"rules": {
+						"type": "array",
+						"items": {
+							"type": "object",
+							"required": ["paths", "reviewers"],
+							"additionalProperties": false,
+							"properties": {
+								"paths": {"type": "array", "items": {"type": "string", "minLength": 1}},
+								"reviewers": {"type": "array", "items": {"type": "string", "minLength": 1}}
+							}
+						}
+					}
+				}
+			},
+			"staleness": {
+				"type": "object",
+				"additionalProperties": false,
+				"properties": {
+					"inactive_after_days": {"type": "integer", "minimum": 1},
+					"action": {"type": "string", "enum": ["notify", "disable_hook", "untrack"]},
+					"untrack_after_days": {"type": "integer", "minimum": 0}
+				}
+			}
+		}
+	}`,
+}
+
+// SettingsSchema returns JSON schema of given settings version.
+func SettingsSchema(version int) (*JSONSchema, error) {
+	doc, ok := settingsSchemas[version]
+	if !ok {
+		return nil, fmt.Errorf("unsupported settings version %d", version)
+	}
+
+	return ParseJSONSchema(doc)
+}
+
+// ValidateSettings checks that settings document conforms the JSON schema of the version
+// it declares.
+func ValidateSettings(settings json.RawMessage) error {
+	var header struct {
+		Version *int `json:"version"`
+	}
+
+	if err := json.Unmarshal(settings, &header); err != nil {
+		return fmt.Errorf("settings must be a JSON object")
+	}
+
+	if header.Version == nil {
+		return fmt.Errorf("settings version must be specified")
+	}
+
+	schema, err := SettingsSchema(*header.Version)
+	if err != nil {
+		return err
+	}
+
+	if err := schema.Validate(settings); err != nil {
+		return fmt.Errorf("invalid settings: %s", err)
+	}
+
+	return nil
+}
+
+// MergeSettings returns overrides merged into defaults. Objects are merged recursively,
+// while any other value in overrides replaces the default one.
+func MergeSettings(defaults, overrides json.RawMessage) (json.RawMessage, error) {
+	if len(defaults) == 0 {
+		return overrides, nil
+	}
+
+	if len(overrides) == 0 {
+		return defaults, nil
+	}
+
+	var d, o interface{}
+
+	if err := json.Unmarshal(defaults, &d); err != nil {
+		return nil, fmt.Errorf("malformed default settings: %s", err)
+	}
+
+	if err := json.Unmarshal(overrides, &o); err != nil {
+		return nil, fmt.Errorf("malformed settings: %s", err)
+	}
+
+	return json.Marshal(mergeSettings(d, o))
+}
+
+func mergeSettings(defaults, overrides interface{}) interface{} {
+	d, ok := defaults.(map[string]interface{})
+	if !ok {
+		return overrides
+	}
+
+	o, ok := overrides.(map[string]interface{})
+	if !ok {
+		return overrides
+	}
+
+	merged := make(map[string]interface{}, len(d))
+	for k, v := range d {
+		merged[k] = v
+	}
+
+	for k, v := range o {
+		merged[k] = mergeSettings(merged[k], v)
+	}
+
+	return merged
+}
+
+// GetRepositorySettings returns effective settings of repository. Settings stored for repository
+// override owner defaults and are themselves overridden by the configuration file kept in repository.
+func GetRepositorySettings(runner SQLRunner, fullName string) (json.RawMessage, error) {
+	repo, err := GetRepositoryByFullName(runner, fullName)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := GetOwnerSettings(runner, repo.Provider, repo.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings, err = MergeSettings(settings, repo.Settings); err != nil {
+		return nil, err
+	}
+
+	if repo.Config != nil {
+		return MergeSettings(settings, repo.Config.Settings)
+	}
+
+	return settings, nil
+}
+
+// GetOwnerSettings returns default settings of repositories that belong to owner at provider.
+// It returns nil if there are no defaults set.
+func GetOwnerSettings(runner SQLRunner, provider, owner string) (json.RawMessage, error) {
+	var settings []byte
+
+	err := runner.QueryRow(GetOwnerSettingsQuery, provider, owner).Scan(&settings)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch owner settings: %s", err)
+	}
+
+	return settings, nil
+}
+
+// UpdateOwnerSettings replaces default settings of repositories that belong to owner at provider.
+func UpdateOwnerSettings(runner SQLRunner, provider, owner string, settings json.RawMessage) error {
+	if err := ValidateSettings(settings); err != nil {
+		return err
+	}
+
+	if _, err := runner.Exec(UpdateOwnerSettingsQuery, provider, owner, string(settings)); err != nil {
+		return fmt.Errorf("failed to update owner settings: %s", err)
+	}
+
+	return nil
+}
+
+const (
+	GetOwnerSettingsQuery    = `SELECT settings FROM owner_settings WHERE provider=$1 AND owner=$2`
+	UpdateOwnerSettingsQuery = `INSERT INTO owner_settings (provider, owner, settings) VALUES ($1, $2, $3) ON CONFLICT (provider, owner) DO UPDATE SET settings=EXCLUDED.settings`
+)
@@ -0,0 +1,115 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSettings(t *testing.T) {
+	examples := map[string]struct {
+		Settings string
+		Err      error
+	}{
+		"empty":           {`{"version":1}`, nil},
+		"full":            {`{"version":1,"ignored_paths":["vendor/"],"reviewers":{"count":2,"rules":[{"paths":["*.go"],"reviewers":["octocat"]}]}}`, nil},
+		"not an object":   {`"settings"`, errors.New("settings must be a JSON object")},
+		"missing version": {`{"ignored_paths":[]}`, errors.New("settings version must be specified")},
+		"unknown version": {`{"version":100}`, errors.New("unsupported settings version 100")},
+		"unknown setting": {`{"version":1,"colour":"red"}`, errors.New("invalid settings: colour is not allowed")},
+		"invalid rule":    {`{"version":1,"reviewers":{"rules":[{"paths":["*.go"]}]}}`, errors.New("invalid settings: reviewers.rules[0].reviewers is required")},
+	}
+
+	for name, example := range examples {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, example.Err, blamewarrior.ValidateSettings(json.RawMessage(example.Settings)))
+		})
+	}
+}
+
+func TestMergeSettings(t *testing.T) {
+	examples := map[string]struct {
+		Defaults, Overrides, Result string
+	}{
+		"no defaults":  {"", `{"version":1}`, `{"version":1}`},
+		"no overrides": {`{"version":1}`, "", `{"version":1}`},
+		"override value": {
+			`{"version":1,"reviewers":{"count":1,"rules":[{"paths":["*"],"reviewers":["a"]}]}}`,
+			`{"version":1,"reviewers":{"count":2}}`,
+			`{"version":1,"reviewers":{"count":2,"rules":[{"paths":["*"],"reviewers":["a"]}]}}`,
+		},
+		"replace array": {
+			`{"version":1,"ignored_paths":["vendor/"]}`,
+			`{"version":1,"ignored_paths":["docs/"]}`,
+			`{"version":1,"ignored_paths":["docs/"]}`,
+		},
+	}
+
+	for name, example := range examples {
+		t.Run(name, func(t *testing.T) {
+			result, err := blamewarrior.MergeSettings(json.RawMessage(example.Defaults), json.RawMessage(example.Overrides))
+			require.NoError(t, err)
+			assert.JSONEq(t, example.Result, string(result))
+		})
+	}
+}
+
+func TestGetRepositorySettings(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, owner_settings CASCADE;")
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{
+		Owner:    "blamewarrior",
+		Name:     "repos",
+		Settings: json.RawMessage(`{"version":1,"reviewers":{"count":2}}`),
+	}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
+
+	settings, err := blamewarrior.GetRepositorySettings(db, repo.FullName())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":1,"reviewers":{"count":2}}`, string(settings))
+
+	err = blamewarrior.UpdateOwnerSettings(db, blamewarrior.ProviderGithub, "blamewarrior", json.RawMessage(`{"version":1,"ignored_paths":["vendor/"],"reviewers":{"count":1}}`))
+	require.NoError(t, err)
+
+	settings, err = blamewarrior.GetRepositorySettings(db, repo.FullName())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":1,"ignored_paths":["vendor/"],"reviewers":{"count":2}}`, string(settings))
+
+	err = blamewarrior.UpdateOwnerSettings(db, blamewarrior.ProviderGithub, "blamewarrior", json.RawMessage(`{"version":2}`))
+	assert.Error(t, err)
+
+	// defaults of an owner with the same name at another provider do not apply
+	err = blamewarrior.UpdateOwnerSettings(db, blamewarrior.ProviderGitlab, "blamewarrior", json.RawMessage(`{"version":1,"ignored_paths":["node_modules/"]}`))
+	require.NoError(t, err)
+
+	settings, err = blamewarrior.GetRepositorySettings(db, repo.FullName())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":1,"ignored_paths":["vendor/"],"reviewers":{"count":2}}`, string(settings))
+
+	settings, err = blamewarrior.GetOwnerSettings(db, blamewarrior.ProviderGitlab, "blamewarrior")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":1,"ignored_paths":["node_modules/"]}`, string(settings))
+}
@@ -0,0 +1,156 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Repository states
+const (
+	StateActive   = "active"
+	StateInactive = "inactive"
+	// StatePending repositories are tracked, but their webhook is not set up yet
+	StatePending = "pending"
+	// StateFailed repositories are tracked, but their webhook could not be set up
+	StateFailed = "failed"
+)
+
+// Actions taken when repository becomes inactive
+const (
+	StalenessNotify      = "notify"
+	StalenessDisableHook = "disable_hook"
+	StalenessUntrack     = "untrack"
+)
+
+// Default staleness policy values
+const (
+	DefaultInactiveAfterDays = 365
+	DefaultUntrackAfterDays  = 30
+)
+
+// StalenessPolicy defines when a repository is considered inactive and what happens to it then.
+// It is configured per owner at provider in "staleness" section of owner settings.
+type StalenessPolicy struct {
+	// InactiveAfterDays is the number of days without pushes after which repository becomes inactive
+	InactiveAfterDays int `json:"inactive_after_days"`
+	// Action is one of StalenessNotify, StalenessDisableHook or StalenessUntrack
+	Action string `json:"action"`
+	// UntrackAfterDays is the number of days repository stays inactive before it is untracked
+	// if Action is StalenessUntrack
+	UntrackAfterDays int `json:"untrack_after_days"`
+}
+
+// GetStalenessPolicy returns staleness policy of repositories that belong to owner at provider
+// with defaults applied.
+func GetStalenessPolicy(runner SQLRunner, provider, owner string) (*StalenessPolicy, error) {
+	settings, err := GetOwnerSettings(runner, provider, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseStalenessPolicy(settings)
+}
+
+// ParseStalenessPolicy extracts staleness policy from settings document applying defaults
+// for missing values.
+func ParseStalenessPolicy(settings json.RawMessage) (*StalenessPolicy, error) {
+	var doc struct {
+		Staleness struct {
+			StalenessPolicy
+			// UntrackAfterDays shadows the embedded field to tell explicit zero from missing value
+			UntrackAfterDays *int `json:"untrack_after_days"`
+		} `json:"staleness"`
+	}
+
+	if len(settings) > 0 {
+		if err := json.Unmarshal(settings, &doc); err != nil {
+			return nil, fmt.Errorf("malformed staleness policy: %s", err)
+		}
+	}
+
+	policy := &doc.Staleness.StalenessPolicy
+
+	if policy.InactiveAfterDays == 0 {
+		policy.InactiveAfterDays = DefaultInactiveAfterDays
+	}
+
+	if policy.Action == "" {
+		policy.Action = StalenessNotify
+	}
+
+	policy.UntrackAfterDays = DefaultUntrackAfterDays
+	if doc.Staleness.UntrackAfterDays != nil {
+		policy.UntrackAfterDays = *doc.Staleness.UntrackAfterDays
+	}
+
+	return policy, nil
+}
+
+// Inactive reports whether repository is archived or has not been pushed to for longer than
+// policy allows. Repositories which metadata has not been fetched yet are never inactive.
+func (policy *StalenessPolicy) Inactive(repo *Repository, now time.Time) bool {
+	if repo.Metadata == nil {
+		return false
+	}
+
+	if repo.Metadata.Archived {
+		return true
+	}
+
+	if repo.Metadata.PushedAt == nil {
+		return false
+	}
+
+	return now.Sub(*repo.Metadata.PushedAt) > days(policy.InactiveAfterDays)
+}
+
+// Untrack reports whether inactive repository should stop being tracked.
+func (policy *StalenessPolicy) Untrack(repo *Repository, now time.Time) bool {
+	if policy.Action != StalenessUntrack || repo.State != StateInactive || repo.InactiveSince == nil {
+		return false
+	}
+
+	return now.Sub(*repo.InactiveSince) >= days(policy.UntrackAfterDays)
+}
+
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
+
+// UpdateRepositoryState changes state of repository. The time repository became inactive is
+// recorded so that untracking can be scheduled.
+func UpdateRepositoryState(runner SQLRunner, repo *Repository, state string, changes ...*Change) error {
+	if state != StateActive && state != StateInactive && state != StateFailed {
+		return fmt.Errorf("unknown repository state %s", state)
+	}
+
+	before := *repo
+
+	err := runner.QueryRow(UpdateRepositoryStateQuery, repo.ID, state).Scan(&repo.Version, &repo.InactiveSince)
+
+	if err != nil {
+		return fmt.Errorf("failed to update repository state: %s", err)
+	}
+
+	repo.State = state
+
+	return recordEvent(runner, EventUpdated, &before, repo, changes...)
+}
+
+const UpdateRepositoryStateQuery = `UPDATE repositories SET state=$2, inactive_since=CASE WHEN $2='inactive' THEN now() END, version=version+1 WHERE id=$1 AND deleted_at IS NULL RETURNING version, inactive_since`
@@ -0,0 +1,153 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStalenessPolicy(t *testing.T) {
+	examples := map[string]struct {
+		Settings string
+		Policy   blamewarrior.StalenessPolicy
+	}{
+		"no settings": {
+			Policy: blamewarrior.StalenessPolicy{InactiveAfterDays: 365, Action: "notify", UntrackAfterDays: 30},
+		},
+		"no staleness section": {
+			Settings: `{"version":1,"ignored_paths":["vendor/"]}`,
+			Policy:   blamewarrior.StalenessPolicy{InactiveAfterDays: 365, Action: "notify", UntrackAfterDays: 30},
+		},
+		"partial policy": {
+			Settings: `{"version":1,"staleness":{"action":"disable_hook","inactive_after_days":90}}`,
+			Policy:   blamewarrior.StalenessPolicy{InactiveAfterDays: 90, Action: "disable_hook", UntrackAfterDays: 30},
+		},
+		"full policy": {
+			Settings: `{"version":1,"staleness":{"action":"untrack","inactive_after_days":180,"untrack_after_days":7}}`,
+			Policy:   blamewarrior.StalenessPolicy{InactiveAfterDays: 180, Action: "untrack", UntrackAfterDays: 7},
+		},
+		"untrack immediately": {
+			Settings: `{"version":1,"staleness":{"action":"untrack","untrack_after_days":0}}`,
+			Policy:   blamewarrior.StalenessPolicy{InactiveAfterDays: 365, Action: "untrack", UntrackAfterDays: 0},
+		},
+	}
+
+	for name, example := range examples {
+		t.Run(name, func(t *testing.T) {
+			policy, err := blamewarrior.ParseStalenessPolicy(json.RawMessage(example.Settings))
+			require.NoError(t, err)
+			assert.Equal(t, example.Policy, *policy)
+		})
+	}
+
+	assert.NoError(t, blamewarrior.ValidateSettings(json.RawMessage(`{"version":1,"staleness":{"action":"untrack","untrack_after_days":7}}`)))
+	assert.Error(t, blamewarrior.ValidateSettings(json.RawMessage(`{"version":1,"staleness":{"action":"archive"}}`)))
+}
+
+func TestStalenessPolicy_Inactive(t *testing.T) {
+	now := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	policy := &blamewarrior.StalenessPolicy{InactiveAfterDays: 365, Action: blamewarrior.StalenessNotify}
+
+	pushedAt := func(t time.Time) *time.Time { return &t }
+
+	examples := map[string]struct {
+		Metadata *blamewarrior.RepositoryMetadata
+		Inactive bool
+	}{
+		"metadata not fetched": {
+			Metadata: nil,
+		},
+		"never pushed": {
+			Metadata: &blamewarrior.RepositoryMetadata{},
+		},
+		"recently pushed": {
+			Metadata: &blamewarrior.RepositoryMetadata{PushedAt: pushedAt(now.AddDate(0, -1, 0))},
+		},
+		"pushed long ago": {
+			Metadata: &blamewarrior.RepositoryMetadata{PushedAt: pushedAt(now.AddDate(-2, 0, 0))},
+			Inactive: true,
+		},
+		"archived": {
+			Metadata: &blamewarrior.RepositoryMetadata{PushedAt: pushedAt(now), Archived: true},
+			Inactive: true,
+		},
+	}
+
+	for name, example := range examples {
+		repo := &blamewarrior.Repository{Metadata: example.Metadata}
+		assert.Equal(t, example.Inactive, policy.Inactive(repo, now), name)
+	}
+}
+
+func TestStalenessPolicy_Untrack(t *testing.T) {
+	now := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	longAgo := now.AddDate(0, -2, 0)
+	recently := now.AddDate(0, 0, -1)
+
+	examples := []struct {
+		Action        string
+		State         string
+		InactiveSince *time.Time
+		Untrack       bool
+	}{
+		{blamewarrior.StalenessUntrack, blamewarrior.StateInactive, &longAgo, true},
+		{blamewarrior.StalenessUntrack, blamewarrior.StateInactive, &recently, false},
+		{blamewarrior.StalenessUntrack, blamewarrior.StateActive, nil, false},
+		{blamewarrior.StalenessDisableHook, blamewarrior.StateInactive, &longAgo, false},
+		{blamewarrior.StalenessNotify, blamewarrior.StateInactive, &longAgo, false},
+	}
+
+	for _, example := range examples {
+		policy := &blamewarrior.StalenessPolicy{InactiveAfterDays: 365, Action: example.Action, UntrackAfterDays: 30}
+		repo := &blamewarrior.Repository{State: example.State, InactiveSince: example.InactiveSince}
+
+		assert.Equal(t, example.Untrack, policy.Untrack(repo, now), "%s %s", example.Action, example.State)
+	}
+}
+
+func TestUpdateRepositoryState(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"}))
+
+	repo, err := blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.StateActive, repo.State)
+
+	require.NoError(t, blamewarrior.UpdateRepositoryState(db, repo, blamewarrior.StateInactive))
+	require.NotNil(t, repo.InactiveSince)
+
+	inactive, err := blamewarrior.GetListRepositoryByOwner(db, "blamewarrior", &blamewarrior.ListOptions{State: blamewarrior.StateInactive})
+	require.NoError(t, err)
+	require.Len(t, inactive, 1)
+	assert.Equal(t, "repos", inactive[0].Name)
+
+	require.NoError(t, blamewarrior.UpdateRepositoryState(db, repo, blamewarrior.StateActive))
+	assert.Nil(t, repo.InactiveSince)
+
+	assert.Error(t, blamewarrior.UpdateRepositoryState(db, repo, "archived"))
+}
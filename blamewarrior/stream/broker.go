@@ -0,0 +1,87 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package stream
+
+import (
+	"sync"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// subscriptionBufferSize is the number of events a subscriber may fall behind before it is
+// disconnected.
+const subscriptionBufferSize = 256
+
+// Broker is a Publisher that passes events to in-process subscribers, such as open
+// Server-Sent Events connections.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[chan blamewarrior.RepositoryEvent]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[chan blamewarrior.RepositoryEvent]struct{}),
+	}
+}
+
+// Subscribe returns channel published events are sent to and a function that cancels
+// the subscription. The channel is closed when subscription is cancelled or if subscriber
+// does not keep up with published events, in which case it is expected to resubscribe and
+// catch up from the audit log.
+func (broker *Broker) Subscribe() (<-chan blamewarrior.RepositoryEvent, func()) {
+	ch := make(chan blamewarrior.RepositoryEvent, subscriptionBufferSize)
+
+	broker.mu.Lock()
+	broker.subscribers[ch] = struct{}{}
+	broker.mu.Unlock()
+
+	return ch, func() {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+
+		broker.unsubscribe(ch)
+	}
+}
+
+func (broker *Broker) Publish(events []blamewarrior.RepositoryEvent) error {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	for ch := range broker.subscribers {
+		for _, event := range events {
+			select {
+			case ch <- event:
+				continue
+			default:
+			}
+
+			broker.unsubscribe(ch)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (broker *Broker) unsubscribe(ch chan blamewarrior.RepositoryEvent) {
+	if _, ok := broker.subscribers[ch]; !ok {
+		return
+	}
+
+	delete(broker.subscribers, ch)
+	close(ch)
+}
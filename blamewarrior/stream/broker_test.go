@@ -0,0 +1,74 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/stream"
+)
+
+func TestBroker(t *testing.T) {
+	broker := stream.NewBroker()
+
+	ch1, cancel1 := broker.Subscribe()
+	ch2, cancel2 := broker.Subscribe()
+	defer cancel2()
+
+	events := []blamewarrior.RepositoryEvent{{ID: 1}, {ID: 2}}
+	require.NoError(t, broker.Publish(events))
+
+	for _, ch := range []<-chan blamewarrior.RepositoryEvent{ch1, ch2} {
+		assert.Equal(t, int64(1), (<-ch).ID)
+		assert.Equal(t, int64(2), (<-ch).ID)
+	}
+
+	cancel1()
+
+	_, ok := <-ch1
+	assert.False(t, ok, "channel should be closed once subscription is cancelled")
+
+	// cancelling twice is harmless
+	cancel1()
+
+	require.NoError(t, broker.Publish([]blamewarrior.RepositoryEvent{{ID: 3}}))
+	assert.Equal(t, int64(3), (<-ch2).ID)
+}
+
+func TestBroker_SlowSubscriber(t *testing.T) {
+	broker := stream.NewBroker()
+
+	ch, cancel := broker.Subscribe()
+	defer cancel()
+
+	events := make([]blamewarrior.RepositoryEvent, 1000)
+	for i := range events {
+		events[i].ID = int64(i + 1)
+	}
+
+	require.NoError(t, broker.Publish(events))
+
+	var received int
+	for range ch {
+		received++
+	}
+
+	assert.True(t, received < len(events), "slow subscriber should be disconnected")
+}
@@ -0,0 +1,72 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package stream delivers repository change events to other BlameWarrior services.
+package stream
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// Publisher delivers repository events. Events are passed in the order they were recorded,
+// and the same events may be passed again if previous attempt failed.
+type Publisher interface {
+	Publish(events []blamewarrior.RepositoryEvent) error
+}
+
+// webhookPublisherTimeout limits the time a single webhook request may take
+const webhookPublisherTimeout = 10 * time.Second
+
+// WebhookPublisher posts events as a JSON array to each of configured URLs. Use a separate
+// WebhookPublisher per URL to retry delivery to each URL independently.
+type WebhookPublisher struct {
+	URLs []string
+	c    *http.Client
+}
+
+func NewWebhookPublisher(urls ...string) *WebhookPublisher {
+	return &WebhookPublisher{
+		URLs: urls,
+		c:    &http.Client{Timeout: webhookPublisherTimeout},
+	}
+}
+
+func (publisher *WebhookPublisher) Publish(events []blamewarrior.RepositoryEvent) error {
+	payload, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	for _, url := range publisher.URLs {
+		response, err := publisher.c.Post(url, "application/json", bytes.NewReader(payload))
+
+		if err != nil {
+			return fmt.Errorf("failed to publish events to %s: %s", url, err)
+		}
+		response.Body.Close()
+
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			return fmt.Errorf("failed to publish events to %s: unexpected response status %d", url, response.StatusCode)
+		}
+	}
+
+	return nil
+}
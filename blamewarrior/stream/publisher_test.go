@@ -0,0 +1,81 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package stream_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/stream"
+)
+
+func TestWebhookPublisher_Publish(t *testing.T) {
+	events := []blamewarrior.RepositoryEvent{
+		{
+			ID: 1, Owner: "blamewarrior", Name: "repos", Action: blamewarrior.EventCreated,
+			Before: json.RawMessage(`null`), After: json.RawMessage(`{"name":"repos"}`),
+		},
+		{
+			ID: 2, Owner: "blamewarrior", Name: "repos", Action: blamewarrior.EventDeleted,
+			Before: json.RawMessage(`{"name":"repos"}`), After: json.RawMessage(`null`),
+		},
+	}
+
+	var received [][]blamewarrior.RepositoryEvent
+
+	handler := func(status int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			var payload []blamewarrior.RepositoryEvent
+			require.NoError(t, json.Unmarshal(body, &payload))
+
+			received = append(received, payload)
+
+			w.WriteHeader(status)
+		}
+	}
+
+	hooks := httptest.NewServer(handler(http.StatusNoContent))
+	defer hooks.Close()
+
+	reviewers := httptest.NewServer(handler(http.StatusOK))
+	defer reviewers.Close()
+
+	broken := httptest.NewServer(handler(http.StatusServiceUnavailable))
+	defer broken.Close()
+
+	publisher := stream.NewWebhookPublisher(hooks.URL, reviewers.URL)
+	require.NoError(t, publisher.Publish(events))
+
+	require.Len(t, received, 2)
+	assert.Equal(t, events, received[0])
+	assert.Equal(t, events, received[1])
+
+	publisher = stream.NewWebhookPublisher(broken.URL)
+	assert.Error(t, publisher.Publish(events))
+}
@@ -0,0 +1,111 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package stream
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// Headers sent along with subscription deliveries
+const (
+	SignatureHeader = "X-BlameWarrior-Signature"
+	EventHeader     = "X-BlameWarrior-Event"
+	DeliveryHeader  = "X-BlameWarrior-Delivery"
+)
+
+const subscriberTimeout = 10 * time.Second
+
+// Sign returns HMAC-SHA256 signature of payload in the form of "sha256=<hex digest>", the same
+// way GitHub signs its webhooks.
+func Sign(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidSignature reports whether signature matches payload signed with secret.
+func ValidSignature(secret, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, payload)), []byte(signature))
+}
+
+// SubscriberClient delivers single repository events to subscriptions.
+type SubscriberClient struct {
+	c *http.Client
+}
+
+func NewSubscriberClient() *SubscriberClient {
+	return &SubscriberClient{
+		c: &http.Client{Timeout: subscriberTimeout},
+	}
+}
+
+// Deliver posts signed event to subscription URL and returns the outcome of the attempt.
+// Delivery is considered successful if subscriber responds with 2xx status.
+func (client *SubscriberClient) Deliver(sub *blamewarrior.Subscription, event *blamewarrior.RepositoryEvent, attempt int) *blamewarrior.Delivery {
+	delivery := &blamewarrior.Delivery{
+		SubscriptionID: sub.ID,
+		EventID:        event.ID,
+		Attempt:        attempt,
+		DeliveredAt:    time.Now(),
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	req, err := http.NewRequest("POST", sub.URL, bytes.NewReader(payload))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign([]byte(sub.Secret), payload))
+	req.Header.Set(EventHeader, event.Action)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(event.ID, 10))
+
+	response, err := client.c.Do(req)
+	delivery.Latency = time.Since(delivery.DeliveredAt)
+
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	io.Copy(ioutil.Discard, io.LimitReader(response.Body, 4096))
+	response.Body.Close()
+
+	delivery.StatusCode = response.StatusCode
+	if !delivery.Succeeded() {
+		delivery.Error = response.Status
+	}
+
+	return delivery
+}
@@ -0,0 +1,95 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package stream_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/stream"
+)
+
+func TestSign(t *testing.T) {
+	signature := stream.Sign([]byte("secret"), []byte(`{"id":1}`))
+
+	assert.Equal(t, "sha256=03def589620c813f198fd03d7967e292b163ef0435ebf43071ce0e9519763cb7", signature)
+	assert.True(t, stream.ValidSignature([]byte("secret"), []byte(`{"id":1}`), signature))
+	assert.False(t, stream.ValidSignature([]byte("other"), []byte(`{"id":1}`), signature))
+	assert.False(t, stream.ValidSignature([]byte("secret"), []byte(`{"id":2}`), signature))
+}
+
+func TestSubscriberClient_Deliver(t *testing.T) {
+	event := &blamewarrior.RepositoryEvent{
+		ID: 42, Owner: "blamewarrior", Name: "repos", Action: blamewarrior.EventCreated,
+		Before: json.RawMessage(`null`), After: json.RawMessage(`{"name":"repos"}`),
+	}
+
+	results := []struct {
+		ResponseStatus int
+		Succeeded      bool
+		Error          string
+	}{
+		{ResponseStatus: http.StatusOK, Succeeded: true},
+		{ResponseStatus: http.StatusAccepted, Succeeded: true},
+		{ResponseStatus: http.StatusGone, Succeeded: false, Error: "410 Gone"},
+	}
+
+	for _, result := range results {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "created", r.Header.Get(stream.EventHeader))
+			assert.Equal(t, "42", r.Header.Get(stream.DeliveryHeader))
+			assert.True(t, stream.ValidSignature([]byte("s3cr3t"), body, r.Header.Get(stream.SignatureHeader)))
+
+			var received blamewarrior.RepositoryEvent
+			require.NoError(t, json.Unmarshal(body, &received))
+			assert.Equal(t, *event, received)
+
+			w.WriteHeader(result.ResponseStatus)
+		}))
+
+		sub := &blamewarrior.Subscription{ID: 7, URL: srv.URL, Secret: "s3cr3t"}
+
+		delivery := stream.NewSubscriberClient().Deliver(sub, event, 3)
+
+		assert.Equal(t, 7, delivery.SubscriptionID)
+		assert.Equal(t, int64(42), delivery.EventID)
+		assert.Equal(t, 3, delivery.Attempt)
+		assert.Equal(t, result.ResponseStatus, delivery.StatusCode)
+		assert.Equal(t, result.Succeeded, delivery.Succeeded())
+		assert.Equal(t, result.Error, delivery.Error)
+		assert.True(t, delivery.Latency > 0)
+
+		srv.Close()
+	}
+
+	// unreachable subscriber
+	delivery := stream.NewSubscriberClient().Deliver(&blamewarrior.Subscription{URL: "http://127.0.0.1:1"}, event, 1)
+
+	assert.False(t, delivery.Succeeded())
+	assert.Equal(t, 0, delivery.StatusCode)
+	assert.NotEmpty(t, delivery.Error)
+}
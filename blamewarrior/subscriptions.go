@@ -0,0 +1,412 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// Subscription is an external endpoint that receives signed repository events.
+type Subscription struct {
+	ID  int    `json:"id"`
+	URL string `json:"url"`
+	// Events is a list of event actions to deliver, all events are delivered if it is empty
+	Events []string `json:"events"`
+	// Secret is used to sign delivered payloads and is never exposed
+	Secret string `json:"-"`
+	Active bool   `json:"active"`
+	// Failures is the number of consecutive failed delivery attempts
+	Failures   int        `json:"failures"`
+	CreatedAt  time.Time  `json:"created_at"`
+	DisabledAt *time.Time `json:"disabled_at"`
+}
+
+// Validate checks that subscription can be stored.
+func (sub *Subscription) Validate() error {
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+
+	if sub.Secret == "" {
+		return fmt.Errorf("secret must not be empty")
+	}
+
+	for _, action := range sub.Events {
+		switch action {
+		case EventCreated, EventDeleted, EventUpdated, EventRestored, EventPurged:
+		default:
+			return fmt.Errorf("unknown event %s", action)
+		}
+	}
+
+	return nil
+}
+
+// Accepts reports whether events with given action are delivered to subscription.
+func (sub *Subscription) Accepts(action string) bool {
+	if len(sub.Events) == 0 {
+		return true
+	}
+
+	for _, a := range sub.Events {
+		if a == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Delivery is a single attempt to deliver an event to subscription.
+type Delivery struct {
+	ID             int64 `json:"id"`
+	SubscriptionID int   `json:"subscription_id"`
+	EventID        int64 `json:"event_id"`
+	Attempt        int   `json:"attempt"`
+	// StatusCode is zero if no response has been received
+	StatusCode  int           `json:"status_code"`
+	Error       string        `json:"error"`
+	Latency     time.Duration `json:"-"`
+	DeliveredAt time.Time     `json:"delivered_at"`
+}
+
+// Succeeded reports whether subscriber has acknowledged delivery.
+func (d *Delivery) Succeeded() bool {
+	return d.StatusCode >= 200 && d.StatusCode < 300
+}
+
+func (d Delivery) MarshalJSON() ([]byte, error) {
+	type Alias Delivery
+
+	return json.Marshal(&struct {
+		Alias
+		LatencyMs int64 `json:"latency_ms"`
+	}{
+		Alias:     Alias(d),
+		LatencyMs: int64(d.Latency / time.Millisecond),
+	})
+}
+
+func (d *Delivery) UnmarshalJSON(b []byte) error {
+	type Alias Delivery
+
+	v := struct {
+		*Alias
+		LatencyMs int64 `json:"latency_ms"`
+	}{
+		Alias: (*Alias)(d),
+	}
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	d.Latency = time.Duration(v.LatencyMs) * time.Millisecond
+
+	return nil
+}
+
+// PendingDelivery is a repository event queued for delivery to subscription.
+type PendingDelivery struct {
+	Subscription Subscription
+	Event        RepositoryEvent
+	// Attempts is the number of previous delivery attempts
+	Attempts int
+}
+
+// CreateSubscription stores a new active subscription.
+func CreateSubscription(runner SQLRunner, sub *Subscription) error {
+	if sub.Events == nil {
+		sub.Events = []string{}
+	}
+
+	err := runner.QueryRow(CreateSubscriptionQuery, sub.URL, pq.Array(sub.Events), sub.Secret).Scan(&sub.ID, &sub.Active, &sub.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create subscription: %s", err)
+	}
+
+	return nil
+}
+
+// GetSubscription returns subscription with given ID.
+func GetSubscription(runner SQLRunner, id int) (*Subscription, error) {
+	subscriptions, err := querySubscriptions(runner, GetSubscriptionQuery, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(subscriptions) == 0 {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	return &subscriptions[0], nil
+}
+
+// GetSubscriptions returns all subscriptions ordered by ID.
+func GetSubscriptions(runner SQLRunner) ([]Subscription, error) {
+	return querySubscriptions(runner, GetSubscriptionsQuery)
+}
+
+func querySubscriptions(runner SQLRunner, query string, args ...interface{}) (subscriptions []Subscription, err error) {
+	rows, err := runner.Query(query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subscriptions: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			sub        Subscription
+			events     pq.StringArray
+			disabledAt pq.NullTime
+		)
+
+		err := rows.Scan(&sub.ID, &sub.URL, &events, &sub.Secret, &sub.Active, &sub.Failures, &sub.CreatedAt, &disabledAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch subscriptions: %s", err)
+		}
+
+		sub.Events = []string(events)
+		if disabledAt.Valid {
+			sub.DisabledAt = &disabledAt.Time
+		}
+
+		subscriptions = append(subscriptions, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch subscriptions: %s", err)
+	}
+
+	return subscriptions, nil
+}
+
+// DeleteSubscription removes subscription along with its pending and logged deliveries.
+func DeleteSubscription(runner SQLRunner, id int) error {
+	result, err := runner.Exec(DeleteSubscriptionQuery, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription: %s", err)
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrSubscriptionNotFound
+	}
+
+	return nil
+}
+
+// EnqueueDeliveries queues events for delivery to every active subscription that accepts them.
+// Events that are already queued are skipped.
+func EnqueueDeliveries(runner SQLRunner, events []RepositoryEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(events))
+	actions := make([]string, len(events))
+
+	for i, event := range events {
+		ids[i], actions[i] = event.ID, event.Action
+	}
+
+	if _, err := runner.Exec(EnqueueDeliveriesQuery, pq.Array(ids), pq.Array(actions)); err != nil {
+		return fmt.Errorf("failed to enqueue deliveries: %s", err)
+	}
+
+	return nil
+}
+
+// ClaimPendingDeliveries leases up to limit queued deliveries that are due at given time to the
+// caller for lease duration, so that concurrent workers skip them. Deliveries that have not been
+// recorded before the lease expires are claimed again.
+func ClaimPendingDeliveries(runner SQLRunner, now time.Time, lease time.Duration, limit int) (pending []PendingDelivery, err error) {
+	rows, err := runner.Query(ClaimPendingDeliveriesQuery, now, limit, now.Add(lease))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pending deliveries: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			p             PendingDelivery
+			events        pq.StringArray
+			before, after []byte
+		)
+
+		err := rows.Scan(
+			&p.Attempts, &p.Subscription.ID, &p.Subscription.URL, &events, &p.Subscription.Secret, &p.Subscription.Failures,
+			&p.Event.ID, &p.Event.RepositoryID, &p.Event.Provider, &p.Event.Owner, &p.Event.Name, &p.Event.Action,
+			&p.Event.Actor, &p.Event.RequestID, &p.Event.Source, &before, &after, &p.Event.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch pending deliveries: %s", err)
+		}
+
+		p.Subscription.Events, p.Subscription.Active = []string(events), true
+		p.Event.Before, p.Event.After = before, after
+
+		pending = append(pending, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch pending deliveries: %s", err)
+	}
+
+	return pending, nil
+}
+
+// RecordDelivery logs delivery attempt and updates the queue. Successful delivery is removed
+// from the queue and resets subscription failures. Failed delivery is rescheduled to retryAt
+// unless retryAt is zero, in which case it is dropped. Delivery is only rescheduled if no other
+// attempt has been recorded since it was claimed.
+func RecordDelivery(runner SQLRunner, delivery *Delivery, retryAt time.Time) error {
+	var statusCode sql.NullInt64
+	if delivery.StatusCode > 0 {
+		statusCode = sql.NullInt64{Int64: int64(delivery.StatusCode), Valid: true}
+	}
+
+	err := runner.QueryRow(
+		CreateDeliveryQuery,
+		delivery.SubscriptionID, delivery.EventID, delivery.Attempt, statusCode, delivery.Error,
+		int64(delivery.Latency/time.Millisecond), delivery.DeliveredAt,
+	).Scan(&delivery.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to record delivery: %s", err)
+	}
+
+	switch {
+	case delivery.Succeeded():
+		if _, err = runner.Exec(DeletePendingDeliveryQuery, delivery.SubscriptionID, delivery.EventID); err == nil {
+			_, err = runner.Exec(ResetSubscriptionFailuresQuery, delivery.SubscriptionID)
+		}
+	case retryAt.IsZero():
+		_, err = runner.Exec(DeletePendingDeliveryQuery, delivery.SubscriptionID, delivery.EventID)
+	default:
+		_, err = runner.Exec(ReschedulePendingDeliveryQuery, delivery.SubscriptionID, delivery.EventID, retryAt, delivery.Attempt-1)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to record delivery: %s", err)
+	}
+
+	return nil
+}
+
+// FailSubscription increments the number of consecutive failures of subscription and disables
+// it once maxFailures is reached. Pending deliveries of a disabled subscription are dropped.
+func FailSubscription(runner SQLRunner, id int, maxFailures int) (disabled bool, err error) {
+	err = runner.QueryRow(FailSubscriptionQuery, id, maxFailures).Scan(&disabled)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to update subscription: %s", err)
+	}
+
+	if disabled {
+		if _, err = runner.Exec(DeletePendingDeliveriesQuery, id); err != nil {
+			return false, fmt.Errorf("failed to update subscription: %s", err)
+		}
+	}
+
+	return disabled, nil
+}
+
+// GetDeliveries returns up to limit latest delivery attempts of subscription, most recent first.
+func GetDeliveries(runner SQLRunner, subscriptionID int, limit int) (deliveries []Delivery, err error) {
+	rows, err := runner.Query(GetDeliveriesQuery, subscriptionID, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deliveries: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			d          Delivery
+			statusCode sql.NullInt64
+			latency    int64
+		)
+
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.Attempt, &statusCode, &d.Error, &latency, &d.DeliveredAt); err != nil {
+			return nil, fmt.Errorf("failed to fetch deliveries: %s", err)
+		}
+
+		d.StatusCode = int(statusCode.Int64)
+		d.Latency = time.Duration(latency) * time.Millisecond
+
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch deliveries: %s", err)
+	}
+
+	return deliveries, nil
+}
+
+const subscriptionColumns = `id, url, events, secret, active, failures, created_at, disabled_at`
+
+const (
+	CreateSubscriptionQuery = `INSERT INTO subscriptions (url, events, secret) VALUES ($1, $2, $3) RETURNING id, active, created_at`
+	GetSubscriptionQuery    = `SELECT ` + subscriptionColumns + ` FROM subscriptions WHERE id=$1`
+	GetSubscriptionsQuery   = `SELECT ` + subscriptionColumns + ` FROM subscriptions ORDER BY id`
+	DeleteSubscriptionQuery = `DELETE FROM subscriptions WHERE id=$1`
+	EnqueueDeliveriesQuery  = `INSERT INTO subscription_queue (subscription_id, event_id)
+		SELECT s.id, e.id FROM subscriptions s, unnest($1::bigint[], $2::varchar[]) AS e (id, action)
+		WHERE s.active AND (cardinality(s.events) = 0 OR e.action = ANY(s.events))
+		ON CONFLICT DO NOTHING`
+	ClaimPendingDeliveriesQuery = `WITH claimed AS (
+			UPDATE subscription_queue SET next_attempt_at=$3 WHERE (subscription_id, event_id) IN (
+				SELECT q.subscription_id, q.event_id FROM subscription_queue q
+				JOIN subscriptions s ON s.id = q.subscription_id
+				WHERE s.active AND q.next_attempt_at <= $1
+				ORDER BY q.event_id, q.subscription_id LIMIT $2 FOR UPDATE OF q SKIP LOCKED
+			) RETURNING subscription_id, event_id, attempts
+		)
+		SELECT c.attempts, s.id, s.url, s.events, s.secret, s.failures,
+		e.id, e.repository_id, e.provider, e.owner, e.name, e.action, e.actor, e.request_id, e.source, e.before, e.after, e.created_at
+		FROM claimed c
+		JOIN subscriptions s ON s.id = c.subscription_id
+		JOIN repository_events e ON e.id = c.event_id
+		ORDER BY c.event_id, c.subscription_id`
+	CreateDeliveryQuery = `INSERT INTO subscription_deliveries (subscription_id, event_id, attempt, status_code, error, latency_ms, delivered_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	DeletePendingDeliveryQuery     = `DELETE FROM subscription_queue WHERE subscription_id=$1 AND event_id=$2`
+	DeletePendingDeliveriesQuery   = `DELETE FROM subscription_queue WHERE subscription_id=$1`
+	ReschedulePendingDeliveryQuery = `UPDATE subscription_queue SET attempts=attempts+1, next_attempt_at=$3 WHERE subscription_id=$1 AND event_id=$2 AND attempts=$4`
+	ResetSubscriptionFailuresQuery = `UPDATE subscriptions SET failures=0 WHERE id=$1`
+	FailSubscriptionQuery          = `UPDATE subscriptions SET failures=failures+1,
+		active=(failures+1 < $2),
+		disabled_at=CASE WHEN failures+1 >= $2 THEN now() ELSE disabled_at END
+		WHERE id=$1 RETURNING NOT active`
+	GetDeliveriesQuery = `SELECT id, subscription_id, event_id, attempt, status_code, error, latency_ms, delivered_at
+		FROM subscription_deliveries WHERE subscription_id=$1 ORDER BY id DESC LIMIT $2`
+)
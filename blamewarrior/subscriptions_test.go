@@ -0,0 +1,163 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscription_Validate(t *testing.T) {
+	results := []struct {
+		Subscription blamewarrior.Subscription
+		Valid        bool
+	}{
+		{Subscription: blamewarrior.Subscription{URL: "https://example.com/hook", Secret: "s"}, Valid: true},
+		{Subscription: blamewarrior.Subscription{URL: "http://example.com", Secret: "s", Events: []string{"created", "deleted"}}, Valid: true},
+		{Subscription: blamewarrior.Subscription{URL: "example.com/hook", Secret: "s"}, Valid: false},
+		{Subscription: blamewarrior.Subscription{URL: "ftp://example.com", Secret: "s"}, Valid: false},
+		{Subscription: blamewarrior.Subscription{URL: "https://example.com/hook"}, Valid: false},
+		{Subscription: blamewarrior.Subscription{URL: "https://example.com/hook", Secret: "s", Events: []string{"pushed"}}, Valid: false},
+	}
+
+	for _, result := range results {
+		err := result.Subscription.Validate()
+		assert.Equal(t, result.Valid, err == nil, "%+v: %v", result.Subscription, err)
+	}
+}
+
+func TestSubscription_Accepts(t *testing.T) {
+	all := &blamewarrior.Subscription{}
+	assert.True(t, all.Accepts(blamewarrior.EventCreated))
+	assert.True(t, all.Accepts(blamewarrior.EventDeleted))
+
+	created := &blamewarrior.Subscription{Events: []string{blamewarrior.EventCreated}}
+	assert.True(t, created.Accepts(blamewarrior.EventCreated))
+	assert.False(t, created.Accepts(blamewarrior.EventDeleted))
+}
+
+func TestSubscriptions(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events, subscriptions CASCADE;")
+	require.NoError(t, err)
+
+	all := &blamewarrior.Subscription{URL: "https://example.com/all", Secret: "s1"}
+	require.NoError(t, blamewarrior.CreateSubscription(db, all))
+
+	deletions := &blamewarrior.Subscription{URL: "https://example.com/deletions", Secret: "s2", Events: []string{blamewarrior.EventDeleted}}
+	require.NoError(t, blamewarrior.CreateSubscription(db, deletions))
+
+	assert.True(t, all.Active)
+
+	subscriptions, err := blamewarrior.GetSubscriptions(db)
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 2)
+	assert.Equal(t, "s2", subscriptions[1].Secret)
+	assert.Equal(t, []string{blamewarrior.EventDeleted}, subscriptions[1].Events)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+	require.NoError(t, blamewarrior.DeleteRepository(db, "blamewarrior/repos"))
+
+	events, err := blamewarrior.ClaimOutboxEvents(db, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	require.NoError(t, blamewarrior.EnqueueDeliveries(db, events))
+	// queueing is idempotent
+	require.NoError(t, blamewarrior.EnqueueDeliveries(db, events))
+
+	now := time.Now()
+
+	pending, err := blamewarrior.ClaimPendingDeliveries(db, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+
+	assert.Equal(t, all.ID, pending[0].Subscription.ID)
+	assert.Equal(t, blamewarrior.EventCreated, pending[0].Event.Action)
+	assert.Equal(t, all.ID, pending[1].Subscription.ID)
+	assert.Equal(t, deletions.ID, pending[2].Subscription.ID)
+	assert.Equal(t, blamewarrior.EventDeleted, pending[2].Event.Action)
+
+	// claimed deliveries are leased
+	leased, err := blamewarrior.ClaimPendingDeliveries(db, now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Len(t, leased, 0)
+
+	// successful delivery is removed from the queue
+	require.NoError(t, blamewarrior.RecordDelivery(db, &blamewarrior.Delivery{
+		SubscriptionID: all.ID, EventID: pending[0].Event.ID, Attempt: 1, StatusCode: 200, Latency: 120 * time.Millisecond, DeliveredAt: now,
+	}, time.Time{}))
+
+	// failed delivery is rescheduled
+	require.NoError(t, blamewarrior.RecordDelivery(db, &blamewarrior.Delivery{
+		SubscriptionID: all.ID, EventID: pending[1].Event.ID, Attempt: 1, StatusCode: 500, Error: "500 Internal Server Error", DeliveredAt: now,
+	}, now.Add(time.Minute)))
+
+	// delivery that is out of attempts is dropped
+	require.NoError(t, blamewarrior.RecordDelivery(db, &blamewarrior.Delivery{
+		SubscriptionID: deletions.ID, EventID: pending[2].Event.ID, Attempt: 8, Error: "connection refused", DeliveredAt: now,
+	}, time.Time{}))
+
+	pending, err = blamewarrior.ClaimPendingDeliveries(db, now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Len(t, pending, 0)
+
+	pending, err = blamewarrior.ClaimPendingDeliveries(db, now.Add(time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, 1, pending[0].Attempts)
+	assert.Equal(t, blamewarrior.EventDeleted, pending[0].Event.Action)
+
+	deliveries, err := blamewarrior.GetDeliveries(db, all.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+
+	assert.Equal(t, 500, deliveries[0].StatusCode)
+	assert.Equal(t, "500 Internal Server Error", deliveries[0].Error)
+	assert.Equal(t, 200, deliveries[1].StatusCode)
+	assert.Equal(t, 120*time.Millisecond, deliveries[1].Latency)
+
+	// subscription is disabled after too many consecutive failures
+	disabled, err := blamewarrior.FailSubscription(db, all.ID, 2)
+	require.NoError(t, err)
+	assert.False(t, disabled)
+
+	disabled, err = blamewarrior.FailSubscription(db, all.ID, 2)
+	require.NoError(t, err)
+	assert.True(t, disabled)
+
+	sub, err := blamewarrior.GetSubscription(db, all.ID)
+	require.NoError(t, err)
+	assert.False(t, sub.Active)
+	assert.Equal(t, 2, sub.Failures)
+	assert.NotNil(t, sub.DisabledAt)
+
+	pending, err = blamewarrior.ClaimPendingDeliveries(db, now.Add(time.Hour), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Len(t, pending, 0)
+
+	require.NoError(t, blamewarrior.DeleteSubscription(db, all.ID))
+	assert.Equal(t, blamewarrior.ErrSubscriptionNotFound, blamewarrior.DeleteSubscription(db, all.ID))
+
+	_, err = blamewarrior.GetSubscription(db, all.ID)
+	assert.Equal(t, blamewarrior.ErrSubscriptionNotFound, err)
+}
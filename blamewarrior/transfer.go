@@ -0,0 +1,398 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Formats of repositories export files
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+)
+
+// ExportColumns are columns of repositories exported in CSV format.
+var ExportColumns = []string{"full_name", "private", "settings", "provider"}
+
+// exportRecord is a single tracked repository in export file.
+type exportRecord struct {
+	FullName string          `json:"full_name"`
+	Provider string          `json:"provider,omitempty"`
+	Owner    string          `json:"owner,omitempty"`
+	Name     string          `json:"name,omitempty"`
+	Private  bool            `json:"private"`
+	Settings json.RawMessage `json:"settings,omitempty"`
+}
+
+// RepositoryWriter writes repositories to export file in either FormatJSONL or FormatCSV.
+type RepositoryWriter struct {
+	enc *json.Encoder
+	cw  *csv.Writer
+
+	headerWritten bool
+}
+
+// NewRepositoryWriter returns writer of repositories in given format.
+func NewRepositoryWriter(w io.Writer, format string) (*RepositoryWriter, error) {
+	switch format {
+	case FormatJSONL:
+		return &RepositoryWriter{enc: json.NewEncoder(w)}, nil
+	case FormatCSV:
+		return &RepositoryWriter{cw: csv.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+// Write writes repo to export file.
+func (rw *RepositoryWriter) Write(repo *Repository) error {
+	if rw.enc != nil {
+		return rw.enc.Encode(&exportRecord{FullName: repo.FullName(), Provider: repo.Provider, Private: repo.Private, Settings: repo.Settings})
+	}
+
+	if !rw.headerWritten {
+		if err := rw.cw.Write(ExportColumns); err != nil {
+			return err
+		}
+
+		rw.headerWritten = true
+	}
+
+	return rw.cw.Write([]string{repo.FullName(), strconv.FormatBool(repo.Private), string(repo.Settings), repo.Provider})
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (rw *RepositoryWriter) Flush() error {
+	if rw.cw == nil {
+		return nil
+	}
+
+	if !rw.headerWritten {
+		rw.cw.Write(ExportColumns)
+		rw.headerWritten = true
+	}
+
+	rw.cw.Flush()
+
+	return rw.cw.Error()
+}
+
+// ImportLine is a repository read from import file. Err is set if the line could not be parsed
+// or the repository it describes is invalid.
+type ImportLine struct {
+	Line       int
+	Repository Repository
+	Fields     ImportFields
+	Err        error
+}
+
+// ImportFields tells which of repository fields that can be updated by import are specified in
+// import file. Fields that are not specified are left intact for tracked repositories.
+type ImportFields struct {
+	Private  bool
+	Settings bool
+}
+
+// ReadRepositories reads repositories from file written by RepositoryWriter. Malformed lines
+// do not stop reading, instead their errors are reported in returned lines. An error is returned
+// only if the file itself can not be read.
+func ReadRepositories(r io.Reader, format string) ([]ImportLine, error) {
+	switch format {
+	case FormatJSONL:
+		return readRepositoriesJSONL(r)
+	case FormatCSV:
+		return readRepositoriesCSV(r)
+	default:
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+func readRepositoriesJSONL(r io.Reader) (lines []ImportLine, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for n := 1; scanner.Scan(); n++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		line := ImportLine{Line: n}
+
+		var (
+			record exportRecord
+			fields map[string]json.RawMessage
+		)
+
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			line.Err = fmt.Errorf("malformed JSON: %s", err)
+		} else if err := json.Unmarshal(scanner.Bytes(), &fields); err != nil {
+			line.Err = fmt.Errorf("malformed JSON: %s", err)
+		} else {
+			_, line.Fields.Private = fields["private"]
+			_, line.Fields.Settings = fields["settings"]
+
+			line.Repository, line.Err = record.repository()
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read repositories: %s", err)
+	}
+
+	return lines, nil
+}
+
+func readRepositoriesCSV(r io.Reader) (lines []ImportLine, err error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repositories: %s", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	if _, ok := columns["full_name"]; !ok {
+		return nil, fmt.Errorf("failed to read repositories: missing full_name column")
+	}
+
+	var present ImportFields
+	_, present.Private = columns["private"]
+	_, present.Settings = columns["settings"]
+
+	field := func(fields []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(fields) {
+			return fields[i]
+		}
+
+		return ""
+	}
+
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if _, ok := err.(*csv.ParseError); !ok && err != nil {
+			return nil, fmt.Errorf("failed to read repositories: %s", err)
+		}
+
+		line := ImportLine{Fields: present, Err: err}
+		line.Line, _ = cr.FieldPos(0)
+
+		if line.Err == nil {
+			record := exportRecord{FullName: field(fields, "full_name"), Provider: field(fields, "provider")}
+
+			if v := field(fields, "private"); v != "" {
+				if record.Private, err = strconv.ParseBool(v); err != nil {
+					line.Err = fmt.Errorf("malformed private value %q", v)
+				}
+			}
+
+			if v := field(fields, "settings"); v != "" {
+				record.Settings = json.RawMessage(v)
+			}
+
+			if line.Err == nil {
+				line.Repository, line.Err = record.repository()
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// repository returns validated repository described by export record. Records written by
+// other tools may specify owner and name instead of full name, and records that do not specify
+// provider describe repositories hosted at DefaultProvider.
+func (record *exportRecord) repository() (repo Repository, err error) {
+	repo = Repository{Provider: record.Provider, Owner: record.Owner, Name: record.Name, Private: record.Private, Settings: record.Settings}
+
+	if record.FullName != "" {
+		var provider string
+
+		if provider, repo.Owner, repo.Name, err = parseFullName(record.FullName); err != nil {
+			return repo, err
+		}
+
+		if repo.Provider == "" {
+			repo.Provider = provider
+		}
+	}
+
+	if repo.Provider == "" {
+		repo.Provider = DefaultProvider
+	}
+
+	return repo, repo.Validate()
+}
+
+// Results of repository import
+const (
+	ImportCreated   = "created"
+	ImportUpdated   = "updated"
+	ImportUnchanged = "unchanged"
+	ImportFailed    = "failed"
+)
+
+// ImportResult is the outcome of importing a single line of import file.
+type ImportResult struct {
+	Line     int    `json:"line"`
+	FullName string `json:"full_name,omitempty"`
+	Result   string `json:"result"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ImportReport summarizes import of repositories file.
+type ImportReport struct {
+	DryRun    bool           `json:"dry_run"`
+	Created   int            `json:"created"`
+	Updated   int            `json:"updated"`
+	Unchanged int            `json:"unchanged"`
+	Failed    int            `json:"failed"`
+	Results   []ImportResult `json:"results"`
+}
+
+// Add appends result to the report and updates its counters.
+func (report *ImportReport) Add(result ImportResult) {
+	switch result.Result {
+	case ImportCreated:
+		report.Created++
+	case ImportUpdated:
+		report.Updated++
+	case ImportUnchanged:
+		report.Unchanged++
+	case ImportFailed:
+		report.Failed++
+	}
+
+	report.Results = append(report.Results, result)
+}
+
+// ExportRepositories calls fn for every tracked repository of owner, or for all tracked
+// repositories if owner is empty. Repositories are read one by one, so that the export can be
+// streamed without loading all of them into memory.
+func ExportRepositories(runner SQLRunner, owner string, fn func(*Repository) error) error {
+	query, args := ExportRepositoriesQuery, []interface{}{}
+	if owner != "" {
+		query, args = ExportOwnerRepositoriesQuery, []interface{}{owner}
+	}
+
+	rows, err := runner.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to export repositories: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var repo Repository
+
+		if err := scanRepository(rows, &repo); err != nil {
+			return fmt.Errorf("failed to export repositories: %s", err)
+		}
+
+		if err := fn(&repo); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to export repositories: %s", err)
+	}
+
+	return nil
+}
+
+// UpsertRepository starts tracking repo or, if it is already tracked, updates those of its
+// visibility and settings that are listed in fields. It returns one of ImportCreated,
+// ImportUpdated or ImportUnchanged. On success repo holds the stored repository.
+func UpsertRepository(runner SQLRunner, repo *Repository, fields ImportFields, changes ...*Change) (result string, err error) {
+	existing, err := GetRepositoryByFullName(runner, repo.QualifiedName())
+
+	if err == ErrRepositoryNotFound {
+		return ImportCreated, CreateRepository(runner, repo, changes...)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	private, settings := existing.Private, existing.Settings
+
+	if fields.Private {
+		private = repo.Private
+	}
+
+	if fields.Settings {
+		settings = repo.Settings
+	}
+
+	if existing.Private == private && sameJSON(existing.Settings, settings) {
+		*repo = *existing
+		return ImportUnchanged, nil
+	}
+
+	existing.Private, existing.Settings = private, settings
+
+	if err := UpdateRepository(runner, existing, changes...); err != nil {
+		return "", err
+	}
+
+	*repo = *existing
+
+	return ImportUpdated, nil
+}
+
+// sameJSON reports whether a and b are equal JSON documents regardless of their formatting.
+func sameJSON(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+
+	var x, y interface{}
+
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(x, y)
+}
+
+const (
+	ExportRepositoriesQuery      = `SELECT ` + repositoryColumns + ` FROM repositories WHERE deleted_at IS NULL ORDER BY provider, owner, name`
+	ExportOwnerRepositoriesQuery = `SELECT ` + repositoryColumns + ` FROM repositories WHERE lower(owner)=lower($1) AND deleted_at IS NULL ORDER BY provider, name`
+)
@@ -0,0 +1,232 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestRepositoryWriter(t *testing.T) {
+	repositories := []blamewarrior.Repository{
+		{Provider: "github", Owner: "blamewarrior", Name: "hooks", Private: true},
+		{Provider: "gitlab", Owner: "blamewarrior", Name: "repos", Settings: json.RawMessage(`{"version":1,"ignored_paths":["vendor/"]}`)},
+	}
+
+	examples := map[string]struct {
+		Output    string
+		FirstLine int
+	}{
+		blamewarrior.FormatJSONL: {`{"full_name":"blamewarrior/hooks","provider":"github","private":true}
+{"full_name":"blamewarrior/repos","provider":"gitlab","private":false,"settings":{"version":1,"ignored_paths":["vendor/"]}}
+`, 1},
+		blamewarrior.FormatCSV: {`full_name,private,settings,provider
+blamewarrior/hooks,true,,github
+blamewarrior/repos,false,"{""version"":1,""ignored_paths"":[""vendor/""]}",gitlab
+`, 2},
+	}
+
+	for format, example := range examples {
+		var buf bytes.Buffer
+
+		rw, err := blamewarrior.NewRepositoryWriter(&buf, format)
+		require.NoError(t, err)
+
+		for i := range repositories {
+			require.NoError(t, rw.Write(&repositories[i]))
+		}
+
+		require.NoError(t, rw.Flush())
+		assert.Equal(t, example.Output, buf.String(), format)
+
+		lines, err := blamewarrior.ReadRepositories(&buf, format)
+		require.NoError(t, err, format)
+
+		require.Len(t, lines, 2, format)
+		for i, line := range lines {
+			assert.Equal(t, example.FirstLine+i, line.Line, format)
+			assert.NoError(t, line.Err, format)
+			assert.Equal(t, repositories[i].QualifiedName(), line.Repository.QualifiedName(), format)
+			assert.Equal(t, repositories[i].Private, line.Repository.Private, format)
+			assert.Equal(t, string(repositories[i].Settings), string(line.Repository.Settings), format)
+			assert.Equal(t, blamewarrior.ImportFields{Private: true, Settings: format == blamewarrior.FormatCSV || len(repositories[i].Settings) > 0}, line.Fields, format)
+		}
+	}
+
+	_, err := blamewarrior.NewRepositoryWriter(&bytes.Buffer{}, "xml")
+	assert.EqualError(t, err, "unsupported format xml")
+}
+
+func TestReadRepositories_Errors(t *testing.T) {
+	examples := map[string]struct {
+		Format string
+		Input  string
+		Lines  []blamewarrior.ImportLine
+		Err    error
+	}{
+		"jsonl": {
+			Format: blamewarrior.FormatJSONL,
+			Input: strings.Join([]string{
+				`{"owner":"blamewarrior","name":"repos"}`,
+				``,
+				`{"full_name":"blamewarrior"}`,
+				`{"full_name":"blamewarrior/hooks",`,
+				`{"full_name":"blamewarrior/hooks","settings":{"version":1,"colour":"red"}}`,
+				`{"full_name":"gitlab:blamewarrior/hooks"}`,
+				`{"full_name":"blamewarrior/hooks","provider":"bitbucket"}`,
+			}, "\n"),
+			Lines: []blamewarrior.ImportLine{
+				{Line: 1, Repository: blamewarrior.Repository{Provider: "github", Owner: "blamewarrior", Name: "repos"}},
+				{Line: 3, Err: blamewarrior.IncorrectFullName},
+				{Line: 4, Err: errors.New("malformed JSON: unexpected end of JSON input")},
+				{
+					Line:       5,
+					Repository: blamewarrior.Repository{Provider: "github", Owner: "blamewarrior", Name: "hooks", Settings: json.RawMessage(`{"version":1,"colour":"red"}`)},
+					Fields:     blamewarrior.ImportFields{Settings: true},
+					Err:        errors.New("invalid settings: colour is not allowed"),
+				},
+				{Line: 6, Repository: blamewarrior.Repository{Provider: "gitlab", Owner: "blamewarrior", Name: "hooks"}},
+				{
+					Line:       7,
+					Repository: blamewarrior.Repository{Provider: "bitbucket", Owner: "blamewarrior", Name: "hooks"},
+					Err:        errors.New("unsupported provider bitbucket"),
+				},
+			},
+		},
+		"csv": {
+			Format: blamewarrior.FormatCSV,
+			Input:  "private,full_name\nyes,blamewarrior/repos\ntrue,blamewarrior/hooks\n",
+			Lines: []blamewarrior.ImportLine{
+				{Line: 2, Fields: blamewarrior.ImportFields{Private: true}, Err: errors.New(`malformed private value "yes"`)},
+				{Line: 3, Repository: blamewarrior.Repository{Provider: "github", Owner: "blamewarrior", Name: "hooks", Private: true}, Fields: blamewarrior.ImportFields{Private: true}},
+			},
+		},
+		"csv without full name": {
+			Format: blamewarrior.FormatCSV,
+			Input:  "owner,name\nblamewarrior,repos\n",
+			Err:    errors.New("failed to read repositories: missing full_name column"),
+		},
+		"unknown format": {
+			Format: "xml",
+			Err:    errors.New("unsupported format xml"),
+		},
+	}
+
+	for name, example := range examples {
+		lines, err := blamewarrior.ReadRepositories(strings.NewReader(example.Input), example.Format)
+		assert.Equal(t, example.Err, err, name)
+		assert.Equal(t, example.Lines, lines, name)
+	}
+}
+
+func TestImportReport_Add(t *testing.T) {
+	report := &blamewarrior.ImportReport{}
+
+	for _, result := range []string{blamewarrior.ImportCreated, blamewarrior.ImportCreated, blamewarrior.ImportUpdated, blamewarrior.ImportFailed} {
+		report.Add(blamewarrior.ImportResult{Result: result})
+	}
+
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 0, report.Unchanged)
+	assert.Equal(t, 1, report.Failed)
+	assert.Len(t, report.Results, 4)
+}
+
+func TestExportRepositories(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "octocat", Name: "hello-world"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "deleted"}))
+	require.NoError(t, blamewarrior.DeleteRepository(db, "blamewarrior/deleted"))
+
+	examples := map[string][]string{
+		"":             {"blamewarrior/hooks", "blamewarrior/repos", "octocat/hello-world"},
+		"blamewarrior": {"blamewarrior/hooks", "blamewarrior/repos"},
+		"unknown":      nil,
+	}
+
+	for owner, expected := range examples {
+		var exported []string
+
+		err := blamewarrior.ExportRepositories(db, owner, func(repo *blamewarrior.Repository) error {
+			exported = append(exported, repo.FullName())
+			return nil
+		})
+
+		require.NoError(t, err, owner)
+		assert.Equal(t, expected, exported, owner)
+	}
+}
+
+func TestUpsertRepository(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos", Settings: json.RawMessage(`{"version":1}`)}
+
+	all := blamewarrior.ImportFields{Private: true, Settings: true}
+
+	result, err := blamewarrior.UpsertRepository(db, repo, all)
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.ImportCreated, result)
+
+	result, err = blamewarrior.UpsertRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos", Settings: json.RawMessage(`{ "version": 1 }`)}, all)
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.ImportUnchanged, result)
+
+	// fields missing in import file are left intact
+	result, err = blamewarrior.UpsertRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos", Private: true}, blamewarrior.ImportFields{Private: true})
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.ImportUpdated, result)
+
+	result, err = blamewarrior.UpsertRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}, blamewarrior.ImportFields{})
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.ImportUnchanged, result)
+
+	stored, err := blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+
+	assert.True(t, stored.Private)
+	assert.JSONEq(t, `{"version":1}`, string(stored.Settings))
+	assert.Equal(t, repo.ID, stored.ID)
+
+	result, err = blamewarrior.UpsertRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos", Private: true}, all)
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.ImportUpdated, result)
+
+	stored, err = blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+
+	assert.Empty(t, stored.Settings)
+}
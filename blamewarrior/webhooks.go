@@ -0,0 +1,133 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PendingWebhookEvent is a repository event queued for delivery to a webhook URL.
+type PendingWebhookEvent struct {
+	URL   string
+	Event RepositoryEvent
+	// Attempts is the number of previous delivery attempts
+	Attempts int
+}
+
+// EnqueueWebhookEvents queues events for delivery to each of webhook URLs. Events that are
+// already queued for an URL are skipped.
+func EnqueueWebhookEvents(runner SQLRunner, urls []string, events []RepositoryEvent) error {
+	if len(urls) == 0 || len(events) == 0 {
+		return nil
+	}
+
+	if _, err := runner.Exec(EnqueueWebhookEventsQuery, pq.Array(urls), pq.Array(eventIDs(events))); err != nil {
+		return fmt.Errorf("failed to enqueue webhook events: %s", err)
+	}
+
+	return nil
+}
+
+// ClaimWebhookEvents leases up to limit queued webhook events that are due at given time to the
+// caller for lease duration, so that concurrent workers skip them. Events are ordered by URL and
+// then by ID. Events that have been neither delivered nor rescheduled before the lease expires
+// are claimed again.
+func ClaimWebhookEvents(runner SQLRunner, now time.Time, lease time.Duration, limit int) (pending []PendingWebhookEvent, err error) {
+	rows, err := runner.Query(ClaimWebhookEventsQuery, now, limit, now.Add(lease))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pending webhook events: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			p             PendingWebhookEvent
+			before, after []byte
+		)
+
+		err := rows.Scan(
+			&p.URL, &p.Attempts,
+			&p.Event.ID, &p.Event.RepositoryID, &p.Event.Provider, &p.Event.Owner, &p.Event.Name, &p.Event.Action,
+			&p.Event.Actor, &p.Event.RequestID, &p.Event.Source, &before, &after, &p.Event.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch pending webhook events: %s", err)
+		}
+
+		p.Event.Before, p.Event.After = before, after
+
+		pending = append(pending, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch pending webhook events: %s", err)
+	}
+
+	return pending, nil
+}
+
+// DeleteWebhookEvents removes events delivered to webhook URL, or dropped after too many
+// failed attempts, from the queue.
+func DeleteWebhookEvents(runner SQLRunner, url string, events []RepositoryEvent) error {
+	if _, err := runner.Exec(DeleteWebhookEventsQuery, url, pq.Array(eventIDs(events))); err != nil {
+		return fmt.Errorf("failed to delete webhook events: %s", err)
+	}
+
+	return nil
+}
+
+// RescheduleWebhookEvents records a failed attempt to deliver events to webhook URL and makes
+// them due again at retryAt.
+func RescheduleWebhookEvents(runner SQLRunner, url string, events []RepositoryEvent, retryAt time.Time) error {
+	if _, err := runner.Exec(RescheduleWebhookEventsQuery, url, pq.Array(eventIDs(events)), retryAt); err != nil {
+		return fmt.Errorf("failed to reschedule webhook events: %s", err)
+	}
+
+	return nil
+}
+
+func eventIDs(events []RepositoryEvent) []int64 {
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+
+	return ids
+}
+
+const (
+	EnqueueWebhookEventsQuery = `INSERT INTO webhook_queue (url, event_id)
+		SELECT u.url, e.id FROM unnest($1::varchar[]) AS u (url), unnest($2::bigint[]) AS e (id)
+		ON CONFLICT DO NOTHING`
+	ClaimWebhookEventsQuery = `WITH claimed AS (
+			UPDATE webhook_queue SET next_attempt_at=$3 WHERE (url, event_id) IN (
+				SELECT url, event_id FROM webhook_queue WHERE next_attempt_at <= $1
+				ORDER BY url, event_id LIMIT $2 FOR UPDATE SKIP LOCKED
+			) RETURNING url, event_id, attempts
+		)
+		SELECT c.url, c.attempts,
+		e.id, e.repository_id, e.provider, e.owner, e.name, e.action, e.actor, e.request_id, e.source, e.before, e.after, e.created_at
+		FROM claimed c
+		JOIN repository_events e ON e.id = c.event_id
+		ORDER BY c.url, c.event_id`
+	DeleteWebhookEventsQuery     = `DELETE FROM webhook_queue WHERE url=$1 AND event_id = ANY($2)`
+	RescheduleWebhookEventsQuery = `UPDATE webhook_queue SET attempts=attempts+1, next_attempt_at=$3 WHERE url=$1 AND event_id = ANY($2)`
+)
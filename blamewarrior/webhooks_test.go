@@ -0,0 +1,75 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package blamewarrior_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookQueue(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events, event_outbox, webhook_queue CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"}))
+
+	events, err := blamewarrior.ClaimOutboxEvents(db, 100)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	urls := []string{"http://reviewers.example.com", "http://hooks.example.com"}
+	require.NoError(t, blamewarrior.EnqueueWebhookEvents(db, urls, events))
+	require.NoError(t, blamewarrior.EnqueueWebhookEvents(db, urls, events))
+
+	now := time.Now()
+
+	pending, err := blamewarrior.ClaimWebhookEvents(db, now, time.Minute, 3)
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+
+	assert.Equal(t, "http://hooks.example.com", pending[0].URL)
+	assert.Equal(t, "repos", pending[0].Event.Name)
+	assert.Equal(t, "http://hooks.example.com", pending[1].URL)
+	assert.Equal(t, "hooks", pending[1].Event.Name)
+	assert.Equal(t, "http://reviewers.example.com", pending[2].URL)
+	assert.Equal(t, "repos", pending[2].Event.Name)
+
+	// leased events are skipped
+	pending, err = blamewarrior.ClaimWebhookEvents(db, now, time.Minute, 100)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "hooks", pending[0].Event.Name)
+
+	require.NoError(t, blamewarrior.DeleteWebhookEvents(db, "http://hooks.example.com", events))
+	require.NoError(t, blamewarrior.RescheduleWebhookEvents(db, "http://reviewers.example.com", events, now.Add(time.Hour)))
+
+	pending, err = blamewarrior.ClaimWebhookEvents(db, now.Add(time.Hour), time.Minute, 100)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+
+	for _, p := range pending {
+		assert.Equal(t, "http://reviewers.example.com", p.URL)
+		assert.Equal(t, 1, p.Attempts)
+	}
+}
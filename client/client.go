@@ -0,0 +1,279 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package client is a Go client of BlameWarrior repositories service API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+const (
+	defaultMaxRetries  = 3
+	defaultRetryPeriod = 100 * time.Millisecond
+	maxErrorMessageLen = 4096
+)
+
+// Client calls repositories service API. Its zero value is not usable, use NewClient instead.
+type Client struct {
+	BaseURL string
+	// Actor is passed to the service in X-Actor header and recorded in the audit log
+	Actor string
+	// MaxRetries is the number of times idempotent requests are retried after network
+	// errors and temporary server failures
+	MaxRetries int
+	// RetryPeriod is the delay before the first retry, it is doubled after each attempt
+	RetryPeriod time.Duration
+
+	c *http.Client
+}
+
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:     strings.TrimSuffix(baseURL, "/"),
+		MaxRetries:  defaultMaxRetries,
+		RetryPeriod: defaultRetryPeriod,
+		c:           http.DefaultClient,
+	}
+}
+
+// Error is returned when the service responds with unsuccessful status.
+type Error struct {
+	StatusCode int
+	// Message is the error description returned by the service
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("repositories service responded with %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	return fmt.Sprintf("repositories service responded with %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is caused by missing repository or resource.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is caused by repository that is already tracked.
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsPreconditionFailed reports whether err is caused by repository that has been modified
+// since its ETag was obtained.
+func IsPreconditionFailed(err error) bool {
+	return hasStatus(err, http.StatusPreconditionFailed)
+}
+
+// IsInvalid reports whether err is caused by malformed request or data that failed validation.
+func IsInvalid(err error) bool {
+	return hasStatus(err, http.StatusBadRequest) || hasStatus(err, http.StatusUnprocessableEntity)
+}
+
+func hasStatus(err error, status int) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == status
+}
+
+// request describes a single API call.
+type request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	// Body is encoded as JSON unless it is json.RawMessage or []byte which are sent as is
+	Body interface{}
+}
+
+// do sends request and decodes successful JSON response into result if it is not nil. Failed
+// idempotent requests are retried with exponential backoff.
+func (client *Client) do(ctx context.Context, r *request, result interface{}) (*http.Response, error) {
+	var (
+		body []byte
+		err  error
+	)
+
+	switch b := r.Body.(type) {
+	case nil:
+	case json.RawMessage:
+		body = b
+	case []byte:
+		body = b
+	default:
+		if body, err = json.Marshal(b); err != nil {
+			return nil, fmt.Errorf("failed to encode request: %s", err)
+		}
+	}
+
+	retries := 0
+	if idempotent(r.Method) {
+		retries = client.MaxRetries
+	}
+
+	delay := client.RetryPeriod
+
+	for attempt := 0; ; attempt++ {
+		response, err := client.send(ctx, r, body)
+
+		if (err == nil && !temporaryStatus(response.StatusCode)) || attempt >= retries || ctx.Err() != nil {
+			if err != nil {
+				return nil, err
+			}
+
+			return response, decodeResponse(response, result)
+		}
+
+		if err == nil {
+			response.Body.Close()
+		}
+
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (client *Client) send(ctx context.Context, r *request, body []byte) (*http.Response, error) {
+	u := client.BaseURL + r.Path
+	if len(r.Query) > 0 {
+		u += "?" + r.Query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(r.Method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range r.Header {
+		req.Header[k] = v
+	}
+
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if client.Actor != "" {
+		req.Header.Set("X-Actor", client.Actor)
+	}
+
+	return client.c.Do(req.WithContext(ctx))
+}
+
+func decodeResponse(response *http.Response, result interface{}) error {
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(response.Body, maxErrorMessageLen))
+
+		return &Error{
+			StatusCode: response.StatusCode,
+			Message:    strings.TrimSpace(string(msg)),
+		}
+	}
+
+	if result == nil || response.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %s", err)
+	}
+
+	return nil
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "PUT", "DELETE":
+		return true
+	}
+
+	return false
+}
+
+func temporaryStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// repositoryPath returns API path of repository with given full or qualified name, e.g.
+// gitlab:owner/name. Name is the last segment of full name, slashes in nested owner
+// namespaces are escaped.
+func repositoryPath(fullName string, suffix ...string) string {
+	var provider string
+	if sep := strings.IndexByte(fullName, ':'); sep >= 0 {
+		provider, fullName = fullName[:sep], fullName[sep+1:]
+	}
+
+	var segments []string
+	if sep := strings.LastIndexByte(fullName, '/'); sep >= 0 {
+		segments = append(segments, url.PathEscape(fullName[:sep]), url.PathEscape(fullName[sep+1:]))
+	} else {
+		segments = append(segments, url.PathEscape(fullName))
+	}
+
+	return ownerPath(provider, strings.Join(append(segments, suffix...), "/"))
+}
+
+// ownerSettingsPath returns API path of default settings of owner optionally qualified with
+// provider.
+func ownerSettingsPath(owner string) string {
+	var provider string
+	if sep := strings.IndexByte(owner, ':'); sep >= 0 {
+		provider, owner = owner[:sep], owner[sep+1:]
+	}
+
+	if provider == "" || provider == blamewarrior.DefaultProvider {
+		return "/owners/" + url.PathEscape(owner) + "/settings"
+	}
+
+	return "/owners/" + url.PathEscape(provider) + "/" + url.PathEscape(owner) + "/settings"
+}
+
+// ownerPath returns API path of repositories of owner hosted at provider. Path is expected to
+// be escaped already.
+func ownerPath(provider, path string) string {
+	if provider == "" || provider == blamewarrior.DefaultProvider {
+		return "/repositories/" + path
+	}
+
+	return "/repositories/" + url.PathEscape(provider) + "/" + path
+}
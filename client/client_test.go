@@ -0,0 +1,261 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/client"
+)
+
+func TestClient_Errors(t *testing.T) {
+	results := []struct {
+		ResponseStatus       int
+		ResponseBody         string
+		NotFound             bool
+		Conflict             bool
+		PreconditionFailed   bool
+		Invalid              bool
+		ExpectedErrorMessage string
+	}{
+		{ResponseStatus: http.StatusNotFound, ResponseBody: "Repository not found\n", NotFound: true, ExpectedErrorMessage: "repositories service responded with 404: Repository not found"},
+		{ResponseStatus: http.StatusConflict, ResponseBody: "Repository is already tracked\n", Conflict: true, ExpectedErrorMessage: "repositories service responded with 409: Repository is already tracked"},
+		{ResponseStatus: http.StatusPreconditionFailed, PreconditionFailed: true, ExpectedErrorMessage: "repositories service responded with 412 Precondition Failed"},
+		{ResponseStatus: http.StatusBadRequest, ResponseBody: "Incorrect full name", Invalid: true, ExpectedErrorMessage: "repositories service responded with 400: Incorrect full name"},
+		{ResponseStatus: http.StatusUnprocessableEntity, Invalid: true, ExpectedErrorMessage: "repositories service responded with 422 Unprocessable Entity"},
+	}
+
+	for _, result := range results {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(result.ResponseStatus)
+			w.Write([]byte(result.ResponseBody))
+		}))
+
+		_, _, err := client.NewClient(srv.URL).GetRepository(context.Background(), "blamewarrior/repos", nil)
+		require.Error(t, err)
+
+		assert.Equal(t, result.ExpectedErrorMessage, err.Error())
+		assert.Equal(t, result.NotFound, client.IsNotFound(err))
+		assert.Equal(t, result.Conflict, client.IsConflict(err))
+		assert.Equal(t, result.PreconditionFailed, client.IsPreconditionFailed(err))
+		assert.Equal(t, result.Invalid, client.IsInvalid(err))
+
+		srv.Close()
+	}
+}
+
+func TestClient_Retries(t *testing.T) {
+	var calls int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`[{"owner":"blamewarrior","name":"repos"}]`))
+	}))
+	defer srv.Close()
+
+	c := client.NewClient(srv.URL)
+	c.RetryPeriod = time.Millisecond
+
+	// idempotent requests are retried
+	repositories, err := c.ListRepositories(context.Background(), "blamewarrior", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []blamewarrior.Repository{{Owner: "blamewarrior", Name: "repos"}}, repositories)
+
+	// until retries are exhausted
+	calls = -10
+
+	_, err = c.ListRepositories(context.Background(), "blamewarrior", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, err.(*client.Error).StatusCode)
+	assert.Equal(t, -6, calls)
+
+	// non-idempotent requests are not
+	calls = 0
+
+	err = c.CreateRepository(context.Background(), &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"})
+	assert.Equal(t, http.StatusServiceUnavailable, err.(*client.Error).StatusCode)
+	assert.Equal(t, 1, calls)
+}
+
+func TestClient_Requests(t *testing.T) {
+	pushedSince := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	results := []struct {
+		Call           func(c *client.Client) error
+		ExpectedMethod string
+		ExpectedURI    string
+		ExpectedBody   string
+		ExpectedHeader http.Header
+		ResponseBody   string
+	}{
+		{
+			Call: func(c *client.Client) error {
+				_, err := c.ListRepositories(context.Background(), "blamewarrior", &blamewarrior.ListOptions{
+					IncludeDeleted: true, Language: "Go", Sort: blamewarrior.SortByStars, PushedSince: pushedSince,
+				})
+				return err
+			},
+			ExpectedMethod: "GET",
+			ExpectedURI:    "/repositories/blamewarrior?include_deleted=true&language=Go&pushed_since=2017-06-01T00%3A00%3A00Z&sort=stars",
+			ResponseBody:   "[]",
+		},
+		{
+			Call: func(c *client.Client) error {
+				return c.CreateRepository(context.Background(), &blamewarrior.Repository{Owner: "blamewarrior", Name: `re"pos`})
+			},
+			ExpectedMethod: "POST",
+			ExpectedURI:    "/repositories",
+			ExpectedBody:   `{"full_name":"blamewarrior/re\"pos","owner":"blamewarrior","name":"re\"pos","private":false}`,
+			ExpectedHeader: http.Header{"X-Actor": {"bot"}, "Content-Type": {"application/json"}},
+		},
+		{
+			Call: func(c *client.Client) error {
+				_, _, err := c.UpdateRepository(context.Background(), "blamewarrior/repos", []byte(`{"private":true}`), `"v1"`)
+				return err
+			},
+			ExpectedMethod: "PATCH",
+			ExpectedURI:    "/repositories/blamewarrior/repos",
+			ExpectedBody:   `{"private":true}`,
+			ExpectedHeader: http.Header{"If-Match": {`"v1"`}, "Content-Type": {"application/merge-patch+json"}},
+		},
+		{
+			Call: func(c *client.Client) error {
+				_, err := c.FileOwners(context.Background(), "blamewarrior/repos", "src/main.go")
+				return err
+			},
+			ExpectedMethod: "GET",
+			ExpectedURI:    "/repositories/blamewarrior/repos/owners?path=src%2Fmain.go",
+		},
+		{
+			Call: func(c *client.Client) error {
+				return c.DeleteRepository(context.Background(), "blamewarrior/repo with spaces")
+			},
+			ExpectedMethod: "DELETE",
+			ExpectedURI:    "/repositories/blamewarrior/repo%20with%20spaces",
+		},
+		{
+			Call: func(c *client.Client) error {
+				_, err := c.ListRepositories(context.Background(), "blamewarrior", &blamewarrior.ListOptions{Provider: blamewarrior.ProviderGitlab})
+				return err
+			},
+			ExpectedMethod: "GET",
+			ExpectedURI:    "/repositories/gitlab/blamewarrior",
+			ResponseBody:   "[]",
+		},
+		{
+			Call: func(c *client.Client) error {
+				return c.RestoreRepository(context.Background(), "gitlab:blamewarrior/repos")
+			},
+			ExpectedMethod: "POST",
+			ExpectedURI:    "/repositories/gitlab/blamewarrior/repos/restore",
+		},
+		{
+			Call: func(c *client.Client) error {
+				_, err := c.RepositoryHistory(context.Background(), "gitlab:blamewarrior/backend/my.repo")
+				return err
+			},
+			ExpectedMethod: "GET",
+			ExpectedURI:    "/repositories/gitlab/blamewarrior%2Fbackend/my.repo/history",
+			ResponseBody:   "[]",
+		},
+		{
+			Call: func(c *client.Client) error {
+				_, err := c.LookupRepositories(context.Background(), "", []string{"blamewarrior/repos", "gitlab:blamewarrior/hooks"}, []int64{42})
+				return err
+			},
+			ExpectedMethod: "POST",
+			ExpectedURI:    "/repositories/lookup",
+			ExpectedBody:   `{"full_names":["blamewarrior/repos","gitlab:blamewarrior/hooks"],"ids":[42]}`,
+			ResponseBody:   "[]",
+		},
+		{
+			Call: func(c *client.Client) error {
+				_, err := c.LookupRepositories(context.Background(), blamewarrior.ProviderGitlab, nil, []int64{7})
+				return err
+			},
+			ExpectedMethod: "POST",
+			ExpectedURI:    "/repositories/gitlab/lookup",
+			ExpectedBody:   `{"ids":[7]}`,
+			ResponseBody:   "[]",
+		},
+		{
+			Call: func(c *client.Client) error {
+				_, err := c.OwnerSettings(context.Background(), "blamewarrior")
+				return err
+			},
+			ExpectedMethod: "GET",
+			ExpectedURI:    "/owners/blamewarrior/settings",
+			ResponseBody:   "{}",
+		},
+		{
+			Call: func(c *client.Client) error {
+				_, err := c.UpdateOwnerSettings(context.Background(), "gitlab:blamewarrior/backend", json.RawMessage(`{"version":1}`))
+				return err
+			},
+			ExpectedMethod: "PUT",
+			ExpectedURI:    "/owners/gitlab/blamewarrior%2Fbackend/settings",
+			ExpectedBody:   `{"version":1}`,
+			ResponseBody:   "{}",
+		},
+	}
+
+	for _, result := range results {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, result.ExpectedMethod, r.Method)
+			assert.Equal(t, result.ExpectedURI, r.RequestURI)
+
+			if result.ExpectedBody != "" {
+				assert.JSONEq(t, result.ExpectedBody, string(body))
+			}
+
+			for k := range result.ExpectedHeader {
+				assert.Equal(t, result.ExpectedHeader.Get(k), r.Header.Get(k), k)
+			}
+
+			if result.ResponseBody == "" {
+				result.ResponseBody = "{}"
+			}
+
+			w.Write([]byte(result.ResponseBody))
+		}))
+
+		c := client.NewClient(srv.URL)
+		c.Actor = "bot"
+
+		require.NoError(t, result.Call(c))
+
+		srv.Close()
+	}
+}
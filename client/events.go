@@ -0,0 +1,208 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// AuditOptions specifies optional parameters for audit log listing.
+type AuditOptions struct {
+	// Since limits listing to events recorded after given time
+	Since time.Time
+	// After is the ID of event to start listing after
+	After int64
+	// PageSize is the number of events fetched at once, the service default is used if zero
+	PageSize int
+}
+
+// EventIterator iterates over audit log fetching it page by page:
+//
+//	it := client.AuditEvents(ctx, nil)
+//	for it.Next() {
+//		event := it.Event()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type EventIterator struct {
+	client *Client
+	ctx    context.Context
+	opts   AuditOptions
+
+	page []blamewarrior.RepositoryEvent
+	cur  blamewarrior.RepositoryEvent
+	done bool
+	err  error
+}
+
+// AuditEvents returns iterator over audit log entries across all owners.
+func (client *Client) AuditEvents(ctx context.Context, opts *AuditOptions) *EventIterator {
+	it := &EventIterator{client: client, ctx: ctx}
+	if opts != nil {
+		it.opts = *opts
+	}
+
+	return it
+}
+
+// Next advances iterator to the next event fetching the next page if needed. It returns false
+// when there are no more events or an error occurred.
+func (it *EventIterator) Next() bool {
+	if len(it.page) == 0 && !it.done && it.err == nil {
+		it.fetch()
+	}
+
+	if len(it.page) == 0 {
+		return false
+	}
+
+	it.cur, it.page = it.page[0], it.page[1:]
+	it.opts.After = it.cur.ID
+
+	return true
+}
+
+// Event returns the current event.
+func (it *EventIterator) Event() blamewarrior.RepositoryEvent {
+	return it.cur
+}
+
+// Err returns the error that stopped iteration, if any.
+func (it *EventIterator) Err() error {
+	return it.err
+}
+
+func (it *EventIterator) fetch() {
+	query := url.Values{}
+
+	if !it.opts.Since.IsZero() {
+		query.Set("since", it.opts.Since.Format(time.RFC3339))
+	}
+
+	if it.opts.After > 0 {
+		query.Set("after", strconv.FormatInt(it.opts.After, 10))
+	}
+
+	if it.opts.PageSize > 0 {
+		query.Set("limit", strconv.Itoa(it.opts.PageSize))
+	}
+
+	response, err := it.client.do(it.ctx, &request{Method: "GET", Path: "/audit", Query: query}, &it.page)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	// the service sets Link header only if there may be more events
+	it.done = !strings.Contains(response.Header.Get("Link"), `rel="next"`)
+}
+
+// EventStream is a live stream of repository events.
+type EventStream struct {
+	response *http.Response
+	scanner  *bufio.Scanner
+	// LastID is the stream cursor sent along with the last received event, it can be used to
+	// resume the stream. Events received before may be replayed again, consumers are expected
+	// to skip them by event ID.
+	LastID int64
+}
+
+// StreamEvents opens live stream of repository events. If cursor is not zero events recorded
+// after event with this ID are replayed first. The stream is closed when ctx is canceled.
+func (client *Client) StreamEvents(ctx context.Context, cursor int64) (*EventStream, error) {
+	header := http.Header{}
+	header.Set("Accept", "text/event-stream")
+
+	if cursor > 0 {
+		header.Set("Last-Event-ID", strconv.FormatInt(cursor, 10))
+	}
+
+	response, err := client.send(ctx, &request{Method: "GET", Path: "/events/stream", Header: header}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, decodeResponse(response, nil)
+	}
+
+	return &EventStream{
+		response: response,
+		scanner:  bufio.NewScanner(response.Body),
+		LastID:   cursor,
+	}, nil
+}
+
+// Next blocks until the next event is received. It returns an error when the stream is closed.
+func (stream *EventStream) Next() (*blamewarrior.RepositoryEvent, error) {
+	var (
+		data []string
+		id   string
+	)
+
+	for stream.scanner.Scan() {
+		line := stream.scanner.Text()
+
+		switch {
+		case line == "":
+			if len(data) == 0 {
+				continue
+			}
+
+			event := new(blamewarrior.RepositoryEvent)
+			if err := json.Unmarshal([]byte(strings.Join(data, "\n")), event); err != nil {
+				return nil, fmt.Errorf("failed to decode event: %s", err)
+			}
+
+			stream.LastID = event.ID
+			if id != "" {
+				cursor, err := strconv.ParseInt(id, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("malformed event stream cursor %q", id)
+				}
+				stream.LastID = cursor
+			}
+
+			return event, nil
+		case strings.HasPrefix(line, "id:"):
+			id = strings.TrimPrefix(strings.TrimPrefix(line, "id:"), " ")
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if err := stream.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("event stream is closed")
+}
+
+// Close closes the stream.
+func (stream *EventStream) Close() error {
+	return stream.response.Body.Close()
+}
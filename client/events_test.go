@@ -0,0 +1,108 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/client"
+)
+
+func TestClient_AuditEvents(t *testing.T) {
+	var requests []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RawQuery)
+
+		switch r.URL.Query().Get("after") {
+		case "":
+			w.Header().Set("Link", `</audit?after=2&limit=2>; rel="next"`)
+			w.Write([]byte(`[{"id":1,"name":"repos"},{"id":2,"name":"hooks"}]`))
+		case "2":
+			w.Write([]byte(`[{"id":3,"name":"tokens"}]`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	it := client.NewClient(srv.URL).AuditEvents(context.Background(), &client.AuditOptions{PageSize: 2})
+
+	var names []string
+	for it.Next() {
+		names = append(names, it.Event().Name)
+	}
+
+	require.NoError(t, it.Err())
+	assert.Equal(t, []string{"repos", "hooks", "tokens"}, names)
+	assert.Equal(t, []string{"limit=2", "after=2&limit=2"}, requests)
+}
+
+func TestClient_AuditEventsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Incorrect limit", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	it := client.NewClient(srv.URL).AuditEvents(context.Background(), nil)
+
+	assert.False(t, it.Next())
+	assert.True(t, client.IsInvalid(it.Err()))
+}
+
+func TestClient_StreamEvents(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "41", r.Header.Get("Last-Event-ID"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "id: 42\nevent: created\ndata: {\"id\":42,\"name\":\"repos\",\"action\":\"created\"}\n\n")
+		fmt.Fprint(w, "id: 43\nevent: deleted\ndata: {\"id\":43,\"name\":\"repos\",\"action\":\"deleted\"}\n\n")
+		// event 44 is not committed yet, so the cursor stays behind
+		fmt.Fprint(w, "id: 43\nevent: updated\ndata: {\"id\":45,\"name\":\"repos\",\"action\":\"updated\"}\n\n")
+	}))
+	defer srv.Close()
+
+	stream, err := client.NewClient(srv.URL).StreamEvents(context.Background(), 41)
+	require.NoError(t, err)
+	defer stream.Close()
+
+	event, err := stream.Next()
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), event.ID)
+	assert.Equal(t, "created", event.Action)
+
+	event, err = stream.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "deleted", event.Action)
+	assert.Equal(t, int64(43), stream.LastID)
+
+	event, err = stream.Next()
+	require.NoError(t, err)
+	assert.Equal(t, int64(45), event.ID)
+	assert.Equal(t, int64(43), stream.LastID)
+
+	_, err = stream.Next()
+	assert.Error(t, err)
+}
@@ -0,0 +1,97 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// JobsOptions narrow down and paginate the list of background jobs.
+type JobsOptions struct {
+	// State and Type only list jobs in given state and of given type if set
+	State, Type string
+	// After only lists jobs with greater IDs, pass ID of the last job to fetch the next page
+	After int64
+	// Limit is the number of jobs, the service default is used if it is zero
+	Limit int
+}
+
+// Jobs returns a page of background jobs in the order they were enqueued.
+func (client *Client) Jobs(ctx context.Context, opts *JobsOptions) (jobs []blamewarrior.Job, err error) {
+	query := url.Values{}
+
+	if opts != nil {
+		if opts.State != "" {
+			query.Set("state", opts.State)
+		}
+
+		if opts.Type != "" {
+			query.Set("type", opts.Type)
+		}
+
+		if opts.After > 0 {
+			query.Set("after", strconv.FormatInt(opts.After, 10))
+		}
+
+		if opts.Limit > 0 {
+			query.Set("limit", strconv.Itoa(opts.Limit))
+		}
+	}
+
+	_, err = client.do(ctx, &request{Method: "GET", Path: "/jobs", Query: query}, &jobs)
+
+	return jobs, err
+}
+
+// Job returns background job with given ID.
+func (client *Client) Job(ctx context.Context, id int64) (*blamewarrior.Job, error) {
+	return client.jobRequest(ctx, "GET", id, "")
+}
+
+// RetryJob schedules dead or cancelled job to run again.
+func (client *Client) RetryJob(ctx context.Context, id int64) (*blamewarrior.Job, error) {
+	return client.jobRequest(ctx, "POST", id, "/retry")
+}
+
+// CancelJob stops pending or running job from being run again.
+func (client *Client) CancelJob(ctx context.Context, id int64) (*blamewarrior.Job, error) {
+	return client.jobRequest(ctx, "POST", id, "/cancel")
+}
+
+// Operation returns progress of request processed in the background.
+func (client *Client) Operation(ctx context.Context, id int64) (*blamewarrior.Operation, error) {
+	op := new(blamewarrior.Operation)
+
+	if _, err := client.do(ctx, &request{Method: "GET", Path: "/operations/" + strconv.FormatInt(id, 10)}, op); err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+func (client *Client) jobRequest(ctx context.Context, method string, id int64, suffix string) (*blamewarrior.Job, error) {
+	job := new(blamewarrior.Job)
+
+	if _, err := client.do(ctx, &request{Method: method, Path: "/jobs/" + strconv.FormatInt(id, 10) + suffix}, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
@@ -0,0 +1,107 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/client"
+)
+
+func TestClient_Jobs(t *testing.T) {
+	var requests []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+
+		switch r.URL.Path {
+		case "/jobs":
+			fmt.Fprint(w, `[{"id": 3, "type": "create_hook", "state": "dead"}]`)
+		case "/jobs/3/retry":
+			fmt.Fprint(w, `{"id": 3, "type": "create_hook", "state": "pending"}`)
+		case "/jobs/4/cancel":
+			http.Error(w, "Operation is not allowed in current job state", http.StatusConflict)
+		default:
+			http.Error(w, "Job not found", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := client.NewClient(srv.URL)
+
+	jobs, err := c.Jobs(context.Background(), &client.JobsOptions{State: blamewarrior.JobDead, After: 2, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, blamewarrior.JobDead, jobs[0].State)
+
+	job, err := c.RetryJob(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.JobPending, job.State)
+
+	_, err = c.CancelJob(context.Background(), 4)
+	assert.True(t, client.IsConflict(err))
+
+	_, err = c.Job(context.Background(), 5)
+	assert.True(t, client.IsNotFound(err))
+
+	assert.Equal(t, []string{
+		"GET /jobs?after=2&limit=10&state=dead",
+		"POST /jobs/3/retry",
+		"POST /jobs/4/cancel",
+		"GET /jobs/5",
+	}, requests)
+}
+
+func TestClient_CreateRepositoryAsync(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /repositories":
+			assert.Equal(t, "respond-async", r.Header.Get("Prefer"))
+
+			w.Header().Set("Location", "/operations/7")
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, `{"id": 7, "type": "create_hook", "status": "running", "repository": "blamewarrior/repos"}`)
+		case "GET /operations/7":
+			fmt.Fprint(w, `{"id": 7, "type": "create_hook", "status": "succeeded", "repository": "blamewarrior/repos"}`)
+		default:
+			http.Error(w, "Operation not found", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := client.NewClient(srv.URL)
+
+	op, err := c.CreateRepositoryAsync(context.Background(), &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), op.ID)
+	assert.Equal(t, blamewarrior.OperationRunning, op.Status)
+
+	op, err = c.Operation(context.Background(), op.ID)
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.OperationSucceeded, op.Status)
+	assert.Equal(t, "blamewarrior/repos", op.Repository)
+
+	_, err = c.Operation(context.Background(), 8)
+	assert.True(t, client.IsNotFound(err))
+}
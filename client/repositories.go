@@ -0,0 +1,275 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// FileOwners are owners of a file according to repository CODEOWNERS.
+type FileOwners struct {
+	Path   string   `json:"path"`
+	Owners []string `json:"owners"`
+	// Pattern and Line identify CODEOWNERS rule that matched the file
+	Pattern string `json:"pattern,omitempty"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// GetRepository returns tracked repository with given full name along with its ETag, which can
+// be passed to UpdateRepository to prevent lost updates.
+func (client *Client) GetRepository(ctx context.Context, fullName string, opts *blamewarrior.ListOptions) (repo *blamewarrior.Repository, etag string, err error) {
+	repo = new(blamewarrior.Repository)
+
+	response, err := client.do(ctx, &request{Method: "GET", Path: repositoryPath(fullName), Query: listQuery(opts)}, repo)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return repo, response.Header.Get("ETag"), nil
+}
+
+// ListRepositories returns repositories of owner that are tracked by BlameWarrior.
+// Repositories hosted at provider other than GitHub are listed if opts.Provider is set.
+func (client *Client) ListRepositories(ctx context.Context, owner string, opts *blamewarrior.ListOptions) (repositories []blamewarrior.Repository, err error) {
+	var provider string
+	if opts != nil {
+		provider = opts.Provider
+	}
+
+	_, err = client.do(ctx, &request{Method: "GET", Path: ownerPath(provider, url.PathEscape(owner)), Query: listQuery(opts)}, &repositories)
+
+	return repositories, err
+}
+
+// ListGithubRepositories returns repositories of owner available on GitHub.
+func (client *Client) ListGithubRepositories(ctx context.Context, owner string) (repositories []blamewarrior.Repository, err error) {
+	_, err = client.do(ctx, &request{Method: "GET", Path: "/repositories/" + url.PathEscape(owner) + "/github"}, &repositories)
+
+	return repositories, err
+}
+
+// LookupRepositories returns tracked repositories with given full or qualified names and IDs at
+// provider in a single request. Results follow the order of full names and then IDs, see
+// blamewarrior.MaxLookupItems for the limit.
+func (client *Client) LookupRepositories(ctx context.Context, provider string, fullNames []string, ids []int64) (results []blamewarrior.LookupResult, err error) {
+	body := struct {
+		FullNames []string `json:"full_names,omitempty"`
+		IDs       []int64  `json:"ids,omitempty"`
+	}{fullNames, ids}
+
+	_, err = client.do(ctx, &request{Method: "POST", Path: ownerPath(provider, "lookup"), Body: body}, &results)
+
+	return results, err
+}
+
+// CreateRepository starts tracking repository.
+func (client *Client) CreateRepository(ctx context.Context, repo *blamewarrior.Repository) error {
+	_, err := client.do(ctx, &request{Method: "POST", Path: "/repositories", Body: repo}, nil)
+
+	return err
+}
+
+// CreateRepositoryAsync starts tracking repository without waiting for its webhook to be
+// created. Repository is pending until the returned operation succeeds, see Operation.
+func (client *Client) CreateRepositoryAsync(ctx context.Context, repo *blamewarrior.Repository) (*blamewarrior.Operation, error) {
+	op := new(blamewarrior.Operation)
+
+	header := http.Header{}
+	header.Set("Prefer", "respond-async")
+
+	if _, err := client.do(ctx, &request{Method: "POST", Path: "/repositories", Header: header, Body: repo}, op); err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// DeleteRepository stops tracking repository. Deleted repository can be restored until it is purged.
+func (client *Client) DeleteRepository(ctx context.Context, fullName string) error {
+	_, err := client.do(ctx, &request{Method: "DELETE", Path: repositoryPath(fullName)}, nil)
+
+	return err
+}
+
+// RestoreRepository resumes tracking of a deleted repository.
+func (client *Client) RestoreRepository(ctx context.Context, fullName string) error {
+	_, err := client.do(ctx, &request{Method: "POST", Path: repositoryPath(fullName, "restore")}, nil)
+
+	return err
+}
+
+// UpdateRepository applies JSON Merge Patch to repository and returns updated repository with
+// its new ETag. If etag is not empty the update is rejected with precondition failed error when
+// repository has been modified since.
+func (client *Client) UpdateRepository(ctx context.Context, fullName string, patch json.RawMessage, etag string) (repo *blamewarrior.Repository, newETag string, err error) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/merge-patch+json")
+
+	if etag != "" {
+		header.Set("If-Match", etag)
+	}
+
+	repo = new(blamewarrior.Repository)
+
+	response, err := client.do(ctx, &request{Method: "PATCH", Path: repositoryPath(fullName), Header: header, Body: patch}, repo)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return repo, response.Header.Get("ETag"), nil
+}
+
+// RepositoryHistory returns audit log of repository in chronological order.
+func (client *Client) RepositoryHistory(ctx context.Context, fullName string) (events []blamewarrior.RepositoryEvent, err error) {
+	_, err = client.do(ctx, &request{Method: "GET", Path: repositoryPath(fullName, "history")}, &events)
+
+	return events, err
+}
+
+// RepositorySettings returns repository settings merged into owner defaults.
+func (client *Client) RepositorySettings(ctx context.Context, fullName string) (settings json.RawMessage, err error) {
+	_, err = client.do(ctx, &request{Method: "GET", Path: repositoryPath(fullName, "settings")}, &settings)
+
+	return settings, err
+}
+
+// UpdateRepositorySettings replaces repository settings.
+func (client *Client) UpdateRepositorySettings(ctx context.Context, fullName string, settings json.RawMessage) (json.RawMessage, error) {
+	var updated json.RawMessage
+
+	_, err := client.do(ctx, &request{Method: "PUT", Path: repositoryPath(fullName, "settings"), Body: settings}, &updated)
+
+	return updated, err
+}
+
+// OwnerSettings returns default settings for repositories of owner. Owners at provider other
+// than GitHub are qualified the same way as repository names, e.g. gitlab:group.
+func (client *Client) OwnerSettings(ctx context.Context, owner string) (settings json.RawMessage, err error) {
+	_, err = client.do(ctx, &request{Method: "GET", Path: ownerSettingsPath(owner)}, &settings)
+
+	return settings, err
+}
+
+// UpdateOwnerSettings replaces default settings for repositories of owner, see OwnerSettings.
+func (client *Client) UpdateOwnerSettings(ctx context.Context, owner string, settings json.RawMessage) (json.RawMessage, error) {
+	var updated json.RawMessage
+
+	_, err := client.do(ctx, &request{Method: "PUT", Path: ownerSettingsPath(owner), Body: settings}, &updated)
+
+	return updated, err
+}
+
+// RefreshRepositoryConfig reloads configuration file from repository and returns it.
+func (client *Client) RefreshRepositoryConfig(ctx context.Context, fullName string) (*blamewarrior.RepositoryConfig, error) {
+	config := new(blamewarrior.RepositoryConfig)
+
+	if _, err := client.do(ctx, &request{Method: "POST", Path: repositoryPath(fullName, "config", "refresh")}, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// FileOwners returns owners of file at path relative to repository root.
+func (client *Client) FileOwners(ctx context.Context, fullName, path string) (*FileOwners, error) {
+	owners := new(FileOwners)
+
+	query := url.Values{}
+	query.Set("path", path)
+
+	if _, err := client.do(ctx, &request{Method: "GET", Path: repositoryPath(fullName, "owners"), Query: query}, owners); err != nil {
+		return nil, err
+	}
+
+	return owners, nil
+}
+
+// RefreshCodeOwners reloads CODEOWNERS file from repository and returns it.
+func (client *Client) RefreshCodeOwners(ctx context.Context, fullName string) (*blamewarrior.CodeOwners, error) {
+	owners := new(blamewarrior.CodeOwners)
+
+	if _, err := client.do(ctx, &request{Method: "POST", Path: repositoryPath(fullName, "owners", "refresh")}, owners); err != nil {
+		return nil, err
+	}
+
+	return owners, nil
+}
+
+// Collaborators returns users and teams that have at least given permission on repository.
+// All collaborators are returned if permission is empty.
+func (client *Client) Collaborators(ctx context.Context, fullName, permission string) (collaborators []blamewarrior.Collaborator, err error) {
+	query := url.Values{}
+	if permission != "" {
+		query.Set("permission", permission)
+	}
+
+	_, err = client.do(ctx, &request{Method: "GET", Path: repositoryPath(fullName, "collaborators"), Query: query}, &collaborators)
+
+	return collaborators, err
+}
+
+// ReconcileRepository refreshes data fetched from GitHub for repository and applies staleness
+// policy to it. The returned repository is marked deleted if it has been untracked.
+func (client *Client) ReconcileRepository(ctx context.Context, fullName string) (*blamewarrior.Repository, error) {
+	repo := new(blamewarrior.Repository)
+
+	if _, err := client.do(ctx, &request{Method: "POST", Path: repositoryPath(fullName, "reconcile")}, repo); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// listQuery encodes listing options as request query parameters.
+func listQuery(opts *blamewarrior.ListOptions) url.Values {
+	query := url.Values{}
+
+	if opts == nil {
+		return query
+	}
+
+	if opts.IncludeDeleted {
+		query.Set("include_deleted", "true")
+	}
+
+	if opts.Language != "" {
+		query.Set("language", opts.Language)
+	}
+
+	if opts.Topic != "" {
+		query.Set("topic", opts.Topic)
+	}
+
+	if opts.State != "" {
+		query.Set("state", opts.State)
+	}
+
+	if opts.Sort != "" {
+		query.Set("sort", opts.Sort)
+	}
+
+	if !opts.PushedSince.IsZero() {
+		query.Set("pushed_since", opts.PushedSince.Format(time.RFC3339))
+	}
+
+	return query
+}
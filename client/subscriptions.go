@@ -0,0 +1,69 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// CreateSubscription registers url to receive repository events with given actions signed with
+// secret. All events are delivered if no actions are given.
+func (client *Client) CreateSubscription(ctx context.Context, u, secret string, actions ...string) (*blamewarrior.Subscription, error) {
+	body := struct {
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+		Secret string   `json:"secret"`
+	}{u, actions, secret}
+
+	sub := new(blamewarrior.Subscription)
+
+	if _, err := client.do(ctx, &request{Method: "POST", Path: "/subscriptions", Body: body}, sub); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// Subscriptions returns all registered subscriptions.
+func (client *Client) Subscriptions(ctx context.Context) (subscriptions []blamewarrior.Subscription, err error) {
+	_, err = client.do(ctx, &request{Method: "GET", Path: "/subscriptions"}, &subscriptions)
+
+	return subscriptions, err
+}
+
+// DeleteSubscription removes subscription.
+func (client *Client) DeleteSubscription(ctx context.Context, id int) error {
+	_, err := client.do(ctx, &request{Method: "DELETE", Path: "/subscriptions/" + strconv.Itoa(id)}, nil)
+
+	return err
+}
+
+// SubscriptionDeliveries returns up to limit latest delivery attempts of subscription. The
+// service default is used if limit is zero.
+func (client *Client) SubscriptionDeliveries(ctx context.Context, id int, limit int) (deliveries []blamewarrior.Delivery, err error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	_, err = client.do(ctx, &request{Method: "GET", Path: "/subscriptions/" + strconv.Itoa(id) + "/deliveries", Query: query}, &deliveries)
+
+	return deliveries, err
+}
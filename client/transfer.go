@@ -0,0 +1,88 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// transferContentTypes maps export file formats to their media types.
+var transferContentTypes = map[string]string{
+	blamewarrior.FormatJSONL: "application/x-ndjson",
+	blamewarrior.FormatCSV:   "text/csv",
+}
+
+// ExportRepositories streams tracked repositories of owner, or all tracked repositories if owner
+// is empty, in either blamewarrior.FormatJSONL or blamewarrior.FormatCSV. The caller is responsible
+// for closing returned reader.
+func (client *Client) ExportRepositories(ctx context.Context, owner, format string) (io.ReadCloser, error) {
+	query := url.Values{"format": {format}}
+	if owner != "" {
+		query.Set("owner", owner)
+	}
+
+	response, err := client.send(ctx, &request{Method: "GET", Path: "/repositories/export", Query: query}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, decodeResponse(response, nil)
+	}
+
+	return response.Body, nil
+}
+
+// ImportRepositories uploads export file in given format to track repositories listed in it or
+// update already tracked ones. In dry run mode changes are only validated. The returned report
+// contains the result for every line of the file.
+func (client *Client) ImportRepositories(ctx context.Context, r io.Reader, format string, dryRun bool) (*blamewarrior.ImportReport, error) {
+	contentType, ok := transferContentTypes[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repositories: %s", err)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+
+	report := &blamewarrior.ImportReport{}
+
+	_, err = client.do(ctx, &request{
+		Method: "POST",
+		Path:   "/repositories/import",
+		Query:  url.Values{"format": {format}, "dry_run": {strconv.FormatBool(dryRun)}},
+		Header: header,
+		Body:   body,
+	}, report)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,86 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package client_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/client"
+)
+
+func TestClient_ExportRepositories(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("format") == "xml" {
+			http.Error(w, "unsupported format xml", http.StatusBadRequest)
+			return
+		}
+
+		assert.Equal(t, "/repositories/export?format=csv&owner=blamewarrior", r.RequestURI)
+
+		w.Write([]byte("full_name,private,settings\nblamewarrior/repos,false,\n"))
+	}))
+	defer srv.Close()
+
+	c := client.NewClient(srv.URL)
+
+	body, err := c.ExportRepositories(context.Background(), "blamewarrior", blamewarrior.FormatCSV)
+	require.NoError(t, err)
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "full_name,private,settings\nblamewarrior/repos,false,\n", string(data))
+
+	_, err = c.ExportRepositories(context.Background(), "", "xml")
+	assert.True(t, client.IsInvalid(err))
+}
+
+func TestClient_ImportRepositories(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/repositories/import?dry_run=true&format=jsonl", r.RequestURI)
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		assert.Equal(t, "{\"full_name\":\"blamewarrior/repos\"}\n", string(body))
+
+		w.Write([]byte(`{"dry_run":true,"created":1,"results":[{"line":1,"full_name":"blamewarrior/repos","result":"created"}]}`))
+	}))
+	defer srv.Close()
+
+	c := client.NewClient(srv.URL)
+
+	report, err := c.ImportRepositories(context.Background(), strings.NewReader("{\"full_name\":\"blamewarrior/repos\"}\n"), blamewarrior.FormatJSONL, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, &blamewarrior.ImportReport{
+		DryRun:  true,
+		Created: 1,
+		Results: []blamewarrior.ImportResult{{Line: 1, FullName: "blamewarrior/repos", Result: blamewarrior.ImportCreated}},
+	}, report)
+
+	_, err = c.ImportRepositories(context.Background(), strings.NewReader(""), "xml", true)
+	assert.EqualError(t, err, "unsupported format xml")
+}
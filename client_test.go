@@ -0,0 +1,143 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/client"
+)
+
+func TestClient(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events, owner_settings, subscriptions CASCADE;")
+	require.NoError(t, err)
+
+	hooksClient := new(hooksClientMock)
+	hooksClient.On("CreateHook", "blamewarrior/repos").Return(nil)
+	hooksClient.On("DeleteHook", "blamewarrior/repos").Return(nil)
+
+	ghClient := new(githubClientMock)
+	ghClient.On("UserRepositories").Return([]blamewarrior.Repository{{Owner: "blamewarrior", Name: "hooks"}})
+
+	router, err := newRouter(&Handlers{
+		db:          db,
+		hooksClient: hooksClient,
+		ghClient:    ghClient,
+	})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	c := client.NewClient(srv.URL)
+	c.Actor = "user1"
+
+	ctx := context.Background()
+
+	require.NoError(t, c.CreateRepository(ctx, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+
+	repo, etag, err := c.GetRepository(ctx, "blamewarrior/repos", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "repos", repo.Name)
+	assert.NotEmpty(t, etag)
+
+	_, _, err = c.GetRepository(ctx, "blamewarrior/missing", nil)
+	assert.True(t, client.IsNotFound(err))
+
+	repositories, err := c.ListRepositories(ctx, "blamewarrior", nil)
+	require.NoError(t, err)
+	assert.Len(t, repositories, 1)
+
+	_, err = c.ListRepositories(ctx, "blamewarrior", &blamewarrior.ListOptions{Sort: "size"})
+	assert.True(t, client.IsInvalid(err))
+
+	repositories, err = c.ListGithubRepositories(ctx, "blamewarrior")
+	require.NoError(t, err)
+	assert.Equal(t, []blamewarrior.Repository{{Owner: "blamewarrior", Name: "hooks"}}, repositories)
+
+	repo, newETag, err := c.UpdateRepository(ctx, "blamewarrior/repos", json.RawMessage(`{"private":true}`), etag)
+	require.NoError(t, err)
+	assert.True(t, repo.Private)
+	assert.NotEqual(t, etag, newETag)
+
+	// stale ETag is rejected
+	_, _, err = c.UpdateRepository(ctx, "blamewarrior/repos", json.RawMessage(`{"private":false}`), etag)
+	assert.True(t, client.IsPreconditionFailed(err))
+
+	settings, err := c.UpdateOwnerSettings(ctx, "blamewarrior", json.RawMessage(`{"version":1}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":1}`, string(settings))
+
+	settings, err = c.OwnerSettings(ctx, "blamewarrior")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":1}`, string(settings))
+
+	collaborators, err := c.Collaborators(ctx, "blamewarrior/repos", blamewarrior.PermissionWrite)
+	require.NoError(t, err)
+	assert.Empty(t, collaborators)
+
+	owners, err := c.FileOwners(ctx, "blamewarrior/repos", "main.go")
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, owners.Owners)
+
+	require.NoError(t, c.DeleteRepository(ctx, "blamewarrior/repos"))
+	require.NoError(t, c.RestoreRepository(ctx, "blamewarrior/repos"))
+
+	history, err := c.RepositoryHistory(ctx, "blamewarrior/repos")
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+	assert.Equal(t, "user1", history[0].Actor)
+
+	it := c.AuditEvents(ctx, &client.AuditOptions{PageSize: 3})
+
+	var actions []string
+	for it.Next() {
+		actions = append(actions, it.Event().Action)
+	}
+
+	require.NoError(t, it.Err())
+	assert.Equal(t, []string{"created", "updated", "deleted", "restored"}, actions)
+
+	sub, err := c.CreateSubscription(ctx, "https://example.com/hook", "s3cr3t", blamewarrior.EventCreated)
+	require.NoError(t, err)
+	assert.True(t, sub.Active)
+
+	_, err = c.CreateSubscription(ctx, "https://example.com/hook", "")
+	assert.True(t, client.IsInvalid(err))
+
+	subscriptions, err := c.Subscriptions(ctx)
+	require.NoError(t, err)
+	assert.Len(t, subscriptions, 1)
+
+	deliveries, err := c.SubscriptionDeliveries(ctx, sub.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	require.NoError(t, c.DeleteSubscription(ctx, sub.ID))
+	assert.True(t, client.IsNotFound(c.DeleteSubscription(ctx, sub.ID)))
+
+	hooksClient.AssertExpectations(t)
+}
@@ -0,0 +1,372 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/client"
+)
+
+// maxParallelRequests is the number of changes applied concurrently
+const maxParallelRequests = 4
+
+type reposctl struct {
+	client *client.Client
+	opts   *options
+	out    *output
+	stderr io.Writer
+}
+
+var listCommand = &command{
+	Usage: "list OWNER [--provider PROVIDER] [--include-deleted] [--language LANG] [--state STATE]",
+	Flags: func(fs *flag.FlagSet, opts *options) {
+		fs.StringVar(&opts.Provider, "provider", blamewarrior.DefaultProvider, "list repositories hosted at provider")
+		fs.BoolVar(&opts.IncludeDeleted, "include-deleted", false, "include deleted repositories")
+		fs.StringVar(&opts.Language, "language", "", "only list repositories written in language")
+		fs.StringVar(&opts.State, "state", "", "only list active or inactive repositories")
+	},
+	Run: func(ctx context.Context, ctl *reposctl, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected OWNER")
+		}
+
+		repositories, err := ctl.client.ListRepositories(ctx, args[0], &blamewarrior.ListOptions{
+			Provider:       ctl.opts.Provider,
+			IncludeDeleted: ctl.opts.IncludeDeleted,
+			Language:       ctl.opts.Language,
+			State:          ctl.opts.State,
+			Sort:           blamewarrior.SortByName,
+		})
+		if err != nil {
+			return err
+		}
+
+		return ctl.out.PrintRepositories(repositories)
+	},
+}
+
+var trackCommand = &command{
+	Usage:   "track OWNER/NAME [--private] [--yes]",
+	Mutates: true,
+	Flags: func(fs *flag.FlagSet, opts *options) {
+		fs.BoolVar(&opts.Private, "private", false, "mark repository private")
+	},
+	Run: func(ctx context.Context, ctl *reposctl, args []string) error {
+		repo, err := repositoryArg(args)
+		if err != nil {
+			return err
+		}
+
+		repo.Private = ctl.opts.Private
+
+		return ctl.trackAll(ctx, []blamewarrior.Repository{*repo})
+	},
+}
+
+var untrackCommand = &command{
+	Usage:   "untrack OWNER/NAME [--yes]",
+	Mutates: true,
+	Run: func(ctx context.Context, ctl *reposctl, args []string) error {
+		repo, err := repositoryArg(args)
+		if err != nil {
+			return err
+		}
+
+		return ctl.apply(ctx, []blamewarrior.Repository{*repo}, "untrack", func(ctx context.Context, repo *blamewarrior.Repository) (string, error) {
+			return resultDone, ctl.client.DeleteRepository(ctx, repo.QualifiedName())
+		})
+	},
+}
+
+var importCommand = &command{
+	Usage:   "import OWNER --from-github [--yes]",
+	Mutates: true,
+	Flags: func(fs *flag.FlagSet, opts *options) {
+		fs.BoolVar(&opts.FromGithub, "from-github", false, "track repositories of owner available on GitHub")
+	},
+	Run: func(ctx context.Context, ctl *reposctl, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected OWNER")
+		}
+
+		if !ctl.opts.FromGithub {
+			return fmt.Errorf("missing import source, only --from-github is supported")
+		}
+
+		available, err := ctl.client.ListGithubRepositories(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		return ctl.trackAll(ctx, available)
+	},
+}
+
+var reconcileCommand = &command{
+	Usage:   "reconcile OWNER[/NAME] [--yes]",
+	Mutates: true,
+	Run: func(ctx context.Context, ctl *reposctl, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected OWNER or OWNER/NAME")
+		}
+
+		var repositories []blamewarrior.Repository
+
+		if strings.Contains(args[0], "/") {
+			repo, err := repositoryArg(args)
+			if err != nil {
+				return err
+			}
+
+			repositories = append(repositories, *repo)
+		} else {
+			var err error
+			if repositories, err = ctl.client.ListRepositories(ctx, args[0], nil); err != nil {
+				return err
+			}
+		}
+
+		return ctl.apply(ctx, repositories, "reconcile", func(ctx context.Context, repo *blamewarrior.Repository) (string, error) {
+			reconciled, err := ctl.client.ReconcileRepository(ctx, repo.QualifiedName())
+			if err != nil {
+				return "", err
+			}
+
+			if reconciled.DeletedAt != nil {
+				return "untracked by staleness policy", nil
+			}
+
+			return resultDone + ", " + reconciled.State, nil
+		})
+	},
+}
+
+var exportCommand = &command{
+	Usage: "export [OWNER] [-o json|csv]",
+	Run: func(ctx context.Context, ctl *reposctl, args []string) error {
+		if len(args) > 1 {
+			return fmt.Errorf("expected at most one OWNER")
+		}
+
+		var owner string
+		if len(args) == 1 {
+			owner = args[0]
+		}
+
+		format := blamewarrior.FormatJSONL
+		if ctl.out.format == formatCSV {
+			format = blamewarrior.FormatCSV
+		}
+
+		body, err := ctl.client.ExportRepositories(ctx, owner, format)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+
+		_, err = io.Copy(ctl.out.w, body)
+
+		return err
+	},
+}
+
+var importFileCommand = &command{
+	Usage:   "import-file FILE [--format jsonl|csv] [--yes]",
+	Mutates: true,
+	Flags: func(fs *flag.FlagSet, opts *options) {
+		fs.StringVar(&opts.Format, "format", "", "file format, detected from extension by default")
+	},
+	Run: func(ctx context.Context, ctl *reposctl, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected FILE")
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		format := ctl.opts.Format
+		if format == "" {
+			format = strings.TrimPrefix(filepath.Ext(args[0]), ".")
+		}
+
+		if format != blamewarrior.FormatCSV {
+			format = blamewarrior.FormatJSONL
+		}
+
+		report, err := ctl.client.ImportRepositories(ctx, f, format, !ctl.opts.Yes)
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %s", args[0], err)
+		}
+
+		if err := ctl.out.PrintImportReport(report); err != nil {
+			return err
+		}
+
+		if report.DryRun {
+			fmt.Fprintf(ctl.stderr, "dry run: %d change(s) not applied, pass --yes to apply\n", report.Created+report.Updated)
+		}
+
+		if report.Failed > 0 {
+			return fmt.Errorf("%d of %d line(s) failed", report.Failed, len(report.Results))
+		}
+
+		return nil
+	},
+}
+
+// repositoryArg parses the only OWNER/NAME argument. Repositories hosted at providers other
+// than GitHub are passed as PROVIDER:OWNER/NAME, OWNER may consist of nested namespaces.
+func repositoryArg(args []string) (*blamewarrior.Repository, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected OWNER/NAME")
+	}
+
+	provider, fullName := blamewarrior.DefaultProvider, args[0]
+	if sep := strings.IndexByte(fullName, ':'); sep >= 0 {
+		provider, fullName = fullName[:sep], fullName[sep+1:]
+	}
+
+	sep := strings.LastIndexByte(fullName, '/')
+	if sep < 0 {
+		return nil, fmt.Errorf("incorrect full name %s, expected OWNER/NAME", args[0])
+	}
+
+	repo := &blamewarrior.Repository{Provider: provider, Owner: fullName[:sep], Name: fullName[sep+1:]}
+
+	if err := blamewarrior.ValidateName(repo.Provider, repo.Owner, repo.Name); err != nil {
+		return nil, fmt.Errorf("incorrect full name %s: %s", args[0], err)
+	}
+
+	return repo, nil
+}
+
+// trackAll starts tracking repositories that are not tracked yet.
+func (ctl *reposctl) trackAll(ctx context.Context, repositories []blamewarrior.Repository) error {
+	tracked := make(map[string]map[string]bool)
+
+	var (
+		missing []blamewarrior.Repository
+		skipped []change
+	)
+
+	for _, repo := range repositories {
+		key := repo.Provider + ":" + repo.Owner
+
+		names, ok := tracked[key]
+		if !ok {
+			owned, err := ctl.client.ListRepositories(ctx, repo.Owner, &blamewarrior.ListOptions{Provider: repo.Provider})
+			if err != nil {
+				return err
+			}
+
+			names = make(map[string]bool)
+			for _, r := range owned {
+				names[r.Name] = true
+			}
+			tracked[key] = names
+		}
+
+		if names[repo.Name] {
+			skipped = append(skipped, change{Repository: repo.QualifiedName(), Action: "skip", Result: "already tracked"})
+			continue
+		}
+
+		missing = append(missing, repo)
+	}
+
+	return ctl.apply(ctx, missing, "track", func(ctx context.Context, repo *blamewarrior.Repository) (string, error) {
+		return resultDone, ctl.client.CreateRepository(ctx, &blamewarrior.Repository{
+			Provider: repo.Provider,
+			Owner:    repo.Owner,
+			Name:     repo.Name,
+			Private:  repo.Private,
+			Settings: repo.Settings,
+		})
+	}, skipped...)
+}
+
+// apply runs fn for each of repositories and prints the outcome along with skipped changes.
+// In dry run mode changes are only printed.
+func (ctl *reposctl) apply(ctx context.Context, repositories []blamewarrior.Repository, action string, fn func(context.Context, *blamewarrior.Repository) (string, error), skipped ...change) error {
+	changes := make([]change, len(repositories))
+
+	var (
+		failed int
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		sem    = make(chan struct{}, maxParallelRequests)
+	)
+
+	for i := range repositories {
+		changes[i] = change{Repository: repositories[i].QualifiedName(), Action: action, Result: resultPlanned}
+
+		if !ctl.opts.Yes {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			result, err := fn(ctx, &repositories[i])
+			if err != nil {
+				mu.Lock()
+				failed++
+				mu.Unlock()
+
+				result = "failed: " + err.Error()
+			}
+
+			changes[i].Result = result
+		}(i)
+	}
+
+	wg.Wait()
+
+	if err := ctl.out.PrintChanges(append(changes, skipped...)); err != nil {
+		return err
+	}
+
+	if !ctl.opts.Yes {
+		if len(changes) > 0 {
+			fmt.Fprintf(ctl.stderr, "dry run: %d change(s) not applied, pass --yes to apply\n", len(changes))
+		}
+		return nil
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d change(s) failed", failed, len(changes))
+	}
+
+	return nil
+}
@@ -0,0 +1,306 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// fakeAPI is an in-memory implementation of repositories service API endpoints used by reposctl.
+type fakeAPI struct {
+	mu      sync.Mutex
+	tracked map[string]blamewarrior.Repository
+	github  []blamewarrior.Repository
+	calls   []string
+}
+
+func newFakeAPI(tracked ...blamewarrior.Repository) *fakeAPI {
+	api := &fakeAPI{tracked: make(map[string]blamewarrior.Repository)}
+	for _, repo := range tracked {
+		api.tracked[repo.FullName()] = repo
+	}
+
+	return api
+}
+
+func (api *fakeAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	api.calls = append(api.calls, req.Method+" "+req.URL.Path)
+
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+
+	switch {
+	case req.Method == "GET" && req.URL.Path == "/repositories/export":
+		rw, _ := blamewarrior.NewRepositoryWriter(w, req.URL.Query().Get("format"))
+		for _, name := range api.trackedNames() {
+			repo := api.tracked[name]
+			rw.Write(&repo)
+		}
+		rw.Flush()
+	case req.Method == "POST" && req.URL.Path == "/repositories/import":
+		lines, err := blamewarrior.ReadRepositories(req.Body, req.URL.Query().Get("format"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		report := &blamewarrior.ImportReport{DryRun: req.URL.Query().Get("dry_run") == "true"}
+		for _, line := range lines {
+			result := blamewarrior.ImportResult{Line: line.Line, FullName: line.Repository.FullName(), Result: blamewarrior.ImportCreated}
+
+			if line.Err != nil {
+				result.Result, result.Error = blamewarrior.ImportFailed, line.Err.Error()
+			} else if !report.DryRun {
+				api.tracked[result.FullName] = line.Repository
+			}
+
+			report.Add(result)
+		}
+
+		json.NewEncoder(w).Encode(report)
+	case req.Method == "GET" && len(segments) == 3 && segments[2] == "github":
+		json.NewEncoder(w).Encode(api.github)
+	case req.Method == "GET" && len(segments) == 2:
+		repositories := []blamewarrior.Repository{}
+		for _, repo := range api.tracked {
+			if repo.Owner == segments[1] {
+				repositories = append(repositories, repo)
+			}
+		}
+
+		sort.Slice(repositories, func(i, j int) bool { return repositories[i].Name < repositories[j].Name })
+
+		json.NewEncoder(w).Encode(repositories)
+	case req.Method == "POST" && len(segments) == 1:
+		var repo blamewarrior.Repository
+		json.NewDecoder(req.Body).Decode(&repo)
+
+		api.tracked[repo.FullName()] = repo
+		w.WriteHeader(http.StatusCreated)
+	case req.Method == "DELETE" && len(segments) == 3:
+		delete(api.tracked, segments[1]+"/"+segments[2])
+		w.WriteHeader(http.StatusNoContent)
+	case req.Method == "POST" && len(segments) == 4 && segments[3] == "reconcile":
+		repo, ok := api.tracked[segments[1]+"/"+segments[2]]
+		if !ok {
+			http.Error(w, "Repository not found", http.StatusNotFound)
+			return
+		}
+
+		repo.State = blamewarrior.StateActive
+		json.NewEncoder(w).Encode(&repo)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func (api *fakeAPI) trackedNames() (names []string) {
+	for name := range api.tracked {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (api *fakeAPI) mutations() (calls []string) {
+	for _, call := range api.calls {
+		if !strings.HasPrefix(call, "GET ") {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+func runCommand(t *testing.T, api *fakeAPI, args ...string) (stdout, stderr string, err error) {
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	var out, errOut bytes.Buffer
+
+	err = run(context.Background(), append(args, "--url", srv.URL), &out, &errOut)
+
+	return out.String(), errOut.String(), err
+}
+
+func TestList(t *testing.T) {
+	api := newFakeAPI(
+		blamewarrior.Repository{Owner: "blamewarrior", Name: "repos", State: "active", Metadata: &blamewarrior.RepositoryMetadata{Language: "Go", Stars: 3}},
+		blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks", Private: true, State: "inactive"},
+	)
+
+	stdout, _, err := runCommand(t, api, "list", "blamewarrior")
+	require.NoError(t, err)
+
+	assert.Equal(t, strings.Join([]string{
+		"FULL_NAME           PRIVATE  STATE     LANGUAGE  STARS  DELETED_AT",
+		"blamewarrior/hooks  true     inactive                   ",
+		"blamewarrior/repos  false    active    Go        3      ",
+		"",
+	}, "\n"), stdout)
+
+	stdout, _, err = runCommand(t, api, "list", "-o", "csv", "blamewarrior")
+	require.NoError(t, err)
+
+	assert.Equal(t, "full_name,private,state,language,stars,deleted_at\nblamewarrior/hooks,true,inactive,,,\nblamewarrior/repos,false,active,Go,3,\n", stdout)
+
+	stdout, _, err = runCommand(t, api, "list", "blamewarrior", "-o", "json")
+	require.NoError(t, err)
+
+	var repositories []blamewarrior.Repository
+	require.NoError(t, json.Unmarshal([]byte(stdout), &repositories))
+	assert.Len(t, repositories, 2)
+
+	_, _, err = runCommand(t, api, "list", "blamewarrior", "-o", "yaml")
+	assert.EqualError(t, err, "unknown output format yaml")
+}
+
+func TestTrackAndUntrack(t *testing.T) {
+	api := newFakeAPI(blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"})
+
+	// mutations are dry run by default
+	stdout, stderr, err := runCommand(t, api, "track", "blamewarrior/repos")
+	require.NoError(t, err)
+
+	assert.Contains(t, stdout, "blamewarrior/repos  track   planned")
+	assert.Contains(t, stderr, "pass --yes to apply")
+	assert.Empty(t, api.mutations())
+
+	stdout, _, err = runCommand(t, api, "track", "blamewarrior/repos", "--private", "--yes")
+	require.NoError(t, err)
+
+	assert.Contains(t, stdout, "blamewarrior/repos  track   done")
+	assert.Equal(t, []string{"POST /repositories"}, api.mutations())
+	assert.True(t, api.tracked["blamewarrior/repos"].Private)
+
+	stdout, _, err = runCommand(t, api, "track", "blamewarrior/hooks", "--yes")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "already tracked")
+
+	_, _, err = runCommand(t, api, "untrack", "blamewarrior/hooks", "--yes")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"POST /repositories", "DELETE /repositories/blamewarrior/hooks"}, api.mutations())
+
+	_, _, err = runCommand(t, api, "track", "blamewarrior")
+	assert.EqualError(t, err, "incorrect full name blamewarrior, expected OWNER/NAME")
+}
+
+func TestImportFromGithub(t *testing.T) {
+	api := newFakeAPI(blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"})
+	api.github = []blamewarrior.Repository{
+		{Owner: "blamewarrior", Name: "hooks"},
+		{Owner: "blamewarrior", Name: "repos"},
+		{Owner: "blamewarrior", Name: "tokens", Private: true},
+	}
+
+	_, _, err := runCommand(t, api, "import", "blamewarrior")
+	assert.Error(t, err)
+
+	stdout, _, err := runCommand(t, api, "import", "blamewarrior", "--from-github", "-o", "json", "--yes")
+	require.NoError(t, err)
+
+	var changes []change
+	require.NoError(t, json.Unmarshal([]byte(stdout), &changes))
+
+	assert.Equal(t, []change{
+		{Repository: "blamewarrior/repos", Action: "track", Result: "done"},
+		{Repository: "blamewarrior/tokens", Action: "track", Result: "done"},
+		{Repository: "blamewarrior/hooks", Action: "skip", Result: "already tracked"},
+	}, changes)
+
+	assert.Len(t, api.tracked, 3)
+	assert.True(t, api.tracked["blamewarrior/tokens"].Private)
+}
+
+func TestReconcile(t *testing.T) {
+	api := newFakeAPI(
+		blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"},
+		blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"},
+	)
+
+	stdout, _, err := runCommand(t, api, "reconcile", "blamewarrior", "--yes")
+	require.NoError(t, err)
+
+	assert.Contains(t, stdout, "blamewarrior/hooks  reconcile  done, active")
+	assert.Contains(t, stdout, "blamewarrior/repos  reconcile  done, active")
+
+	_, _, err = runCommand(t, api, "reconcile", "blamewarrior/missing", "--yes")
+	assert.EqualError(t, err, "1 of 1 change(s) failed")
+}
+
+func TestExportAndImportFile(t *testing.T) {
+	source := newFakeAPI(
+		blamewarrior.Repository{Owner: "blamewarrior", Name: "repos", Settings: json.RawMessage(`{"version":1}`)},
+		blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks", Private: true},
+	)
+
+	dir, err := ioutil.TempDir("", "reposctl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, format := range []string{"json", "csv"} {
+		stdout, _, err := runCommand(t, source, "export", "blamewarrior", "-o", format)
+		require.NoError(t, err)
+
+		ext := map[string]string{"json": ".jsonl", "csv": ".csv"}[format]
+		file := filepath.Join(dir, "repositories"+ext)
+		require.NoError(t, ioutil.WriteFile(file, []byte(stdout), 0644))
+
+		target := newFakeAPI()
+
+		stdout, stderr, err := runCommand(t, target, "import-file", file)
+		require.NoError(t, err, format)
+
+		assert.Contains(t, stdout, "blamewarrior/hooks  created", format)
+		assert.Equal(t, "dry run: 2 change(s) not applied, pass --yes to apply\n", stderr, format)
+		assert.Empty(t, target.tracked, format)
+
+		_, _, err = runCommand(t, target, "import-file", file, "--yes")
+		require.NoError(t, err, format)
+
+		require.Len(t, target.tracked, 2, format)
+		assert.True(t, target.tracked["blamewarrior/hooks"].Private, format)
+		assert.JSONEq(t, `{"version":1}`, string(target.tracked["blamewarrior/repos"].Settings), format)
+	}
+
+	file := filepath.Join(dir, "broken.csv")
+	require.NoError(t, ioutil.WriteFile(file, []byte("full_name\nblamewarrior\nblamewarrior/repos\n"), 0644))
+
+	stdout, _, err := runCommand(t, newFakeAPI(), "import-file", file, "--yes")
+	assert.EqualError(t, err, "1 of 2 line(s) failed")
+	assert.Contains(t, stdout, "incorrect full name for repository")
+}
@@ -0,0 +1,174 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Command reposctl is an admin tool for BlameWarrior repositories service. It talks to the
+// service API, and every mutation is a dry run unless --yes is given:
+//
+//	reposctl [flags] list OWNER
+//	reposctl [flags] track OWNER/NAME
+//	reposctl [flags] untrack OWNER/NAME
+//	reposctl [flags] import OWNER --from-github
+//	reposctl [flags] reconcile OWNER[/NAME]
+//	reposctl [flags] export [OWNER]
+//	reposctl [flags] import-file FILE
+//
+// Repositories hosted at providers other than GitHub are referred to as PROVIDER:OWNER/NAME.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/blamewarrior/repos/client"
+)
+
+type command struct {
+	Usage string
+	// Mutates is set for commands that change data and respect --yes
+	Mutates bool
+	// Flags registers command specific flags
+	Flags func(fs *flag.FlagSet, opts *options)
+	Run   func(ctx context.Context, ctl *reposctl, args []string) error
+}
+
+var commands = map[string]*command{
+	"list":        listCommand,
+	"track":       trackCommand,
+	"untrack":     untrackCommand,
+	"import":      importCommand,
+	"reconcile":   reconcileCommand,
+	"export":      exportCommand,
+	"import-file": importFileCommand,
+}
+
+// options are flags shared by all commands as well as command specific ones.
+type options struct {
+	URL    string
+	Actor  string
+	Output string
+	Yes    bool
+
+	Provider       string
+	IncludeDeleted bool
+	Language       string
+	State          string
+	Private        bool
+	FromGithub     bool
+	Format         string
+}
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "reposctl: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		usage(stderr)
+		return fmt.Errorf("missing command")
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage(stderr)
+		return fmt.Errorf("unknown command %s", args[0])
+	}
+
+	opts := &options{}
+
+	fs := flag.NewFlagSet("reposctl "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: reposctl %s\n\nFlags:\n", cmd.Usage)
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&opts.URL, "url", envOr("BW_REPOS_URL", "http://localhost:8080"), "repositories service base URL")
+	fs.StringVar(&opts.Actor, "actor", envOr("USER", ""), "name recorded in the audit log")
+	fs.StringVar(&opts.Output, "o", "table", "output format: table, json or csv")
+	if cmd.Mutates {
+		fs.BoolVar(&opts.Yes, "yes", false, "apply changes instead of printing them")
+	}
+	if cmd.Flags != nil {
+		cmd.Flags(fs, opts)
+	}
+
+	positional, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		return err
+	}
+
+	switch opts.Output {
+	case formatTable, formatJSON, formatCSV:
+	default:
+		return fmt.Errorf("unknown output format %s", opts.Output)
+	}
+
+	c := client.NewClient(opts.URL)
+	c.Actor = opts.Actor
+
+	ctl := &reposctl{
+		client: c,
+		opts:   opts,
+		out:    &output{w: stdout, format: opts.Output},
+		stderr: stderr,
+	}
+
+	return cmd.Run(ctx, ctl, positional)
+}
+
+// parseInterspersed parses flags that may appear anywhere among positional arguments and
+// returns the positional ones.
+func parseInterspersed(fs *flag.FlagSet, args []string) (positional []string, err error) {
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: reposctl COMMAND [flags] ARGS")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+
+	for _, name := range []string{"list", "track", "untrack", "import", "reconcile", "export", "import-file"} {
+		fmt.Fprintf(w, "  %s\n", commands[name].Usage)
+	}
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Mutations are printed but not applied unless --yes is given.")
+}
+
+func envOr(name, def string) string {
+	if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+		return v
+	}
+
+	return def
+}
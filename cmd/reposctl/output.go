@@ -0,0 +1,134 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+type output struct {
+	w      io.Writer
+	format string
+}
+
+// Print writes rows under given columns as an aligned table or CSV. In JSON format values are
+// encoded instead.
+func (out *output) Print(columns []string, rows [][]string, values interface{}) error {
+	switch out.format {
+	case formatJSON:
+		enc := json.NewEncoder(out.w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(values)
+	case formatCSV:
+		w := csv.NewWriter(out.w)
+		w.Write(columns)
+		w.WriteAll(rows)
+
+		return w.Error()
+	default:
+		w := tabwriter.NewWriter(out.w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, strings.ToUpper(strings.Join(columns, "\t")))
+
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+
+		return w.Flush()
+	}
+}
+
+// PrintRepositories writes repositories listing.
+func (out *output) PrintRepositories(repositories []blamewarrior.Repository) error {
+	columns := []string{"full_name", "private", "state", "language", "stars", "deleted_at"}
+
+	rows := make([][]string, len(repositories))
+	for i, repo := range repositories {
+		var language, stars string
+		if repo.Metadata != nil {
+			language, stars = repo.Metadata.Language, strconv.Itoa(repo.Metadata.Stars)
+		}
+
+		rows[i] = []string{repo.FullName(), strconv.FormatBool(repo.Private), repo.State, language, stars, formatTime(repo.DeletedAt)}
+	}
+
+	if repositories == nil {
+		repositories = []blamewarrior.Repository{}
+	}
+
+	return out.Print(columns, rows, repositories)
+}
+
+// change is a mutation planned or applied by a command.
+type change struct {
+	Repository string `json:"repository"`
+	Action     string `json:"action"`
+	// Result is "planned" in dry run mode, "done" or error description otherwise
+	Result string `json:"result"`
+}
+
+const (
+	resultPlanned = "planned"
+	resultDone    = "done"
+)
+
+// PrintChanges writes planned or applied changes.
+func (out *output) PrintChanges(changes []change) error {
+	rows := make([][]string, len(changes))
+	for i, c := range changes {
+		rows[i] = []string{c.Repository, c.Action, c.Result}
+	}
+
+	if changes == nil {
+		changes = []change{}
+	}
+
+	return out.Print([]string{"repository", "action", "result"}, rows, changes)
+}
+
+// PrintImportReport writes results of repositories file import.
+func (out *output) PrintImportReport(report *blamewarrior.ImportReport) error {
+	rows := make([][]string, len(report.Results))
+	for i, result := range report.Results {
+		rows[i] = []string{strconv.Itoa(result.Line), result.FullName, result.Result, result.Error}
+	}
+
+	return out.Print([]string{"line", "full_name", "result", "error"}, rows, report)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,99 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// defaultCacheControl makes clients revalidate repositories before reusing them, which is cheap
+// since conditional requests are supported. Shared caches must not store private repositories.
+const defaultCacheControl = "private, no-cache"
+
+// setValidators sets headers clients use to make conditional requests along with Cache-Control
+// configured for handlers. Last-Modified is omitted if lastModified is zero.
+func (h *Handlers) setValidators(w http.ResponseWriter, etag string, lastModified time.Time) {
+	w.Header().Set("ETag", etag)
+
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if h.cacheControl != "" {
+		w.Header().Set("Cache-Control", h.cacheControl)
+	}
+}
+
+// notModified reports whether representation identified by etag and lastModified is the one
+// client already has according to request preconditions (RFC 7232). If-Modified-Since is
+// ignored when If-None-Match is present.
+func notModified(req *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etagMatches(ifNoneMatch, etag)
+	}
+
+	ifModifiedSince := req.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+
+	// HTTP dates have a precision of one second
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagMatches reports whether etag is listed in If-None-Match header value using weak comparison.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// respondNotModified tells client to reuse the representation it has. Validators need to be set
+// beforehand.
+func respondNotModified(w http.ResponseWriter) {
+	w.Header().Del("Content-Type")
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// repositoriesETag returns entity tag of repositories listing. It is derived from entity tags
+// of listed repositories, so it changes once any of them changes, is added, removed or reordered.
+func repositoriesETag(repositories []blamewarrior.Repository) string {
+	hash := sha1.New()
+	for i := range repositories {
+		hash.Write([]byte(repositories[i].ETag()))
+	}
+
+	return fmt.Sprintf(`"%x"`, hash.Sum(nil))
+}
@@ -0,0 +1,249 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestNotModified(t *testing.T) {
+	etag := `"1.2.3"`
+	lastModified := time.Date(2017, 5, 1, 12, 0, 0, 500000000, time.UTC)
+
+	httpDate := func(t time.Time) string {
+		return t.Format(http.TimeFormat)
+	}
+
+	results := []struct {
+		Name            string
+		IfNoneMatch     string
+		IfModifiedSince string
+		LastModified    time.Time
+		NotModified     bool
+	}{
+		{Name: "unconditional", LastModified: lastModified, NotModified: false},
+		{Name: "matching etag", IfNoneMatch: etag, LastModified: lastModified, NotModified: true},
+		{Name: "weak etag", IfNoneMatch: "W/" + etag, LastModified: lastModified, NotModified: true},
+		{Name: "etag in list", IfNoneMatch: `"0.1.2", ` + etag, LastModified: lastModified, NotModified: true},
+		{Name: "any etag", IfNoneMatch: "*", LastModified: lastModified, NotModified: true},
+		{Name: "other etag", IfNoneMatch: `"1.2.4"`, LastModified: lastModified, NotModified: false},
+		{Name: "unquoted etag", IfNoneMatch: "1.2.3", LastModified: lastModified, NotModified: false},
+		{Name: "modified at the same second", IfModifiedSince: httpDate(lastModified), LastModified: lastModified, NotModified: true},
+		{Name: "modified before", IfModifiedSince: httpDate(lastModified.Add(time.Hour)), LastModified: lastModified, NotModified: true},
+		{Name: "modified after", IfModifiedSince: httpDate(lastModified.Add(-time.Second)), LastModified: lastModified, NotModified: false},
+		{Name: "malformed date", IfModifiedSince: "yesterday", LastModified: lastModified, NotModified: false},
+		{Name: "unknown modification time", IfModifiedSince: httpDate(lastModified), NotModified: false},
+		{Name: "other etag takes precedence", IfNoneMatch: `"1.2.4"`, IfModifiedSince: httpDate(lastModified.Add(time.Hour)), LastModified: lastModified, NotModified: false},
+		{Name: "matching etag takes precedence", IfNoneMatch: etag, IfModifiedSince: httpDate(lastModified.Add(-time.Hour)), LastModified: lastModified, NotModified: true},
+	}
+
+	for _, result := range results {
+		req, err := http.NewRequest("GET", "/repositories/blamewarrior/repos", nil)
+		require.NoError(t, err)
+
+		if result.IfNoneMatch != "" {
+			req.Header.Set("If-None-Match", result.IfNoneMatch)
+		}
+
+		if result.IfModifiedSince != "" {
+			req.Header.Set("If-Modified-Since", result.IfModifiedSince)
+		}
+
+		assert.Equal(t, result.NotModified, notModified(req, etag, result.LastModified), result.Name)
+	}
+}
+
+func TestRepositoriesETag(t *testing.T) {
+	repos := blamewarrior.Repository{ID: 1, Version: 1}
+	hooks := blamewarrior.Repository{ID: 2, Version: 1}
+
+	etag := repositoriesETag([]blamewarrior.Repository{repos, hooks})
+	assert.Equal(t, etag, repositoriesETag([]blamewarrior.Repository{repos, hooks}))
+
+	assert.NotEqual(t, etag, repositoriesETag([]blamewarrior.Repository{hooks, repos}))
+	assert.NotEqual(t, etag, repositoriesETag([]blamewarrior.Repository{repos}))
+	assert.NotEqual(t, repositoriesETag(nil), repositoriesETag([]blamewarrior.Repository{repos}))
+
+	hooks.Version++
+	assert.NotEqual(t, etag, repositoriesETag([]blamewarrior.Repository{repos, hooks}))
+}
+
+func TestConditionalGet(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+
+	handlers := &Handlers{db: db, cacheControl: defaultCacheControl}
+
+	endpoints := []struct {
+		Name         string
+		Handler      http.HandlerFunc
+		Query        url.Values
+		LastModified bool
+	}{
+		{"repository", handlers.GetRepositoryByFullName, url.Values{":owner": {"blamewarrior"}, ":name": {"repos"}}, true},
+		{"owner repositories", handlers.GetListRepositoryByOwner, url.Values{":owner": {"blamewarrior"}}, false},
+	}
+
+	get := func(handler http.HandlerFunc, query url.Values, header http.Header) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", "/repositories?"+query.Encode(), nil)
+		require.NoError(t, err)
+
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		return w
+	}
+
+	for _, endpoint := range endpoints {
+		w := get(endpoint.Handler, endpoint.Query, nil)
+		require.Equal(t, http.StatusOK, w.Code, endpoint.Name)
+
+		etag, lastModified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+		require.NotEmpty(t, etag, endpoint.Name)
+		assert.Equal(t, defaultCacheControl, w.Header().Get("Cache-Control"), endpoint.Name)
+
+		// owner listing is validated with ETag only, so that If-Modified-Since never matches it
+		notModifiedSince, modifiedAt := http.StatusOK, time.Now().Add(time.Hour)
+		if endpoint.LastModified {
+			require.NotEmpty(t, lastModified, endpoint.Name)
+
+			modifiedAt, err = http.ParseTime(lastModified)
+			require.NoError(t, err)
+
+			notModifiedSince = http.StatusNotModified
+		} else {
+			assert.Empty(t, lastModified, endpoint.Name)
+		}
+
+		since := modifiedAt.Format(http.TimeFormat)
+
+		results := []struct {
+			Name         string
+			Header       http.Header
+			ResponseCode int
+		}{
+			{"matching etag", http.Header{"If-None-Match": {etag}}, http.StatusNotModified},
+			{"weak etag", http.Header{"If-None-Match": {"W/" + etag}}, http.StatusNotModified},
+			{"any etag", http.Header{"If-None-Match": {"*"}}, http.StatusNotModified},
+			{"other etag", http.Header{"If-None-Match": {`"0"`}}, http.StatusOK},
+			{"not modified since", http.Header{"If-Modified-Since": {since}}, notModifiedSince},
+			{"modified since", http.Header{"If-Modified-Since": {modifiedAt.Add(-time.Second).Format(http.TimeFormat)}}, http.StatusOK},
+			{"malformed date", http.Header{"If-Modified-Since": {"yesterday"}}, http.StatusOK},
+			{"other etag, not modified since", http.Header{"If-None-Match": {`"0"`}, "If-Modified-Since": {since}}, http.StatusOK},
+			{"matching etag, modified since", http.Header{"If-None-Match": {etag}, "If-Modified-Since": {modifiedAt.Add(-time.Second).Format(http.TimeFormat)}}, http.StatusNotModified},
+		}
+
+		for _, result := range results {
+			name := fmt.Sprintf("%s: %s", endpoint.Name, result.Name)
+
+			w := get(endpoint.Handler, endpoint.Query, result.Header)
+			require.Equal(t, result.ResponseCode, w.Code, name)
+
+			assert.Equal(t, etag, w.Header().Get("ETag"), name)
+			assert.Equal(t, lastModified, w.Header().Get("Last-Modified"), name)
+			assert.Equal(t, defaultCacheControl, w.Header().Get("Cache-Control"), name)
+
+			if w.Code == http.StatusNotModified {
+				assert.Empty(t, w.Body.String(), name)
+				assert.Empty(t, w.Header().Get("Content-Type"), name)
+			}
+		}
+	}
+
+	// any change of repository, including refresh of its metadata, is a modification
+	repo, err := blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+
+	etags := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		etags[i] = get(endpoint.Handler, endpoint.Query, nil).Header().Get("ETag")
+	}
+
+	require.NoError(t, blamewarrior.UpdateRepositoryMetadata(db, repo, &blamewarrior.RepositoryMetadata{Stars: 1, RefreshedAt: time.Now()}))
+
+	for i, endpoint := range endpoints {
+		w := get(endpoint.Handler, endpoint.Query, http.Header{"If-None-Match": {etags[i]}})
+		assert.Equal(t, http.StatusOK, w.Code, endpoint.Name)
+		assert.NotEqual(t, etags[i], w.Header().Get("ETag"), endpoint.Name)
+	}
+
+	// deleted repositories are removed from owner listing
+	w := get(handlers.GetListRepositoryByOwner, endpoints[1].Query, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, blamewarrior.DeleteRepository(db, "blamewarrior/repos"))
+
+	w = get(handlers.GetListRepositoryByOwner, endpoints[1].Query, http.Header{"If-None-Match": {w.Header().Get("ETag")}})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var repositories []blamewarrior.Repository
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &repositories))
+	assert.Empty(t, repositories)
+}
+
+func TestConditionalGet_Cached(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+
+	handlers := &Handlers{db: db, cache: newRepositoryCache(db, 10, time.Minute)}
+
+	query := url.Values{":owner": {"blamewarrior"}}
+
+	req, err := http.NewRequest("GET", "/repositories?"+query.Encode(), nil)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handlers.GetListRepositoryByOwner(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.Empty(t, w.Header().Get("Last-Modified"), "owner listing is validated with ETag only")
+
+	req, err = http.NewRequest("GET", "/repositories?"+query.Encode(), nil)
+	require.NoError(t, err)
+
+	req.Header.Set("If-Modified-Since", time.Now().Add(time.Hour).Format(http.TimeFormat))
+
+	w = httptest.NewRecorder()
+	handlers.GetListRepositoryByOwner(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "owner listing should not be validated by modification time")
+}
@@ -0,0 +1,3 @@
+ALTER TABLE repositories ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE UNIQUE INDEX repositories_owner_name ON repositories (owner, name) WHERE deleted_at IS NULL;
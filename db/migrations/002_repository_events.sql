@@ -0,0 +1,19 @@
+CREATE TABLE repository_events (
+  id BIGSERIAL primary key,
+  repository_id INTEGER NOT NULL,
+  owner VARCHAR NOT NULL,
+  name VARCHAR NOT NULL,
+  action VARCHAR NOT NULL,
+  actor VARCHAR NOT NULL DEFAULT '',
+  request_id VARCHAR NOT NULL DEFAULT '',
+  source VARCHAR NOT NULL DEFAULT '',
+  before JSONB,
+  after JSONB,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX repository_events_owner_name ON repository_events (owner, name);
+CREATE INDEX repository_events_created_at ON repository_events (created_at);
+
+CREATE RULE repository_events_no_update AS ON UPDATE TO repository_events DO INSTEAD NOTHING;
+CREATE RULE repository_events_no_delete AS ON DELETE TO repository_events DO INSTEAD NOTHING;
@@ -0,0 +1 @@
+ALTER TABLE repositories ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,6 @@
+ALTER TABLE repositories ADD COLUMN settings JSONB;
+
+CREATE TABLE owner_settings (
+  owner VARCHAR primary key,
+  settings JSONB NOT NULL
+);
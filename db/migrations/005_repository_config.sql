@@ -0,0 +1,5 @@
+ALTER TABLE repositories
+  ADD COLUMN config JSONB,
+  ADD COLUMN config_sha VARCHAR,
+  ADD COLUMN config_error VARCHAR,
+  ADD COLUMN config_refreshed_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,8 @@
+CREATE TABLE code_owners (
+  repository_id INTEGER primary key REFERENCES repositories (id) ON DELETE CASCADE,
+  path VARCHAR NOT NULL,
+  commit_sha VARCHAR NOT NULL,
+  rules JSONB NOT NULL,
+  errors JSONB,
+  refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
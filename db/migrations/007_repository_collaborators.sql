@@ -0,0 +1,8 @@
+CREATE TABLE repository_collaborators (
+  repository_id INTEGER NOT NULL REFERENCES repositories (id) ON DELETE CASCADE,
+  login VARCHAR NOT NULL,
+  type VARCHAR NOT NULL,
+  permission VARCHAR NOT NULL,
+  synced_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  PRIMARY KEY (repository_id, type, login)
+);
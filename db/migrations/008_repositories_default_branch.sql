@@ -0,0 +1 @@
+ALTER TABLE repositories ADD COLUMN default_branch JSONB;
@@ -0,0 +1,11 @@
+ALTER TABLE repositories
+  ADD COLUMN description VARCHAR,
+  ADD COLUMN language VARCHAR,
+  ADD COLUMN topics TEXT[],
+  ADD COLUMN size INTEGER,
+  ADD COLUMN stars INTEGER,
+  ADD COLUMN pushed_at TIMESTAMP WITH TIME ZONE,
+  ADD COLUMN metadata_refreshed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX repositories_language ON repositories (lower(language));
+CREATE INDEX repositories_topics ON repositories USING GIN (topics);
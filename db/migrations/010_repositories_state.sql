@@ -0,0 +1,4 @@
+ALTER TABLE repositories
+  ADD COLUMN archived BOOLEAN,
+  ADD COLUMN state VARCHAR NOT NULL DEFAULT 'active',
+  ADD COLUMN inactive_since TIMESTAMP WITH TIME ZONE;
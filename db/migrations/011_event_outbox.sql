@@ -0,0 +1,4 @@
+CREATE TABLE event_outbox (
+  event_id BIGINT primary key,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
@@ -0,0 +1,33 @@
+CREATE TABLE subscriptions (
+  id SERIAL primary key,
+  url VARCHAR NOT NULL,
+  events VARCHAR[] NOT NULL DEFAULT '{}',
+  secret VARCHAR NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT true,
+  failures INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  disabled_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE subscription_queue (
+  subscription_id INTEGER NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+  event_id BIGINT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  PRIMARY KEY (subscription_id, event_id)
+);
+
+CREATE INDEX subscription_queue_next_attempt_at ON subscription_queue (next_attempt_at);
+
+CREATE TABLE subscription_deliveries (
+  id BIGSERIAL primary key,
+  subscription_id INTEGER NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+  event_id BIGINT NOT NULL,
+  attempt INTEGER NOT NULL,
+  status_code INTEGER,
+  error VARCHAR NOT NULL DEFAULT '',
+  latency_ms INTEGER NOT NULL,
+  delivered_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX subscription_deliveries_subscription_id ON subscription_deliveries (subscription_id, id);
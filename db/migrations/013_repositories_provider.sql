@@ -0,0 +1,11 @@
+ALTER TABLE repositories
+  ADD COLUMN provider VARCHAR NOT NULL DEFAULT 'github';
+
+DROP INDEX repositories_owner_name;
+CREATE UNIQUE INDEX repositories_provider_owner_name ON repositories (provider, owner, name) WHERE deleted_at IS NULL;
+
+ALTER TABLE repository_events
+  ADD COLUMN provider VARCHAR NOT NULL DEFAULT 'github';
+
+DROP INDEX repository_events_owner_name;
+CREATE INDEX repository_events_provider_owner_name ON repository_events (provider, owner, name);
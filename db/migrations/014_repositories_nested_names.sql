@@ -0,0 +1,11 @@
+ALTER TABLE repositories
+  DROP CONSTRAINT proper_owner,
+  DROP CONSTRAINT proper_name,
+  ADD CONSTRAINT proper_owner CHECK (owner ~ '^[A-Za-z0-9_.\-]+(/[A-Za-z0-9_.\-]+)*$'),
+  ADD CONSTRAINT proper_name CHECK (name ~ '^[A-Za-z0-9_.\-]+$');
+
+DROP INDEX repositories_provider_owner_name;
+CREATE UNIQUE INDEX repositories_provider_owner_name ON repositories (provider, lower(owner), lower(name)) WHERE deleted_at IS NULL;
+
+DROP INDEX repository_events_provider_owner_name;
+CREATE INDEX repository_events_provider_owner_name ON repository_events (provider, lower(owner), lower(name));
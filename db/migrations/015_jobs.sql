@@ -0,0 +1,17 @@
+CREATE TABLE jobs (
+  id BIGSERIAL primary key,
+  type VARCHAR NOT NULL,
+  payload JSONB NOT NULL DEFAULT '{}',
+  state VARCHAR NOT NULL DEFAULT 'pending',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  max_attempts INTEGER NOT NULL DEFAULT 5,
+  last_error VARCHAR NOT NULL DEFAULT '',
+  run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  locked_until TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  finished_at TIMESTAMP WITH TIME ZONE,
+  CONSTRAINT proper_state CHECK (state IN ('pending', 'running', 'succeeded', 'dead', 'cancelled'))
+);
+
+CREATE INDEX jobs_due ON jobs (run_at, id) WHERE state IN ('pending', 'running');
+CREATE INDEX jobs_state ON jobs (state, id);
@@ -0,0 +1,4 @@
+ALTER TABLE repositories
+  ADD COLUMN provider_id BIGINT;
+
+CREATE INDEX repositories_provider_id ON repositories (provider, provider_id) WHERE deleted_at IS NULL;
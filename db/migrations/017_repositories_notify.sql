@@ -0,0 +1,16 @@
+CREATE FUNCTION notify_repository_change() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP IN ('UPDATE', 'DELETE') THEN
+    PERFORM pg_notify('repository_changes', json_build_object('provider', OLD.provider, 'owner', OLD.owner, 'name', OLD.name)::text);
+  END IF;
+
+  IF TG_OP IN ('INSERT', 'UPDATE') THEN
+    PERFORM pg_notify('repository_changes', json_build_object('provider', NEW.provider, 'owner', NEW.owner, 'name', NEW.name)::text);
+  END IF;
+
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER repositories_notify_change AFTER INSERT OR UPDATE OR DELETE ON repositories
+  FOR EACH ROW EXECUTE PROCEDURE notify_repository_change();
@@ -0,0 +1,15 @@
+ALTER TABLE repositories
+  ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now();
+
+CREATE FUNCTION touch_repository() RETURNS trigger AS $$
+BEGIN
+  IF NEW IS DISTINCT FROM OLD THEN
+    NEW.updated_at := clock_timestamp();
+  END IF;
+
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER repositories_touch BEFORE UPDATE ON repositories
+  FOR EACH ROW EXECUTE PROCEDURE touch_repository();
@@ -0,0 +1,5 @@
+ALTER TABLE owner_settings
+  ADD COLUMN provider VARCHAR NOT NULL DEFAULT 'github';
+
+ALTER TABLE owner_settings DROP CONSTRAINT owner_settings_pkey;
+ALTER TABLE owner_settings ADD PRIMARY KEY (provider, owner);
@@ -0,0 +1,4 @@
+ALTER TABLE jobs
+  ADD COLUMN key VARCHAR;
+
+CREATE UNIQUE INDEX jobs_type_key ON jobs (type, key) WHERE state IN ('pending', 'running');
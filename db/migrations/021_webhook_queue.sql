@@ -0,0 +1,9 @@
+CREATE TABLE webhook_queue (
+  url VARCHAR NOT NULL,
+  event_id BIGINT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  PRIMARY KEY (url, event_id)
+);
+
+CREATE INDEX webhook_queue_next_attempt_at ON webhook_queue (next_attempt_at);
@@ -1,12 +1,183 @@
 CREATE TABLE repositories (
   id SERIAL primary key,
+  provider VARCHAR NOT NULL DEFAULT 'github',
   owner VARCHAR
   CONSTRAINT proper_owner
-            CHECK (owner ~* '^([a-z0-9\-_]+)$')
+            CHECK (owner ~ '^[A-Za-z0-9_.\-]+(/[A-Za-z0-9_.\-]+)*$')
   NOT NULL,
   name VARCHAR
   CONSTRAINT proper_name
-            CHECK (name ~* '^([a-z0-9\-_]+)$')
+            CHECK (name ~ '^[A-Za-z0-9_.\-]+$')
   NOT NULL,
-  private BOOLEAN NOT NULL DEFAULT FALSE
-)
+  private BOOLEAN NOT NULL DEFAULT FALSE,
+  deleted_at TIMESTAMP WITH TIME ZONE,
+  version INTEGER NOT NULL DEFAULT 1,
+  settings JSONB,
+  config JSONB,
+  config_sha VARCHAR,
+  config_error VARCHAR,
+  config_refreshed_at TIMESTAMP WITH TIME ZONE,
+  default_branch JSONB,
+  description VARCHAR,
+  language VARCHAR,
+  topics TEXT[],
+  size INTEGER,
+  stars INTEGER,
+  pushed_at TIMESTAMP WITH TIME ZONE,
+  archived BOOLEAN,
+  metadata_refreshed_at TIMESTAMP WITH TIME ZONE,
+  state VARCHAR NOT NULL DEFAULT 'active',
+  inactive_since TIMESTAMP WITH TIME ZONE,
+  provider_id BIGINT,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX repositories_provider_owner_name ON repositories (provider, lower(owner), lower(name)) WHERE deleted_at IS NULL;
+CREATE INDEX repositories_language ON repositories (lower(language));
+CREATE INDEX repositories_topics ON repositories USING GIN (topics);
+CREATE INDEX repositories_provider_id ON repositories (provider, provider_id) WHERE deleted_at IS NULL;
+
+CREATE TABLE repository_events (
+  id BIGSERIAL primary key,
+  repository_id INTEGER NOT NULL,
+  provider VARCHAR NOT NULL DEFAULT 'github',
+  owner VARCHAR NOT NULL,
+  name VARCHAR NOT NULL,
+  action VARCHAR NOT NULL,
+  actor VARCHAR NOT NULL DEFAULT '',
+  request_id VARCHAR NOT NULL DEFAULT '',
+  source VARCHAR NOT NULL DEFAULT '',
+  before JSONB,
+  after JSONB,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX repository_events_provider_owner_name ON repository_events (provider, lower(owner), lower(name));
+CREATE INDEX repository_events_created_at ON repository_events (created_at);
+
+CREATE RULE repository_events_no_update AS ON UPDATE TO repository_events DO INSTEAD NOTHING;
+CREATE RULE repository_events_no_delete AS ON DELETE TO repository_events DO INSTEAD NOTHING;
+
+CREATE TABLE owner_settings (
+  provider VARCHAR NOT NULL DEFAULT 'github',
+  owner VARCHAR NOT NULL,
+  settings JSONB NOT NULL,
+  PRIMARY KEY (provider, owner)
+);
+
+CREATE TABLE code_owners (
+  repository_id INTEGER primary key REFERENCES repositories (id) ON DELETE CASCADE,
+  path VARCHAR NOT NULL,
+  commit_sha VARCHAR NOT NULL,
+  rules JSONB NOT NULL,
+  errors JSONB,
+  refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE repository_collaborators (
+  repository_id INTEGER NOT NULL REFERENCES repositories (id) ON DELETE CASCADE,
+  login VARCHAR NOT NULL,
+  type VARCHAR NOT NULL,
+  permission VARCHAR NOT NULL,
+  synced_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  PRIMARY KEY (repository_id, type, login)
+);
+
+CREATE TABLE event_outbox (
+  event_id BIGINT primary key,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE TABLE subscriptions (
+  id SERIAL primary key,
+  url VARCHAR NOT NULL,
+  events VARCHAR[] NOT NULL DEFAULT '{}',
+  secret VARCHAR NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT true,
+  failures INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  disabled_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE subscription_queue (
+  subscription_id INTEGER NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+  event_id BIGINT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  PRIMARY KEY (subscription_id, event_id)
+);
+
+CREATE INDEX subscription_queue_next_attempt_at ON subscription_queue (next_attempt_at);
+
+CREATE TABLE subscription_deliveries (
+  id BIGSERIAL primary key,
+  subscription_id INTEGER NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+  event_id BIGINT NOT NULL,
+  attempt INTEGER NOT NULL,
+  status_code INTEGER,
+  error VARCHAR NOT NULL DEFAULT '',
+  latency_ms INTEGER NOT NULL,
+  delivered_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX subscription_deliveries_subscription_id ON subscription_deliveries (subscription_id, id);
+
+CREATE TABLE webhook_queue (
+  url VARCHAR NOT NULL,
+  event_id BIGINT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  PRIMARY KEY (url, event_id)
+);
+
+CREATE INDEX webhook_queue_next_attempt_at ON webhook_queue (next_attempt_at);
+
+CREATE TABLE jobs (
+  id BIGSERIAL primary key,
+  type VARCHAR NOT NULL,
+  key VARCHAR,
+  payload JSONB NOT NULL DEFAULT '{}',
+  state VARCHAR NOT NULL DEFAULT 'pending',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  max_attempts INTEGER NOT NULL DEFAULT 5,
+  last_error VARCHAR NOT NULL DEFAULT '',
+  run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  locked_until TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  finished_at TIMESTAMP WITH TIME ZONE,
+  CONSTRAINT proper_state CHECK (state IN ('pending', 'running', 'succeeded', 'dead', 'cancelled'))
+);
+
+CREATE INDEX jobs_due ON jobs (run_at, id) WHERE state IN ('pending', 'running');
+CREATE INDEX jobs_state ON jobs (state, id);
+CREATE UNIQUE INDEX jobs_type_key ON jobs (type, key) WHERE state IN ('pending', 'running');
+
+CREATE FUNCTION notify_repository_change() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP IN ('UPDATE', 'DELETE') THEN
+    PERFORM pg_notify('repository_changes', json_build_object('provider', OLD.provider, 'owner', OLD.owner, 'name', OLD.name)::text);
+  END IF;
+
+  IF TG_OP IN ('INSERT', 'UPDATE') THEN
+    PERFORM pg_notify('repository_changes', json_build_object('provider', NEW.provider, 'owner', NEW.owner, 'name', NEW.name)::text);
+  END IF;
+
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER repositories_notify_change AFTER INSERT OR UPDATE OR DELETE ON repositories
+  FOR EACH ROW EXECUTE PROCEDURE notify_repository_change();
+
+CREATE FUNCTION touch_repository() RETURNS trigger AS $$
+BEGIN
+  IF NEW IS DISTINCT FROM OLD THEN
+    NEW.updated_at := clock_timestamp();
+  END IF;
+
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER repositories_touch BEFORE UPDATE ON repositories
+  FOR EACH ROW EXECUTE PROCEDURE touch_repository();
@@ -0,0 +1,135 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/stream"
+)
+
+// outboxBatchSize is the maximum number of events published at once
+const outboxBatchSize = 100
+
+// dispatchEvents periodically moves repository events from the outbox to delivery queues.
+func dispatchEvents(db *blamewarrior.DB, webhookURLs []string, interval time.Duration) {
+	for range time.Tick(interval) {
+		for {
+			n, err := publishOutboxEvents(db, webhookURLs, outboxBatchSize)
+			if err != nil {
+				log.Printf("failed to publish repository events: %s", err)
+				break
+			}
+
+			if n < outboxBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// publishOutboxEvents queues a batch of events from the outbox for delivery to subscriptions
+// and webhook URLs and removes them from the outbox. No network requests are made while the
+// batch is claimed: every subscription and webhook URL is delivered to from its own queue later
+// on, so that a failing one neither holds the outbox nor delays the others.
+func publishOutboxEvents(db *blamewarrior.DB, webhookURLs []string, limit int) (int, error) {
+	tx, err := db.Begin()
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	events, err := blamewarrior.ClaimOutboxEvents(tx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	if err = blamewarrior.EnqueueDeliveries(tx, events); err != nil {
+		return 0, err
+	}
+
+	if err = blamewarrior.EnqueueWebhookEvents(tx, webhookURLs, events); err != nil {
+		return 0, err
+	}
+
+	if err = blamewarrior.DeleteOutboxEvents(tx, events); err != nil {
+		return 0, err
+	}
+
+	return len(events), tx.Commit()
+}
+
+// streamEvents periodically passes events recorded since the previous poll to in-process
+// broker. Each instance polls the audit log on its own, so that its stream consumers receive
+// all events regardless of which instance has recorded or dispatched them. Only events recorded
+// after the instance has started are streamed.
+func streamEvents(db *blamewarrior.DB, broker stream.Publisher, interval time.Duration) {
+	var cursor *streamCursor
+
+	for range time.Tick(interval) {
+		if cursor == nil {
+			lastID, err := blamewarrior.GetLastEventID(db)
+			if err != nil {
+				log.Printf("failed to stream repository events: %s", err)
+				continue
+			}
+
+			cursor = newStreamCursor()
+			cursor.Reset(lastID)
+		}
+
+		if err := pollEvents(db, broker, cursor, time.Now()); err != nil {
+			log.Printf("failed to stream repository events: %s", err)
+		}
+	}
+}
+
+// pollEvents passes events recorded after cursor position to broker. Events with lower IDs may
+// still be committed later, so the cursor stays behind them until they arrive or time out, and
+// events that have already been passed are skipped when they are read again.
+func pollEvents(runner blamewarrior.SQLRunner, broker stream.Publisher, cursor *streamCursor, now time.Time) error {
+	for after := cursor.Position(); ; {
+		events, err := blamewarrior.GetEvents(runner, time.Time{}, after, maxAuditPageSize)
+		if err != nil {
+			return err
+		}
+
+		var recent []blamewarrior.RepositoryEvent
+		for _, event := range events {
+			if !cursor.Sent(event.ID) {
+				cursor.Add(event, now)
+				recent = append(recent, event)
+			}
+			after = event.ID
+		}
+
+		if err = broker.Publish(recent); err != nil {
+			return err
+		}
+
+		if len(events) < maxAuditPageSize {
+			return nil
+		}
+	}
+}
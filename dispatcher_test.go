@@ -0,0 +1,125 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+type publisherMock struct {
+	events []blamewarrior.RepositoryEvent
+	err    error
+}
+
+func (p *publisherMock) Publish(events []blamewarrior.RepositoryEvent) error {
+	if p.err != nil {
+		return p.err
+	}
+
+	p.events = append(p.events, events...)
+
+	return nil
+}
+
+func TestPublishOutboxEvents(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events, event_outbox CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "tokens"}))
+
+	urls := []string{"http://hooks.example.com", "http://reviewers.example.com"}
+
+	n, err := publishOutboxEvents(db, urls, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = publishOutboxEvents(db, urls, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = publishOutboxEvents(db, urls, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// events are queued for each webhook URL
+	pending, err := blamewarrior.ClaimWebhookEvents(db, time.Now(), deliveryLease, webhookBatchSize)
+	require.NoError(t, err)
+	require.Len(t, pending, 6)
+
+	for i, p := range pending {
+		assert.Equal(t, urls[i/3], p.URL)
+		assert.Equal(t, []string{"repos", "hooks", "tokens"}[i%3], p.Event.Name)
+		assert.Equal(t, blamewarrior.EventCreated, p.Event.Action)
+	}
+}
+
+func TestPollEvents(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events, event_outbox CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+
+	lastID, err := blamewarrior.GetLastEventID(db)
+	require.NoError(t, err)
+
+	cursor := newStreamCursor()
+	cursor.Reset(lastID)
+
+	broker := &publisherMock{}
+
+	require.NoError(t, pollEvents(db, broker, cursor, time.Now()))
+	assert.Empty(t, broker.events)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "tokens"}))
+
+	// events are streamed regardless of whether they have been dispatched from the outbox
+	require.NoError(t, pollEvents(db, broker, cursor, time.Now()))
+	require.Len(t, broker.events, 2)
+	assert.Equal(t, "hooks", broker.events[0].Name)
+	assert.Equal(t, "tokens", broker.events[1].Name)
+
+	// events are passed once
+	require.NoError(t, pollEvents(db, broker, cursor, time.Now()))
+	assert.Len(t, broker.events, 2)
+
+	// the cursor stays behind a missing ID as the event may still be committed
+	_, err = db.Exec("DELETE FROM repository_events WHERE id = $1", broker.events[0].ID)
+	require.NoError(t, err)
+
+	cursor = newStreamCursor()
+	cursor.Reset(lastID)
+	broker.events = nil
+
+	require.NoError(t, pollEvents(db, broker, cursor, time.Now()))
+	require.Len(t, broker.events, 1)
+	assert.Equal(t, "tokens", broker.events[0].Name)
+	assert.Equal(t, lastID, cursor.Position())
+}
@@ -0,0 +1,70 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package github
+
+import (
+	"time"
+
+	bw "github.com/blamewarrior/repos/blamewarrior"
+)
+
+// DefaultBranch returns the default branch of repository along with its protection settings.
+// Protection of a branch can only be read by repository admins, so the branch is reported as
+// unprotected if the token owner lacks access to them.
+func (c *GithubClient) DefaultBranch(ctx Context, owner, name string) (*bw.DefaultBranch, error) {
+	api, err := initAPIClient(ctx, c.tokenClient, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, _, err := api.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		return nil, apiError(err)
+	}
+
+	branch, _, err := api.Repositories.GetBranch(ctx, owner, name, repo.GetDefaultBranch())
+	if err != nil {
+		return nil, apiError(err)
+	}
+
+	snapshot := &bw.DefaultBranch{
+		Name:        branch.GetName(),
+		RefreshedAt: time.Now(),
+	}
+
+	if !branch.GetProtected() {
+		return snapshot, nil
+	}
+
+	protection, _, err := api.Repositories.GetBranchProtection(ctx, owner, name, snapshot.Name)
+	if err != nil {
+		if isNotFound(err) {
+			return snapshot, nil
+		}
+
+		return nil, apiError(err)
+	}
+
+	snapshot.Protected = true
+
+	if reviews := protection.RequiredPullRequestReviews; reviews != nil {
+		snapshot.RequiredReviews = true
+		snapshot.RequireCodeOwnerReviews = reviews.RequireCodeOwnerReviews
+		snapshot.DismissStaleReviews = reviews.DismissStaleReviews
+	}
+
+	return snapshot, nil
+}
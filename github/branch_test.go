@@ -0,0 +1,105 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package github_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	bw "github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/github"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGithubService_DefaultBranch(t *testing.T) {
+	examples := map[string]struct {
+		Protected        bool
+		ProtectionStatus int
+		Protection       string
+		Expected         bw.DefaultBranch
+	}{
+		"unprotected branch": {
+			Expected: bw.DefaultBranch{Name: "develop"},
+		},
+		"protected branch with required reviews": {
+			Protected:        true,
+			ProtectionStatus: http.StatusOK,
+			Protection:       `{"required_pull_request_reviews":{"dismiss_stale_reviews":true,"require_code_owner_reviews":true}}`,
+			Expected: bw.DefaultBranch{
+				Name:                    "develop",
+				Protected:               true,
+				RequiredReviews:         true,
+				RequireCodeOwnerReviews: true,
+				DismissStaleReviews:     true,
+			},
+		},
+		"protected branch without required reviews": {
+			Protected:        true,
+			ProtectionStatus: http.StatusOK,
+			Protection:       `{"required_status_checks":{"strict":true,"contexts":["ci"]}}`,
+			Expected:         bw.DefaultBranch{Name: "develop", Protected: true},
+		},
+		"protection not accessible": {
+			Protected:        true,
+			ProtectionStatus: http.StatusNotFound,
+			Protection:       `{"message":"Not Found"}`,
+			Expected:         bw.DefaultBranch{Name: "develop"},
+		},
+	}
+
+	for name, example := range examples {
+		t.Run(name, func(t *testing.T) {
+			baseURL, mux, teardown := setup()
+			defer teardown()
+
+			ts := new(tokenServiceMock)
+			ts.On("GetToken", "user1").Return("test-token", nil)
+
+			mux.HandleFunc("/repos/user1/repo1", func(w http.ResponseWriter, req *http.Request) {
+				w.Write([]byte(`{"name":"repo1","default_branch":"develop","owner":{"login":"user1"}}`))
+			})
+
+			mux.HandleFunc("/repos/user1/repo1/branches/develop", func(w http.ResponseWriter, req *http.Request) {
+				if example.Protected {
+					w.Write([]byte(`{"name":"develop","commit":{"sha":"abc123"},"protected":true}`))
+					return
+				}
+
+				w.Write([]byte(`{"name":"develop","commit":{"sha":"abc123"},"protected":false}`))
+			})
+
+			mux.HandleFunc("/repos/user1/repo1/branches/develop/protection", func(w http.ResponseWriter, req *http.Request) {
+				require.True(t, example.Protected, "protection should only be requested for protected branches")
+
+				w.WriteHeader(example.ProtectionStatus)
+				w.Write([]byte(example.Protection))
+			})
+
+			c := github.NewGithubClient(ts)
+
+			branch, err := c.DefaultBranch(github.Context{context.Background(), baseURL}, "user1", "repo1")
+			require.NoError(t, err)
+
+			assert.False(t, branch.RefreshedAt.IsZero())
+
+			branch.RefreshedAt = example.Expected.RefreshedAt
+			assert.Equal(t, example.Expected, *branch)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package github
+
+import (
+	"fmt"
+	"time"
+
+	bw "github.com/blamewarrior/repos/blamewarrior"
+
+	gh "github.com/google/go-github/github"
+)
+
+// CodeOwners reads CODEOWNERS file from the head of the default branch of repository looking it up
+// in the same locations GitHub does. Returned CodeOwners have empty path if there is no such file.
+// Lines that cannot be parsed are skipped and listed in CodeOwners.Errors.
+func (c *GithubClient) CodeOwners(ctx Context, owner, name string) (*bw.CodeOwners, error) {
+	api, err := initAPIClient(ctx, c.tokenClient, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	sha, err := defaultBranchHead(ctx, api, owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := &bw.CodeOwners{
+		CommitSHA:   sha,
+		Rules:       []bw.CodeOwnersRule{},
+		RefreshedAt: time.Now(),
+	}
+
+	for _, path := range bw.CodeOwnersPaths {
+		file, _, _, err := api.Repositories.GetContents(ctx, owner, name, path, &gh.RepositoryContentGetOptions{
+			Ref: sha,
+		})
+
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+
+			return nil, apiError(err)
+		}
+
+		if file == nil {
+			continue
+		}
+
+		content, err := file.GetContent()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %s", path, err)
+		}
+
+		owners.Path = path
+
+		rules, errs := bw.ParseCodeOwners(content)
+		if rules != nil {
+			owners.Rules = rules
+		}
+		owners.Errors = errs
+
+		break
+	}
+
+	return owners, nil
+}
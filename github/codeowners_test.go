@@ -0,0 +1,99 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package github_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/blamewarrior/repos/github"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGithubService_CodeOwners(t *testing.T) {
+	examples := map[string]struct {
+		Files  map[string]string
+		Path   string
+		Rules  int
+		Errors []string
+	}{
+		"in .github directory": {
+			Files: map[string]string{
+				".github/CODEOWNERS": "* @octocat\n*.go @gopher\n",
+				"CODEOWNERS":         "* @someone-else\n",
+			},
+			Path:  ".github/CODEOWNERS",
+			Rules: 2,
+		},
+		"in docs directory": {
+			Files: map[string]string{"docs/CODEOWNERS": "docs/ @writers\n!x @octocat\n"},
+			Path:  "docs/CODEOWNERS",
+			Rules: 1,
+			Errors: []string{
+				"line 2: negated pattern !x is not supported",
+			},
+		},
+		"no CODEOWNERS": {
+			Files: map[string]string{},
+		},
+	}
+
+	for name, example := range examples {
+		t.Run(name, func(t *testing.T) {
+			baseURL, mux, teardown := setup()
+			defer teardown()
+
+			ts := new(tokenServiceMock)
+			ts.On("GetToken", "user1").Return("test-token", nil)
+
+			mux.HandleFunc("/repos/user1/repo1", func(w http.ResponseWriter, req *http.Request) {
+				w.Write([]byte(`{"name":"repo1","default_branch":"master","owner":{"login":"user1"}}`))
+			})
+
+			mux.HandleFunc("/repos/user1/repo1/branches/master", func(w http.ResponseWriter, req *http.Request) {
+				w.Write([]byte(`{"name":"master","commit":{"sha":"abc123"}}`))
+			})
+
+			mux.HandleFunc("/repos/user1/repo1/contents/", func(w http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "abc123", req.FormValue("ref"))
+
+				content, ok := example.Files[req.URL.Path[len("/repos/user1/repo1/contents/"):]]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(`{"message":"Not Found"}`))
+					return
+				}
+
+				encoded := base64.StdEncoding.EncodeToString([]byte(content))
+				w.Write([]byte(`{"type":"file","encoding":"base64","content":"` + encoded + `"}`))
+			})
+
+			c := github.NewGithubClient(ts)
+
+			owners, err := c.CodeOwners(github.Context{context.Background(), baseURL}, "user1", "repo1")
+			require.NoError(t, err)
+
+			assert.Equal(t, "abc123", owners.CommitSHA)
+			assert.Equal(t, example.Path, owners.Path)
+			assert.Len(t, owners.Rules, example.Rules)
+			assert.Equal(t, example.Errors, owners.Errors)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package github
+
+import (
+	bw "github.com/blamewarrior/repos/blamewarrior"
+
+	gh "github.com/google/go-github/github"
+)
+
+// Collaborators returns users that have access to repository along with their permission
+// levels. For repositories that belong to an organization teams with access are returned as well.
+func (c *GithubClient) Collaborators(ctx Context, owner, name string) (collaborators []bw.Collaborator, err error) {
+	api, err := initAPIClient(ctx, c.tokenClient, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, _, err := api.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		return nil, apiError(err)
+	}
+
+	opt := &gh.ListCollaboratorsOptions{
+		ListOptions: gh.ListOptions{PerPage: 100},
+	}
+	for {
+		users, resp, err := api.Repositories.ListCollaborators(ctx, owner, name, opt)
+		if err != nil {
+			return nil, apiError(err)
+		}
+
+		for _, user := range users {
+			var permissions map[string]bool
+			if user.Permissions != nil {
+				permissions = *user.Permissions
+			}
+
+			collaborators = append(collaborators, bw.Collaborator{
+				Login:      user.GetLogin(),
+				Type:       bw.CollaboratorUser,
+				Permission: userPermission(permissions),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	if repo.GetOwner().GetType() != "Organization" {
+		return collaborators, nil
+	}
+
+	teamsOpt := &gh.ListOptions{PerPage: 100}
+	for {
+		teams, resp, err := api.Repositories.ListTeams(ctx, owner, name, teamsOpt)
+		if err != nil {
+			return nil, apiError(err)
+		}
+
+		for _, team := range teams {
+			collaborators = append(collaborators, bw.Collaborator{
+				Login:      team.GetSlug(),
+				Type:       bw.CollaboratorTeam,
+				Permission: teamPermission(team.GetPermission()),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		teamsOpt.Page = resp.NextPage
+	}
+
+	return collaborators, nil
+}
+
+// Permission returns permission level user has for repository or an empty string if user
+// has no access to it.
+func (c *GithubClient) Permission(ctx Context, owner, name, login string) (string, error) {
+	api, err := initAPIClient(ctx, c.tokenClient, owner)
+	if err != nil {
+		return "", err
+	}
+
+	level, _, err := api.Repositories.GetPermissionLevel(ctx, owner, name, login)
+	if err != nil {
+		return "", apiError(err)
+	}
+
+	switch level.GetPermission() {
+	case "admin":
+		return bw.PermissionAdmin, nil
+	case "write":
+		return bw.PermissionWrite, nil
+	case "read":
+		return bw.PermissionRead, nil
+	default:
+		return "", nil
+	}
+}
+
+// userPermission returns the highest permission level granted to collaborator.
+func userPermission(permissions map[string]bool) string {
+	switch {
+	case permissions["admin"]:
+		return bw.PermissionAdmin
+	case permissions["push"]:
+		return bw.PermissionWrite
+	default:
+		return bw.PermissionRead
+	}
+}
+
+// teamPermission converts GitHub team permission into permission level.
+func teamPermission(permission string) string {
+	switch permission {
+	case "admin":
+		return bw.PermissionAdmin
+	case "push":
+		return bw.PermissionWrite
+	default:
+		return bw.PermissionRead
+	}
+}
@@ -0,0 +1,122 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package github_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	bw "github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/github"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGithubService_Collaborators(t *testing.T) {
+	examples := map[string]struct {
+		OwnerType     string
+		Collaborators []bw.Collaborator
+	}{
+		"user repository": {
+			OwnerType: "User",
+			Collaborators: []bw.Collaborator{
+				{Login: "user1", Type: bw.CollaboratorUser, Permission: bw.PermissionAdmin},
+				{Login: "user2", Type: bw.CollaboratorUser, Permission: bw.PermissionWrite},
+				{Login: "user3", Type: bw.CollaboratorUser, Permission: bw.PermissionRead},
+			},
+		},
+		"organization repository": {
+			OwnerType: "Organization",
+			Collaborators: []bw.Collaborator{
+				{Login: "user1", Type: bw.CollaboratorUser, Permission: bw.PermissionAdmin},
+				{Login: "user2", Type: bw.CollaboratorUser, Permission: bw.PermissionWrite},
+				{Login: "user3", Type: bw.CollaboratorUser, Permission: bw.PermissionRead},
+				{Login: "core", Type: bw.CollaboratorTeam, Permission: bw.PermissionWrite},
+				{Login: "readers", Type: bw.CollaboratorTeam, Permission: bw.PermissionRead},
+			},
+		},
+	}
+
+	for name, example := range examples {
+		t.Run(name, func(t *testing.T) {
+			baseURL, mux, teardown := setup()
+			defer teardown()
+
+			ts := new(tokenServiceMock)
+			ts.On("GetToken", "user1").Return("test-token", nil)
+
+			mux.HandleFunc("/repos/user1/repo1", func(w http.ResponseWriter, req *http.Request) {
+				w.Write([]byte(`{"name":"repo1","owner":{"login":"user1","type":"` + example.OwnerType + `"}}`))
+			})
+
+			mux.HandleFunc("/repos/user1/repo1/collaborators", func(w http.ResponseWriter, req *http.Request) {
+				w.Write([]byte(`[
+					{"login":"user1","permissions":{"admin":true,"push":true,"pull":true}},
+					{"login":"user2","permissions":{"admin":false,"push":true,"pull":true}},
+					{"login":"user3","permissions":{"admin":false,"push":false,"pull":true}}
+				]`))
+			})
+
+			mux.HandleFunc("/repos/user1/repo1/teams", func(w http.ResponseWriter, req *http.Request) {
+				require.Equal(t, "Organization", example.OwnerType, "teams should only be requested for organization repositories")
+
+				w.Write([]byte(`[{"slug":"core","permission":"push"},{"slug":"readers","permission":"pull"}]`))
+			})
+
+			c := github.NewGithubClient(ts)
+
+			collaborators, err := c.Collaborators(github.Context{context.Background(), baseURL}, "user1", "repo1")
+			require.NoError(t, err)
+
+			assert.Equal(t, example.Collaborators, collaborators)
+		})
+	}
+}
+
+func TestGithubService_Permission(t *testing.T) {
+	examples := map[string]struct {
+		Response   string
+		Permission string
+	}{
+		"admin":         {`{"permission":"admin","user":{"login":"user2"}}`, bw.PermissionAdmin},
+		"write":         {`{"permission":"write","user":{"login":"user2"}}`, bw.PermissionWrite},
+		"read":          {`{"permission":"read","user":{"login":"user2"}}`, bw.PermissionRead},
+		"no permission": {`{"permission":"none","user":{"login":"user2"}}`, ""},
+	}
+
+	for name, example := range examples {
+		t.Run(name, func(t *testing.T) {
+			baseURL, mux, teardown := setup()
+			defer teardown()
+
+			ts := new(tokenServiceMock)
+			ts.On("GetToken", "user1").Return("test-token", nil)
+
+			mux.HandleFunc("/repos/user1/repo1/collaborators/user2/permission", func(w http.ResponseWriter, req *http.Request) {
+				w.Write([]byte(example.Response))
+			})
+
+			var provider bw.Provider = github.NewProvider(github.NewGithubClient(ts))
+
+			permission, err := provider.Permission(github.Context{context.Background(), baseURL}, "user1", "repo1", "user2")
+			require.NoError(t, err)
+
+			assert.Equal(t, example.Permission, permission)
+		})
+	}
+}
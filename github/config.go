@@ -0,0 +1,122 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	bw "github.com/blamewarrior/repos/blamewarrior"
+
+	gh "github.com/google/go-github/github"
+)
+
+// ConfigError is returned when configuration file kept in repository cannot be parsed or
+// contains invalid settings.
+type ConfigError struct {
+	// CommitSHA is the commit configuration file was read from
+	CommitSHA string
+	Err       error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s", bw.ConfigFileName, e.Err)
+}
+
+// RepositoryConfig reads BlameWarrior configuration file from the head of the default branch
+// of repository. Config with empty settings is returned if there is no such file.
+func (c *GithubClient) RepositoryConfig(ctx Context, owner, name string) (*bw.RepositoryConfig, error) {
+	api, err := initAPIClient(ctx, c.tokenClient, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	sha, err := defaultBranchHead(ctx, api, owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &bw.RepositoryConfig{
+		CommitSHA:   sha,
+		RefreshedAt: time.Now(),
+	}
+
+	file, _, _, err := api.Repositories.GetContents(ctx, owner, name, bw.ConfigFileName, &gh.RepositoryContentGetOptions{
+		Ref: config.CommitSHA,
+	})
+
+	if err != nil {
+		if isNotFound(err) {
+			return config, nil
+		}
+
+		return nil, apiError(err)
+	}
+
+	if file == nil {
+		return nil, &ConfigError{CommitSHA: config.CommitSHA, Err: fmt.Errorf("not a file")}
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, &ConfigError{CommitSHA: config.CommitSHA, Err: err}
+	}
+
+	settings, err := bw.ParseConfigFile([]byte(content))
+	if err != nil {
+		return nil, &ConfigError{CommitSHA: config.CommitSHA, Err: err}
+	}
+
+	config.Settings = settings
+
+	return config, nil
+}
+
+// defaultBranchHead returns SHA of the last commit in the default branch of repository.
+func defaultBranchHead(ctx Context, api *gh.Client, owner, name string) (string, error) {
+	repo, _, err := api.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		return "", apiError(err)
+	}
+
+	branch, _, err := api.Repositories.GetBranch(ctx, owner, name, repo.GetDefaultBranch())
+	if err != nil {
+		return "", apiError(err)
+	}
+
+	return branch.GetCommit().GetSHA(), nil
+}
+
+// apiError converts error returned by GitHub API client into one of package errors.
+func apiError(err error) error {
+	switch err.(type) {
+	case *gh.RateLimitError:
+		return ErrRateLimitReached
+	case *gh.ErrorResponse:
+		if isNotFound(err) {
+			return ErrNotFound
+		}
+	}
+
+	return fmt.Errorf("request failed: %s", err)
+}
+
+func isNotFound(err error) bool {
+	apiErr, ok := err.(*gh.ErrorResponse)
+
+	return ok && apiErr.Response != nil && apiErr.Response.StatusCode == http.StatusNotFound
+}
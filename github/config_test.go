@@ -0,0 +1,111 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package github_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/blamewarrior/repos/github"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGithubService_RepositoryConfig(t *testing.T) {
+	examples := map[string]struct {
+		Status   int
+		Content  string
+		Settings string
+		Err      string
+	}{
+		"valid config": {
+			Status:   http.StatusOK,
+			Content:  "version: 1\nignored_paths:\n  - vendor/\n",
+			Settings: `{"version":1,"ignored_paths":["vendor/"]}`,
+		},
+		"no config": {
+			Status: http.StatusNotFound,
+		},
+		"malformed YAML": {
+			Status:  http.StatusOK,
+			Content: "version: 1\n  ignored_paths: []\n",
+			Err:     ".blamewarrior.yml: yaml: line 2: mapping values are not allowed in this context",
+		},
+		"invalid settings": {
+			Status:  http.StatusOK,
+			Content: "version: 1\nreviewers: 2\n",
+			Err:     ".blamewarrior.yml: invalid settings: reviewers must be object",
+		},
+	}
+
+	for name, example := range examples {
+		t.Run(name, func(t *testing.T) {
+			baseURL, mux, teardown := setup()
+			defer teardown()
+
+			ts := new(tokenServiceMock)
+			ts.On("GetToken", "user1").Return("test-token", nil)
+
+			mux.HandleFunc("/repos/user1/repo1", func(w http.ResponseWriter, req *http.Request) {
+				w.Write([]byte(`{"name":"repo1","default_branch":"develop","owner":{"login":"user1"}}`))
+			})
+
+			mux.HandleFunc("/repos/user1/repo1/branches/develop", func(w http.ResponseWriter, req *http.Request) {
+				w.Write([]byte(`{"name":"develop","commit":{"sha":"abc123"}}`))
+			})
+
+			mux.HandleFunc("/repos/user1/repo1/contents/.blamewarrior.yml", func(w http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "abc123", req.FormValue("ref"))
+
+				w.WriteHeader(example.Status)
+				if example.Status != http.StatusOK {
+					w.Write([]byte(`{"message":"Not Found"}`))
+					return
+				}
+
+				content := base64.StdEncoding.EncodeToString([]byte(example.Content))
+				w.Write([]byte(`{"type":"file","encoding":"base64","content":"` + content + `"}`))
+			})
+
+			c := github.NewGithubClient(ts)
+
+			config, err := c.RepositoryConfig(github.Context{context.Background(), baseURL}, "user1", "repo1")
+
+			if example.Err != "" {
+				require.Error(t, err)
+				assert.Equal(t, example.Err, err.Error())
+
+				configErr, ok := err.(*github.ConfigError)
+				require.True(t, ok)
+				assert.Equal(t, "abc123", configErr.CommitSHA)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "abc123", config.CommitSHA)
+
+			if example.Settings == "" {
+				assert.Empty(t, config.Settings)
+			} else {
+				assert.JSONEq(t, example.Settings, string(config.Settings))
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package github
+
+import (
+	"time"
+
+	bw "github.com/blamewarrior/repos/blamewarrior"
+
+	gh "github.com/google/go-github/github"
+)
+
+// RepositoryMetadata returns descriptive information about repository.
+func (c *GithubClient) RepositoryMetadata(ctx Context, owner, name string) (*bw.RepositoryMetadata, error) {
+	api, err := initAPIClient(ctx, c.tokenClient, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, _, err := api.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		return nil, apiError(err)
+	}
+
+	return repositoryMetadata(repo), nil
+}
+
+func repositoryMetadata(repo *gh.Repository) *bw.RepositoryMetadata {
+	metadata := &bw.RepositoryMetadata{
+		ProviderID:  int64(repo.GetID()),
+		Description: repo.GetDescription(),
+		Language:    repo.GetLanguage(),
+		Topics:      repo.Topics,
+		Size:        repo.GetSize(),
+		Stars:       repo.GetStargazersCount(),
+		Archived:    repo.GetArchived(),
+		RefreshedAt: time.Now(),
+	}
+
+	if metadata.Topics == nil {
+		metadata.Topics = []string{}
+	}
+
+	if repo.PushedAt != nil {
+		pushedAt := repo.PushedAt.Time
+		metadata.PushedAt = &pushedAt
+	}
+
+	return metadata
+}
@@ -0,0 +1,57 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package github
+
+import (
+	"context"
+
+	bw "github.com/blamewarrior/repos/blamewarrior"
+)
+
+// Provider serves repositories hosted at GitHub through Client.
+type Provider struct {
+	Client Client
+}
+
+// NewProvider returns provider backed by GitHub API client.
+func NewProvider(client Client) *Provider {
+	return &Provider{client}
+}
+
+func (p *Provider) UserRepositories(ctx context.Context, owner string) ([]bw.Repository, error) {
+	return p.Client.UserRepositories(contextOf(ctx), owner)
+}
+
+func (p *Provider) RepositoryMetadata(ctx context.Context, owner, name string) (*bw.RepositoryMetadata, error) {
+	return p.Client.RepositoryMetadata(contextOf(ctx), owner, name)
+}
+
+func (p *Provider) Collaborators(ctx context.Context, owner, name string) ([]bw.Collaborator, error) {
+	return p.Client.Collaborators(contextOf(ctx), owner, name)
+}
+
+func (p *Provider) Permission(ctx context.Context, owner, name, login string) (string, error) {
+	return p.Client.Permission(contextOf(ctx), owner, name, login)
+}
+
+// contextOf converts ctx into Context keeping API endpoint override if ctx is a Context already.
+func contextOf(ctx context.Context) Context {
+	if c, ok := ctx.(Context); ok {
+		return c
+	}
+
+	return Context{Context: ctx}
+}
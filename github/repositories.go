@@ -16,7 +16,6 @@
 package github
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
 
@@ -27,13 +26,22 @@ import (
 	gh "github.com/google/go-github/github"
 )
 
+// Errors are shared with other providers, so that callers can handle them regardless of
+// the provider repository is hosted at.
 var (
-	ErrRateLimitReached = errors.New("GitHub API request rate limit reached")
-	ErrNoSuchUser       = errors.New("no such user")
+	ErrRateLimitReached = bw.ErrRateLimitReached
+	ErrNoSuchUser       = bw.ErrNoSuchUser
+	ErrNotFound         = bw.ErrNotFound
 )
 
 type Client interface {
 	UserRepositories(ctx Context, username string) ([]bw.Repository, error)
+	RepositoryConfig(ctx Context, owner, name string) (*bw.RepositoryConfig, error)
+	CodeOwners(ctx Context, owner, name string) (*bw.CodeOwners, error)
+	Collaborators(ctx Context, owner, name string) ([]bw.Collaborator, error)
+	DefaultBranch(ctx Context, owner, name string) (*bw.DefaultBranch, error)
+	RepositoryMetadata(ctx Context, owner, name string) (*bw.RepositoryMetadata, error)
+	Permission(ctx Context, owner, name, login string) (string, error)
 }
 
 type GithubClient struct {
@@ -72,9 +80,11 @@ func (c *GithubClient) UserRepositories(ctx Context, username string) (repos []b
 
 		for _, repo := range ghRepositories {
 			repos = append(repos, bw.Repository{
-				Owner:   *repo.Owner.Login,
-				Name:    *repo.Name,
-				Private: *repo.Private,
+				Provider: bw.ProviderGithub,
+				Owner:    *repo.Owner.Login,
+				Name:     *repo.Name,
+				Private:  *repo.Private,
+				Metadata: repositoryMetadata(repo),
 			})
 		}
 
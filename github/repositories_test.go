@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/blamewarrior/repos/github"
 
@@ -45,7 +46,7 @@ func TestGithubService_UserRepositories(t *testing.T) {
 
 		if req.FormValue("page") != "2" {
 			w.Header().Set("Link", `<`+url+`?page=2>; rel="next", `+w.Header().Get("Link"))
-			w.Write([]byte(`[{"name":"repo1","private":false,"owner":{"login":"user1"}},{"name":"repo2","private":true,"owner":{"login":"user1"}}]`))
+			w.Write([]byte(`[{"name":"repo1","private":false,"owner":{"login":"user1"},"description":"First","language":"Go","topics":["backend","api"],"size":42,"stargazers_count":7,"pushed_at":"2017-06-01T10:00:00Z"},{"name":"repo2","private":true,"owner":{"login":"user1"}}]`))
 		} else {
 			w.Write([]byte(`[{"name":"repo3","private":true,"owner":{"login":"user1"}}]`))
 		}
@@ -57,9 +58,32 @@ func TestGithubService_UserRepositories(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, repositories, 3)
 
-	assert.Contains(t, repositories, bw.Repository{Name: "repo1", Private: false, Owner: "user1"})
-	assert.Contains(t, repositories, bw.Repository{Name: "repo2", Private: true, Owner: "user1"})
-	assert.Contains(t, repositories, bw.Repository{Name: "repo3", Private: true, Owner: "user1"})
+	expected := []bw.Repository{
+		{Provider: bw.ProviderGithub, Name: "repo1", Private: false, Owner: "user1"},
+		{Provider: bw.ProviderGithub, Name: "repo2", Private: true, Owner: "user1"},
+		{Provider: bw.ProviderGithub, Name: "repo3", Private: true, Owner: "user1"},
+	}
+
+	for i, repo := range repositories {
+		require.NotNil(t, repo.Metadata)
+
+		repo.Metadata = nil
+		assert.Equal(t, expected[i], repo)
+	}
+
+	metadata := repositories[0].Metadata
+	pushedAt := time.Date(2017, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "First", metadata.Description)
+	assert.Equal(t, "Go", metadata.Language)
+	assert.Equal(t, []string{"backend", "api"}, metadata.Topics)
+	assert.Equal(t, 42, metadata.Size)
+	assert.Equal(t, 7, metadata.Stars)
+	require.NotNil(t, metadata.PushedAt)
+	assert.True(t, pushedAt.Equal(*metadata.PushedAt))
+
+	assert.Equal(t, []string{}, repositories[1].Metadata.Topics)
+	assert.Nil(t, repositories[1].Metadata.PushedAt)
 }
 
 func setup() (baseURL *url.URL, mux *http.ServeMux, teardownFn func()) {
@@ -69,3 +93,26 @@ func setup() (baseURL *url.URL, mux *http.ServeMux, teardownFn func()) {
 
 	return baseURL, mux, srv.Close
 }
+
+func TestGithubService_RepositoryMetadata(t *testing.T) {
+	baseURL, mux, teardown := setup()
+	defer teardown()
+
+	ts := new(tokenServiceMock)
+	ts.On("GetToken", "user1").Return("test-token", nil)
+
+	mux.HandleFunc("/repos/user1/repo1", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"id":1296269,"name":"repo1","owner":{"login":"user1"},"language":"Go","topics":["backend"],"stargazers_count":3}`))
+	})
+
+	c := github.NewGithubClient(ts)
+
+	metadata, err := c.RepositoryMetadata(github.Context{context.Background(), baseURL}, "user1", "repo1")
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1296269), metadata.ProviderID)
+	assert.Equal(t, "Go", metadata.Language)
+	assert.Equal(t, []string{"backend"}, metadata.Topics)
+	assert.Equal(t, 3, metadata.Stars)
+	assert.False(t, metadata.RefreshedAt.IsZero())
+}
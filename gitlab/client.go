@@ -0,0 +1,97 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package gitlab implements provider of repositories hosted at GitLab using its REST API v4.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	bw "github.com/blamewarrior/repos/blamewarrior"
+)
+
+// perPage is the page size requested from paginated endpoints, the maximum GitLab allows
+const perPage = "100"
+
+// Client is a client of GitLab REST API v4.
+type Client struct {
+	// BaseURL is GitLab API endpoint, e.g. https://gitlab.example.com/api/v4
+	BaseURL string
+
+	token string
+	c     *http.Client
+}
+
+// NewClient returns client of GitLab instance API at baseURL authenticated with personal,
+// group or project access token.
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		c:       http.DefaultClient,
+	}
+}
+
+// get requests API endpoint and decodes response into result. It returns the number of the next
+// page for paginated endpoints or an empty string if there are no more pages.
+func (c *Client) get(ctx context.Context, path string, query url.Values, result interface{}) (nextPage string, err error) {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.c.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("request failed: %s", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", bw.ErrNotFound
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return "", bw.ErrRateLimitReached
+	case resp.StatusCode != http.StatusOK:
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("request failed: %s %s: %d %s", req.Method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %s", err)
+	}
+
+	return resp.Header.Get("X-Next-Page"), nil
+}
+
+// projectPath returns API path of project with given namespace and name.
+func projectPath(owner, name string, suffix ...string) string {
+	return "/projects/" + url.PathEscape(owner+"/"+name) + strings.Join(suffix, "")
+}
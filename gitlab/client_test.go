@@ -0,0 +1,57 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package gitlab_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	bw "github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/gitlab"
+)
+
+func TestClient_Errors(t *testing.T) {
+	examples := map[string]struct {
+		Status int
+		Err    error
+	}{
+		"not found":           {http.StatusNotFound, bw.ErrNotFound},
+		"rate limit":          {http.StatusTooManyRequests, bw.ErrRateLimitReached},
+		"internal error":      {http.StatusInternalServerError, nil},
+		"authorization error": {http.StatusUnauthorized, nil},
+	}
+
+	for name, example := range examples {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				http.Error(w, `{"message":"error"}`, example.Status)
+			}))
+			defer srv.Close()
+
+			_, err := gitlab.NewClient(srv.URL, "").RepositoryMetadata(context.Background(), "blamewarrior", "repos")
+			if example.Err != nil {
+				assert.Equal(t, example.Err, err)
+				return
+			}
+
+			assert.Contains(t, err.Error(), "request failed: GET /projects/blamewarrior%2Frepos")
+		})
+	}
+}
@@ -0,0 +1,233 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package gitlab
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	bw "github.com/blamewarrior/repos/blamewarrior"
+)
+
+type project struct {
+	ID             int        `json:"id"`
+	Path           string     `json:"path"`
+	Description    string     `json:"description"`
+	Visibility     string     `json:"visibility"`
+	Topics         []string   `json:"topics"`
+	TagList        []string   `json:"tag_list"`
+	StarCount      int        `json:"star_count"`
+	LastActivityAt *time.Time `json:"last_activity_at"`
+	Archived       bool       `json:"archived"`
+	Namespace      struct {
+		FullPath string `json:"full_path"`
+	} `json:"namespace"`
+	Statistics *struct {
+		RepositorySize int64 `json:"repository_size"`
+	} `json:"statistics"`
+	SharedWithGroups []struct {
+		GroupFullPath    string `json:"group_full_path"`
+		GroupAccessLevel int    `json:"group_access_level"`
+	} `json:"shared_with_groups"`
+}
+
+// UserRepositories returns projects that belong to GitLab group or user.
+func (c *Client) UserRepositories(ctx context.Context, owner string) ([]bw.Repository, error) {
+	repos, err := c.listProjects(ctx, "/groups/"+url.PathEscape(owner)+"/projects")
+	if err == bw.ErrNotFound {
+		repos, err = c.listProjects(ctx, "/users/"+url.PathEscape(owner)+"/projects")
+	}
+
+	if err == bw.ErrNotFound {
+		return nil, bw.ErrNoSuchUser
+	}
+
+	return repos, err
+}
+
+func (c *Client) listProjects(ctx context.Context, path string) (repos []bw.Repository, err error) {
+	query := url.Values{"per_page": {perPage}}
+
+	for page := "1"; page != ""; {
+		query.Set("page", page)
+
+		var projects []project
+
+		if page, err = c.get(ctx, path, query, &projects); err != nil {
+			return nil, err
+		}
+
+		for i := range projects {
+			repos = append(repos, bw.Repository{
+				Provider: bw.ProviderGitlab,
+				Owner:    projects[i].Namespace.FullPath,
+				Name:     projects[i].Path,
+				Private:  projects[i].Visibility != "public",
+				Metadata: repositoryMetadata(&projects[i]),
+			})
+		}
+	}
+
+	return repos, nil
+}
+
+// RepositoryMetadata returns descriptive information about project.
+func (c *Client) RepositoryMetadata(ctx context.Context, owner, name string) (*bw.RepositoryMetadata, error) {
+	var p project
+
+	if _, err := c.get(ctx, projectPath(owner, name), url.Values{"statistics": {"true"}}, &p); err != nil {
+		return nil, err
+	}
+
+	metadata := repositoryMetadata(&p)
+
+	// GitLab reports the share of every language used in project instead of the primary one
+	var languages map[string]float64
+
+	if _, err := c.get(ctx, projectPath(owner, name, "/languages"), nil, &languages); err != nil {
+		return nil, err
+	}
+
+	var share float64
+	for language, s := range languages {
+		if s > share || (s == share && language < metadata.Language) {
+			metadata.Language, share = language, s
+		}
+	}
+
+	return metadata, nil
+}
+
+func repositoryMetadata(p *project) *bw.RepositoryMetadata {
+	metadata := &bw.RepositoryMetadata{
+		ProviderID:  int64(p.ID),
+		Description: p.Description,
+		Topics:      p.Topics,
+		Stars:       p.StarCount,
+		PushedAt:    p.LastActivityAt,
+		Archived:    p.Archived,
+		RefreshedAt: time.Now(),
+	}
+
+	// topics replaced tag list in GitLab 14.0
+	if metadata.Topics == nil {
+		metadata.Topics = p.TagList
+	}
+
+	if metadata.Topics == nil {
+		metadata.Topics = []string{}
+	}
+
+	// GitHub reports repository size in kilobytes, and so do providers
+	if p.Statistics != nil {
+		metadata.Size = int(p.Statistics.RepositorySize / 1024)
+	}
+
+	return metadata
+}
+
+type member struct {
+	ID          int    `json:"id"`
+	Username    string `json:"username"`
+	AccessLevel int    `json:"access_level"`
+}
+
+// Collaborators returns members of project including inherited ones along with groups project
+// is shared with.
+func (c *Client) Collaborators(ctx context.Context, owner, name string) (collaborators []bw.Collaborator, err error) {
+	var p project
+
+	if _, err := c.get(ctx, projectPath(owner, name), nil, &p); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"per_page": {perPage}}
+
+	for page := "1"; page != ""; {
+		query.Set("page", page)
+
+		var members []member
+
+		if page, err = c.get(ctx, projectPath(owner, name, "/members/all"), query, &members); err != nil {
+			return nil, err
+		}
+
+		for _, m := range members {
+			collaborators = append(collaborators, bw.Collaborator{
+				Login:      m.Username,
+				Type:       bw.CollaboratorUser,
+				Permission: accessLevelPermission(m.AccessLevel),
+			})
+		}
+	}
+
+	for _, group := range p.SharedWithGroups {
+		collaborators = append(collaborators, bw.Collaborator{
+			Login:      group.GroupFullPath,
+			Type:       bw.CollaboratorTeam,
+			Permission: accessLevelPermission(group.GroupAccessLevel),
+		})
+	}
+
+	return collaborators, nil
+}
+
+// Permission returns permission level user has for project or an empty string if user has
+// no access to it.
+func (c *Client) Permission(ctx context.Context, owner, name, login string) (string, error) {
+	var users []member
+
+	if _, err := c.get(ctx, "/users", url.Values{"username": {login}}, &users); err != nil {
+		return "", err
+	}
+
+	if len(users) == 0 {
+		return "", bw.ErrNoSuchUser
+	}
+
+	var m member
+
+	_, err := c.get(ctx, projectPath(owner, name, "/members/all/", strconv.Itoa(users[0].ID)), nil, &m)
+	if err == bw.ErrNotFound {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return accessLevelPermission(m.AccessLevel), nil
+}
+
+// GitLab access levels, see https://docs.gitlab.com/ee/api/members.html#valid-access-levels
+const (
+	accessDeveloper  = 30
+	accessMaintainer = 40
+)
+
+// accessLevelPermission converts GitLab access level into permission level.
+func accessLevelPermission(level int) string {
+	switch {
+	case level >= accessMaintainer:
+		return bw.PermissionAdmin
+	case level >= accessDeveloper:
+		return bw.PermissionWrite
+	default:
+		return bw.PermissionRead
+	}
+}
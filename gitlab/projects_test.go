@@ -0,0 +1,188 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package gitlab_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bw "github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/gitlab"
+)
+
+// setup starts fake GitLab API serving given responses keyed by escaped request path
+// and page number.
+func setup(t *testing.T, responses map[string]string) (client *gitlab.Client, teardown func()) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "secret", req.Header.Get("PRIVATE-TOKEN"))
+
+		key := req.URL.EscapedPath()
+		if page := req.URL.Query().Get("page"); page != "" && page != "1" {
+			key += "?page=" + page
+		}
+
+		if _, ok := responses[key+"?page=2"]; ok {
+			w.Header().Set("X-Next-Page", "2")
+		}
+
+		body, ok := responses[key]
+		if !ok {
+			http.Error(w, `{"message":"404 Not Found"}`, http.StatusNotFound)
+			return
+		}
+
+		w.Write([]byte(body))
+	}))
+
+	return gitlab.NewClient(srv.URL+"/api/v4/", "secret"), srv.Close
+}
+
+func TestClient_UserRepositories(t *testing.T) {
+	examples := map[string]struct {
+		Owner     string
+		Responses map[string]string
+		Expected  []bw.Repository
+		Err       error
+	}{
+		"group": {
+			Owner: "blamewarrior",
+			Responses: map[string]string{
+				"/api/v4/groups/blamewarrior/projects":        `[{"path":"repos","visibility":"private","namespace":{"full_path":"blamewarrior"}}]`,
+				"/api/v4/groups/blamewarrior/projects?page=2": `[{"path":"hooks","visibility":"public","namespace":{"full_path":"blamewarrior"}}]`,
+			},
+			Expected: []bw.Repository{
+				{Provider: bw.ProviderGitlab, Owner: "blamewarrior", Name: "repos", Private: true},
+				{Provider: bw.ProviderGitlab, Owner: "blamewarrior", Name: "hooks", Private: false},
+			},
+		},
+		"user": {
+			Owner: "octocat",
+			Responses: map[string]string{
+				"/api/v4/users/octocat/projects": `[{"path":"hello-world","visibility":"internal","namespace":{"full_path":"octocat"}}]`,
+			},
+			Expected: []bw.Repository{
+				{Provider: bw.ProviderGitlab, Owner: "octocat", Name: "hello-world", Private: true},
+			},
+		},
+		"unknown": {
+			Owner: "nobody",
+			Err:   bw.ErrNoSuchUser,
+		},
+	}
+
+	for name, example := range examples {
+		t.Run(name, func(t *testing.T) {
+			client, teardown := setup(t, example.Responses)
+			defer teardown()
+
+			repos, err := client.UserRepositories(context.Background(), example.Owner)
+			assert.Equal(t, example.Err, err)
+
+			for i := range repos {
+				require.NotNil(t, repos[i].Metadata)
+				repos[i].Metadata = nil
+			}
+
+			assert.Equal(t, example.Expected, repos)
+		})
+	}
+}
+
+func TestClient_RepositoryMetadata(t *testing.T) {
+	client, teardown := setup(t, map[string]string{
+		"/api/v4/projects/blamewarrior%2Frepos": `{
+			"id":4,
+			"path":"repos",
+			"description":"Repositories service",
+			"tag_list":["backend"],
+			"star_count":7,
+			"last_activity_at":"2017-06-01T10:00:00Z",
+			"archived":true,
+			"statistics":{"repository_size":43008}
+		}`,
+		"/api/v4/projects/blamewarrior%2Frepos/languages": `{"Go":80.5,"Shell":19.5}`,
+	})
+	defer teardown()
+
+	metadata, err := client.RepositoryMetadata(context.Background(), "blamewarrior", "repos")
+	require.NoError(t, err)
+
+	pushedAt := time.Date(2017, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, int64(4), metadata.ProviderID)
+	assert.Equal(t, "Repositories service", metadata.Description)
+	assert.Equal(t, "Go", metadata.Language)
+	assert.Equal(t, []string{"backend"}, metadata.Topics)
+	assert.Equal(t, 42, metadata.Size)
+	assert.Equal(t, 7, metadata.Stars)
+	assert.True(t, metadata.Archived)
+	require.NotNil(t, metadata.PushedAt)
+	assert.True(t, pushedAt.Equal(*metadata.PushedAt))
+	assert.False(t, metadata.RefreshedAt.IsZero())
+
+	_, err = client.RepositoryMetadata(context.Background(), "blamewarrior", "missing")
+	assert.Equal(t, bw.ErrNotFound, err)
+}
+
+func TestClient_Collaborators(t *testing.T) {
+	client, teardown := setup(t, map[string]string{
+		"/api/v4/projects/blamewarrior%2Frepos": `{"path":"repos","shared_with_groups":[{"group_full_path":"blamewarrior/core","group_access_level":30}]}`,
+		"/api/v4/projects/blamewarrior%2Frepos/members/all": `[
+			{"id":1,"username":"owner","access_level":50},
+			{"id":2,"username":"maintainer","access_level":40}
+		]`,
+		"/api/v4/projects/blamewarrior%2Frepos/members/all?page=2": `[
+			{"id":3,"username":"developer","access_level":30},
+			{"id":4,"username":"guest","access_level":10}
+		]`,
+	})
+	defer teardown()
+
+	collaborators, err := client.Collaborators(context.Background(), "blamewarrior", "repos")
+	require.NoError(t, err)
+
+	assert.Equal(t, []bw.Collaborator{
+		{Login: "owner", Type: bw.CollaboratorUser, Permission: bw.PermissionAdmin},
+		{Login: "maintainer", Type: bw.CollaboratorUser, Permission: bw.PermissionAdmin},
+		{Login: "developer", Type: bw.CollaboratorUser, Permission: bw.PermissionWrite},
+		{Login: "guest", Type: bw.CollaboratorUser, Permission: bw.PermissionRead},
+		{Login: "blamewarrior/core", Type: bw.CollaboratorTeam, Permission: bw.PermissionWrite},
+	}, collaborators)
+}
+
+func TestClient_Permission(t *testing.T) {
+	client, teardown := setup(t, map[string]string{
+		"/api/v4/users": `[{"id":3,"username":"developer"}]`,
+		"/api/v4/projects/blamewarrior%2Frepos/members/all/3": `{"id":3,"username":"developer","access_level":30}`,
+	})
+	defer teardown()
+
+	var provider bw.Provider = client
+
+	permission, err := provider.Permission(context.Background(), "blamewarrior", "repos", "developer")
+	require.NoError(t, err)
+	assert.Equal(t, bw.PermissionWrite, permission)
+
+	permission, err = provider.Permission(context.Background(), "blamewarrior", "hooks", "developer")
+	require.NoError(t, err)
+	assert.Empty(t, permission)
+}
@@ -0,0 +1,296 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/proto/reposv1"
+)
+
+const defaultGRPCAddr = ":8081"
+
+// grpcServer serves RepositoryService sharing database and clients with HTTP handlers.
+type grpcServer struct {
+	h *Handlers
+}
+
+// newGRPCServer returns gRPC server of repository service backed by handlers.
+func newGRPCServer(h *Handlers) *grpc.Server {
+	s := grpc.NewServer()
+	reposv1.RegisterRepositoryServiceServer(s, &grpcServer{h})
+
+	return s
+}
+
+func (s *grpcServer) Get(ctx context.Context, req *reposv1.GetRequest) (*reposv1.Repository, error) {
+	repo, err := s.h.findRepository(req.FullName, &blamewarrior.ListOptions{IncludeDeleted: req.IncludeDeleted})
+	if err != nil {
+		return nil, grpcError("Get", err)
+	}
+
+	return repositoryMessage(repo), nil
+}
+
+func (s *grpcServer) ListByOwner(ctx context.Context, req *reposv1.ListByOwnerRequest) (*reposv1.ListRepositoriesResponse, error) {
+	if req.Owner == "" {
+		return nil, status.Error(codes.InvalidArgument, "incorrect owner")
+	}
+
+	opts := &blamewarrior.ListOptions{
+		Provider:       req.Provider,
+		IncludeDeleted: req.IncludeDeleted,
+		Language:       req.Language,
+		Topic:          req.Topic,
+		State:          req.State,
+		Sort:           req.Sort,
+	}
+
+	if opts.Provider == "" {
+		opts.Provider = blamewarrior.DefaultProvider
+	}
+
+	switch opts.State {
+	case "", blamewarrior.StateActive, blamewarrior.StateInactive, blamewarrior.StatePending, blamewarrior.StateFailed:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown state %s", opts.State)
+	}
+
+	if opts.Sort != "" && !blamewarrior.ValidSortOrder(opts.Sort) {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown sort order %s", opts.Sort)
+	}
+
+	if req.PushedSince != nil {
+		pushedSince, err := ptypes.Timestamp(req.PushedSince)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "malformed pushed_since: %s", err)
+		}
+
+		opts.PushedSince = pushedSince
+	}
+
+	results, err := s.h.findOwnerRepositories(req.Owner, opts)
+	if err != nil {
+		return nil, grpcError("ListByOwner", err)
+	}
+
+	return repositoriesMessage(results), nil
+}
+
+func (s *grpcServer) Create(ctx context.Context, req *reposv1.CreateRequest) (*reposv1.Repository, error) {
+	repository := &blamewarrior.Repository{
+		Owner:    req.Owner,
+		Name:     req.Name,
+		Private:  req.Private,
+		Provider: req.Provider,
+		State:    blamewarrior.StateActive,
+	}
+
+	if req.SettingsJson != "" {
+		repository.Settings = json.RawMessage(req.SettingsJson)
+	}
+
+	if err := repository.Validate(); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "error when creating repository: %s", err)
+	}
+
+	tx, err := s.h.db.Session().Begin()
+	if err != nil {
+		return nil, grpcError("Create", err)
+	}
+
+	defer tx.Rollback()
+
+	if err = blamewarrior.CreateRepository(tx, repository, grpcChange(req.Actor, req.RequestId)); err != nil {
+		return nil, grpcError("Create", err)
+	}
+
+	if err = s.h.hooksClient.CreateHook(repository.QualifiedName()); err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to create webhook: %s", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, grpcError("Create", err)
+	}
+
+	s.h.cache.Invalidate(repository.Provider, repository.Owner, repository.Name)
+
+	return repositoryMessage(repository), nil
+}
+
+func (s *grpcServer) Delete(ctx context.Context, req *reposv1.DeleteRequest) (*reposv1.DeleteResponse, error) {
+	tx, err := s.h.db.Session().Begin()
+	if err != nil {
+		return nil, grpcError("Delete", err)
+	}
+
+	defer tx.Rollback()
+
+	repo, err := blamewarrior.GetRepositoryByFullName(tx, req.FullName)
+	if err != nil {
+		return nil, grpcError("Delete", err)
+	}
+
+	if err = blamewarrior.DeleteRepository(tx, req.FullName, grpcChange(req.Actor, req.RequestId)); err != nil {
+		return nil, grpcError("Delete", err)
+	}
+
+	if err = s.h.hooksClient.DeleteHook(repo.QualifiedName()); err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to delete webhook: %s", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, grpcError("Delete", err)
+	}
+
+	s.h.cache.Invalidate(repo.Provider, repo.Owner, repo.Name)
+
+	return &reposv1.DeleteResponse{}, nil
+}
+
+func (s *grpcServer) ListGithub(ctx context.Context, req *reposv1.ListGithubRequest) (*reposv1.ListRepositoriesResponse, error) {
+	provider, err := s.h.provider(blamewarrior.ProviderGithub)
+	if err != nil {
+		return nil, grpcError("ListGithub", err)
+	}
+
+	repositories, err := provider.UserRepositories(ctx, req.Owner)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to fetch repositories: %s", err)
+	}
+
+	return repositoriesMessage(repositories), nil
+}
+
+func (s *grpcServer) Lookup(ctx context.Context, req *reposv1.LookupRequest) (*reposv1.LookupResponse, error) {
+	if n := len(req.FullNames) + len(req.Ids); n > blamewarrior.MaxLookupItems {
+		return nil, status.Errorf(codes.InvalidArgument, "too many repositories, expected at most %d", blamewarrior.MaxLookupItems)
+	}
+
+	provider := req.Provider
+	if provider == "" {
+		provider = blamewarrior.DefaultProvider
+	}
+
+	results, err := blamewarrior.LookupRepositories(s.h.db.Session(), provider, req.FullNames, req.Ids)
+	if err != nil {
+		return nil, grpcError("Lookup", err)
+	}
+
+	response := &reposv1.LookupResponse{Results: make([]*reposv1.LookupResult, len(results))}
+	for i, result := range results {
+		response.Results[i] = &reposv1.LookupResult{FullName: result.FullName, Id: result.ID, Found: result.Found}
+
+		if result.Repository != nil {
+			response.Results[i].Repository = repositoryMessage(result.Repository)
+		}
+	}
+
+	return response, nil
+}
+
+// grpcError converts error returned by store into gRPC status. Unexpected errors are logged
+// and reported as internal ones.
+func grpcError(method string, err error) error {
+	switch err {
+	case blamewarrior.IncorrectFullName:
+		return status.Error(codes.InvalidArgument, "incorrect full name")
+	case blamewarrior.ErrRepositoryNotFound:
+		return status.Error(codes.NotFound, "repository not found")
+	case blamewarrior.ErrRepositoryExists:
+		return status.Error(codes.AlreadyExists, "repository is already tracked")
+	case errProviderNotConfigured:
+		return status.Error(codes.Unimplemented, "provider is not configured")
+	default:
+		log.Printf("%s\t%s\t%v\t%s", "GRPC", method, codes.Internal, err)
+		return status.Error(codes.Internal, fmt.Sprintf("%s failed", method))
+	}
+}
+
+// grpcChange returns audit information for mutations made through gRPC API.
+func grpcChange(actor, requestID string) *blamewarrior.Change {
+	return &blamewarrior.Change{
+		Actor:     actor,
+		RequestID: requestID,
+		Source:    blamewarrior.SourceAPI,
+	}
+}
+
+func repositoriesMessage(repositories []blamewarrior.Repository) *reposv1.ListRepositoriesResponse {
+	response := &reposv1.ListRepositoriesResponse{Repositories: make([]*reposv1.Repository, len(repositories))}
+	for i := range repositories {
+		response.Repositories[i] = repositoryMessage(&repositories[i])
+	}
+
+	return response
+}
+
+func repositoryMessage(repo *blamewarrior.Repository) *reposv1.Repository {
+	msg := &reposv1.Repository{
+		Id:            int64(repo.ID),
+		Owner:         repo.Owner,
+		Name:          repo.Name,
+		Private:       repo.Private,
+		State:         repo.State,
+		DeletedAt:     timestampMessage(repo.DeletedAt),
+		InactiveSince: timestampMessage(repo.InactiveSince),
+		SettingsJson:  string(repo.Settings),
+		Provider:      repo.Provider,
+	}
+
+	if msg.Provider == "" {
+		msg.Provider = blamewarrior.DefaultProvider
+	}
+
+	if md := repo.Metadata; md != nil {
+		msg.Metadata = &reposv1.RepositoryMetadata{
+			Description: md.Description,
+			Language:    md.Language,
+			Topics:      md.Topics,
+			Size:        int32(md.Size),
+			Stars:       int32(md.Stars),
+			PushedAt:    timestampMessage(md.PushedAt),
+			Archived:    md.Archived,
+			ProviderId:  md.ProviderID,
+		}
+	}
+
+	return msg
+}
+
+func timestampMessage(t *time.Time) *timestamp.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	ts, err := ptypes.TimestampProto(*t)
+	if err != nil {
+		return nil
+	}
+
+	return ts
+}
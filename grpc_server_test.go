@@ -0,0 +1,196 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/proto/reposv1"
+)
+
+// grpcTestClient starts gRPC server of handlers and returns a client connected to it.
+func grpcTestClient(t *testing.T, handlers *Handlers) (reposv1.RepositoryServiceClient, func()) {
+	listener := bufconn.Listen(1024 * 1024)
+
+	server := newGRPCServer(handlers)
+	go server.Serve(listener)
+
+	conn, err := grpc.Dial("bufconn", grpc.WithInsecure(), grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	require.NoError(t, err)
+
+	return reposv1.NewRepositoryServiceClient(conn), func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func TestGRPCError(t *testing.T) {
+	results := []struct {
+		Err  error
+		Code codes.Code
+	}{
+		{Err: blamewarrior.IncorrectFullName, Code: codes.InvalidArgument},
+		{Err: blamewarrior.ErrRepositoryNotFound, Code: codes.NotFound},
+		{Err: blamewarrior.ErrRepositoryExists, Code: codes.AlreadyExists},
+		{Err: errProviderNotConfigured, Code: codes.Unimplemented},
+		{Err: errors.New("connection refused"), Code: codes.Internal},
+	}
+
+	for _, result := range results {
+		assert.Equal(t, result.Code, status.Code(grpcError("Get", result.Err)), "%s", result.Err)
+	}
+}
+
+func TestGRPCServer_Validation(t *testing.T) {
+	ghClient := new(githubClientMock)
+	ghClient.On("UserRepositories", mock.Anything, "blamewarrior").Return([]blamewarrior.Repository{{Owner: "blamewarrior", Name: "hooks"}}, nil)
+
+	client, teardown := grpcTestClient(t, &Handlers{ghClient: ghClient})
+	defer teardown()
+
+	ctx := context.Background()
+
+	_, err := client.ListByOwner(ctx, &reposv1.ListByOwnerRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.ListByOwner(ctx, &reposv1.ListByOwnerRequest{Owner: "blamewarrior", State: "archived"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.ListByOwner(ctx, &reposv1.ListByOwnerRequest{Owner: "blamewarrior", Sort: "size"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Lookup(ctx, &reposv1.LookupRequest{Ids: make([]int64, blamewarrior.MaxLookupItems+1)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Create(ctx, &reposv1.CreateRequest{Owner: "blamewarrior"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	response, err := client.ListGithub(ctx, &reposv1.ListGithubRequest{Owner: "blamewarrior"})
+	require.NoError(t, err)
+	require.Len(t, response.Repositories, 1)
+	assert.Equal(t, "hooks", response.Repositories[0].Name)
+	assert.Equal(t, blamewarrior.ProviderGithub, response.Repositories[0].Provider)
+}
+
+func TestGRPCServer(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	hooksClient := new(hooksClientMock)
+	hooksClient.On("CreateHook", "blamewarrior/repos").Return(nil)
+	hooksClient.On("CreateHook", "blamewarrior/hooks").Return(errors.New("hooks service unavailable"))
+	hooksClient.On("DeleteHook", "blamewarrior/repos").Return(nil)
+
+	client, stop := grpcTestClient(t, &Handlers{db: db, hooksClient: hooksClient})
+	defer stop()
+
+	ctx := context.Background()
+
+	created, err := client.Create(ctx, &reposv1.CreateRequest{Owner: "blamewarrior", Name: "repos", Actor: "octocat"})
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.StateActive, created.State)
+
+	_, err = client.Create(ctx, &reposv1.CreateRequest{Owner: "blamewarrior", Name: "repos"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = client.Create(ctx, &reposv1.CreateRequest{Owner: "blamewarrior", Name: "hooks"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	repo, err := client.Get(ctx, &reposv1.GetRequest{FullName: "BlameWarrior/Repos"})
+	require.NoError(t, err)
+	assert.Equal(t, "blamewarrior", repo.Owner)
+	assert.Equal(t, "repos", repo.Name)
+	assert.Equal(t, blamewarrior.ProviderGithub, repo.Provider)
+
+	_, err = client.Get(ctx, &reposv1.GetRequest{FullName: "blamewarrior/hooks"})
+	assert.Equal(t, codes.NotFound, status.Code(err), "repository should not be tracked if its webhook failed")
+
+	_, err = client.Get(ctx, &reposv1.GetRequest{FullName: "blamewarrior"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	listing, err := client.ListByOwner(ctx, &reposv1.ListByOwnerRequest{Owner: "blamewarrior"})
+	require.NoError(t, err)
+	require.Len(t, listing.Repositories, 1)
+	assert.Equal(t, "repos", listing.Repositories[0].Name)
+
+	lookup, err := client.Lookup(ctx, &reposv1.LookupRequest{FullNames: []string{"blamewarrior/repos", "blamewarrior/hooks"}})
+	require.NoError(t, err)
+	require.Len(t, lookup.Results, 2)
+	assert.True(t, lookup.Results[0].Found)
+	assert.Equal(t, "repos", lookup.Results[0].Repository.Name)
+	assert.False(t, lookup.Results[1].Found)
+	assert.Nil(t, lookup.Results[1].Repository)
+
+	_, err = client.Delete(ctx, &reposv1.DeleteRequest{FullName: "blamewarrior/repos"})
+	require.NoError(t, err)
+
+	_, err = client.Delete(ctx, &reposv1.DeleteRequest{FullName: "blamewarrior/repos"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	deleted, err := client.Get(ctx, &reposv1.GetRequest{FullName: "blamewarrior/repos", IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.NotNil(t, deleted.DeletedAt)
+
+	hooksClient.AssertExpectations(t)
+}
+
+func TestGRPCServer_Cached(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+
+	client, stop := grpcTestClient(t, &Handlers{db: db, cache: newRepositoryCache(db, 10, time.Minute)})
+	defer stop()
+
+	ctx := context.Background()
+
+	_, err = client.Get(ctx, &reposv1.GetRequest{FullName: "blamewarrior/repos"})
+	require.NoError(t, err)
+
+	// changes made bypassing the handlers are not seen until cached repository expires
+	_, err = db.Exec("UPDATE repositories SET private = true WHERE name = 'repos'")
+	require.NoError(t, err)
+
+	repo, err := client.Get(ctx, &reposv1.GetRequest{FullName: "blamewarrior/repos"})
+	require.NoError(t, err)
+	assert.False(t, repo.Private)
+
+	repo, err = client.Get(ctx, &reposv1.GetRequest{FullName: "blamewarrior/repos", IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.True(t, repo.Private, "lookups including deleted repositories should not be cached")
+}
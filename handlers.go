@@ -20,41 +20,87 @@
 package main
 
 import (
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/blamewarrior/repos/blamewarrior"
 	"github.com/blamewarrior/repos/blamewarrior/hooks"
+	"github.com/blamewarrior/repos/blamewarrior/stream"
 
 	"github.com/blamewarrior/repos/github"
 )
 
+const (
+	defaultAuditPageSize = 100
+	maxAuditPageSize     = 1000
+)
+
 type Handlers struct {
 	ghClient    github.Client
 	hooksClient hooks.Client
-	db          *sql.DB
+	db          *blamewarrior.DB
+
+	// providers are API clients of code hosting services other than GitHub
+	providers map[string]blamewarrior.Provider
+
+	// webhookSecret is used to verify GitHub webhook payloads, deliveries are rejected if empty
+	webhookSecret []byte
+
+	// broker passes published repository events to event stream connections
+	broker *stream.Broker
+
+	// cache serves repository lookups by full name and by owner if set
+	cache *repositoryCache
+
+	// cacheControl is sent in Cache-Control header of repository resources if set
+	cacheControl string
 }
 
-func (h *Handlers) GetRepositoryByFullName(w http.ResponseWriter, req *http.Request) {
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+// findRepository looks up repository by full name, going through the cache if it is set and
+// deleted repositories are not requested.
+func (h *Handlers) findRepository(fullName string, opts *blamewarrior.ListOptions) (*blamewarrior.Repository, error) {
+	if h.cache != nil && !opts.IncludeDeleted {
+		return h.cache.Repository(fullName)
+	}
+
+	return blamewarrior.GetRepositoryByFullName(h.db.Session(), fullName, opts)
+}
+
+// findOwnerRepositories lists repositories of owner, going through the cache if it is set.
+func (h *Handlers) findOwnerRepositories(owner string, opts *blamewarrior.ListOptions) ([]blamewarrior.Repository, error) {
+	if h.cache != nil {
+		return h.cache.OwnerRepositories(owner, opts)
+	}
 
-	owner := req.URL.Query().Get(":owner")
+	return blamewarrior.GetListRepositoryByOwner(h.db.Session(), owner, opts)
+}
 
-	name := req.URL.Query().Get(":name")
+func (h *Handlers) GetRepositoryByFullName(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 
-	fullName := fmt.Sprintf("%s/%s", owner, name)
+	fullName := repositoryName(req)
 
-	results, err := blamewarrior.GetRepositoryByFullName(h.db, fullName)
+	opts, err := listOptions(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
+	results, err := h.findRepository(fullName, opts)
 	if err != nil {
 
 		if err == blamewarrior.IncorrectFullName {
 			http.Error(w, "Incorrect full name", http.StatusBadRequest)
+		} else if err == blamewarrior.ErrRepositoryNotFound {
+			http.Error(w, "Repository not found", http.StatusNotFound)
 		} else {
 			w.WriteHeader(http.StatusInternalServerError)
 			log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
@@ -62,6 +108,13 @@ func (h *Handlers) GetRepositoryByFullName(w http.ResponseWriter, req *http.Requ
 		return
 	}
 
+	h.setValidators(w, results.ETag(), results.UpdatedAt)
+
+	if notModified(req, results.ETag(), results.UpdatedAt) {
+		respondNotModified(w)
+		return
+	}
+
 	if err := json.NewEncoder(w).Encode(results); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "Error when unmarshalling json")
@@ -76,15 +129,22 @@ func (h *Handlers) GetRepositoryByFullName(w http.ResponseWriter, req *http.Requ
 func (h *Handlers) GetListRepositoryByOwner(w http.ResponseWriter, req *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 
-	owner := req.URL.Query().Get(":owner")
+	owner := requestOwner(req)
 
 	if owner == "" {
 		http.Error(w, "Incorrect owner", http.StatusBadRequest)
 		return
 	}
 
-	results, err := blamewarrior.GetListRepositoryByOwner(h.db, owner)
+	opts, err := listOptions(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts.Provider = requestProvider(req)
 
+	results, err := h.findOwnerRepositories(owner, opts)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
@@ -92,6 +152,17 @@ func (h *Handlers) GetListRepositoryByOwner(w http.ResponseWriter, req *http.Req
 
 	}
 
+	// repositories may leave owner listing without a trace in their modification time, e.g. when
+	// they are transferred or purged, so listing is validated with ETag only
+	etag := repositoriesETag(results)
+
+	h.setValidators(w, etag, time.Time{})
+
+	if notModified(req, etag, time.Time{}) {
+		respondNotModified(w)
+		return
+	}
+
 	if err := json.NewEncoder(w).Encode(results); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprintf(w, "Error when unmarshalling json")
@@ -104,6 +175,8 @@ func (h *Handlers) GetListRepositoryByOwner(w http.ResponseWriter, req *http.Req
 }
 
 func (h *Handlers) CreateRepository(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
 	var err error
 	var body []byte
 
@@ -127,7 +200,14 @@ func (h *Handlers) CreateRepository(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	tx, err := h.db.Begin()
+	if respondAsync(req) {
+		h.createRepositoryAsync(w, req, repository)
+		return
+	}
+
+	repository.State = blamewarrior.StateActive
+
+	tx, err := db.Begin()
 
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -136,13 +216,13 @@ func (h *Handlers) CreateRepository(w http.ResponseWriter, req *http.Request) {
 
 	defer tx.Rollback()
 
-	if err = blamewarrior.CreateRepository(tx, repository); err != nil {
+	if err = blamewarrior.CreateRepository(tx, repository, changeFromRequest(req)); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
 		return
 	}
 
-	if err = h.hooksClient.CreateHook(repository.FullName()); err != nil {
+	if err = h.hooksClient.CreateHook(repository.QualifiedName()); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
 		return
@@ -154,51 +234,307 @@ func (h *Handlers) CreateRepository(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	h.cache.Invalidate(repository.Provider, repository.Owner, repository.Name)
+
 	w.WriteHeader(http.StatusCreated)
 	return
 
 }
 
-func (h Handlers) DeleteRepository(w http.ResponseWriter, req *http.Request) {
-	var err error
+func (h *Handlers) DeleteRepository(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
 
-	owner := req.URL.Query().Get(":owner")
-	name := req.URL.Query().Get(":name")
+	fullName := repositoryName(req)
 
-	repositoryName := owner + "/" + name
+	tx, err := db.Begin()
 
-	tx, err := h.db.Begin()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "DELETE", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
 
-	if err = blamewarrior.DeleteRepository(tx, repositoryName); err != nil {
+	defer tx.Rollback()
+
+	if err = blamewarrior.DeleteRepository(tx, fullName, changeFromRequest(req)); err != nil {
+		switch err {
+		case blamewarrior.IncorrectFullName:
+			http.Error(w, "Incorrect full name", http.StatusBadRequest)
+		case blamewarrior.ErrRepositoryNotFound:
+			http.Error(w, "Repository not found", http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "DELETE", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if err = h.hooksClient.DeleteHook(fullName); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Printf("%s\t%s\t%v\t%s", "DELETE", req.RequestURI, http.StatusInternalServerError, err)
 		return
 	}
 
-	if err = h.hooksClient.DeleteHook(repositoryName); err != nil {
+	if err = tx.Commit(); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Printf("%s\t%s\t%v\t%s", "DELETE", req.RequestURI, http.StatusInternalServerError, err)
 		return
 	}
 
-	defer func() {
-		if err != nil {
-			tx.Rollback()
+	h.cache.Invalidate(requestProvider(req), requestOwner(req), req.URL.Query().Get(":name"))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateRepository applies JSON Merge Patch to a tracked repository. If-Match header is
+// checked against repository ETag when provided.
+func (h *Handlers) UpdateRepository(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	if ct := req.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "application/merge-patch+json") && !strings.HasPrefix(ct, "application/json") {
+		http.Error(w, "Unsupported content type, expected application/merge-patch+json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	patch, err := requestBody(req)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := db.Begin()
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	defer tx.Rollback()
+
+	repository, err := blamewarrior.GetRepositoryByFullName(tx, repositoryName(req))
+
+	if err != nil {
+		switch err {
+		case blamewarrior.IncorrectFullName:
+			http.Error(w, "Incorrect full name", http.StatusBadRequest)
+		case blamewarrior.ErrRepositoryNotFound:
+			http.Error(w, "Repository not found", http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "PATCH", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if ifMatch := req.Header.Get("If-Match"); ifMatch != "" && ifMatch != "*" && ifMatch != repository.ETag() {
+		http.Error(w, "Repository has been modified", http.StatusPreconditionFailed)
+		return
+	}
+
+	updated, err := repository.ApplyMergePatch(patch)
+
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error when updating repository: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if err = updated.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf("Error when updating repository: %s", err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err = blamewarrior.UpdateRepository(tx, updated, changeFromRequest(req)); err != nil {
+		switch err {
+		case blamewarrior.ErrVersionMismatch:
+			http.Error(w, "Repository has been modified", http.StatusPreconditionFailed)
+		case blamewarrior.ErrRepositoryExists:
+			http.Error(w, "Repository is already tracked", http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "PATCH", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if updated.FullName() != repository.FullName() {
+		if err = h.hooksClient.UpdateHook(repository.QualifiedName(), updated.QualifiedName()); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "PATCH", req.RequestURI, http.StatusInternalServerError, err)
 			return
 		}
-		err = tx.Commit()
-	}()
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "PATCH", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.cache.Invalidate(repository.Provider, repository.Owner, repository.Name)
+	h.cache.Invalidate(updated.Provider, updated.Owner, updated.Name)
+
+	w.Header().Set("ETag", updated.ETag())
+
+	if err := json.NewEncoder(w).Encode(updated); err != nil {
+		log.Printf("%s\t%s\t%v\t%s", "PATCH", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
+
+func (h *Handlers) RestoreRepository(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	fullName := repositoryName(req)
+
+	tx, err := db.Begin()
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	defer tx.Rollback()
+
+	if err = blamewarrior.RestoreRepository(tx, fullName, changeFromRequest(req)); err != nil {
+		switch err {
+		case blamewarrior.IncorrectFullName:
+			http.Error(w, "Incorrect full name", http.StatusBadRequest)
+		case blamewarrior.ErrRepositoryNotFound:
+			http.Error(w, "Repository not found", http.StatusNotFound)
+		case blamewarrior.ErrRepositoryExists:
+			http.Error(w, "Repository is already tracked", http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if err = h.hooksClient.CreateHook(fullName); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.cache.Invalidate(requestProvider(req), requestOwner(req), req.URL.Query().Get(":name"))
 
 	w.WriteHeader(http.StatusNoContent)
-	return
+}
 
+func (h *Handlers) GetRepositoryHistory(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	events, err := blamewarrior.GetRepositoryHistory(db, repositoryName(req))
+
+	if err != nil {
+		switch err {
+		case blamewarrior.IncorrectFullName:
+			http.Error(w, "Incorrect full name", http.StatusBadRequest)
+		case blamewarrior.ErrRepositoryNotFound:
+			http.Error(w, "Repository not found", http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if events == nil {
+		events = []blamewarrior.RepositoryEvent{}
+	}
+
+	if err := json.NewEncoder(w).Encode(events); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
 }
 
+// GetAuditEvents returns a page of audit log entries across all owners. Next page URL
+// is passed in Link header.
+func (h *Handlers) GetAuditEvents(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	query := req.URL.Query()
+
+	var (
+		since time.Time
+		after int64
+		limit = defaultAuditPageSize
+		err   error
+	)
+
+	if v := query.Get("since"); v != "" {
+		if since, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "Incorrect since, expected RFC3339 timestamp", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if v := query.Get("after"); v != "" {
+		if after, err = strconv.ParseInt(v, 10, 64); err != nil {
+			http.Error(w, "Incorrect after", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > maxAuditPageSize {
+			http.Error(w, fmt.Sprintf("Incorrect limit, expected a number between 1 and %d", maxAuditPageSize), http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, err := blamewarrior.GetEvents(db, since, after, limit)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	if events == nil {
+		events = []blamewarrior.RepositoryEvent{}
+	}
+
+	if len(events) == limit {
+		next := url.Values{}
+		next.Set("since", since.Format(time.RFC3339))
+		next.Set("after", strconv.FormatInt(events[len(events)-1].ID, 10))
+		next.Set("limit", strconv.Itoa(limit))
+
+		w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, req.URL.Path, next.Encode()))
+	}
+
+	if err := json.NewEncoder(w).Encode(events); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+// GetListGithubRepositories responds with repositories of owner available at provider.
 func (h *Handlers) GetListGithubRepositories(w http.ResponseWriter, req *http.Request) {
-	ctx := github.Context{}
+	provider, err := h.provider(requestProvider(req))
+
+	if err != nil {
+		http.Error(w, "Provider is not configured", http.StatusNotImplemented)
+		return
+	}
 
-	owner := req.URL.Query().Get(":owner")
-	repositories, err := h.ghClient.UserRepositories(ctx, owner)
+	owner := requestOwner(req)
+	repositories, err := provider.UserRepositories(req.Context(), owner)
 
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -213,6 +549,49 @@ func (h *Handlers) GetListGithubRepositories(w http.ResponseWriter, req *http.Re
 	}
 }
 
+// changeFromRequest returns audit information for mutations made through the API.
+func changeFromRequest(req *http.Request) *blamewarrior.Change {
+	return &blamewarrior.Change{
+		Actor:     req.Header.Get("X-Actor"),
+		RequestID: req.Header.Get("X-Request-ID"),
+		Source:    blamewarrior.SourceAPI,
+	}
+}
+
+// listOptions parses repository listing parameters from request query.
+func listOptions(req *http.Request) (*blamewarrior.ListOptions, error) {
+	query := req.URL.Query()
+
+	opts := &blamewarrior.ListOptions{
+		IncludeDeleted: query.Get("include_deleted") == "true",
+		Language:       query.Get("language"),
+		Topic:          query.Get("topic"),
+		State:          query.Get("state"),
+		Sort:           query.Get("sort"),
+	}
+
+	switch opts.State {
+	case "", blamewarrior.StateActive, blamewarrior.StateInactive, blamewarrior.StatePending, blamewarrior.StateFailed:
+	default:
+		return nil, fmt.Errorf("unknown state %s", opts.State)
+	}
+
+	if opts.Sort != "" && !blamewarrior.ValidSortOrder(opts.Sort) {
+		return nil, fmt.Errorf("unknown sort order %s", opts.Sort)
+	}
+
+	if s := query.Get("pushed_since"); s != "" {
+		pushedSince, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, fmt.Errorf("malformed pushed_since, expected RFC 3339 time")
+		}
+
+		opts.PushedSince = pushedSince
+	}
+
+	return opts, nil
+}
+
 func requestBody(r *http.Request) ([]byte, error) {
 	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1048576))
 
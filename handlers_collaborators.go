@@ -0,0 +1,93 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// GetRepositoryCollaborators responds with users and teams that have access to repository.
+// The list can be narrowed down to those having at least the level passed in "permission"
+// query parameter.
+func (h *Handlers) GetRepositoryCollaborators(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	permission := req.URL.Query().Get("permission")
+	if permission != "" && !blamewarrior.ValidPermission(permission) {
+		http.Error(w, "Unknown permission", http.StatusBadRequest)
+		return
+	}
+
+	repository, err := blamewarrior.GetRepositoryByFullName(db, repositoryName(req))
+
+	if err != nil {
+		switch err {
+		case blamewarrior.IncorrectFullName:
+			http.Error(w, "Incorrect full name", http.StatusBadRequest)
+		case blamewarrior.ErrRepositoryNotFound:
+			http.Error(w, "Repository not found", http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	collaborators, err := blamewarrior.GetCollaborators(db, repository.ID, permission)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	if collaborators == nil {
+		collaborators = []blamewarrior.Collaborator{}
+	}
+
+	if err := json.NewEncoder(w).Encode(collaborators); err != nil {
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
+
+// syncCollaborators fetches collaborators of repository from provider and replaces stored ones.
+func syncCollaborators(ctx context.Context, provider blamewarrior.Provider, db blamewarrior.Conn, repo *blamewarrior.Repository) error {
+	collaborators, err := provider.Collaborators(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if err = blamewarrior.ReplaceCollaborators(tx, repo.ID, collaborators); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,107 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestGetRepositoryCollaboratorsHandler(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_collaborators CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+
+	ghClient := new(githubClientMock)
+	ghClient.On("Collaborators", "blamewarrior", "repos").Return([]blamewarrior.Collaborator{
+		{Login: "user1", Type: blamewarrior.CollaboratorUser, Permission: blamewarrior.PermissionAdmin},
+		{Login: "user2", Type: blamewarrior.CollaboratorUser, Permission: blamewarrior.PermissionRead},
+		{Login: "core", Type: blamewarrior.CollaboratorTeam, Permission: blamewarrior.PermissionWrite},
+	}, nil)
+
+	handlers := &Handlers{
+		db:            db,
+		hooksClient:   new(hooksClientMock),
+		ghClient:      ghClient,
+		webhookSecret: []byte("secret"),
+	}
+
+	// collaborators are synced when a member webhook is received
+	payload := `{"action":"added","member":{"login":"user2"},"repository":{"full_name":"blamewarrior/repos"}}`
+	req, err := http.NewRequest("POST", "/webhooks/github", strings.NewReader(payload))
+	require.NoError(t, err)
+	req.Header.Set("X-GitHub-Event", "member")
+	signWebhookPayload(req, "secret", payload)
+
+	w := httptest.NewRecorder()
+	handlers.GithubWebhook(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	ghClient.AssertNumberOfCalls(t, "Collaborators", 1)
+
+	results := []struct {
+		Permission   string
+		ResponseCode int
+		Logins       []string
+	}{
+		{Permission: "", ResponseCode: http.StatusOK, Logins: []string{"user1", "user2", "core"}},
+		{Permission: "write", ResponseCode: http.StatusOK, Logins: []string{"user1", "core"}},
+		{Permission: "admin", ResponseCode: http.StatusOK, Logins: []string{"user1"}},
+		{Permission: "owner", ResponseCode: http.StatusBadRequest},
+	}
+
+	for _, result := range results {
+		urlValues := make(url.Values)
+		urlValues[":owner"] = []string{"blamewarrior"}
+		urlValues[":name"] = []string{"repos"}
+		urlValues.Set("permission", result.Permission)
+
+		req, err := http.NewRequest("GET", "/repositories?"+urlValues.Encode(), nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handlers.GetRepositoryCollaborators(w, req)
+
+		require.Equal(t, result.ResponseCode, w.Code, result.Permission)
+
+		if result.ResponseCode != http.StatusOK {
+			continue
+		}
+
+		var collaborators []blamewarrior.Collaborator
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &collaborators))
+
+		var logins []string
+		for _, c := range collaborators {
+			logins = append(logins, c.Login)
+		}
+
+		assert.Equal(t, result.Logins, logins, result.Permission)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/github"
+)
+
+// RefreshRepositoryConfig reloads BlameWarrior configuration file from repository. Errors
+// in configuration file are stored along with repository and do not fail the request.
+func (h *Handlers) RefreshRepositoryConfig(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	tx, err := db.Begin()
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	defer tx.Rollback()
+
+	repository, err := blamewarrior.GetRepositoryByFullName(tx, repositoryName(req))
+
+	if err != nil {
+		switch err {
+		case blamewarrior.IncorrectFullName:
+			http.Error(w, "Incorrect full name", http.StatusBadRequest)
+		case blamewarrior.ErrRepositoryNotFound:
+			http.Error(w, "Repository not found", http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if repository.Provider != blamewarrior.ProviderGithub {
+		http.Error(w, "Not supported for repositories hosted at "+repository.Provider, http.StatusNotImplemented)
+		return
+	}
+
+	ctx := github.Context{Context: req.Context()}
+
+	if err = h.refreshConfig(ctx, tx, repository, changeFromRequest(req)); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(repository.Config); err != nil {
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
+
+// refreshConfig loads configuration file of repository from GitHub and stores it. If the file
+// is invalid, previously loaded settings are kept and the error is recorded instead.
+func (h *Handlers) refreshConfig(ctx github.Context, runner blamewarrior.SQLRunner, repo *blamewarrior.Repository, change *blamewarrior.Change) error {
+	config, err := h.ghClient.RepositoryConfig(ctx, repo.Owner, repo.Name)
+
+	if configErr, ok := err.(*github.ConfigError); ok {
+		config = &blamewarrior.RepositoryConfig{
+			CommitSHA:   configErr.CommitSHA,
+			Error:       configErr.Error(),
+			RefreshedAt: time.Now(),
+		}
+
+		if repo.Config != nil {
+			config.Settings = repo.Config.Settings
+		}
+	} else if err != nil {
+		return err
+	}
+
+	return blamewarrior.UpdateRepositoryConfig(runner, repo, config, change)
+}
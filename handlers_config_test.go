@@ -0,0 +1,89 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/github"
+)
+
+func TestRefreshRepositoryConfigHandler(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+
+	ghClient := new(githubClientMock)
+	ghClient.On("RepositoryConfig", "blamewarrior", "repos").Return(&blamewarrior.RepositoryConfig{
+		Settings:  json.RawMessage(`{"version":1,"ignored_paths":["vendor/"]}`),
+		CommitSHA: "abc123",
+	}, nil).Once()
+	ghClient.On("RepositoryConfig", "blamewarrior", "repos").Return(nil, &github.ConfigError{
+		CommitSHA: "def456",
+		Err:       assert.AnError,
+	}).Once()
+
+	handlers := &Handlers{
+		db:          db,
+		hooksClient: new(hooksClientMock),
+		ghClient:    ghClient,
+	}
+
+	urlValues := make(url.Values)
+	urlValues[":owner"] = []string{"blamewarrior"}
+	urlValues[":name"] = []string{"repos"}
+
+	results := []struct {
+		CommitSHA string
+		Error     string
+	}{
+		{CommitSHA: "abc123"},
+		{CommitSHA: "def456", Error: ".blamewarrior.yml: " + assert.AnError.Error()},
+	}
+
+	for _, result := range results {
+		req, err := http.NewRequest("POST", "/repositories?"+urlValues.Encode(), nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		handlers.RefreshRepositoryConfig(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var config blamewarrior.RepositoryConfig
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &config))
+
+		assert.Equal(t, result.CommitSHA, config.CommitSHA)
+		assert.Equal(t, result.Error, config.Error)
+		// settings from the last valid configuration file are kept
+		assert.JSONEq(t, `{"version":1,"ignored_paths":["vendor/"]}`, string(config.Settings))
+	}
+
+	ghClient.AssertExpectations(t)
+}
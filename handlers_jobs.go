@@ -0,0 +1,141 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+const (
+	defaultJobsPageSize = 50
+	maxJobsPageSize     = 500
+)
+
+// GetJobs responds with background jobs in the order they were enqueued, optionally narrowed
+// down to jobs of given state and type. Jobs are paginated by ID using Link header.
+func (h *Handlers) GetJobs(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	query := req.URL.Query()
+
+	var (
+		after int64
+		limit = defaultJobsPageSize
+		err   error
+	)
+
+	state, jobType := query.Get("state"), query.Get("type")
+
+	if v := query.Get("after"); v != "" {
+		if after, err = strconv.ParseInt(v, 10, 64); err != nil {
+			http.Error(w, "Incorrect after", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > maxJobsPageSize {
+			http.Error(w, fmt.Sprintf("Incorrect limit, expected a number between 1 and %d", maxJobsPageSize), http.StatusBadRequest)
+			return
+		}
+	}
+
+	jobs, err := blamewarrior.GetJobs(db, state, jobType, after, limit)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	if jobs == nil {
+		jobs = []blamewarrior.Job{}
+	}
+
+	if len(jobs) == limit {
+		next := url.Values{}
+		if state != "" {
+			next.Set("state", state)
+		}
+		if jobType != "" {
+			next.Set("type", jobType)
+		}
+		next.Set("after", strconv.FormatInt(jobs[len(jobs)-1].ID, 10))
+		next.Set("limit", strconv.Itoa(limit))
+
+		w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, req.URL.Path, next.Encode()))
+	}
+
+	if err := json.NewEncoder(w).Encode(jobs); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
+
+// GetJob responds with a single background job.
+func (h *Handlers) GetJob(w http.ResponseWriter, req *http.Request) {
+	h.respondWithJob(w, req, "GET", blamewarrior.GetJob)
+}
+
+// RetryJob schedules dead or cancelled job to run again with all of its attempts available.
+func (h *Handlers) RetryJob(w http.ResponseWriter, req *http.Request) {
+	h.respondWithJob(w, req, "POST", blamewarrior.RetryJob)
+}
+
+// CancelJob stops pending or running job from being run again.
+func (h *Handlers) CancelJob(w http.ResponseWriter, req *http.Request) {
+	h.respondWithJob(w, req, "POST", blamewarrior.CancelJob)
+}
+
+func (h *Handlers) respondWithJob(w http.ResponseWriter, req *http.Request, method string, fn func(blamewarrior.SQLRunner, int64) (*blamewarrior.Job, error)) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	id, err := strconv.ParseInt(req.URL.Query().Get(":id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Incorrect job id", http.StatusBadRequest)
+		return
+	}
+
+	job, err := fn(db, id)
+
+	if err != nil {
+		switch err {
+		case blamewarrior.ErrJobNotFound:
+			http.Error(w, "Job not found", http.StatusNotFound)
+		case blamewarrior.ErrJobState:
+			http.Error(w, "Operation is not allowed in current job state", http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", method, req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		log.Printf("%s\t%s\t%v\t%s", method, req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestGetJobsHandler(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE jobs;")
+	require.NoError(t, err)
+
+	for _, jobType := range []string{jobCreateHook, jobReconcileRepository, jobCreateHook} {
+		require.NoError(t, blamewarrior.EnqueueJob(db, &blamewarrior.Job{Type: jobType}))
+	}
+
+	handlers := &Handlers{db: db}
+
+	results := []struct {
+		Query        string
+		ResponseCode int
+		Types        []string
+		Link         bool
+	}{
+		{Query: "", ResponseCode: http.StatusOK, Types: []string{jobCreateHook, jobReconcileRepository, jobCreateHook}},
+		{Query: "type=create_hook&limit=1", ResponseCode: http.StatusOK, Types: []string{jobCreateHook}, Link: true},
+		{Query: "state=dead", ResponseCode: http.StatusOK, Types: []string{}},
+		{Query: "limit=1000", ResponseCode: http.StatusBadRequest},
+		{Query: "after=abc", ResponseCode: http.StatusBadRequest},
+	}
+
+	for _, result := range results {
+		req, err := http.NewRequest("GET", "/jobs?"+result.Query, nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handlers.GetJobs(w, req)
+
+		require.Equal(t, result.ResponseCode, w.Code, result.Query)
+		assert.Equal(t, result.Link, w.Header().Get("Link") != "", result.Query)
+
+		if w.Code != http.StatusOK {
+			continue
+		}
+
+		var jobs []blamewarrior.Job
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &jobs))
+
+		types := []string{}
+		for _, job := range jobs {
+			types = append(types, job.Type)
+		}
+
+		assert.Equal(t, result.Types, types, result.Query)
+	}
+}
+
+func TestRetryAndCancelJobHandlers(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE jobs;")
+	require.NoError(t, err)
+
+	job := &blamewarrior.Job{Type: jobCreateHook}
+	require.NoError(t, blamewarrior.EnqueueJob(db, job))
+
+	handlers := &Handlers{db: db}
+	id := fmt.Sprint(job.ID)
+
+	results := []struct {
+		Handler      http.HandlerFunc
+		ID           string
+		ResponseCode int
+		State        string
+	}{
+		{Handler: handlers.RetryJob, ID: id, ResponseCode: http.StatusConflict},
+		{Handler: handlers.CancelJob, ID: id, ResponseCode: http.StatusOK, State: blamewarrior.JobCancelled},
+		{Handler: handlers.CancelJob, ID: id, ResponseCode: http.StatusConflict},
+		{Handler: handlers.RetryJob, ID: id, ResponseCode: http.StatusOK, State: blamewarrior.JobPending},
+		{Handler: handlers.GetJob, ID: id, ResponseCode: http.StatusOK, State: blamewarrior.JobPending},
+		{Handler: handlers.GetJob, ID: fmt.Sprint(job.ID + 1), ResponseCode: http.StatusNotFound},
+		{Handler: handlers.CancelJob, ID: "abc", ResponseCode: http.StatusBadRequest},
+	}
+
+	for i, result := range results {
+		urlValues := make(url.Values)
+		urlValues[":id"] = []string{result.ID}
+
+		req, err := http.NewRequest("POST", "/jobs/"+result.ID+"?"+urlValues.Encode(), nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		result.Handler(w, req)
+
+		require.Equal(t, result.ResponseCode, w.Code, "step %d", i)
+
+		if result.State == "" {
+			continue
+		}
+
+		var response blamewarrior.Job
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, result.State, response.State, "step %d", i)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// LookupRepositories responds with tracked repositories with given full names and IDs at provider
+// in a single round trip. Results follow the order of full names and then IDs, repositories that
+// are not tracked are marked as not found.
+func (h *Handlers) LookupRepositories(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	body, err := requestBody(req)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var payload struct {
+		FullNames []string `json:"full_names"`
+		IDs       []int64  `json:"ids"`
+	}
+
+	if err = json.Unmarshal(body, &payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "Error when unmarshalling json")
+		return
+	}
+
+	if n := len(payload.FullNames) + len(payload.IDs); n > blamewarrior.MaxLookupItems {
+		http.Error(w, fmt.Sprintf("Too many repositories, expected at most %d", blamewarrior.MaxLookupItems), http.StatusUnprocessableEntity)
+		return
+	}
+
+	results, err := blamewarrior.LookupRepositories(db, requestProvider(req), payload.FullNames, payload.IDs)
+
+	if err != nil {
+		if err == blamewarrior.IncorrectFullName {
+			http.Error(w, "Incorrect full name", http.StatusUnprocessableEntity)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
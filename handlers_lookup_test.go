@@ -0,0 +1,90 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestLookupRepositoriesHandler(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
+	require.NoError(t, blamewarrior.UpdateRepositoryMetadata(db, repo, &blamewarrior.RepositoryMetadata{ProviderID: 42, RefreshedAt: time.Now()}))
+
+	handlers := &Handlers{db: db}
+
+	tooMany := make([]string, blamewarrior.MaxLookupItems+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("blamewarrior/repo%d", i)
+	}
+
+	tooManyBody, err := json.Marshal(map[string][]string{"full_names": tooMany})
+	require.NoError(t, err)
+
+	results := []struct {
+		Body         string
+		ResponseCode int
+		Found        []bool
+	}{
+		{Body: `{"full_names": ["blamewarrior/repos", "blamewarrior/missing"], "ids": [42, 43]}`, ResponseCode: http.StatusOK, Found: []bool{true, false, true, false}},
+		{Body: `{}`, ResponseCode: http.StatusOK, Found: []bool{}},
+		{Body: `{"full_names": ["blamewarrior"]}`, ResponseCode: http.StatusUnprocessableEntity},
+		{Body: string(tooManyBody), ResponseCode: http.StatusUnprocessableEntity},
+		{Body: `{"full_names":`, ResponseCode: http.StatusBadRequest},
+	}
+
+	for _, result := range results {
+		req, err := http.NewRequest("POST", "/repositories/lookup", strings.NewReader(result.Body))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handlers.LookupRepositories(w, req)
+
+		require.Equal(t, result.ResponseCode, w.Code, result.Body)
+
+		if w.Code != http.StatusOK {
+			continue
+		}
+
+		var response []blamewarrior.LookupResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		found := []bool{}
+		for _, r := range response {
+			found = append(found, r.Found)
+			assert.Equal(t, r.Found, r.Repository != nil)
+		}
+
+		assert.Equal(t, result.Found, found, result.Body)
+	}
+}
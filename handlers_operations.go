@@ -0,0 +1,143 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// respondAsync reports whether client prefers request to be processed asynchronously, see
+// RFC 7240.
+func respondAsync(req *http.Request) bool {
+	for _, v := range req.Header["Prefer"] {
+		for _, pref := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(strings.SplitN(pref, ";", 2)[0]), "respond-async") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// createRepositoryAsync stores repository as pending and leaves creation of its webhook to a
+// background job enqueued in the same transaction, so that there are no pending repositories
+// left without a job. It responds with the operation that can be polled for the outcome.
+func (h *Handlers) createRepositoryAsync(w http.ResponseWriter, req *http.Request, repository *blamewarrior.Repository) {
+	db := h.db.Session()
+
+	repository.State = blamewarrior.StatePending
+
+	change := changeFromRequest(req)
+
+	tx, err := db.Begin()
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	defer tx.Rollback()
+
+	if err = blamewarrior.CreateRepository(tx, repository, change); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	job, err := newRepositoryJob(jobCreateHook, repository, change)
+
+	if err == nil {
+		err = blamewarrior.EnqueueJob(tx, job)
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.cache.Invalidate(repository.Provider, repository.Owner, repository.Name)
+
+	w.Header().Set("Location", fmt.Sprintf("/operations/%d", job.ID))
+	w.Header().Set("Preference-Applied", "respond-async")
+	w.WriteHeader(http.StatusAccepted)
+
+	op := blamewarrior.NewOperation(job)
+	op.RepositoryState = repository.State
+
+	if err := json.NewEncoder(w).Encode(op); err != nil {
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
+
+// GetOperation responds with progress of a request processed in the background along with
+// the current state of repository it is performed on. Operations are read from the primary
+// since they are polled right after being started.
+func (h *Handlers) GetOperation(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	id, err := strconv.ParseInt(req.URL.Query().Get(":id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Incorrect operation id", http.StatusBadRequest)
+		return
+	}
+
+	job, err := blamewarrior.GetJob(h.db.Primary(), id)
+
+	if err != nil {
+		if err == blamewarrior.ErrJobNotFound {
+			http.Error(w, "Operation not found", http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	op := blamewarrior.NewOperation(job)
+
+	if op.Repository != "" {
+		repo, err := blamewarrior.GetRepositoryByFullName(h.db.Primary(), op.Repository)
+
+		switch err {
+		case nil:
+			op.RepositoryState = repo.State
+		case blamewarrior.ErrRepositoryNotFound, blamewarrior.IncorrectFullName:
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(op); err != nil {
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,186 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/jobs"
+)
+
+func TestRespondAsync(t *testing.T) {
+	examples := []struct {
+		Prefer   []string
+		Expected bool
+	}{
+		{Prefer: nil, Expected: false},
+		{Prefer: []string{"respond-async"}, Expected: true},
+		{Prefer: []string{"return=minimal, Respond-Async; wait=10"}, Expected: true},
+		{Prefer: []string{"return=minimal", "respond-async"}, Expected: true},
+		{Prefer: []string{"return=representation"}, Expected: false},
+	}
+
+	for _, example := range examples {
+		req, err := http.NewRequest("POST", "/repositories", nil)
+		require.NoError(t, err)
+
+		req.Header["Prefer"] = example.Prefer
+
+		assert.Equal(t, example.Expected, respondAsync(req), "%v", example.Prefer)
+	}
+}
+
+func TestCreateRepositoryHandler_Async(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, jobs CASCADE;")
+	require.NoError(t, err)
+
+	hooksClient := new(hooksClientMock)
+	hooksClient.On("CreateHook", "blamewarrior/repos").Return(nil)
+	hooksClient.On("CreateHook", "blamewarrior/hooks").Return(errors.New("hook already exists"))
+
+	handlers := &Handlers{db: db, hooksClient: hooksClient}
+
+	pool := jobs.NewPool(db, handlers.jobHandlers())
+	pool.Backoff = func(int) time.Duration { return -time.Second }
+	pool.Dead = handlers.deadJobHandlers()
+
+	results := []struct {
+		Name   string
+		Status string
+		State  string
+		Error  string
+	}{
+		{Name: "repos", Status: blamewarrior.OperationSucceeded, State: blamewarrior.StateActive},
+		{Name: "hooks", Status: blamewarrior.OperationFailed, State: blamewarrior.StateFailed, Error: "hook already exists"},
+	}
+
+	for _, result := range results {
+		body := fmt.Sprintf(`{"owner": "blamewarrior", "name": %q}`, result.Name)
+
+		req, err := http.NewRequest("POST", "/repositories", strings.NewReader(body))
+		require.NoError(t, err)
+
+		req.Header.Set("Prefer", "respond-async")
+		req.Header.Set("X-Actor", "octocat")
+
+		w := httptest.NewRecorder()
+		handlers.CreateRepository(w, req)
+
+		require.Equal(t, http.StatusAccepted, w.Code, result.Name)
+		assert.Equal(t, "respond-async", w.Header().Get("Preference-Applied"), result.Name)
+
+		var op blamewarrior.Operation
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &op))
+
+		assert.Equal(t, fmt.Sprintf("/operations/%d", op.ID), w.Header().Get("Location"), result.Name)
+		assert.Equal(t, blamewarrior.OperationRunning, op.Status, result.Name)
+		assert.Equal(t, "blamewarrior/"+result.Name, op.Repository, result.Name)
+		assert.Equal(t, blamewarrior.StatePending, op.RepositoryState, result.Name)
+
+		repo, err := blamewarrior.GetRepositoryByFullName(db, "blamewarrior/"+result.Name)
+		require.NoError(t, err)
+		assert.Equal(t, blamewarrior.StatePending, repo.State, result.Name)
+
+		for {
+			ran, err := pool.RunOnce(context.Background())
+			require.NoError(t, err)
+
+			if !ran {
+				break
+			}
+		}
+
+		urlValues := make(url.Values)
+		urlValues[":id"] = []string{fmt.Sprint(op.ID)}
+
+		req, err = http.NewRequest("GET", fmt.Sprintf("/operations/%d?%s", op.ID, urlValues.Encode()), nil)
+		require.NoError(t, err)
+
+		w = httptest.NewRecorder()
+		handlers.GetOperation(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code, result.Name)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &op))
+
+		assert.Equal(t, result.Status, op.Status, result.Name)
+		assert.Equal(t, result.Error, op.Error, result.Name)
+		assert.Equal(t, result.State, op.RepositoryState, result.Name)
+
+		repo, err = blamewarrior.GetRepositoryByFullName(db, "blamewarrior/"+result.Name)
+		require.NoError(t, err)
+		assert.Equal(t, result.State, repo.State, result.Name)
+	}
+
+	history, err := blamewarrior.GetRepositoryHistory(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, blamewarrior.SourceJob, history[1].Source)
+	assert.Equal(t, "octocat", history[1].Actor)
+}
+
+func TestGetOperationHandler(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE jobs;")
+	require.NoError(t, err)
+
+	job, err := blamewarrior.NewJob(jobCreateHook, repositoryJobPayload{Repository: "blamewarrior/repos"})
+	require.NoError(t, err)
+	require.NoError(t, blamewarrior.EnqueueJob(db, job))
+
+	handlers := &Handlers{db: db}
+
+	results := []struct {
+		ID           string
+		ResponseCode int
+		ResponseBody string
+	}{
+		{ID: fmt.Sprint(job.ID), ResponseCode: http.StatusOK, ResponseBody: `"status":"running","repository":"blamewarrior/repos"`},
+		{ID: fmt.Sprint(job.ID + 1), ResponseCode: http.StatusNotFound, ResponseBody: "Operation not found"},
+		{ID: "abc", ResponseCode: http.StatusBadRequest, ResponseBody: "Incorrect operation id"},
+	}
+
+	for _, result := range results {
+		urlValues := make(url.Values)
+		urlValues[":id"] = []string{result.ID}
+
+		req, err := http.NewRequest("GET", "/operations/"+result.ID+"?"+urlValues.Encode(), nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handlers.GetOperation(w, req)
+
+		require.Equal(t, result.ResponseCode, w.Code, result.ID)
+		assert.Contains(t, w.Body.String(), result.ResponseBody, result.ID)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/github"
+)
+
+// GetFileOwners responds with owners of a file in repository according to its CODEOWNERS.
+// File path is passed in "path" query parameter.
+func (h *Handlers) GetFileOwners(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	path := strings.TrimPrefix(req.URL.Query().Get("path"), "/")
+	if path == "" {
+		http.Error(w, "Missing path", http.StatusBadRequest)
+		return
+	}
+
+	repository, err := blamewarrior.GetRepositoryByFullName(db, repositoryName(req))
+
+	if err != nil {
+		switch err {
+		case blamewarrior.IncorrectFullName:
+			http.Error(w, "Incorrect full name", http.StatusBadRequest)
+		case blamewarrior.ErrRepositoryNotFound:
+			http.Error(w, "Repository not found", http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	codeOwners, err := blamewarrior.GetCodeOwners(db, repository.ID)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	result := struct {
+		Path    string   `json:"path"`
+		Owners  []string `json:"owners"`
+		Pattern string   `json:"pattern,omitempty"`
+		Line    int      `json:"line,omitempty"`
+	}{Path: path, Owners: []string{}}
+
+	if codeOwners != nil {
+		if rule := codeOwners.Match(path); rule != nil {
+			result.Owners, result.Pattern, result.Line = rule.Owners, rule.Pattern, rule.Line
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
+
+// RefreshCodeOwners reloads CODEOWNERS file from repository.
+func (h *Handlers) RefreshCodeOwners(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	repository, err := blamewarrior.GetRepositoryByFullName(db, repositoryName(req))
+
+	if err != nil {
+		switch err {
+		case blamewarrior.IncorrectFullName:
+			http.Error(w, "Incorrect full name", http.StatusBadRequest)
+		case blamewarrior.ErrRepositoryNotFound:
+			http.Error(w, "Repository not found", http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if repository.Provider != blamewarrior.ProviderGithub {
+		http.Error(w, "Not supported for repositories hosted at "+repository.Provider, http.StatusNotImplemented)
+		return
+	}
+
+	ctx := github.Context{Context: req.Context()}
+
+	if err = h.refreshCodeOwners(ctx, db, repository); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	codeOwners, err := blamewarrior.GetCodeOwners(db, repository.ID)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(codeOwners); err != nil {
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
+
+// refreshCodeOwners loads CODEOWNERS file of repository from GitHub and stores it.
+func (h *Handlers) refreshCodeOwners(ctx github.Context, runner blamewarrior.SQLRunner, repo *blamewarrior.Repository) error {
+	codeOwners, err := h.ghClient.CodeOwners(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		return err
+	}
+
+	return blamewarrior.UpdateCodeOwners(runner, repo.ID, codeOwners)
+}
@@ -0,0 +1,107 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestFileOwnersHandlers(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, code_owners CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+
+	rules, errs := blamewarrior.ParseCodeOwners("* @blamewarrior/core\n*.go @gopher\n/vendor/\n")
+	require.Empty(t, errs)
+
+	ghClient := new(githubClientMock)
+	ghClient.On("CodeOwners", "blamewarrior", "repos").Return(&blamewarrior.CodeOwners{
+		Path:      ".github/CODEOWNERS",
+		CommitSHA: "abc123",
+		Rules:     rules,
+	}, nil)
+
+	handlers := &Handlers{
+		db:          db,
+		hooksClient: new(hooksClientMock),
+		ghClient:    ghClient,
+	}
+
+	urlValues := make(url.Values)
+	urlValues[":owner"] = []string{"blamewarrior"}
+	urlValues[":name"] = []string{"repos"}
+
+	req, err := http.NewRequest("POST", "/repositories?"+urlValues.Encode(), nil)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handlers.RefreshCodeOwners(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	results := []struct {
+		Path         string
+		ResponseCode int
+		Owners       []string
+		Line         int
+	}{
+		{Path: "", ResponseCode: http.StatusBadRequest},
+		{Path: "README.md", ResponseCode: http.StatusOK, Owners: []string{"@blamewarrior/core"}, Line: 1},
+		{Path: "cmd/main.go", ResponseCode: http.StatusOK, Owners: []string{"@gopher"}, Line: 2},
+		{Path: "vendor/lib/lib.go", ResponseCode: http.StatusOK, Owners: []string{}, Line: 3},
+	}
+
+	for _, result := range results {
+		query := make(url.Values)
+		for k, v := range urlValues {
+			query[k] = v
+		}
+		query.Set("path", result.Path)
+
+		req, err := http.NewRequest("GET", "/repositories?"+query.Encode(), nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handlers.GetFileOwners(w, req)
+
+		require.Equal(t, result.ResponseCode, w.Code, result.Path)
+
+		if result.ResponseCode != http.StatusOK {
+			continue
+		}
+
+		var response struct {
+			Owners []string `json:"owners"`
+			Line   int      `json:"line"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		assert.Equal(t, result.Owners, response.Owners, result.Path)
+		assert.Equal(t, result.Line, response.Line, result.Path)
+	}
+}
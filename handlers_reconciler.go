@@ -0,0 +1,77 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// ReconcileRepository brings data fetched from provider up to date for a single repository
+// without waiting for the periodic reconciliation and responds with the result.
+func (h *Handlers) ReconcileRepository(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	repository, err := blamewarrior.GetRepositoryByFullName(db, repositoryName(req))
+
+	if err != nil {
+		switch err {
+		case blamewarrior.IncorrectFullName:
+			http.Error(w, "Incorrect full name", http.StatusBadRequest)
+		case blamewarrior.ErrRepositoryNotFound:
+			http.Error(w, "Repository not found", http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	provider, err := h.provider(repository.Provider)
+
+	if err != nil {
+		http.Error(w, "Provider is not configured", http.StatusNotImplemented)
+		return
+	}
+
+	if err = reconcileRepository(req.Context(), provider, h.ghClient, h.hooksClient, db, repository); err != nil {
+		if err == blamewarrior.ErrRateLimitReached {
+			http.Error(w, "Provider API rate limit reached", http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	// repository could have been untracked by staleness policy
+	repository, err = blamewarrior.GetRepositoryByFullName(db, repository.QualifiedName(), &blamewarrior.ListOptions{IncludeDeleted: true})
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(repository); err != nil {
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/github"
+)
+
+func TestReconcileRepositoryHandler(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events, repository_collaborators CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"}))
+
+	ghClient := new(githubClientMock)
+	ghClient.On("RepositoryMetadata", "blamewarrior", "repos").Return(&blamewarrior.RepositoryMetadata{Language: "Go", RefreshedAt: time.Now()}, nil)
+	ghClient.On("DefaultBranch", "blamewarrior", "repos").Return(&blamewarrior.DefaultBranch{Name: "master"}, nil)
+	ghClient.On("Collaborators", "blamewarrior", "repos").Return([]blamewarrior.Collaborator{}, nil)
+	ghClient.On("RepositoryMetadata", "blamewarrior", "hooks").Return(nil, github.ErrRateLimitReached)
+
+	handlers := &Handlers{
+		db:          db,
+		hooksClient: new(hooksClientMock),
+		ghClient:    ghClient,
+	}
+
+	results := []struct {
+		Name         string
+		ResponseCode int
+	}{
+		{Name: "repos", ResponseCode: http.StatusOK},
+		{Name: "hooks", ResponseCode: http.StatusServiceUnavailable},
+		{Name: "missing", ResponseCode: http.StatusNotFound},
+	}
+
+	for _, result := range results {
+		urlValues := make(url.Values)
+		urlValues[":owner"] = []string{"blamewarrior"}
+		urlValues[":name"] = []string{result.Name}
+
+		req, err := http.NewRequest("POST", "/repositories/blamewarrior/"+result.Name+"/reconcile?"+urlValues.Encode(), nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handlers.ReconcileRepository(w, req)
+
+		require.Equal(t, result.ResponseCode, w.Code, result.Name)
+
+		if result.ResponseCode != http.StatusOK {
+			continue
+		}
+
+		var repo blamewarrior.Repository
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &repo))
+
+		require.NotNil(t, repo.Metadata)
+		assert.Equal(t, "Go", repo.Metadata.Language)
+		require.NotNil(t, repo.DefaultBranch)
+		assert.Equal(t, "master", repo.DefaultBranch.Name)
+	}
+}
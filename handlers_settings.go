@@ -0,0 +1,177 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// GetRepositorySettings responds with repository settings merged into owner defaults.
+func (h *Handlers) GetRepositorySettings(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	settings, err := blamewarrior.GetRepositorySettings(db, repositoryName(req))
+
+	if err != nil {
+		switch err {
+		case blamewarrior.IncorrectFullName:
+			http.Error(w, "Incorrect full name", http.StatusBadRequest)
+		case blamewarrior.ErrRepositoryNotFound:
+			http.Error(w, "Repository not found", http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	writeSettings(w, settings)
+}
+
+// UpdateRepositorySettings replaces repository settings.
+func (h *Handlers) UpdateRepositorySettings(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	settings, err := requestBody(req)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := db.Begin()
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	defer tx.Rollback()
+
+	repository, err := blamewarrior.GetRepositoryByFullName(tx, repositoryName(req))
+
+	if err != nil {
+		switch err {
+		case blamewarrior.IncorrectFullName:
+			http.Error(w, "Incorrect full name", http.StatusBadRequest)
+		case blamewarrior.ErrRepositoryNotFound:
+			http.Error(w, "Repository not found", http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "PUT", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	repository.Settings = settings
+
+	if err = repository.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf("Error when updating settings: %s", err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err = blamewarrior.UpdateRepository(tx, repository, changeFromRequest(req)); err != nil {
+		switch err {
+		case blamewarrior.ErrVersionMismatch:
+			http.Error(w, "Repository has been modified", http.StatusPreconditionFailed)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "PUT", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "PUT", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeSettings(w, repository.Settings)
+}
+
+// GetOwnerSettings responds with default settings for repositories of an owner at provider.
+func (h *Handlers) GetOwnerSettings(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	provider, owner := requestProvider(req), requestOwner(req)
+
+	if !blamewarrior.ValidProvider(provider) {
+		http.NotFound(w, req)
+		return
+	}
+
+	settings, err := blamewarrior.GetOwnerSettings(db, provider, owner)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeSettings(w, settings)
+}
+
+// UpdateOwnerSettings replaces default settings for repositories of an owner at provider.
+func (h *Handlers) UpdateOwnerSettings(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	provider, owner := requestProvider(req), requestOwner(req)
+
+	if !blamewarrior.ValidProvider(provider) {
+		http.NotFound(w, req)
+		return
+	}
+
+	settings, err := requestBody(req)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err = blamewarrior.ValidateSettings(settings); err != nil {
+		http.Error(w, fmt.Sprintf("Error when updating settings: %s", err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err = blamewarrior.UpdateOwnerSettings(db, provider, owner, settings); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "PUT", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeSettings(w, settings)
+}
+
+func writeSettings(w http.ResponseWriter, settings json.RawMessage) {
+	if len(settings) == 0 {
+		settings = json.RawMessage(`{}`)
+	}
+
+	w.Write(settings)
+}
@@ -0,0 +1,116 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestRepositorySettingsHandlers(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, owner_settings CASCADE;")
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
+
+	require.NoError(t, blamewarrior.UpdateOwnerSettings(db, blamewarrior.ProviderGithub, "blamewarrior", json.RawMessage(`{"version":1,"ignored_paths":["vendor/"]}`)))
+
+	handlers := &Handlers{
+		db:          db,
+		hooksClient: new(hooksClientMock),
+		ghClient:    new(githubClientMock),
+	}
+
+	results := []struct {
+		Method       string
+		Name         string
+		RequestBody  string
+		ResponseCode int
+		ResponseBody string
+	}{
+		{
+			Method:       "GET",
+			Name:         "repos",
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"version":1,"ignored_paths":["vendor/"]}`,
+		},
+		{
+			Method:       "PUT",
+			Name:         "repos",
+			RequestBody:  `{"version":1,"reviewers":{"count":"two"}}`,
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: "Error when updating settings: invalid settings: reviewers.count must be integer\n",
+		},
+		{
+			Method:       "PUT",
+			Name:         "repos",
+			RequestBody:  `{"version":1,"reviewers":{"count":2}}`,
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"version":1,"reviewers":{"count":2}}`,
+		},
+		{
+			Method:       "GET",
+			Name:         "repos",
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"version":1,"ignored_paths":["vendor/"],"reviewers":{"count":2}}`,
+		},
+		{
+			Method:       "GET",
+			Name:         "unknown",
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: "Repository not found\n",
+		},
+	}
+
+	for _, result := range results {
+		urlValues := make(url.Values)
+		urlValues[":owner"] = []string{"blamewarrior"}
+		urlValues[":name"] = []string{result.Name}
+
+		req, err := http.NewRequest(result.Method, "/repositories?"+urlValues.Encode(), strings.NewReader(result.RequestBody))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		if result.Method == "GET" {
+			handlers.GetRepositorySettings(w, req)
+		} else {
+			handlers.UpdateRepositorySettings(w, req)
+		}
+
+		require.Equal(t, result.ResponseCode, w.Code)
+
+		if w.Code == http.StatusOK {
+			assert.JSONEq(t, result.ResponseBody, w.Body.String())
+		} else {
+			assert.Equal(t, result.ResponseBody, fmt.Sprintf("%v", w.Body))
+		}
+	}
+}
@@ -0,0 +1,232 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// streamKeepAliveInterval is how often a comment is sent to idle event stream connections
+// to prevent proxies from closing them.
+const streamKeepAliveInterval = 30 * time.Second
+
+const (
+	// streamRecentEvents is the number of recently sent event IDs kept to skip duplicates
+	streamRecentEvents = 1000
+	// streamMaxGaps is the maximum number of missing event IDs a stream waits for
+	streamMaxGaps = 1000
+	// streamGapTimeout is how long after an event has been recorded a missing event with a lower
+	// ID is still expected to be committed
+	streamGapTimeout = time.Minute
+)
+
+// StreamEvents streams repository events using Server-Sent Events. Consumers resume from the
+// last received event passing its ID either in Last-Event-ID header or in "cursor" query
+// parameter, in which case events recorded since then are replayed first. Without cursor only
+// new events are streamed.
+//
+// Event IDs are allocated before transactions commit, so events may be recorded out of ID order.
+// The ID sent along with an event is therefore the stream cursor, which is the highest ID below
+// which no event is missing, and events received before may be replayed again on resume.
+func (h *Handlers) StreamEvents(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	cursor := req.Header.Get("Last-Event-ID")
+	if cursor == "" {
+		cursor = req.URL.Query().Get("cursor")
+	}
+
+	position := newStreamCursor()
+
+	if cursor != "" {
+		lastID, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || lastID < 0 {
+			http.Error(w, "Incorrect cursor", http.StatusBadRequest)
+			return
+		}
+
+		position.Reset(lastID)
+	}
+
+	// subscribe before replaying so that no event is missed in between
+	events, cancel := h.broker.Subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	if cursor != "" {
+		for after := position.Position(); ; {
+			replay, err := blamewarrior.GetEvents(db, time.Time{}, after, maxAuditPageSize)
+
+			if err != nil {
+				log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+				return
+			}
+
+			for _, event := range replay {
+				if err := writeEvent(w, position.Add(event, time.Now()), event); err != nil {
+					return
+				}
+				after = event.ID
+			}
+			flusher.Flush()
+
+			if len(replay) < maxAuditPageSize {
+				break
+			}
+		}
+	}
+
+	keepAlive := time.NewTicker(streamKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				// subscriber fell behind, the consumer is expected to reconnect and resume
+				return
+			}
+
+			if position.Sent(event.ID) {
+				continue
+			}
+
+			if err := writeEvent(w, position.Add(event, time.Now()), event); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-req.Context().Done():
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, cursor int64, event blamewarrior.RepositoryEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", cursor, event.Action, data)
+
+	return err
+}
+
+// streamCursor keeps track of events sent over a single stream. Since events may arrive out of
+// ID order, the position of the stream only moves past a missing ID once the event is sent or
+// after streamGapTimeout, when the transaction it belonged to is considered rolled back.
+type streamCursor struct {
+	position, last int64
+	// missing holds IDs between position and last that have not been sent yet along with
+	// the time they are expected by
+	missing map[int64]time.Time
+	// sent is the set of recently sent IDs, recent is the same IDs in order they were sent
+	sent   map[int64]bool
+	recent []int64
+}
+
+func newStreamCursor() *streamCursor {
+	return &streamCursor{
+		missing: make(map[int64]time.Time),
+		sent:    make(map[int64]bool),
+	}
+}
+
+// Reset sets stream position to given event ID, so that events with higher IDs are expected.
+func (c *streamCursor) Reset(id int64) {
+	c.position, c.last = id, id
+}
+
+// Position returns the ID below which all events have been sent.
+func (c *streamCursor) Position() int64 {
+	return c.position
+}
+
+// Sent reports whether event with given ID has recently been sent.
+func (c *streamCursor) Sent(id int64) bool {
+	return c.sent[id]
+}
+
+// Add marks event as sent and returns new position of the stream.
+func (c *streamCursor) Add(event blamewarrior.RepositoryEvent, now time.Time) int64 {
+	c.sent[event.ID] = true
+	c.recent = append(c.recent, event.ID)
+
+	if len(c.recent) > streamRecentEvents {
+		delete(c.sent, c.recent[0])
+		c.recent = c.recent[1:]
+	}
+
+	delete(c.missing, event.ID)
+
+	switch {
+	case c.last == 0:
+		// without cursor the stream starts with the first event it receives
+		c.position = event.ID
+	case event.ID > c.last:
+		from := c.last + 1
+		if event.ID-from > streamMaxGaps {
+			from = event.ID - streamMaxGaps
+		}
+
+		// transactions that got lower IDs have started before this event was recorded
+		for id := from; id < event.ID; id++ {
+			c.missing[id] = event.CreatedAt.Add(streamGapTimeout)
+		}
+	}
+
+	if event.ID > c.last {
+		c.last = event.ID
+	}
+
+	for id, deadline := range c.missing {
+		if id <= c.position || now.After(deadline) {
+			delete(c.missing, id)
+		}
+	}
+
+	c.position = c.last
+	for id := range c.missing {
+		if id <= c.position {
+			c.position = id - 1
+		}
+	}
+
+	return c.position
+}
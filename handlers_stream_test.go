@@ -0,0 +1,154 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/stream"
+)
+
+func TestStreamEventsHandler(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events, event_outbox CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"}))
+
+	history, err := blamewarrior.GetRepositoryHistory(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+
+	broker := stream.NewBroker()
+
+	handlers := &Handlers{
+		db:          db,
+		hooksClient: new(hooksClientMock),
+		ghClient:    new(githubClientMock),
+		broker:      broker,
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(handlers.StreamEvents))
+	defer srv.Close()
+
+	req, err := http.NewRequest("GET", srv.URL+"/events/stream?cursor=abc", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err = http.NewRequest("GET", srv.URL+"/events/stream", nil)
+	require.NoError(t, err)
+	req = req.WithContext(ctx)
+	req.Header.Set("Last-Event-ID", strconv.FormatInt(history[0].ID-1, 10))
+
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	r := bufio.NewReader(resp.Body)
+
+	readEvent := func() (id, action string, event blamewarrior.RepositoryEvent) {
+		for {
+			line, err := r.ReadString('\n')
+			require.NoError(t, err)
+
+			line = strings.TrimSuffix(line, "\n")
+
+			switch {
+			case line == "":
+				return id, action, event
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				action = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+			}
+		}
+	}
+
+	// recorded events are replayed
+	_, action, event := readEvent()
+	assert.Equal(t, blamewarrior.EventCreated, action)
+	assert.Equal(t, "repos", event.Name)
+
+	_, _, event = readEvent()
+	assert.Equal(t, "hooks", event.Name)
+
+	// already replayed events are not sent twice
+	require.NoError(t, broker.Publish([]blamewarrior.RepositoryEvent{event, {ID: event.ID + 1, Name: "tokens", Action: blamewarrior.EventDeleted}}))
+
+	id, action, event := readEvent()
+	assert.Equal(t, strconv.FormatInt(event.ID, 10), id)
+	assert.Equal(t, blamewarrior.EventDeleted, action)
+	assert.Equal(t, "tokens", event.Name)
+}
+
+func TestStreamCursor(t *testing.T) {
+	now := time.Now()
+
+	event := func(id int64, recorded time.Time) blamewarrior.RepositoryEvent {
+		return blamewarrior.RepositoryEvent{ID: id, CreatedAt: recorded}
+	}
+
+	cursor := newStreamCursor()
+	cursor.Reset(10)
+
+	assert.EqualValues(t, 11, cursor.Add(event(11, now), now))
+
+	// event 12 is still being committed
+	assert.EqualValues(t, 11, cursor.Add(event(13, now), now))
+	assert.EqualValues(t, 11, cursor.Add(event(14, now), now))
+	assert.False(t, cursor.Sent(12))
+	assert.True(t, cursor.Sent(13))
+
+	assert.EqualValues(t, 14, cursor.Add(event(12, now), now))
+
+	// event 15 has been rolled back
+	assert.EqualValues(t, 14, cursor.Add(event(16, now), now))
+	assert.EqualValues(t, 17, cursor.Add(event(17, now), now.Add(streamGapTimeout+time.Second)))
+
+	// without cursor stream starts with the first event
+	cursor = newStreamCursor()
+
+	assert.EqualValues(t, 100, cursor.Add(event(100, now), now))
+	assert.EqualValues(t, 100, cursor.Add(event(99, now), now))
+	assert.True(t, cursor.Sent(99))
+}
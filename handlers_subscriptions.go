@@ -0,0 +1,176 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+const (
+	defaultDeliveriesPageSize = 50
+	maxDeliveriesPageSize     = 500
+)
+
+// CreateSubscription registers an endpoint to receive signed repository events.
+func (h *Handlers) CreateSubscription(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	body, err := requestBody(req)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var payload struct {
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+		Secret string   `json:"secret"`
+	}
+
+	if err = json.Unmarshal(body, &payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "Error when unmarshalling json")
+		return
+	}
+
+	sub := &blamewarrior.Subscription{
+		URL:    payload.URL,
+		Events: payload.Events,
+		Secret: payload.Secret,
+	}
+
+	if err = sub.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf("Error when creating subscription: %s", err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err = blamewarrior.CreateSubscription(db, sub); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/subscriptions/%d", sub.ID))
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(sub); err != nil {
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
+
+// GetSubscriptions responds with all registered subscriptions.
+func (h *Handlers) GetSubscriptions(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	subscriptions, err := blamewarrior.GetSubscriptions(db)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	if subscriptions == nil {
+		subscriptions = []blamewarrior.Subscription{}
+	}
+
+	if err := json.NewEncoder(w).Encode(subscriptions); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
+
+// DeleteSubscription stops deliveries to subscription and removes its delivery log.
+func (h *Handlers) DeleteSubscription(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	id, err := strconv.Atoi(req.URL.Query().Get(":id"))
+	if err != nil {
+		http.Error(w, "Incorrect subscription id", http.StatusBadRequest)
+		return
+	}
+
+	if err = blamewarrior.DeleteSubscription(db, id); err != nil {
+		if err == blamewarrior.ErrSubscriptionNotFound {
+			http.Error(w, "Subscription not found", http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "DELETE", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSubscriptionDeliveries responds with latest delivery attempts of subscription including
+// response status and latency.
+func (h *Handlers) GetSubscriptionDeliveries(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	id, err := strconv.Atoi(req.URL.Query().Get(":id"))
+	if err != nil {
+		http.Error(w, "Incorrect subscription id", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultDeliveriesPageSize
+	if v := req.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > maxDeliveriesPageSize {
+			http.Error(w, fmt.Sprintf("Incorrect limit, expected a number between 1 and %d", maxDeliveriesPageSize), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if _, err = blamewarrior.GetSubscription(db, id); err != nil {
+		if err == blamewarrior.ErrSubscriptionNotFound {
+			http.Error(w, "Subscription not found", http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	deliveries, err := blamewarrior.GetDeliveries(db, id, limit)
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []blamewarrior.Delivery{}
+	}
+
+	if err := json.NewEncoder(w).Encode(deliveries); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
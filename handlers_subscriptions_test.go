@@ -0,0 +1,140 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestCreateSubscriptionHandler(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE subscriptions CASCADE;")
+	require.NoError(t, err)
+
+	handlers := &Handlers{db: db}
+
+	results := []struct {
+		Body         string
+		ResponseCode int
+	}{
+		{Body: `{"url":"https://example.com/hook","events":["created"],"secret":"s3cr3t"}`, ResponseCode: http.StatusCreated},
+		{Body: `{"url":"https://example.com/hook","events":["pushed"],"secret":"s3cr3t"}`, ResponseCode: http.StatusUnprocessableEntity},
+		{Body: `{"url":"https://example.com/hook"}`, ResponseCode: http.StatusUnprocessableEntity},
+		{Body: `{"url":`, ResponseCode: http.StatusBadRequest},
+	}
+
+	for _, result := range results {
+		req, err := http.NewRequest("POST", "/subscriptions", strings.NewReader(result.Body))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handlers.CreateSubscription(w, req)
+
+		assert.Equal(t, result.ResponseCode, w.Code, result.Body)
+	}
+
+	subscriptions, err := blamewarrior.GetSubscriptions(db)
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+
+	req, err := http.NewRequest("GET", "/subscriptions", nil)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handlers.GetSubscriptions(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "s3cr3t")
+
+	var response []blamewarrior.Subscription
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response, 1)
+	assert.Equal(t, "https://example.com/hook", response[0].URL)
+	assert.Equal(t, []string{"created"}, response[0].Events)
+}
+
+func TestGetSubscriptionDeliveriesHandler(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE subscriptions CASCADE;")
+	require.NoError(t, err)
+
+	sub := &blamewarrior.Subscription{URL: "https://example.com/hook", Secret: "s3cr3t"}
+	require.NoError(t, blamewarrior.CreateSubscription(db, sub))
+
+	require.NoError(t, blamewarrior.RecordDelivery(db, &blamewarrior.Delivery{
+		SubscriptionID: sub.ID, EventID: 1, Attempt: 1, StatusCode: 502, Error: "502 Bad Gateway", Latency: 250 * time.Millisecond, DeliveredAt: time.Now(),
+	}, time.Now()))
+
+	handlers := &Handlers{db: db}
+
+	results := []struct {
+		ID           string
+		ResponseCode int
+		ResponseBody string
+	}{
+		{ID: fmt.Sprint(sub.ID), ResponseCode: http.StatusOK, ResponseBody: `"status_code":502`},
+		{ID: fmt.Sprint(sub.ID + 1), ResponseCode: http.StatusNotFound},
+		{ID: "abc", ResponseCode: http.StatusBadRequest},
+	}
+
+	for _, result := range results {
+		urlValues := make(url.Values)
+		urlValues[":id"] = []string{result.ID}
+
+		req, err := http.NewRequest("GET", "/subscriptions/"+result.ID+"/deliveries?"+urlValues.Encode(), nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handlers.GetSubscriptionDeliveries(w, req)
+
+		require.Equal(t, result.ResponseCode, w.Code, result.ID)
+		assert.Contains(t, w.Body.String(), result.ResponseBody)
+
+		if result.ResponseCode == http.StatusOK {
+			assert.Contains(t, w.Body.String(), `"latency_ms":250`)
+		}
+	}
+
+	urlValues := make(url.Values)
+	urlValues[":id"] = []string{fmt.Sprint(sub.ID)}
+
+	req, err := http.NewRequest("DELETE", "/subscriptions/"+fmt.Sprint(sub.ID)+"?"+urlValues.Encode(), nil)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handlers.DeleteSubscription(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	handlers.DeleteSubscription(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
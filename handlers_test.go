@@ -20,18 +20,19 @@
 package main
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 
-	"database/sql"
 	"fmt"
 	"log"
 	"os"
 
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -51,6 +52,46 @@ func (ghClientMock *githubClientMock) UserRepositories(ctx github.Context, usern
 
 }
 
+func (ghClientMock *githubClientMock) RepositoryConfig(ctx github.Context, owner, name string) (*blamewarrior.RepositoryConfig, error) {
+	args := ghClientMock.Called(owner, name)
+
+	config, _ := args.Get(0).(*blamewarrior.RepositoryConfig)
+	return config, args.Error(1)
+}
+
+func (ghClientMock *githubClientMock) CodeOwners(ctx github.Context, owner, name string) (*blamewarrior.CodeOwners, error) {
+	args := ghClientMock.Called(owner, name)
+
+	owners, _ := args.Get(0).(*blamewarrior.CodeOwners)
+	return owners, args.Error(1)
+}
+
+func (ghClientMock *githubClientMock) Collaborators(ctx github.Context, owner, name string) ([]blamewarrior.Collaborator, error) {
+	args := ghClientMock.Called(owner, name)
+
+	collaborators, _ := args.Get(0).([]blamewarrior.Collaborator)
+	return collaborators, args.Error(1)
+}
+
+func (ghClientMock *githubClientMock) DefaultBranch(ctx github.Context, owner, name string) (*blamewarrior.DefaultBranch, error) {
+	args := ghClientMock.Called(owner, name)
+
+	branch, _ := args.Get(0).(*blamewarrior.DefaultBranch)
+	return branch, args.Error(1)
+}
+
+func (ghClientMock *githubClientMock) RepositoryMetadata(ctx github.Context, owner, name string) (*blamewarrior.RepositoryMetadata, error) {
+	args := ghClientMock.Called(owner, name)
+
+	metadata, _ := args.Get(0).(*blamewarrior.RepositoryMetadata)
+	return metadata, args.Error(1)
+}
+
+func (ghClientMock *githubClientMock) Permission(ctx github.Context, owner, name, login string) (string, error) {
+	args := ghClientMock.Called(owner, name, login)
+	return args.String(0), args.Error(1)
+}
+
 type hooksClientMock struct {
 	mock.Mock
 }
@@ -65,11 +106,21 @@ func (hooksClientMock *hooksClientMock) DeleteHook(repositoryName string) error
 	return args.Error(0)
 }
 
+func (hooksClientMock *hooksClientMock) UpdateHook(oldRepositoryName, newRepositoryName string) error {
+	args := hooksClientMock.Called(oldRepositoryName, newRepositoryName)
+	return args.Error(0)
+}
+
+func (hooksClientMock *hooksClientMock) SetHookActive(repositoryName string, active bool) error {
+	args := hooksClientMock.Called(repositoryName, active)
+	return args.Error(0)
+}
+
 func TestGetRepositoryByFullName(t *testing.T) {
 	db, teardown := setup()
 	defer teardown()
 
-	_, err := db.Exec("TRUNCATE repositories;")
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
 
 	require.NoError(t, err)
 
@@ -102,7 +153,7 @@ func TestGetRepositoryByFullName(t *testing.T) {
 			Owner:        "blamewarrior",
 			Name:         "test",
 			ResponseCode: http.StatusOK,
-			ResponseBody: "{\"full_name\":\"blamewarrior/test\",\"owner\":\"blamewarrior\",\"name\":\"test\",\"private\":true}\n",
+			ResponseBody: "{\"full_name\":\"blamewarrior/test\",\"owner\":\"blamewarrior\",\"name\":\"test\",\"private\":true,\"provider\":\"github\",\"state\":\"active\"}\n",
 		},
 	}
 
@@ -124,7 +175,7 @@ func TestCreateRepositoryHandler(t *testing.T) {
 
 	db, teardown := setup()
 
-	_, err := db.Exec("TRUNCATE repositories;")
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
 
 	require.NoError(t, err)
 
@@ -179,7 +230,7 @@ func TestCreateRepositoryHandler(t *testing.T) {
 func TestDeleteRepositoryHandler(t *testing.T) {
 	db, teardown := setup()
 
-	_, err := db.Exec("TRUNCATE repositories;")
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
 	require.NoError(t, err)
 
 	defer teardown()
@@ -190,7 +241,7 @@ func TestDeleteRepositoryHandler(t *testing.T) {
 	require.NoError(t, err)
 
 	hooksClient := new(hooksClientMock)
-	hooksClient.On("DeleteHook", "blamewarrior/test_repo").Return(nil)
+	hooksClient.On("DeleteHook", "blamewarrior/repos").Return(nil).Once()
 	ghClient := new(githubClientMock)
 
 	handlers := &Handlers{
@@ -199,28 +250,267 @@ func TestDeleteRepositoryHandler(t *testing.T) {
 		ghClient:    ghClient,
 	}
 
-	urlValues := make(url.Values)
-	urlValues[":owner"] = []string{"blamewarrior"}
-	urlValues[":name"] = []string{"test_repo"}
+	results := []struct {
+		Name         string
+		ExpectedCode int
+	}{
+		{Name: "repos", ExpectedCode: http.StatusNoContent},
+		{Name: "repos", ExpectedCode: http.StatusNotFound},
+		{Name: "test_repo", ExpectedCode: http.StatusNotFound},
+	}
+
+	for _, result := range results {
+		urlValues := make(url.Values)
+		urlValues[":owner"] = []string{"blamewarrior"}
+		urlValues[":name"] = []string{result.Name}
+
+		req, err := http.NewRequest("DELETE", "/repositories?"+urlValues.Encode(), nil)
+
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		handlers.DeleteRepository(w, req)
+
+		assert.Equal(t, result.ExpectedCode, w.Code, result.Name)
+	}
+
+	hooksClient.AssertExpectations(t)
+}
+
+func TestGetRepositoryHistoryHandler(t *testing.T) {
+	db, teardown := setup()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events CASCADE;")
+	require.NoError(t, err)
+
+	defer teardown()
+
+	err = blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"})
+	require.NoError(t, err)
+
+	handlers := &Handlers{db: db}
+
+	results := []struct {
+		Name         string
+		ExpectedCode int
+	}{
+		{Name: "repos", ExpectedCode: http.StatusOK},
+		{Name: "unknown", ExpectedCode: http.StatusNotFound},
+	}
+
+	for _, result := range results {
+		urlValues := make(url.Values)
+		urlValues[":owner"] = []string{"blamewarrior"}
+		urlValues[":name"] = []string{result.Name}
+
+		req, err := http.NewRequest("GET", "/repositories?"+urlValues.Encode(), nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		handlers.GetRepositoryHistory(w, req)
+
+		assert.Equal(t, result.ExpectedCode, w.Code, result.Name)
+	}
+}
+
+func TestUpdateRepositoryHandler(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
 
-	req, err := http.NewRequest("DELETE", "/repositories?"+urlValues.Encode(), nil)
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
 
+	repo, err = blamewarrior.GetRepositoryByFullName(db, repo.FullName())
 	require.NoError(t, err)
 
-	w := httptest.NewRecorder()
+	hooksClient := new(hooksClientMock)
+	hooksClient.On("UpdateHook", "blamewarrior/repos", "blamewarrior/repositories").Return(nil)
+
+	handlers := &Handlers{
+		db:          db,
+		hooksClient: hooksClient,
+		ghClient:    new(githubClientMock),
+	}
+
+	results := []struct {
+		Name         string
+		IfMatch      string
+		RequestBody  string
+		ResponseCode int
+		ResponseBody string
+	}{
+		{
+			Name:         "repos",
+			IfMatch:      `"0.0"`,
+			RequestBody:  `{"private":true}`,
+			ResponseCode: http.StatusPreconditionFailed,
+			ResponseBody: "Repository has been modified\n",
+		},
+		{
+			Name:         "repos",
+			RequestBody:  `{"name":null}`,
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: "Error when updating repository: name must not be empty\n",
+		},
+		{
+			Name:         "repos",
+			IfMatch:      repo.ETag(),
+			RequestBody:  `{"private":true}`,
+			ResponseCode: http.StatusOK,
+			ResponseBody: "{\"full_name\":\"blamewarrior/repos\",\"owner\":\"blamewarrior\",\"name\":\"repos\",\"private\":true,\"provider\":\"github\",\"state\":\"active\"}\n",
+		},
+		{
+			Name:         "repos",
+			RequestBody:  `{"name":"repositories"}`,
+			ResponseCode: http.StatusOK,
+			ResponseBody: "{\"full_name\":\"blamewarrior/repositories\",\"owner\":\"blamewarrior\",\"name\":\"repositories\",\"private\":true,\"provider\":\"github\",\"state\":\"active\"}\n",
+		},
+		{
+			Name:         "repos",
+			RequestBody:  `{"private":false}`,
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: "Repository not found\n",
+		},
+	}
+
+	for _, result := range results {
+		urlValues := make(url.Values)
+		urlValues[":owner"] = []string{"blamewarrior"}
+		urlValues[":name"] = []string{result.Name}
 
-	handlers.DeleteRepository(w, req)
+		req, err := http.NewRequest("PATCH", "/repositories?"+urlValues.Encode(), strings.NewReader(result.RequestBody))
+		require.NoError(t, err)
 
-	assert.Equal(t, http.StatusNoContent, w.Code)
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		if result.IfMatch != "" {
+			req.Header.Set("If-Match", result.IfMatch)
+		}
+
+		w := httptest.NewRecorder()
+
+		handlers.UpdateRepository(w, req)
+
+		assert.Equal(t, result.ResponseCode, w.Code)
+		assert.Equal(t, result.ResponseBody, fmt.Sprintf("%v", w.Body))
+	}
 
 	hooksClient.AssertExpectations(t)
 }
 
+func TestRestoreRepositoryHandler(t *testing.T) {
+	db, teardown := setup()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	defer teardown()
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos", Private: true}
+	err = blamewarrior.CreateRepository(db, repo)
+	require.NoError(t, err)
+
+	err = blamewarrior.DeleteRepository(db, repo.FullName())
+	require.NoError(t, err)
+
+	hooksClient := new(hooksClientMock)
+	hooksClient.On("CreateHook", "blamewarrior/repos").Return(nil)
+	ghClient := new(githubClientMock)
+
+	handlers := &Handlers{
+		db:          db,
+		hooksClient: hooksClient,
+		ghClient:    ghClient,
+	}
+
+	results := []struct {
+		Name         string
+		ResponseCode int
+	}{
+		{Name: "repos", ResponseCode: http.StatusNoContent},
+		{Name: "repos", ResponseCode: http.StatusNotFound},
+		{Name: "unknown", ResponseCode: http.StatusNotFound},
+	}
+
+	for _, result := range results {
+		urlValues := make(url.Values)
+		urlValues[":owner"] = []string{"blamewarrior"}
+		urlValues[":name"] = []string{result.Name}
+
+		req, err := http.NewRequest("POST", "/repositories?"+urlValues.Encode(), nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		handlers.RestoreRepository(w, req)
+
+		assert.Equal(t, result.ResponseCode, w.Code)
+	}
+
+	hooksClient.AssertNumberOfCalls(t, "CreateHook", 1)
+}
+
+func TestGetAuditEventsHandler(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events CASCADE;")
+	require.NoError(t, err)
+
+	for _, name := range []string{"repos", "hooks", "tokens"} {
+		err = blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: name})
+		require.NoError(t, err)
+	}
+
+	handlers := &Handlers{
+		db:          db,
+		hooksClient: new(hooksClientMock),
+		ghClient:    new(githubClientMock),
+	}
+
+	results := []struct {
+		Query        string
+		ResponseCode int
+		Events       int
+		HasNext      bool
+	}{
+		{Query: "", ResponseCode: http.StatusOK, Events: 3},
+		{Query: "limit=2", ResponseCode: http.StatusOK, Events: 2, HasNext: true},
+		{Query: "limit=0", ResponseCode: http.StatusBadRequest},
+		{Query: "since=yesterday", ResponseCode: http.StatusBadRequest},
+	}
+
+	for _, result := range results {
+		req, err := http.NewRequest("GET", "/audit?"+result.Query, nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		handlers.GetAuditEvents(w, req)
+
+		require.Equal(t, result.ResponseCode, w.Code)
+
+		if result.ResponseCode != http.StatusOK {
+			continue
+		}
+
+		var events []blamewarrior.RepositoryEvent
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &events))
+
+		assert.Len(t, events, result.Events)
+		assert.Equal(t, result.HasNext, w.Header().Get("Link") != "")
+	}
+}
+
 func TestGetListRepositoryByOwner(t *testing.T) {
 	db, teardown := setup()
 	defer teardown()
 
-	_, err := db.Exec("TRUNCATE repositories;")
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
 
 	require.NoError(t, err)
 
@@ -253,7 +543,7 @@ func TestGetListRepositoryByOwner(t *testing.T) {
 		{
 			Owner:        "blamewarrior",
 			ResponseCode: http.StatusOK,
-			ResponseBody: "[{\"full_name\":\"blamewarrior/test\",\"owner\":\"blamewarrior\",\"name\":\"test\",\"private\":true}]\n",
+			ResponseBody: "[{\"full_name\":\"blamewarrior/test\",\"owner\":\"blamewarrior\",\"name\":\"test\",\"private\":true,\"provider\":\"github\",\"state\":\"active\"}]\n",
 		},
 	}
 
@@ -271,7 +561,7 @@ func TestGetListRepositoryByOwner(t *testing.T) {
 	}
 }
 
-func setup() (db *sql.DB, teardownFn func()) {
+func setup() (db *blamewarrior.DB, teardownFn func()) {
 	dbName := os.Getenv("DB_NAME")
 
 	if dbName == "" {
@@ -304,3 +594,77 @@ func setup() (db *sql.DB, teardownFn func()) {
 		}
 	}
 }
+
+func TestGetListRepositoryByOwner_Filters(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	handlers := &Handlers{
+		db:          db,
+		hooksClient: new(hooksClientMock),
+		ghClient:    new(githubClientMock),
+	}
+
+	for _, metadata := range []struct {
+		Name     string
+		Language string
+		Topics   []string
+		PushedAt time.Time
+	}{
+		{"api", "Go", []string{"backend"}, time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"dashboard", "JavaScript", []string{"frontend"}, time.Date(2017, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"worker", "Go", []string{"backend", "queue"}, time.Date(2017, 4, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: metadata.Name}
+		require.NoError(t, blamewarrior.CreateRepository(db, repo))
+
+		pushedAt := metadata.PushedAt
+		require.NoError(t, blamewarrior.UpdateRepositoryMetadata(db, repo, &blamewarrior.RepositoryMetadata{
+			Language:    metadata.Language,
+			Topics:      metadata.Topics,
+			PushedAt:    &pushedAt,
+			RefreshedAt: time.Now(),
+		}))
+	}
+
+	results := []struct {
+		Query        string
+		ResponseCode int
+		Names        []string
+	}{
+		{Query: "sort=name", ResponseCode: http.StatusOK, Names: []string{"api", "dashboard", "worker"}},
+		{Query: "language=go&sort=name", ResponseCode: http.StatusOK, Names: []string{"api", "worker"}},
+		{Query: "topic=queue", ResponseCode: http.StatusOK, Names: []string{"worker"}},
+		{Query: "pushed_since=2017-04-15T00:00:00Z&sort=pushed", ResponseCode: http.StatusOK, Names: []string{"api", "dashboard"}},
+		{Query: "pushed_since=yesterday", ResponseCode: http.StatusBadRequest},
+		{Query: "sort=size", ResponseCode: http.StatusBadRequest},
+	}
+
+	for _, result := range results {
+		req, err := http.NewRequest("GET", "/repositories?:owner=blamewarrior&"+result.Query, nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+
+		handlers.GetListRepositoryByOwner(w, req)
+
+		require.Equal(t, result.ResponseCode, w.Code, result.Query)
+
+		if result.ResponseCode != http.StatusOK {
+			continue
+		}
+
+		var repositories []blamewarrior.Repository
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &repositories))
+
+		var names []string
+		for _, repo := range repositories {
+			names = append(names, repo.Name)
+		}
+
+		assert.Equal(t, result.Names, names, result.Query)
+	}
+}
@@ -0,0 +1,200 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sync"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// importBatchSize is the number of repositories imported, and hooks created, concurrently
+const importBatchSize = 10
+
+// maxImportSize limits the size of import file
+const maxImportSize = 16 * 1048576
+
+// transferContentTypes maps export file formats to their media types.
+var transferContentTypes = map[string]string{
+	blamewarrior.FormatJSONL: "application/x-ndjson",
+	blamewarrior.FormatCSV:   "text/csv",
+}
+
+// transferFormat returns export file format requested either explicitly via format query
+// parameter or by media type. JSON Lines is the default one.
+func transferFormat(req *http.Request, mediaType string) (string, error) {
+	if format := req.URL.Query().Get("format"); format != "" {
+		if _, ok := transferContentTypes[format]; !ok {
+			return "", fmt.Errorf("unsupported format %s", format)
+		}
+
+		return format, nil
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(mediaType); err == nil {
+		for format, contentType := range transferContentTypes {
+			if contentType == mediaType {
+				return format, nil
+			}
+		}
+	}
+
+	return blamewarrior.FormatJSONL, nil
+}
+
+// ExportRepositories streams tracked repositories along with their settings as JSON Lines or CSV.
+func (h *Handlers) ExportRepositories(w http.ResponseWriter, req *http.Request) {
+	db := h.db.Session()
+
+	format, err := transferFormat(req, req.Header.Get("Accept"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw, err := blamewarrior.NewRepositoryWriter(w, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", transferContentTypes[format]+"; charset=UTF-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"repositories.%s\"", format))
+
+	// the response has been already started by the time an error occurs, so it can only be logged
+	if err = blamewarrior.ExportRepositories(db, req.URL.Query().Get("owner"), rw.Write); err == nil {
+		err = rw.Flush()
+	}
+
+	if err != nil {
+		log.Printf("%s\t%s\t%v\t%s", "GET", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
+
+// ImportRepositories starts tracking repositories listed in JSON Lines or CSV file or updates
+// them if they are already tracked. Only visibility and settings present in the file are updated.
+// Every line is imported separately and the response reports the result for each of them. With
+// dry_run=true changes are validated, but not stored.
+func (h *Handlers) ImportRepositories(w http.ResponseWriter, req *http.Request) {
+	format, err := transferFormat(req, req.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lines, err := blamewarrior.ReadRepositories(io.LimitReader(req.Body, maxImportSize), format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report := &blamewarrior.ImportReport{DryRun: req.URL.Query().Get("dry_run") == "true"}
+
+	change := changeFromRequest(req)
+	change.Source = blamewarrior.SourceImport
+
+	results := make([]blamewarrior.ImportResult, len(lines))
+	seen := make(map[string]int, len(lines))
+
+	for i, line := range lines {
+		results[i] = blamewarrior.ImportResult{Line: line.Line}
+		if line.Repository.Owner != "" && line.Repository.Name != "" {
+			results[i].FullName = line.Repository.QualifiedName()
+		}
+
+		if line.Err != nil {
+			results[i].Result, results[i].Error = blamewarrior.ImportFailed, line.Err.Error()
+			continue
+		}
+
+		if n, ok := seen[results[i].FullName]; ok {
+			results[i].Result, results[i].Error = blamewarrior.ImportFailed, fmt.Sprintf("duplicate of line %d", n)
+			continue
+		}
+
+		seen[results[i].FullName] = line.Line
+	}
+
+	for start := 0; start < len(lines); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		var wg sync.WaitGroup
+
+		for i := start; i < end; i++ {
+			if results[i].Result != "" {
+				continue
+			}
+
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				result, err := h.importRepository(&lines[i].Repository, lines[i].Fields, report.DryRun, change)
+				if err != nil {
+					result = blamewarrior.ImportFailed
+					results[i].Error = err.Error()
+				}
+
+				results[i].Result = result
+			}(i)
+		}
+
+		wg.Wait()
+	}
+
+	for _, result := range results {
+		report.Add(result)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+	}
+}
+
+// importRepository upserts a single repository and creates hook for it if it was not tracked
+// before. In dry run mode the transaction is rolled back and no hook is created.
+func (h *Handlers) importRepository(repo *blamewarrior.Repository, fields blamewarrior.ImportFields, dryRun bool, change *blamewarrior.Change) (string, error) {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	defer tx.Rollback()
+
+	result, err := blamewarrior.UpsertRepository(tx, repo, fields, change)
+	if err != nil || dryRun {
+		return result, err
+	}
+
+	if result == blamewarrior.ImportCreated {
+		if err := h.hooksClient.CreateHook(repo.QualifiedName()); err != nil {
+			return "", err
+		}
+	}
+
+	return result, tx.Commit()
+}
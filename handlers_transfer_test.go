@@ -0,0 +1,203 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestTransferFormat(t *testing.T) {
+	results := []struct {
+		Query     string
+		MediaType string
+		Format    string
+		Err       error
+	}{
+		{Format: blamewarrior.FormatJSONL},
+		{MediaType: "text/csv; charset=UTF-8", Format: blamewarrior.FormatCSV},
+		{MediaType: "application/x-ndjson", Format: blamewarrior.FormatJSONL},
+		{MediaType: "*/*", Format: blamewarrior.FormatJSONL},
+		{Query: "?format=csv", MediaType: "application/x-ndjson", Format: blamewarrior.FormatCSV},
+		{Query: "?format=xml", Err: errors.New("unsupported format xml")},
+	}
+
+	for _, result := range results {
+		req, err := http.NewRequest("GET", "/repositories/export"+result.Query, nil)
+		require.NoError(t, err)
+
+		format, err := transferFormat(req, result.MediaType)
+		assert.Equal(t, result.Err, err, result.Query)
+		assert.Equal(t, result.Format, format, result.Query)
+	}
+}
+
+func TestExportRepositoriesHandler(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos", Settings: json.RawMessage(`{"version":1}`)}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks", Private: true}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "octocat", Name: "hello-world"}))
+
+	handlers := &Handlers{db: db}
+
+	results := []struct {
+		Query        string
+		Accept       string
+		ResponseCode int
+		ContentType  string
+		ResponseBody string
+	}{
+		{
+			Query:        "?owner=blamewarrior",
+			ResponseCode: http.StatusOK,
+			ContentType:  "application/x-ndjson; charset=UTF-8",
+			ResponseBody: "{\"full_name\":\"blamewarrior/hooks\",\"private\":true}\n" +
+				"{\"full_name\":\"blamewarrior/repos\",\"private\":false,\"settings\":{\"version\": 1}}\n",
+		},
+		{
+			Accept:       "text/csv",
+			ResponseCode: http.StatusOK,
+			ContentType:  "text/csv; charset=UTF-8",
+			ResponseBody: "full_name,private,settings\n" +
+				"blamewarrior/hooks,true,\n" +
+				"blamewarrior/repos,false,\"{\"\"version\"\": 1}\"\n" +
+				"octocat/hello-world,false,\n",
+		},
+		{
+			Query:        "?format=xml",
+			ResponseCode: http.StatusBadRequest,
+			ContentType:  "text/plain; charset=utf-8",
+			ResponseBody: "unsupported format xml\n",
+		},
+	}
+
+	for _, result := range results {
+		req, err := http.NewRequest("GET", "/repositories/export"+result.Query, nil)
+		require.NoError(t, err)
+
+		req.Header.Set("Accept", result.Accept)
+
+		w := httptest.NewRecorder()
+		handlers.ExportRepositories(w, req)
+
+		assert.Equal(t, result.ResponseCode, w.Code, result.Query)
+		assert.Equal(t, result.ContentType, w.Header().Get("Content-Type"), result.Query)
+		assert.Equal(t, result.ResponseBody, w.Body.String(), result.Query)
+	}
+}
+
+func TestImportRepositoriesHandler(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "tokens", Private: true}))
+
+	hooksClient := new(hooksClientMock)
+	hooksClient.On("CreateHook", "blamewarrior/repos").Return(nil)
+	hooksClient.On("CreateHook", "octocat/hello-world").Return(errors.New("Impossible to create hook for octocat/hello-world"))
+
+	handlers := &Handlers{
+		db:          db,
+		hooksClient: hooksClient,
+	}
+
+	file := strings.Join([]string{
+		"full_name,private,settings",
+		"blamewarrior/repos,false,\"{\"\"version\"\":1}\"",
+		"blamewarrior/hooks,true,",
+		"blamewarrior/tokens,true,",
+		"blamewarrior,false,",
+		"blamewarrior/repos,true,",
+		"octocat/hello-world,false,",
+		"",
+	}, "\n")
+
+	expected := []blamewarrior.ImportResult{
+		{Line: 2, FullName: "blamewarrior/repos", Result: blamewarrior.ImportCreated},
+		{Line: 3, FullName: "blamewarrior/hooks", Result: blamewarrior.ImportUpdated},
+		{Line: 4, FullName: "blamewarrior/tokens", Result: blamewarrior.ImportUnchanged},
+		{Line: 5, Result: blamewarrior.ImportFailed, Error: "incorrect full name for repository"},
+		{Line: 6, FullName: "blamewarrior/repos", Result: blamewarrior.ImportFailed, Error: "duplicate of line 2"},
+		{Line: 7, FullName: "octocat/hello-world", Result: blamewarrior.ImportCreated},
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		query := "?format=csv"
+		if dryRun {
+			query += "&dry_run=true"
+		}
+
+		req, err := http.NewRequest("POST", "/repositories/import"+query, strings.NewReader(file))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handlers.ImportRepositories(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report blamewarrior.ImportReport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+
+		if !dryRun {
+			expected[5].Result, expected[5].Error = blamewarrior.ImportFailed, "Impossible to create hook for octocat/hello-world"
+		}
+
+		assert.Equal(t, dryRun, report.DryRun)
+		assert.Equal(t, expected, report.Results, "dry run: %t", dryRun)
+	}
+
+	repos, err := blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":1}`, string(repos.Settings))
+
+	hooks, err := blamewarrior.GetRepositoryByFullName(db, "blamewarrior/hooks")
+	require.NoError(t, err)
+	assert.True(t, hooks.Private)
+
+	_, err = blamewarrior.GetRepositoryByFullName(db, "octocat/hello-world")
+	assert.Equal(t, blamewarrior.ErrRepositoryNotFound, err)
+
+	hooksClient.AssertExpectations(t)
+
+	req, err := http.NewRequest("POST", "/repositories/import", strings.NewReader("owner,name\nblamewarrior,repos\n"))
+	require.NoError(t, err)
+
+	req.Header.Set("Content-Type", "text/csv")
+
+	w := httptest.NewRecorder()
+	handlers.ImportRepositories(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "failed to read repositories: missing full_name column\n", w.Body.String())
+}
@@ -0,0 +1,158 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/github"
+
+	gh "github.com/google/go-github/github"
+)
+
+// GithubWebhook handles GitHub webhook deliveries. Payload signature is always verified, so
+// deliveries are rejected unless webhook secret is configured. Events that are not relevant
+// are acknowledged and ignored.
+func (h *Handlers) GithubWebhook(w http.ResponseWriter, req *http.Request) {
+	if len(h.webhookSecret) == 0 {
+		http.Error(w, "Webhook secret is not configured", http.StatusUnauthorized)
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusUnauthorized, "webhook secret is not configured")
+		return
+	}
+
+	payload, err := gh.ValidatePayload(req, h.webhookSecret)
+
+	if err != nil {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	event, err := gh.ParseWebHook(gh.WebHookType(req), payload)
+
+	if err != nil {
+		// unknown event types are not an error, GitHub sends everything the hook is subscribed to
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	switch event := event.(type) {
+	case *gh.PushEvent:
+		err = h.handlePushEvent(req, event)
+	case *gh.MemberEvent:
+		err = h.handleMemberEvent(req, event)
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("%s\t%s\t%v\t%s", "POST", req.RequestURI, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePushEvent reloads configuration file and CODEOWNERS of a tracked repository when
+// they are changed on the default branch.
+func (h *Handlers) handlePushEvent(req *http.Request, event *gh.PushEvent) error {
+	repo := event.GetRepo()
+
+	if event.GetRef() != "refs/heads/"+repo.GetDefaultBranch() {
+		return nil
+	}
+
+	configChanged := touchesFile(event.Commits, blamewarrior.ConfigFileName)
+	ownersChanged := touchesFile(event.Commits, blamewarrior.CodeOwnersPaths...)
+
+	if !configChanged && !ownersChanged {
+		return nil
+	}
+
+	tx, err := h.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	repository, err := blamewarrior.GetRepositoryByFullName(tx, repo.GetFullName())
+
+	if err == blamewarrior.ErrRepositoryNotFound || err == blamewarrior.IncorrectFullName {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	change := &blamewarrior.Change{
+		Actor:     event.GetSender().GetLogin(),
+		RequestID: gh.DeliveryID(req),
+		Source:    blamewarrior.SourceWebhook,
+	}
+
+	ctx := github.Context{Context: req.Context()}
+
+	if configChanged {
+		if err = h.refreshConfig(ctx, tx, repository, change); err != nil {
+			return err
+		}
+	}
+
+	if ownersChanged {
+		if err = h.refreshCodeOwners(ctx, tx, repository); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// handleMemberEvent syncs collaborators of a tracked repository when somebody is added to
+// or removed from it, or their permissions change.
+func (h *Handlers) handleMemberEvent(req *http.Request, event *gh.MemberEvent) error {
+	repository, err := blamewarrior.GetRepositoryByFullName(h.db, event.GetRepo().GetFullName())
+
+	if err == blamewarrior.ErrRepositoryNotFound || err == blamewarrior.IncorrectFullName {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return syncCollaborators(req.Context(), github.NewProvider(h.ghClient), h.db, repository)
+}
+
+// touchesFile reports whether any of commits adds, modifies or removes one of files.
+func touchesFile(commits []gh.PushEventCommit, files ...string) bool {
+	for _, commit := range commits {
+		for _, paths := range [][]string{commit.Added, commit.Modified, commit.Removed} {
+			for _, p := range paths {
+				for _, file := range files {
+					if strings.TrimPrefix(p, "/") == file {
+						return true
+					}
+				}
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,158 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestGithubWebhookHandler_Push(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+
+	ghClient := new(githubClientMock)
+	ghClient.On("RepositoryConfig", "blamewarrior", "repos").Return(&blamewarrior.RepositoryConfig{CommitSHA: "abc123"}, nil)
+	ghClient.On("CodeOwners", "blamewarrior", "repos").Return(&blamewarrior.CodeOwners{
+		Path:      ".github/CODEOWNERS",
+		CommitSHA: "def456",
+		Rules:     []blamewarrior.CodeOwnersRule{},
+	}, nil)
+
+	handlers := &Handlers{
+		db:            db,
+		hooksClient:   new(hooksClientMock),
+		ghClient:      ghClient,
+		webhookSecret: []byte("secret"),
+	}
+
+	results := []struct {
+		Event   string
+		Payload string
+	}{
+		{
+			Event:   "push",
+			Payload: `{"ref":"refs/heads/feature","repository":{"full_name":"blamewarrior/repos","default_branch":"master"},"commits":[{"modified":[".blamewarrior.yml"]}]}`,
+		},
+		{
+			Event:   "push",
+			Payload: `{"ref":"refs/heads/master","repository":{"full_name":"blamewarrior/repos","default_branch":"master"},"commits":[{"modified":["README.md"]}]}`,
+		},
+		{
+			Event:   "push",
+			Payload: `{"ref":"refs/heads/master","repository":{"full_name":"octocat/unknown","default_branch":"master"},"commits":[{"added":[".blamewarrior.yml"]}]}`,
+		},
+		{
+			Event:   "watch",
+			Payload: `{"action":"started"}`,
+		},
+		{
+			Event:   "push",
+			Payload: `{"ref":"refs/heads/master","repository":{"full_name":"blamewarrior/repos","default_branch":"master"},"commits":[{"modified":[".blamewarrior.yml"]}]}`,
+		},
+		{
+			Event:   "push",
+			Payload: `{"ref":"refs/heads/master","repository":{"full_name":"blamewarrior/repos","default_branch":"master"},"commits":[{"added":[".github/CODEOWNERS"]}]}`,
+		},
+	}
+
+	for _, result := range results {
+		req, err := http.NewRequest("POST", "/webhooks/github", strings.NewReader(result.Payload))
+		require.NoError(t, err)
+
+		req.Header.Set("X-GitHub-Event", result.Event)
+		signWebhookPayload(req, "secret", result.Payload)
+
+		w := httptest.NewRecorder()
+
+		handlers.GithubWebhook(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	}
+
+	ghClient.AssertNumberOfCalls(t, "RepositoryConfig", 1)
+	ghClient.AssertNumberOfCalls(t, "CodeOwners", 1)
+
+	repo, err := blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	require.NotNil(t, repo.Config)
+	assert.Equal(t, "abc123", repo.Config.CommitSHA)
+
+	owners, err := blamewarrior.GetCodeOwners(db, repo.ID)
+	require.NoError(t, err)
+	require.NotNil(t, owners)
+	assert.Equal(t, "def456", owners.CommitSHA)
+}
+
+func TestGithubWebhookHandler_Signature(t *testing.T) {
+	payload := `{"action":"started"}`
+
+	results := []struct {
+		Secret       string
+		Signature    string
+		ExpectedCode int
+	}{
+		{Secret: "", Signature: "", ExpectedCode: http.StatusUnauthorized},
+		{Secret: "", Signature: "secret", ExpectedCode: http.StatusUnauthorized},
+		{Secret: "secret", Signature: "", ExpectedCode: http.StatusBadRequest},
+		{Secret: "secret", Signature: "wrong", ExpectedCode: http.StatusBadRequest},
+		{Secret: "secret", Signature: "secret", ExpectedCode: http.StatusNoContent},
+	}
+
+	for _, result := range results {
+		handlers := &Handlers{webhookSecret: []byte(result.Secret)}
+
+		req, err := http.NewRequest("POST", "/webhooks/github", strings.NewReader(payload))
+		require.NoError(t, err)
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", "watch")
+		if result.Signature != "" {
+			signWebhookPayload(req, result.Signature, payload)
+		}
+
+		w := httptest.NewRecorder()
+
+		handlers.GithubWebhook(w, req)
+
+		assert.Equal(t, result.ExpectedCode, w.Code, "secret %q, signed with %q", result.Secret, result.Signature)
+	}
+}
+
+// signWebhookPayload sets headers GitHub sends along with a delivery signed with secret.
+func signWebhookPayload(req *http.Request, secret, payload string) {
+	req.Header.Set("Content-Type", "application/json")
+
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(payload))
+
+	req.Header.Set("X-Hub-Signature", "sha1="+hex.EncodeToString(mac.Sum(nil)))
+}
@@ -0,0 +1,172 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/jobs"
+)
+
+// Background job types
+const (
+	jobCreateHook          = "create_hook"
+	jobReconcileRepository = "reconcile_repository"
+)
+
+// repositoryJobPayload is the payload of jobs processing a single repository.
+type repositoryJobPayload struct {
+	// Repository is the qualified name of repository
+	Repository string `json:"repository"`
+	// Actor and RequestID identify request job has been enqueued by, they are recorded
+	// in the audit log along with changes made by job
+	Actor     string `json:"actor,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// newRepositoryJob returns job of given type processing repository on behalf of change author.
+func newRepositoryJob(jobType string, repo *blamewarrior.Repository, change *blamewarrior.Change) (*blamewarrior.Job, error) {
+	return blamewarrior.NewJob(jobType, repositoryJobPayload{
+		Repository: repo.QualifiedName(),
+		Actor:      change.Actor,
+		RequestID:  change.RequestID,
+	})
+}
+
+// jobHandlers returns handlers of background jobs run by job workers.
+func (h *Handlers) jobHandlers() map[string]jobs.Handler {
+	return map[string]jobs.Handler{
+		jobCreateHook:          h.runCreateHookJob,
+		jobReconcileRepository: h.runReconcileRepositoryJob,
+	}
+}
+
+// deadJobHandlers returns handlers cleaning up after background jobs that have been moved
+// to dead jobs.
+func (h *Handlers) deadJobHandlers() map[string]jobs.DeadHandler {
+	return map[string]jobs.DeadHandler{
+		jobCreateHook: h.failCreateHookJob,
+	}
+}
+
+// runCreateHookJob sets up webhook of tracked repository. Pending repositories become active
+// once their webhook is created, and so do failed ones when the job is retried.
+func (h *Handlers) runCreateHookJob(ctx context.Context, job *blamewarrior.Job) error {
+	repo, err := h.jobRepository(job)
+	if err != nil {
+		return err
+	}
+
+	if err = h.hooksClient.CreateHook(repo.QualifiedName()); err != nil {
+		return err
+	}
+
+	if repo.State != blamewarrior.StatePending && repo.State != blamewarrior.StateFailed {
+		return nil
+	}
+
+	return h.updateJobRepositoryState(job, repo, blamewarrior.StateActive)
+}
+
+// failCreateHookJob marks pending repository failed once its webhook could not be created,
+// so that it is not left pending forever.
+func (h *Handlers) failCreateHookJob(ctx context.Context, job *blamewarrior.Job) error {
+	repo, err := h.jobRepository(job)
+	if err != nil {
+		if jobs.IsPermanent(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if repo.State != blamewarrior.StatePending {
+		return nil
+	}
+
+	return h.updateJobRepositoryState(job, repo, blamewarrior.StateFailed)
+}
+
+// updateJobRepositoryState moves repository processed by job to given state.
+func (h *Handlers) updateJobRepositoryState(job *blamewarrior.Job, repo *blamewarrior.Repository, state string) error {
+	tx, err := h.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if err = blamewarrior.UpdateRepositoryState(tx, repo, state, jobChange(job)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	h.cache.Invalidate(repo.Provider, repo.Owner, repo.Name)
+
+	return nil
+}
+
+// runReconcileRepositoryJob brings data fetched from provider up to date for tracked repository.
+func (h *Handlers) runReconcileRepositoryJob(ctx context.Context, job *blamewarrior.Job) error {
+	repo, err := h.jobRepository(job)
+	if err != nil {
+		return err
+	}
+
+	provider, err := h.provider(repo.Provider)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+
+	return reconcileRepository(ctx, provider, h.ghClient, h.hooksClient, h.db, repo)
+}
+
+// jobChange returns the author of changes made by job processing repository.
+func jobChange(job *blamewarrior.Job) *blamewarrior.Change {
+	var payload repositoryJobPayload
+	job.DecodePayload(&payload)
+
+	return &blamewarrior.Change{
+		Actor:     payload.Actor,
+		RequestID: payload.RequestID,
+		Source:    blamewarrior.SourceJob,
+	}
+}
+
+// jobRepository returns tracked repository job payload refers to. Repository is read from the
+// primary since jobs are run right after the repository has been stored. Jobs of malformed
+// payloads and untracked repositories are not retried.
+func (h *Handlers) jobRepository(job *blamewarrior.Job) (*blamewarrior.Repository, error) {
+	var payload repositoryJobPayload
+	if err := job.DecodePayload(&payload); err != nil {
+		return nil, jobs.Permanent(err)
+	}
+
+	repo, err := blamewarrior.GetRepositoryByFullName(h.db.Primary(), payload.Repository)
+	switch err {
+	case nil:
+		return repo, nil
+	case blamewarrior.IncorrectFullName, blamewarrior.ErrRepositoryNotFound:
+		return nil, jobs.Permanent(err)
+	default:
+		return nil, err
+	}
+}
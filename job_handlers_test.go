@@ -0,0 +1,112 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/jobs"
+)
+
+func TestCreateHookJob(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
+
+	hooksClient := new(hooksClientMock)
+	hooksClient.On("CreateHook", "blamewarrior/repos").Return(errors.New("hooks service unavailable")).Once()
+	hooksClient.On("CreateHook", "blamewarrior/repos").Return(nil).Once()
+
+	handlers := &Handlers{db: db, hooksClient: hooksClient}
+	runner := jobs.NewInProcess(handlers.jobHandlers())
+
+	results := []struct {
+		Payload  interface{}
+		State    string
+		Attempts int
+	}{
+		{Payload: map[string]string{"repository": "blamewarrior/repos"}, State: blamewarrior.JobSucceeded, Attempts: 2},
+		{Payload: map[string]string{"repository": "blamewarrior/hooks"}, State: blamewarrior.JobDead, Attempts: 1},
+		{Payload: []string{"blamewarrior/repos"}, State: blamewarrior.JobDead, Attempts: 1},
+	}
+
+	for _, result := range results {
+		job, err := blamewarrior.NewJob(jobCreateHook, result.Payload)
+		require.NoError(t, err)
+
+		require.NoError(t, runner.Enqueue(context.Background(), job))
+
+		assert.Equal(t, result.State, job.State, "%v", result.Payload)
+		assert.Equal(t, result.Attempts, job.Attempts, "%v", result.Payload)
+	}
+
+	hooksClient.AssertExpectations(t)
+}
+
+func TestCreateHookJob_Dead(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos", State: blamewarrior.StatePending}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
+
+	hooksClient := new(hooksClientMock)
+	hooksClient.On("CreateHook", "blamewarrior/repos").Return(errors.New("hooks service unavailable")).Times(2)
+	hooksClient.On("CreateHook", "blamewarrior/repos").Return(nil).Once()
+
+	handlers := &Handlers{db: db, hooksClient: hooksClient}
+
+	runner := jobs.NewInProcess(handlers.jobHandlers())
+	runner.Dead = handlers.deadJobHandlers()
+
+	job, err := newRepositoryJob(jobCreateHook, repo, &blamewarrior.Change{})
+	require.NoError(t, err)
+
+	job.MaxAttempts = 2
+	require.NoError(t, runner.Enqueue(context.Background(), job))
+	assert.Equal(t, blamewarrior.JobDead, job.State)
+
+	failed, err := blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.StateFailed, failed.State, "repository should not be left pending")
+
+	// retried job activates failed repository
+	job, err = newRepositoryJob(jobCreateHook, repo, &blamewarrior.Change{})
+	require.NoError(t, err)
+
+	require.NoError(t, runner.Enqueue(context.Background(), job))
+	assert.Equal(t, blamewarrior.JobSucceeded, job.State)
+
+	active, err := blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	assert.Equal(t, blamewarrior.StateActive, active.State)
+
+	hooksClient.AssertExpectations(t)
+}
@@ -20,18 +20,36 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/blamewarrior/repos/github"
+	"github.com/blamewarrior/repos/gitlab"
 	"github.com/bmizerany/pat"
 
 	"github.com/blamewarrior/repos/blamewarrior"
 	"github.com/blamewarrior/repos/blamewarrior/hooks"
+	"github.com/blamewarrior/repos/blamewarrior/jobs"
+	"github.com/blamewarrior/repos/blamewarrior/stream"
 	"github.com/blamewarrior/repos/blamewarrior/tokens"
 )
 
+const (
+	replicasCheckInterval     = 10 * time.Second
+	tombstonesPurgeInterval   = time.Hour
+	reconciliationInterval    = 6 * time.Hour
+	eventsDispatchInterval    = time.Second
+	eventsStreamInterval      = time.Second
+	deliveriesSendInterval    = 5 * time.Second
+	defaultTombstoneRetention = 30 * 24 * time.Hour
+)
+
 func main() {
 
 	dbName := os.Getenv("DB_NAME")
@@ -46,9 +64,13 @@ func main() {
 		Password: os.Getenv("DB_PASSWORD"),
 	}
 
-	hooksBaseURL := os.Getenv("BW_HOOKS_BASE_URL")
-	if hooksBaseURL == "" {
-		log.Fatal("missing hooks base url (expected to be passed via ENV['BW_HOOKS_BASE_URL'])")
+	if replicas := os.Getenv("DB_REPLICAS"); replicas != "" {
+		opts.Replicas = strings.Split(replicas, ",")
+	}
+
+	hooksAddr := os.Getenv("BW_HOOKS_ADDR")
+	if hooksAddr == "" {
+		log.Fatal("missing hooks service address (expected to be passed via ENV['BW_HOOKS_ADDR'])")
 	}
 
 	tokensBaseURL := os.Getenv("BW_TOKENS_BASE_URL")
@@ -61,26 +83,131 @@ func main() {
 		log.Fatalf("failed to establish connection with test db %s using connection string %s: %s", dbName, opts.ConnectionString(), err)
 	}
 
+	go db.MonitorReplicas(replicasCheckInterval, nil)
+
+	tombstoneRetention := defaultTombstoneRetention
+	if retention := os.Getenv("BW_TOMBSTONE_RETENTION"); retention != "" {
+		if tombstoneRetention, err = time.ParseDuration(retention); err != nil {
+			log.Fatalf("malformed tombstone retention %q: %s", retention, err)
+		}
+	}
+
+	go purgeTombstones(db, tombstoneRetention, tombstonesPurgeInterval)
+
 	tokenClient := tokens.NewTokenClient(tokensBaseURL)
 	ghClient := github.NewGithubClient(tokenClient)
 
-	hooksclient := hooks.NewHooksClient(hooksBaseURL)
+	hooksAPI, err := hooks.NewHooksClient(hooksAddr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	hooksclient := githubHooks{hooksAPI}
+
+	providers := make(map[string]blamewarrior.Provider)
+	if gitlabURL := os.Getenv("BW_GITLAB_URL"); gitlabURL != "" {
+		providers[blamewarrior.ProviderGitlab] = gitlab.NewClient(gitlabURL, os.Getenv("BW_GITLAB_TOKEN"))
+	}
+
+	go reconcileRepositories(db, reconciliationInterval)
+
+	broker := stream.NewBroker()
+
+	var webhookURLs []string
+	webhooks := make(map[string]stream.Publisher)
+	if urls := os.Getenv("BW_EVENTS_WEBHOOK_URLS"); urls != "" {
+		for _, url := range strings.Split(urls, ",") {
+			webhookURLs = append(webhookURLs, url)
+			webhooks[url] = stream.NewWebhookPublisher(url)
+		}
+	}
+
+	go dispatchEvents(db, webhookURLs, eventsDispatchInterval)
+	go streamEvents(db, broker, eventsStreamInterval)
+	go deliverSubscriptionEvents(db, stream.NewSubscriberClient(), deliveriesSendInterval)
+	go deliverWebhookEvents(db, webhooks, deliveriesSendInterval)
+
+	cacheControl := defaultCacheControl
+	if v, ok := os.LookupEnv("BW_CACHE_CONTROL"); ok {
+		cacheControl = v
+	}
 
 	handlers := &Handlers{
-		db:          db,
-		hooksClient: hooksclient,
-		ghClient:    ghClient,
+		db:            db,
+		hooksClient:   hooksclient,
+		ghClient:      ghClient,
+		providers:     providers,
+		webhookSecret: []byte(os.Getenv("BW_GITHUB_WEBHOOK_SECRET")),
+		broker:        broker,
+		cacheControl:  cacheControl,
 	}
 
-	mux := pat.New()
+	if len(handlers.webhookSecret) == 0 {
+		log.Printf("BW_GITHUB_WEBHOOK_SECRET is not set, GitHub webhook deliveries will be rejected")
+	}
+
+	jobWorkers := jobs.DefaultWorkers
+	if workers := os.Getenv("BW_JOB_WORKERS"); workers != "" {
+		if jobWorkers, err = strconv.Atoi(workers); err != nil || jobWorkers < 0 {
+			log.Fatalf("malformed number of job workers %q", workers)
+		}
+	}
+
+	if os.Getenv("BW_CACHE") != "off" {
+		cacheSize := defaultCacheSize
+		if size := os.Getenv("BW_CACHE_SIZE"); size != "" {
+			if cacheSize, err = strconv.Atoi(size); err != nil || cacheSize <= 0 {
+				log.Fatalf("malformed repository cache size %q", size)
+			}
+		}
+
+		cacheTTL := defaultCacheTTL
+		if ttl := os.Getenv("BW_CACHE_TTL"); ttl != "" {
+			if cacheTTL, err = time.ParseDuration(ttl); err != nil {
+				log.Fatalf("malformed repository cache ttl %q: %s", ttl, err)
+			}
+		}
+
+		listener, err := blamewarrior.ListenRepositoryChanges(dbName, opts)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		handlers.cache = newRepositoryCache(db.Primary(), cacheSize, cacheTTL)
+
+		go handlers.cache.listen(listener.Notify)
+	}
+
+	pool := jobs.NewPool(db, handlers.jobHandlers())
+	pool.Workers = jobWorkers
+	pool.Dead = handlers.deadJobHandlers()
+
+	go pool.Run(context.Background())
+
+	grpcAddr := defaultGRPCAddr
+	if addr := os.Getenv("BW_GRPC_ADDR"); addr != "" {
+		grpcAddr = addr
+	}
 
-	mux.Get("/repositories/:owner/:name", http.HandlerFunc(handlers.GetRepositoryByFullName))
-	mux.Get("/repositories/:owner", http.HandlerFunc(handlers.GetListRepositoryByOwner))
-	mux.Get("/repositories/:owner/github", http.HandlerFunc(handlers.GetListGithubRepositories))
-	mux.Post("/repositories", http.HandlerFunc(handlers.CreateRepository))
-	mux.Del("/repositories/:owner/:name", http.HandlerFunc(handlers.DeleteRepository))
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	http.Handle("/", mux)
+	go func() {
+		if err := newGRPCServer(handlers).Serve(grpcListener); err != nil {
+			log.Panic(err)
+		}
+	}()
+
+	log.Printf("blamewarrior repositories gRPC API is running on %s", grpcAddr)
+
+	router, err := newRouter(handlers)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	http.Handle("/", router)
 
 	log.Printf("blamewarrior repositories is running on 8080 port")
 
@@ -90,3 +217,102 @@ func main() {
 	}
 
 }
+
+// route is an API endpoint served by a handler.
+type route struct {
+	Method  string
+	Pattern string
+	Handler http.HandlerFunc
+}
+
+// routes returns API endpoints. More specific patterns go first since pat picks the first
+// matching one, and every route has to be described in openAPISpec.
+func (h *Handlers) routes() []route {
+	return []route{
+		{"GET", "/repositories/export", h.ExportRepositories},
+		{"GET", "/repositories/:owner/github", h.GetListGithubRepositories},
+		{"GET", "/repositories/:owner/:name", h.GetRepositoryByFullName},
+		{"GET", "/repositories/:owner", h.GetListRepositoryByOwner},
+		{"POST", "/repositories", h.CreateRepository},
+		{"POST", "/repositories/import", h.ImportRepositories},
+		{"POST", "/repositories/lookup", h.LookupRepositories},
+		{"DELETE", "/repositories/:owner/:name", h.DeleteRepository},
+		{"PATCH", "/repositories/:owner/:name", h.UpdateRepository},
+		{"POST", "/repositories/:owner/:name/restore", h.RestoreRepository},
+		{"GET", "/repositories/:owner/:name/history", h.GetRepositoryHistory},
+		{"GET", "/repositories/:owner/:name/settings", h.GetRepositorySettings},
+		{"PUT", "/repositories/:owner/:name/settings", h.UpdateRepositorySettings},
+		{"POST", "/repositories/:owner/:name/config/refresh", h.RefreshRepositoryConfig},
+		{"GET", "/repositories/:owner/:name/owners", h.GetFileOwners},
+		{"POST", "/repositories/:owner/:name/owners/refresh", h.RefreshCodeOwners},
+		{"GET", "/repositories/:owner/:name/collaborators", h.GetRepositoryCollaborators},
+		{"POST", "/repositories/:owner/:name/reconcile", h.ReconcileRepository},
+		{"GET", "/owners/:owner/settings", h.GetOwnerSettings},
+		{"PUT", "/owners/:owner/settings", h.UpdateOwnerSettings},
+		{"GET", "/owners/:provider/:owner/settings", h.GetOwnerSettings},
+		{"PUT", "/owners/:provider/:owner/settings", h.UpdateOwnerSettings},
+		{"GET", "/audit", h.GetAuditEvents},
+		{"GET", "/events/stream", h.StreamEvents},
+		{"POST", "/subscriptions", h.CreateSubscription},
+		{"GET", "/subscriptions", h.GetSubscriptions},
+		{"DELETE", "/subscriptions/:id", h.DeleteSubscription},
+		{"GET", "/subscriptions/:id/deliveries", h.GetSubscriptionDeliveries},
+		{"GET", "/operations/:id", h.GetOperation},
+		{"GET", "/jobs", h.GetJobs},
+		{"GET", "/jobs/:id", h.GetJob},
+		{"POST", "/jobs/:id/retry", h.RetryJob},
+		{"POST", "/jobs/:id/cancel", h.CancelJob},
+		{"POST", "/webhooks/github", h.GithubWebhook},
+		{"GET", "/openapi.json", h.GetOpenAPISpec},
+	}
+}
+
+// newRouter registers API routes served by handlers. Request bodies are validated against
+// OpenAPI document. Repository routes are also served under provider prefix, see routeRepositories.
+func newRouter(h *Handlers) (http.Handler, error) {
+	doc, err := parseOpenAPIDocument(openAPISpec)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := pat.New()
+
+	for _, r := range h.routes() {
+		if r.Method == "GET" {
+			mux.Get(r.Pattern, r.Handler)
+		} else {
+			mux.Add(r.Method, r.Pattern, r.Handler)
+		}
+	}
+
+	return routeRepositories(validateRequests(doc, mux)), nil
+}
+
+// purgeTombstones periodically removes repositories that were deleted more than retention ago.
+func purgeTombstones(db *blamewarrior.DB, retention, interval time.Duration) {
+	for range time.Tick(interval) {
+		purged, err := purgeDeletedRepositories(db, retention)
+		if err != nil {
+			log.Printf("failed to purge deleted repositories: %s", err)
+			continue
+		}
+
+		if purged > 0 {
+			log.Printf("purged %d deleted repositories", purged)
+		}
+	}
+}
+
+func purgeDeletedRepositories(db *blamewarrior.DB, retention time.Duration) (purged int64, err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	if purged, err = blamewarrior.PurgeDeletedRepositories(tx, retention, &blamewarrior.Change{Source: blamewarrior.SourceRetention}); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return purged, tx.Commit()
+}
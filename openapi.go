@@ -0,0 +1,175 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+// openAPIDocument is the part of OpenAPI document used to validate requests.
+type openAPIDocument struct {
+	Paths map[string]map[string]*openAPIOperation `json:"paths"`
+}
+
+type openAPIOperation struct {
+	OperationID string `json:"operationId"`
+	RequestBody *struct {
+		Required bool `json:"required"`
+		Content  map[string]struct {
+			Schema *blamewarrior.JSONSchema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+}
+
+func parseOpenAPIDocument(spec string) (*openAPIDocument, error) {
+	doc := &openAPIDocument{}
+	if err := json.Unmarshal([]byte(spec), doc); err != nil {
+		return nil, fmt.Errorf("malformed OpenAPI document: %s", err)
+	}
+
+	return doc, nil
+}
+
+// Operation returns the operation for given method and request path. Paths with literal
+// segments take precedence over templated ones, i.e. /repositories/{owner}/github is chosen
+// over /repositories/{owner}/{name}.
+func (doc *openAPIDocument) Operation(method, path string) *openAPIOperation {
+	var (
+		match  *openAPIOperation
+		params = -1
+	)
+
+	segments := strings.Split(path, "/")
+
+	for template, operations := range doc.Paths {
+		op, ok := operations[strings.ToLower(method)]
+		if !ok {
+			continue
+		}
+
+		n, ok := matchPathTemplate(strings.Split(template, "/"), segments)
+		if ok && (match == nil || n < params) {
+			match, params = op, n
+		}
+	}
+
+	return match
+}
+
+// matchPathTemplate reports whether path segments match template segments and returns the
+// number of template parameters.
+func matchPathTemplate(template, segments []string) (params int, ok bool) {
+	if len(template) != len(segments) {
+		return 0, false
+	}
+
+	for i, t := range template {
+		switch {
+		case strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}"):
+			if segments[i] == "" {
+				return 0, false
+			}
+			params++
+		case t != segments[i]:
+			return 0, false
+		}
+	}
+
+	return params, true
+}
+
+// Schema returns JSON schema of request body or nil if operation does not expect one.
+func (op *openAPIOperation) Schema() *blamewarrior.JSONSchema {
+	if op.RequestBody == nil {
+		return nil
+	}
+
+	for _, content := range op.RequestBody.Content {
+		if content.Schema != nil {
+			return content.Schema
+		}
+	}
+
+	return nil
+}
+
+// validateRequests rejects requests which body does not match the schema from OpenAPI document
+// with 400 problem response. Requests to operations that are not described are passed as is.
+func validateRequests(doc *openAPIDocument, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		op := doc.Operation(req.Method, req.URL.Path)
+		if op == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		schema := op.Schema()
+		if schema == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		body, err := ioutil.ReadAll(io.LimitReader(req.Body, 1048576))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("%s\t%s\t%v\t%s", req.Method, req.RequestURI, http.StatusInternalServerError, err)
+			return
+		}
+		req.Body.Close()
+
+		if len(bytes.TrimSpace(body)) == 0 {
+			if op.RequestBody.Required {
+				writeProblem(w, http.StatusBadRequest, "request body is required")
+				return
+			}
+		} else if err := schema.Validate(body); err != nil {
+			writeProblem(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, req)
+	})
+}
+
+// writeProblem responds with RFC 7807 problem details.
+func writeProblem(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(struct {
+		Type   string `json:"type"`
+		Title  string `json:"title"`
+		Status int    `json:"status"`
+		Detail string `json:"detail"`
+	}{"about:blank", http.StatusText(status), status, detail})
+}
+
+// GetOpenAPISpec responds with OpenAPI document describing the API.
+func (h *Handlers) GetOpenAPISpec(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	io.WriteString(w, openAPISpec)
+}
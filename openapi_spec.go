@@ -0,0 +1,1588 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+// openAPISpec is OpenAPI 3 description of the API. Request body schemas are inlined since they
+// are used to validate requests, see validateRequests.
+const openAPISpec = `{
+	"openapi": "3.0.0",
+	"info": {
+		"title": "BlameWarrior repositories",
+		"description": "Information about repositories tracked by BlameWarrior. Repositories hosted at GitHub are served under /repositories, routes of repositories hosted at other providers are prefixed with provider name, e.g. /repositories/gitlab/{owner}/{name}. Owners named after a provider have to use the prefixed form. Owners of GitLab repositories may consist of nested namespaces, slashes in them have to be escaped in paths, e.g. /repositories/gitlab/group%2Fsubgroup/{name}.",
+		"version": "1.0.0"
+	},
+	"paths": {
+		"/repositories": {
+			"post": {
+				"operationId": "createRepository",
+				"summary": "Start tracking a repository and install its webhook",
+				"parameters": [
+					{
+						"name": "Prefer",
+						"in": "header",
+						"required": false,
+						"description": "Pass respond-async to create webhook in the background",
+						"schema": {"type": "string"}
+					}
+				],
+				"requestBody": {
+					"required": true,
+					"content": {
+						"application/json": {
+							"schema": {
+								"type": "object",
+								"required": ["owner", "name"],
+								"properties": {
+									"owner": {"type": "string", "minLength": 1},
+									"name": {"type": "string", "minLength": 1},
+									"private": {"type": "boolean"},
+									"provider": {"type": "string", "enum": ["github", "gitlab"]},
+									"settings": {"type": "object"}
+								}
+							}
+						}
+					}
+				},
+				"responses": {
+					"201": {"description": "Repository is tracked"},
+					"202": {
+						"description": "Repository is tracked as pending, its webhook is being created",
+						"headers": {"Location": {"description": "URL of the operation", "schema": {"type": "string"}}},
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Operation"}}}
+					},
+					"400": {
+						"description": "Request body does not match the schema",
+						"content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}
+					},
+					"422": {
+						"description": "Data failed validation",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/repositories/export": {
+			"get": {
+				"operationId": "exportRepositories",
+				"summary": "Stream tracked repositories along with their settings as JSON Lines or CSV",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "query",
+						"required": false,
+						"description": "Export only repositories of this owner",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "format",
+						"in": "query",
+						"required": false,
+						"description": "Export file format, overrides the media type",
+						"schema": {"type": "string", "enum": ["jsonl", "csv"]}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Tracked repositories, one per line",
+						"content": {
+							"application/x-ndjson": {"schema": {"type": "string"}},
+							"text/csv": {"schema": {"type": "string"}}
+						}
+					},
+					"400": {
+						"description": "Unsupported format",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/repositories/import": {
+			"post": {
+				"operationId": "importRepositories",
+				"summary": "Track repositories listed in JSON Lines or CSV file or update already tracked ones",
+				"parameters": [
+					{
+						"name": "format",
+						"in": "query",
+						"required": false,
+						"description": "Export file format, overrides the media type",
+						"schema": {"type": "string", "enum": ["jsonl", "csv"]}
+					},
+					{
+						"name": "dry_run",
+						"in": "query",
+						"required": false,
+						"description": "Validate changes without storing them",
+						"schema": {"type": "boolean"}
+					}
+				],
+				"requestBody": {"required": true, "content": {"application/x-ndjson": {}, "text/csv": {}}},
+				"responses": {
+					"200": {
+						"description": "Result of import of every line",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/ImportReport"}}}
+					},
+					"400": {
+						"description": "Unsupported format or unreadable file",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/repositories/lookup": {
+			"post": {
+				"operationId": "lookupRepositories",
+				"summary": "Look up tracked repositories by full names and provider IDs at once",
+				"description": "Full names may be qualified with provider, e.g. gitlab:owner/name. IDs are looked up at GitHub, use /repositories/{provider}/lookup for other providers. IDs are known once repository metadata has been fetched.",
+				"requestBody": {
+					"required": true,
+					"content": {
+						"application/json": {
+							"schema": {
+								"type": "object",
+								"properties": {
+									"full_names": {
+										"type": "array",
+										"maxItems": 100,
+										"items": {"type": "string", "minLength": 1}
+									},
+									"ids": {"type": "array", "maxItems": 100, "items": {"type": "integer"}}
+								}
+							}
+						}
+					}
+				},
+				"responses": {
+					"200": {
+						"description": "Lookup results in the order of full names and then IDs",
+						"content": {
+							"application/json": {
+								"schema": {"type": "array", "items": {"$ref": "#/components/schemas/LookupResult"}}
+							}
+						}
+					},
+					"400": {
+						"description": "Request body does not match the schema",
+						"content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}
+					},
+					"422": {
+						"description": "Incorrect full name or too many repositories",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/repositories/{owner}": {
+			"get": {
+				"operationId": "listRepositories",
+				"summary": "List tracked repositories of owner",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "include_deleted",
+						"in": "query",
+						"required": false,
+						"description": "Include soft deleted repositories",
+						"schema": {"type": "boolean"}
+					},
+					{
+						"name": "language",
+						"in": "query",
+						"required": false,
+						"description": "Primary language of repository",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "topic",
+						"in": "query",
+						"required": false,
+						"description": "Repository topic",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "state",
+						"in": "query",
+						"required": false,
+						"description": "Repository state",
+						"schema": {"type": "string", "enum": ["active", "inactive", "pending", "failed"]}
+					},
+					{
+						"name": "sort",
+						"in": "query",
+						"required": false,
+						"description": "Sort order",
+						"schema": {"type": "string", "enum": ["name", "pushed", "stars"]}
+					},
+					{
+						"name": "pushed_since",
+						"in": "query",
+						"required": false,
+						"description": "Limit to repositories pushed after RFC 3339 time",
+						"schema": {"type": "string", "format": "date-time"}
+					},
+					{
+						"name": "If-None-Match",
+						"in": "header",
+						"required": false,
+						"description": "Entity tags of representations client has",
+						"schema": {"type": "string"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Repositories",
+						"content": {
+							"application/json": {
+								"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Repository"}}
+							}
+						},
+						"headers": {
+							"ETag": {"schema": {"type": "string"}},
+							"Cache-Control": {"description": "Configured caching directives", "schema": {"type": "string"}}
+						}
+					},
+					"304": {
+						"description": "Representation client has is up to date",
+						"headers": {
+							"ETag": {"schema": {"type": "string"}},
+							"Cache-Control": {"description": "Configured caching directives", "schema": {"type": "string"}}
+						}
+					},
+					"400": {
+						"description": "Incorrect full name or parameters",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/repositories/{owner}/github": {
+			"get": {
+				"operationId": "listGithubRepositories",
+				"summary": "List repositories of owner available at provider",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Repositories",
+						"content": {
+							"application/json": {
+								"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Repository"}}
+							}
+						}
+					},
+					"501": {
+						"description": "Provider is not configured",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/repositories/{owner}/{name}": {
+			"get": {
+				"operationId": "getRepository",
+				"summary": "Get tracked repository",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "name",
+						"in": "path",
+						"required": true,
+						"description": "Repository name",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "include_deleted",
+						"in": "query",
+						"required": false,
+						"description": "Include soft deleted repositories",
+						"schema": {"type": "boolean"}
+					},
+					{
+						"name": "If-None-Match",
+						"in": "header",
+						"required": false,
+						"description": "Entity tags of representations client has",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "If-Modified-Since",
+						"in": "header",
+						"required": false,
+						"description": "Time client's representation has been last modified at, ignored if If-None-Match is present",
+						"schema": {"type": "string"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Repository",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Repository"}}},
+						"headers": {
+							"ETag": {"schema": {"type": "string"}},
+							"Last-Modified": {
+								"description": "Time repository has been last modified at",
+								"schema": {"type": "string"}
+							},
+							"Cache-Control": {"description": "Configured caching directives", "schema": {"type": "string"}}
+						}
+					},
+					"304": {
+						"description": "Representation client has is up to date",
+						"headers": {
+							"ETag": {"schema": {"type": "string"}},
+							"Last-Modified": {
+								"description": "Time repository has been last modified at",
+								"schema": {"type": "string"}
+							},
+							"Cache-Control": {"description": "Configured caching directives", "schema": {"type": "string"}}
+						}
+					},
+					"400": {
+						"description": "Incorrect full name or parameters",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"404": {
+						"description": "Repository not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			},
+			"patch": {
+				"operationId": "updateRepository",
+				"summary": "Apply JSON Merge Patch to repository",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "name",
+						"in": "path",
+						"required": true,
+						"description": "Repository name",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "If-Match",
+						"in": "header",
+						"required": false,
+						"description": "ETag of repository the patch is based on",
+						"schema": {"type": "string"}
+					}
+				],
+				"requestBody": {
+					"required": true,
+					"content": {"application/merge-patch+json": {"schema": {"type": "object"}}}
+				},
+				"responses": {
+					"200": {
+						"description": "Updated repository",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Repository"}}},
+						"headers": {"ETag": {"schema": {"type": "string"}}}
+					},
+					"400": {
+						"description": "Request body does not match the schema",
+						"content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}
+					},
+					"404": {
+						"description": "Repository not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"409": {
+						"description": "Repository is already tracked",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"412": {
+						"description": "Repository has been modified",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"422": {
+						"description": "Data failed validation",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			},
+			"delete": {
+				"operationId": "deleteRepository",
+				"summary": "Stop tracking repository and remove its webhook",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "name",
+						"in": "path",
+						"required": true,
+						"description": "Repository name",
+						"schema": {"type": "string"}
+					}
+				],
+				"responses": {
+					"204": {"description": "Repository is deleted"},
+					"404": {
+						"description": "Repository not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/repositories/{owner}/{name}/restore": {
+			"post": {
+				"operationId": "restoreRepository",
+				"summary": "Restore deleted repository",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "name",
+						"in": "path",
+						"required": true,
+						"description": "Repository name",
+						"schema": {"type": "string"}
+					}
+				],
+				"responses": {
+					"204": {"description": "Repository is restored"},
+					"404": {
+						"description": "Repository not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"409": {
+						"description": "Repository is already tracked",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/repositories/{owner}/{name}/history": {
+			"get": {
+				"operationId": "getRepositoryHistory",
+				"summary": "Get audit log of repository",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "name",
+						"in": "path",
+						"required": true,
+						"description": "Repository name",
+						"schema": {"type": "string"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Events in chronological order",
+						"content": {
+							"application/json": {
+								"schema": {"type": "array", "items": {"$ref": "#/components/schemas/RepositoryEvent"}}
+							}
+						}
+					},
+					"400": {
+						"description": "Incorrect full name or parameters",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"404": {
+						"description": "Repository not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/repositories/{owner}/{name}/settings": {
+			"get": {
+				"operationId": "getRepositorySettings",
+				"summary": "Get repository settings merged into owner defaults",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "name",
+						"in": "path",
+						"required": true,
+						"description": "Repository name",
+						"schema": {"type": "string"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Settings",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Settings"}}}
+					},
+					"404": {
+						"description": "Repository not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			},
+			"put": {
+				"operationId": "updateRepositorySettings",
+				"summary": "Replace repository settings",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "name",
+						"in": "path",
+						"required": true,
+						"description": "Repository name",
+						"schema": {"type": "string"}
+					}
+				],
+				"requestBody": {
+					"required": true,
+					"content": {
+						"application/json": {
+							"schema": {
+								"type": "object",
+								"required": ["version"],
+								"properties": {"version": {"type": "integer"}}
+							}
+						}
+					}
+				},
+				"responses": {
+					"200": {
+						"description": "Settings",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Settings"}}}
+					},
+					"400": {
+						"description": "Request body does not match the schema",
+						"content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}
+					},
+					"404": {
+						"description": "Repository not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"412": {
+						"description": "Repository has been modified",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"422": {
+						"description": "Data failed validation",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/repositories/{owner}/{name}/config/refresh": {
+			"post": {
+				"operationId": "refreshRepositoryConfig",
+				"summary": "Reload configuration file from repository",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "name",
+						"in": "path",
+						"required": true,
+						"description": "Repository name",
+						"schema": {"type": "string"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Configuration",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/RepositoryConfig"}}}
+					},
+					"404": {
+						"description": "Repository not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"501": {
+						"description": "Not supported for repositories hosted at provider",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/repositories/{owner}/{name}/owners": {
+			"get": {
+				"operationId": "getFileOwners",
+				"summary": "Get owners of file according to CODEOWNERS",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "name",
+						"in": "path",
+						"required": true,
+						"description": "Repository name",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "path",
+						"in": "query",
+						"required": true,
+						"description": "File path relative to repository root",
+						"schema": {"type": "string"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "File owners",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/FileOwners"}}}
+					},
+					"400": {
+						"description": "Incorrect full name or parameters",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"404": {
+						"description": "Repository not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/repositories/{owner}/{name}/owners/refresh": {
+			"post": {
+				"operationId": "refreshCodeOwners",
+				"summary": "Reload CODEOWNERS from repository",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "name",
+						"in": "path",
+						"required": true,
+						"description": "Repository name",
+						"schema": {"type": "string"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "CODEOWNERS",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/CodeOwners"}}}
+					},
+					"404": {
+						"description": "Repository not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"501": {
+						"description": "Not supported for repositories hosted at provider",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/repositories/{owner}/{name}/collaborators": {
+			"get": {
+				"operationId": "getRepositoryCollaborators",
+				"summary": "List users and teams with access to repository",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "name",
+						"in": "path",
+						"required": true,
+						"description": "Repository name",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "permission",
+						"in": "query",
+						"required": false,
+						"description": "Minimum permission level",
+						"schema": {"type": "string", "enum": ["read", "write", "admin"]}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Collaborators",
+						"content": {
+							"application/json": {
+								"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Collaborator"}}
+							}
+						}
+					},
+					"400": {
+						"description": "Incorrect full name or parameters",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"404": {
+						"description": "Repository not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/repositories/{owner}/{name}/reconcile": {
+			"post": {
+				"operationId": "reconcileRepository",
+				"summary": "Refresh data fetched from GitHub and apply staleness policy",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "name",
+						"in": "path",
+						"required": true,
+						"description": "Repository name",
+						"schema": {"type": "string"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Reconciled repository",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Repository"}}}
+					},
+					"404": {
+						"description": "Repository not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"503": {
+						"description": "GitHub API rate limit reached",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"501": {
+						"description": "Provider is not configured",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/owners/{owner}/settings": {
+			"get": {
+				"operationId": "getOwnerSettings",
+				"summary": "Get default settings for repositories of owner at GitHub",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Settings",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Settings"}}}
+					}
+				}
+			},
+			"put": {
+				"operationId": "updateOwnerSettings",
+				"summary": "Replace default settings for repositories of owner at GitHub",
+				"parameters": [
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					}
+				],
+				"requestBody": {
+					"required": true,
+					"content": {
+						"application/json": {
+							"schema": {
+								"type": "object",
+								"required": ["version"],
+								"properties": {"version": {"type": "integer"}}
+							}
+						}
+					}
+				},
+				"responses": {
+					"200": {
+						"description": "Settings",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Settings"}}}
+					},
+					"400": {
+						"description": "Request body does not match the schema",
+						"content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}
+					},
+					"422": {
+						"description": "Data failed validation",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/owners/{provider}/{owner}/settings": {
+			"get": {
+				"operationId": "getProviderOwnerSettings",
+				"summary": "Get default settings for repositories of owner at provider",
+				"parameters": [
+					{
+						"name": "provider",
+						"in": "path",
+						"required": true,
+						"description": "Provider repositories are hosted at",
+						"schema": {"type": "string", "enum": ["github", "gitlab"]}
+					},
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Settings",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Settings"}}}
+					},
+					"404": {
+						"description": "Unknown provider",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			},
+			"put": {
+				"operationId": "updateProviderOwnerSettings",
+				"summary": "Replace default settings for repositories of owner at provider",
+				"parameters": [
+					{
+						"name": "provider",
+						"in": "path",
+						"required": true,
+						"description": "Provider repositories are hosted at",
+						"schema": {"type": "string", "enum": ["github", "gitlab"]}
+					},
+					{
+						"name": "owner",
+						"in": "path",
+						"required": true,
+						"description": "Repository owner login or namespace with escaped slashes",
+						"schema": {"type": "string"}
+					}
+				],
+				"requestBody": {
+					"required": true,
+					"content": {
+						"application/json": {
+							"schema": {
+								"type": "object",
+								"required": ["version"],
+								"properties": {"version": {"type": "integer"}}
+							}
+						}
+					}
+				},
+				"responses": {
+					"200": {
+						"description": "Settings",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Settings"}}}
+					},
+					"404": {
+						"description": "Unknown provider",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"400": {
+						"description": "Request body does not match the schema",
+						"content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}
+					},
+					"422": {
+						"description": "Data failed validation",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/audit": {
+			"get": {
+				"operationId": "getAuditEvents",
+				"summary": "List audit log entries across all owners",
+				"parameters": [
+					{
+						"name": "since",
+						"in": "query",
+						"required": false,
+						"description": "Limit to events recorded after RFC 3339 time",
+						"schema": {"type": "string", "format": "date-time"}
+					},
+					{
+						"name": "after",
+						"in": "query",
+						"required": false,
+						"description": "ID of the last event of previous page",
+						"schema": {"type": "integer"}
+					},
+					{
+						"name": "limit",
+						"in": "query",
+						"required": false,
+						"description": "Page size",
+						"schema": {"type": "integer", "minimum": 1, "maximum": 1000}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Events ordered by ID",
+						"content": {
+							"application/json": {
+								"schema": {"type": "array", "items": {"$ref": "#/components/schemas/RepositoryEvent"}}
+							}
+						},
+						"headers": {"Link": {"description": "URL of the next page", "schema": {"type": "string"}}}
+					},
+					"400": {
+						"description": "Incorrect full name or parameters",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/events/stream": {
+			"get": {
+				"operationId": "streamEvents",
+				"summary": "Stream repository events as Server-Sent Events",
+				"parameters": [
+					{
+						"name": "Last-Event-ID",
+						"in": "header",
+						"required": false,
+						"description": "Stream cursor sent as ID of the last received event, events received before may be replayed again",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "cursor",
+						"in": "query",
+						"required": false,
+						"description": "Same as Last-Event-ID header",
+						"schema": {"type": "integer"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Event stream",
+						"content": {"text/event-stream": {"schema": {"type": "string"}}}
+					},
+					"400": {
+						"description": "Incorrect full name or parameters",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/subscriptions": {
+			"post": {
+				"operationId": "createSubscription",
+				"summary": "Register endpoint to receive signed repository events",
+				"requestBody": {
+					"required": true,
+					"content": {
+						"application/json": {
+							"schema": {
+								"type": "object",
+								"required": ["url", "secret"],
+								"properties": {
+									"url": {"type": "string", "minLength": 1},
+									"secret": {"type": "string", "minLength": 1},
+									"events": {
+										"type": "array",
+										"items": {
+											"type": "string",
+											"enum": ["created", "deleted", "updated", "restored", "purged"]
+										}
+									}
+								}
+							}
+						}
+					}
+				},
+				"responses": {
+					"201": {
+						"description": "Subscription",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Subscription"}}}
+					},
+					"400": {
+						"description": "Request body does not match the schema",
+						"content": {"application/problem+json": {"schema": {"$ref": "#/components/schemas/Problem"}}}
+					},
+					"422": {
+						"description": "Data failed validation",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			},
+			"get": {
+				"operationId": "getSubscriptions",
+				"summary": "List subscriptions",
+				"responses": {
+					"200": {
+						"description": "Subscriptions",
+						"content": {
+							"application/json": {
+								"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Subscription"}}
+							}
+						}
+					}
+				}
+			}
+		},
+		"/subscriptions/{id}": {
+			"delete": {
+				"operationId": "deleteSubscription",
+				"summary": "Remove subscription",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "path",
+						"required": true,
+						"description": "Subscription ID",
+						"schema": {"type": "integer"}
+					}
+				],
+				"responses": {
+					"204": {"description": "Subscription is removed"},
+					"404": {
+						"description": "Subscription not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/subscriptions/{id}/deliveries": {
+			"get": {
+				"operationId": "getSubscriptionDeliveries",
+				"summary": "List latest delivery attempts of subscription",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "path",
+						"required": true,
+						"description": "Subscription ID",
+						"schema": {"type": "integer"}
+					},
+					{
+						"name": "limit",
+						"in": "query",
+						"required": false,
+						"description": "Number of attempts",
+						"schema": {"type": "integer", "minimum": 1, "maximum": 500}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Delivery attempts, most recent first",
+						"content": {
+							"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Delivery"}}}
+						}
+					},
+					"404": {
+						"description": "Subscription not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/operations/{id}": {
+			"get": {
+				"operationId": "getOperation",
+				"summary": "Get progress of request processed in the background",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "path",
+						"required": true,
+						"description": "Operation ID",
+						"schema": {"type": "integer"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Operation",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Operation"}}}
+					},
+					"400": {
+						"description": "Incorrect operation id",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"404": {
+						"description": "Operation not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/jobs": {
+			"get": {
+				"operationId": "getJobs",
+				"summary": "List background jobs",
+				"parameters": [
+					{
+						"name": "state",
+						"in": "query",
+						"required": false,
+						"description": "Only list jobs in state",
+						"schema": {"type": "string", "enum": ["pending", "running", "succeeded", "dead", "cancelled"]}
+					},
+					{
+						"name": "type",
+						"in": "query",
+						"required": false,
+						"description": "Only list jobs of type",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "after",
+						"in": "query",
+						"required": false,
+						"description": "Only list jobs with greater IDs",
+						"schema": {"type": "integer"}
+					},
+					{
+						"name": "limit",
+						"in": "query",
+						"required": false,
+						"description": "Number of jobs",
+						"schema": {"type": "integer", "minimum": 1, "maximum": 500}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Jobs in the order they were enqueued",
+						"headers": {"Link": {"description": "URL of the next page", "schema": {"type": "string"}}},
+						"content": {
+							"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Job"}}}
+						}
+					},
+					"400": {
+						"description": "Incorrect query parameters",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/jobs/{id}": {
+			"get": {
+				"operationId": "getJob",
+				"summary": "Get background job",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "path",
+						"required": true,
+						"description": "Job ID",
+						"schema": {"type": "integer"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Job",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}
+					},
+					"400": {
+						"description": "Incorrect job id",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"404": {
+						"description": "Job not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/jobs/{id}/retry": {
+			"post": {
+				"operationId": "retryJob",
+				"summary": "Retry dead or cancelled job",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "path",
+						"required": true,
+						"description": "Job ID",
+						"schema": {"type": "integer"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Job scheduled to run again",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}
+					},
+					"400": {
+						"description": "Incorrect job id",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"404": {
+						"description": "Job not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"409": {
+						"description": "Operation is not allowed in current job state",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/jobs/{id}/cancel": {
+			"post": {
+				"operationId": "cancelJob",
+				"summary": "Cancel pending or running job",
+				"parameters": [
+					{
+						"name": "id",
+						"in": "path",
+						"required": true,
+						"description": "Job ID",
+						"schema": {"type": "integer"}
+					}
+				],
+				"responses": {
+					"200": {
+						"description": "Cancelled job",
+						"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}
+					},
+					"400": {
+						"description": "Incorrect job id",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"404": {
+						"description": "Job not found",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"409": {
+						"description": "Operation is not allowed in current job state",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/webhooks/github": {
+			"post": {
+				"operationId": "githubWebhook",
+				"summary": "Receive GitHub webhook delivery",
+				"parameters": [
+					{
+						"name": "X-GitHub-Event",
+						"in": "header",
+						"required": true,
+						"description": "GitHub event type",
+						"schema": {"type": "string"}
+					},
+					{
+						"name": "X-Hub-Signature",
+						"in": "header",
+						"required": true,
+						"description": "Payload signature",
+						"schema": {"type": "string"}
+					}
+				],
+				"responses": {
+					"204": {"description": "Delivery is processed"},
+					"400": {
+						"description": "Invalid payload",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					},
+					"401": {
+						"description": "Webhook secret is not configured",
+						"content": {"text/plain": {"schema": {"type": "string"}}}
+					}
+				}
+			}
+		},
+		"/openapi.json": {
+			"get": {
+				"operationId": "getOpenAPISpec",
+				"summary": "Get this document",
+				"responses": {
+					"200": {
+						"description": "OpenAPI document",
+						"content": {"application/json": {"schema": {"type": "object"}}}
+					}
+				}
+			}
+		}
+	},
+	"components": {
+		"schemas": {
+			"Repository": {
+				"type": "object",
+				"properties": {
+					"full_name": {"type": "string"},
+					"owner": {"type": "string"},
+					"name": {"type": "string"},
+					"private": {"type": "boolean"},
+					"deleted_at": {"type": "string", "format": "date-time"},
+					"provider": {"type": "string", "enum": ["github", "gitlab"]},
+					"state": {"type": "string", "enum": ["active", "inactive", "pending", "failed"]},
+					"inactive_since": {"type": "string", "format": "date-time"},
+					"settings": {"$ref": "#/components/schemas/Settings"},
+					"config": {"$ref": "#/components/schemas/RepositoryConfig"},
+					"default_branch": {"$ref": "#/components/schemas/DefaultBranch"},
+					"metadata": {"$ref": "#/components/schemas/RepositoryMetadata"}
+				}
+			},
+			"LookupResult": {
+				"type": "object",
+				"properties": {
+					"full_name": {"type": "string"},
+					"id": {"type": "integer"},
+					"found": {"type": "boolean"},
+					"repository": {"$ref": "#/components/schemas/Repository"}
+				}
+			},
+			"Settings": {
+				"type": "object",
+				"required": ["version"],
+				"properties": {"version": {"type": "integer", "enum": [1]}}
+			},
+			"RepositoryConfig": {
+				"type": "object",
+				"properties": {
+					"settings": {"$ref": "#/components/schemas/Settings"},
+					"commit_sha": {"type": "string"},
+					"error": {"type": "string"},
+					"refreshed_at": {"type": "string", "format": "date-time"}
+				}
+			},
+			"DefaultBranch": {
+				"type": "object",
+				"properties": {
+					"name": {"type": "string"},
+					"protected": {"type": "boolean"},
+					"required_reviews": {"type": "boolean"},
+					"require_code_owner_reviews": {"type": "boolean"},
+					"dismiss_stale_reviews": {"type": "boolean"},
+					"refreshed_at": {"type": "string", "format": "date-time"}
+				}
+			},
+			"RepositoryMetadata": {
+				"type": "object",
+				"properties": {
+					"provider_id": {"type": "integer"},
+					"description": {"type": "string"},
+					"language": {"type": "string"},
+					"topics": {"type": "array", "items": {"type": "string"}},
+					"size": {"type": "integer"},
+					"stars": {"type": "integer"},
+					"pushed_at": {"type": "string", "format": "date-time"},
+					"archived": {"type": "boolean"},
+					"refreshed_at": {"type": "string", "format": "date-time"}
+				}
+			},
+			"RepositoryEvent": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "integer"},
+					"repository_id": {"type": "integer"},
+					"owner": {"type": "string"},
+					"name": {"type": "string"},
+					"action": {"type": "string", "enum": ["created", "deleted", "updated", "restored", "purged"]},
+					"actor": {"type": "string"},
+					"request_id": {"type": "string"},
+					"source": {"type": "string"},
+					"before": {"$ref": "#/components/schemas/Repository"},
+					"after": {"$ref": "#/components/schemas/Repository"},
+					"created_at": {"type": "string", "format": "date-time"}
+				}
+			},
+			"Collaborator": {
+				"type": "object",
+				"properties": {
+					"login": {"type": "string"},
+					"type": {"type": "string", "enum": ["user", "team"]},
+					"permission": {"type": "string", "enum": ["read", "write", "admin"]},
+					"synced_at": {"type": "string", "format": "date-time"}
+				}
+			},
+			"CodeOwners": {
+				"type": "object",
+				"properties": {
+					"path": {"type": "string"},
+					"commit_sha": {"type": "string"},
+					"rules": {
+						"type": "array",
+						"items": {
+							"type": "object",
+							"properties": {
+								"pattern": {"type": "string"},
+								"owners": {"type": "array", "items": {"type": "string"}},
+								"line": {"type": "integer"}
+							}
+						}
+					},
+					"errors": {"type": "array", "items": {"type": "string"}},
+					"refreshed_at": {"type": "string", "format": "date-time"}
+				}
+			},
+			"FileOwners": {
+				"type": "object",
+				"properties": {
+					"path": {"type": "string"},
+					"owners": {"type": "array", "items": {"type": "string"}},
+					"pattern": {"type": "string"},
+					"line": {"type": "integer"}
+				}
+			},
+			"Subscription": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "integer"},
+					"url": {"type": "string"},
+					"events": {"type": "array", "items": {"type": "string"}},
+					"active": {"type": "boolean"},
+					"failures": {"type": "integer"},
+					"created_at": {"type": "string", "format": "date-time"},
+					"disabled_at": {"type": "string", "format": "date-time"}
+				}
+			},
+			"Delivery": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "integer"},
+					"subscription_id": {"type": "integer"},
+					"event_id": {"type": "integer"},
+					"attempt": {"type": "integer"},
+					"status_code": {"type": "integer"},
+					"error": {"type": "string"},
+					"latency_ms": {"type": "integer"},
+					"delivered_at": {"type": "string", "format": "date-time"}
+				}
+			},
+			"Job": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "integer"},
+					"type": {"type": "string"},
+					"key": {"type": "string"},
+					"payload": {"type": "object"},
+					"state": {"type": "string", "enum": ["pending", "running", "succeeded", "dead", "cancelled"]},
+					"attempts": {"type": "integer"},
+					"max_attempts": {"type": "integer"},
+					"last_error": {"type": "string"},
+					"run_at": {"type": "string", "format": "date-time"},
+					"locked_until": {"type": "string", "format": "date-time"},
+					"created_at": {"type": "string", "format": "date-time"},
+					"finished_at": {"type": "string", "format": "date-time"}
+				}
+			},
+			"Operation": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "integer"},
+					"type": {"type": "string"},
+					"status": {"type": "string", "enum": ["running", "succeeded", "failed"]},
+					"repository": {"type": "string"},
+					"repository_state": {"type": "string", "enum": ["active", "inactive", "pending", "failed"]},
+					"attempts": {"type": "integer"},
+					"max_attempts": {"type": "integer"},
+					"error": {"type": "string"},
+					"created_at": {"type": "string", "format": "date-time"},
+					"finished_at": {"type": "string", "format": "date-time"}
+				}
+			},
+			"Problem": {
+				"type": "object",
+				"properties": {
+					"type": {"type": "string"},
+					"title": {"type": "string"},
+					"status": {"type": "integer"},
+					"detail": {"type": "string"}
+				}
+			},
+			"ImportReport": {
+				"type": "object",
+				"properties": {
+					"dry_run": {"type": "boolean"},
+					"created": {"type": "integer"},
+					"updated": {"type": "integer"},
+					"unchanged": {"type": "integer"},
+					"failed": {"type": "integer"},
+					"results": {
+						"type": "array",
+						"items": {
+							"type": "object",
+							"properties": {
+								"line": {"type": "integer"},
+								"full_name": {"type": "string"},
+								"result": {"type": "string", "enum": ["created", "updated", "unchanged", "failed"]},
+								"error": {"type": "string"}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}`
@@ -0,0 +1,171 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPISpec_Routes(t *testing.T) {
+	doc, err := parseOpenAPIDocument(openAPISpec)
+	require.NoError(t, err)
+
+	param := regexp.MustCompile(`:(\w+)`)
+
+	registered := make(map[string]bool)
+
+	for _, r := range (&Handlers{}).routes() {
+		path := param.ReplaceAllString(r.Pattern, "{$1}")
+		method := strings.ToLower(r.Method)
+
+		registered[method+" "+path] = true
+
+		_, ok := doc.Paths[path][method]
+		assert.True(t, ok, "%s %s is not described in OpenAPI document", r.Method, path)
+	}
+
+	for path, operations := range doc.Paths {
+		for method, op := range operations {
+			assert.True(t, registered[method+" "+path], "%s %s is described in OpenAPI document but not routed", strings.ToUpper(method), path)
+			assert.NotEmpty(t, op.OperationID, "%s %s has no operationId", strings.ToUpper(method), path)
+		}
+	}
+}
+
+func TestOpenAPIDocument_Operation(t *testing.T) {
+	doc, err := parseOpenAPIDocument(openAPISpec)
+	require.NoError(t, err)
+
+	results := []struct {
+		Method      string
+		Path        string
+		OperationID string
+	}{
+		{Method: "GET", Path: "/repositories/blamewarrior/repos", OperationID: "getRepository"},
+		{Method: "GET", Path: "/repositories/blamewarrior/github", OperationID: "listGithubRepositories"},
+		{Method: "GET", Path: "/repositories/blamewarrior", OperationID: "listRepositories"},
+		{Method: "GET", Path: "/repositories/export", OperationID: "exportRepositories"},
+		{Method: "POST", Path: "/repositories/import", OperationID: "importRepositories"},
+		{Method: "PUT", Path: "/repositories/blamewarrior/repos/settings", OperationID: "updateRepositorySettings"},
+		{Method: "PUT", Path: "/owners/blamewarrior/settings", OperationID: "updateOwnerSettings"},
+		{Method: "PUT", Path: "/owners/gitlab/blamewarrior/settings", OperationID: "updateProviderOwnerSettings"},
+		{Method: "POST", Path: "/repositories/blamewarrior/repos", OperationID: ""},
+		{Method: "GET", Path: "/repositories//repos", OperationID: ""},
+		{Method: "GET", Path: "/unknown", OperationID: ""},
+	}
+
+	for _, result := range results {
+		op := doc.Operation(result.Method, result.Path)
+
+		if result.OperationID == "" {
+			assert.Nil(t, op, "%s %s", result.Method, result.Path)
+			continue
+		}
+
+		require.NotNil(t, op, "%s %s", result.Method, result.Path)
+		assert.Equal(t, result.OperationID, op.OperationID)
+	}
+}
+
+func TestValidateRequests(t *testing.T) {
+	doc, err := parseOpenAPIDocument(openAPISpec)
+	require.NoError(t, err)
+
+	var passed string
+
+	handler := validateRequests(doc, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		passed = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	results := []struct {
+		Method         string
+		Path           string
+		RequestBody    string
+		ResponseCode   int
+		ExpectedDetail string
+	}{
+		{Method: "POST", Path: "/repositories", RequestBody: `{"owner":"blamewarrior","name":"repos"}`, ResponseCode: http.StatusNoContent},
+		{Method: "POST", Path: "/repositories", RequestBody: `{"owner":"blamewarrior"}`, ResponseCode: http.StatusBadRequest, ExpectedDetail: "name is required"},
+		{Method: "POST", Path: "/repositories", RequestBody: `{"owner":"blamewarrior","name":"repos","private":"yes"}`, ResponseCode: http.StatusBadRequest, ExpectedDetail: "private must be boolean"},
+		{Method: "POST", Path: "/repositories", RequestBody: `{"owner":`, ResponseCode: http.StatusBadRequest},
+		{Method: "POST", Path: "/repositories", RequestBody: "", ResponseCode: http.StatusBadRequest, ExpectedDetail: "request body is required"},
+		{Method: "PATCH", Path: "/repositories/blamewarrior/repos", RequestBody: `[]`, ResponseCode: http.StatusBadRequest, ExpectedDetail: "document must be object"},
+		{Method: "PATCH", Path: "/repositories/blamewarrior/repos", RequestBody: `{"private":true}`, ResponseCode: http.StatusNoContent},
+		{Method: "POST", Path: "/subscriptions", RequestBody: `{"url":"https://example.com","secret":"s","events":["pushed"]}`, ResponseCode: http.StatusBadRequest, ExpectedDetail: "events[0] has unexpected value"},
+		{Method: "PUT", Path: "/owners/blamewarrior/settings", RequestBody: `{"ignored_paths":[]}`, ResponseCode: http.StatusBadRequest, ExpectedDetail: "version is required"},
+		// operations without request body are passed as is
+		{Method: "POST", Path: "/repositories/blamewarrior/repos/restore", RequestBody: "whatever", ResponseCode: http.StatusNoContent},
+		{Method: "POST", Path: "/webhooks/github", RequestBody: `{"zen":"Keep it simple"}`, ResponseCode: http.StatusNoContent},
+		{Method: "POST", Path: "/repositories/import", RequestBody: "full_name\nblamewarrior/repos\n", ResponseCode: http.StatusNoContent},
+	}
+
+	for _, result := range results {
+		passed = ""
+
+		req, err := http.NewRequest(result.Method, result.Path, strings.NewReader(result.RequestBody))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		require.Equal(t, result.ResponseCode, w.Code, "%s %s %s", result.Method, result.Path, result.RequestBody)
+
+		if result.ResponseCode != http.StatusBadRequest {
+			assert.Equal(t, result.RequestBody, passed)
+			continue
+		}
+
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+		var problem struct {
+			Status int    `json:"status"`
+			Title  string `json:"title"`
+			Detail string `json:"detail"`
+		}
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, http.StatusBadRequest, problem.Status)
+		assert.Equal(t, "Bad Request", problem.Title)
+		assert.Contains(t, problem.Detail, result.ExpectedDetail)
+	}
+}
+
+func TestGetOpenAPISpecHandler(t *testing.T) {
+	req, err := http.NewRequest("GET", "/openapi.json", nil)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	(&Handlers{}).GetOpenAPISpec(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.0.0", doc["openapi"])
+}
@@ -0,0 +1,482 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: hooks.proto
+
+package hooksv1
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Hook struct {
+	// full_name is repository name in the form of "owner/name", repositories hosted at
+	// providers other than GitHub are prefixed with provider, e.g. "gitlab:owner/name"
+	FullName             string   `protobuf:"bytes,1,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	Active               bool     `protobuf:"varint,2,opt,name=active,proto3" json:"active,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Hook) Reset()         { *m = Hook{} }
+func (m *Hook) String() string { return proto.CompactTextString(m) }
+func (*Hook) ProtoMessage()    {}
+func (*Hook) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hooks_1e5ec577dea80e77, []int{0}
+}
+func (m *Hook) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Hook.Unmarshal(m, b)
+}
+func (m *Hook) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Hook.Marshal(b, m, deterministic)
+}
+func (dst *Hook) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Hook.Merge(dst, src)
+}
+func (m *Hook) XXX_Size() int {
+	return xxx_messageInfo_Hook.Size(m)
+}
+func (m *Hook) XXX_DiscardUnknown() {
+	xxx_messageInfo_Hook.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Hook proto.InternalMessageInfo
+
+func (m *Hook) GetFullName() string {
+	if m != nil {
+		return m.FullName
+	}
+	return ""
+}
+
+func (m *Hook) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+type CreateHookRequest struct {
+	FullName             string   `protobuf:"bytes,1,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateHookRequest) Reset()         { *m = CreateHookRequest{} }
+func (m *CreateHookRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateHookRequest) ProtoMessage()    {}
+func (*CreateHookRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hooks_1e5ec577dea80e77, []int{1}
+}
+func (m *CreateHookRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateHookRequest.Unmarshal(m, b)
+}
+func (m *CreateHookRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateHookRequest.Marshal(b, m, deterministic)
+}
+func (dst *CreateHookRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateHookRequest.Merge(dst, src)
+}
+func (m *CreateHookRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateHookRequest.Size(m)
+}
+func (m *CreateHookRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateHookRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateHookRequest proto.InternalMessageInfo
+
+func (m *CreateHookRequest) GetFullName() string {
+	if m != nil {
+		return m.FullName
+	}
+	return ""
+}
+
+type DeleteHookRequest struct {
+	FullName             string   `protobuf:"bytes,1,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteHookRequest) Reset()         { *m = DeleteHookRequest{} }
+func (m *DeleteHookRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteHookRequest) ProtoMessage()    {}
+func (*DeleteHookRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hooks_1e5ec577dea80e77, []int{2}
+}
+func (m *DeleteHookRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteHookRequest.Unmarshal(m, b)
+}
+func (m *DeleteHookRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteHookRequest.Marshal(b, m, deterministic)
+}
+func (dst *DeleteHookRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteHookRequest.Merge(dst, src)
+}
+func (m *DeleteHookRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteHookRequest.Size(m)
+}
+func (m *DeleteHookRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteHookRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteHookRequest proto.InternalMessageInfo
+
+func (m *DeleteHookRequest) GetFullName() string {
+	if m != nil {
+		return m.FullName
+	}
+	return ""
+}
+
+type DeleteHookResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteHookResponse) Reset()         { *m = DeleteHookResponse{} }
+func (m *DeleteHookResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteHookResponse) ProtoMessage()    {}
+func (*DeleteHookResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hooks_1e5ec577dea80e77, []int{3}
+}
+func (m *DeleteHookResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteHookResponse.Unmarshal(m, b)
+}
+func (m *DeleteHookResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteHookResponse.Marshal(b, m, deterministic)
+}
+func (dst *DeleteHookResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteHookResponse.Merge(dst, src)
+}
+func (m *DeleteHookResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteHookResponse.Size(m)
+}
+func (m *DeleteHookResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteHookResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteHookResponse proto.InternalMessageInfo
+
+type UpdateHookRequest struct {
+	FullName             string   `protobuf:"bytes,1,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	NewFullName          string   `protobuf:"bytes,2,opt,name=new_full_name,json=newFullName,proto3" json:"new_full_name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateHookRequest) Reset()         { *m = UpdateHookRequest{} }
+func (m *UpdateHookRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateHookRequest) ProtoMessage()    {}
+func (*UpdateHookRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hooks_1e5ec577dea80e77, []int{4}
+}
+func (m *UpdateHookRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateHookRequest.Unmarshal(m, b)
+}
+func (m *UpdateHookRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateHookRequest.Marshal(b, m, deterministic)
+}
+func (dst *UpdateHookRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateHookRequest.Merge(dst, src)
+}
+func (m *UpdateHookRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateHookRequest.Size(m)
+}
+func (m *UpdateHookRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateHookRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateHookRequest proto.InternalMessageInfo
+
+func (m *UpdateHookRequest) GetFullName() string {
+	if m != nil {
+		return m.FullName
+	}
+	return ""
+}
+
+func (m *UpdateHookRequest) GetNewFullName() string {
+	if m != nil {
+		return m.NewFullName
+	}
+	return ""
+}
+
+type SetHookActiveRequest struct {
+	FullName             string   `protobuf:"bytes,1,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	Active               bool     `protobuf:"varint,2,opt,name=active,proto3" json:"active,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetHookActiveRequest) Reset()         { *m = SetHookActiveRequest{} }
+func (m *SetHookActiveRequest) String() string { return proto.CompactTextString(m) }
+func (*SetHookActiveRequest) ProtoMessage()    {}
+func (*SetHookActiveRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_hooks_1e5ec577dea80e77, []int{5}
+}
+func (m *SetHookActiveRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetHookActiveRequest.Unmarshal(m, b)
+}
+func (m *SetHookActiveRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetHookActiveRequest.Marshal(b, m, deterministic)
+}
+func (dst *SetHookActiveRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetHookActiveRequest.Merge(dst, src)
+}
+func (m *SetHookActiveRequest) XXX_Size() int {
+	return xxx_messageInfo_SetHookActiveRequest.Size(m)
+}
+func (m *SetHookActiveRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetHookActiveRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetHookActiveRequest proto.InternalMessageInfo
+
+func (m *SetHookActiveRequest) GetFullName() string {
+	if m != nil {
+		return m.FullName
+	}
+	return ""
+}
+
+func (m *SetHookActiveRequest) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterType((*Hook)(nil), "blamewarrior.hooks.v1.Hook")
+	proto.RegisterType((*CreateHookRequest)(nil), "blamewarrior.hooks.v1.CreateHookRequest")
+	proto.RegisterType((*DeleteHookRequest)(nil), "blamewarrior.hooks.v1.DeleteHookRequest")
+	proto.RegisterType((*DeleteHookResponse)(nil), "blamewarrior.hooks.v1.DeleteHookResponse")
+	proto.RegisterType((*UpdateHookRequest)(nil), "blamewarrior.hooks.v1.UpdateHookRequest")
+	proto.RegisterType((*SetHookActiveRequest)(nil), "blamewarrior.hooks.v1.SetHookActiveRequest")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// HookServiceClient is the client API for HookService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type HookServiceClient interface {
+	// CreateHook installs webhook into repository.
+	CreateHook(ctx context.Context, in *CreateHookRequest, opts ...grpc.CallOption) (*Hook, error)
+	// DeleteHook removes webhook from repository.
+	DeleteHook(ctx context.Context, in *DeleteHookRequest, opts ...grpc.CallOption) (*DeleteHookResponse, error)
+	// UpdateHook moves webhook along with renamed or transferred repository.
+	UpdateHook(ctx context.Context, in *UpdateHookRequest, opts ...grpc.CallOption) (*Hook, error)
+	// SetHookActive enables or disables delivery of events from webhook without removing it.
+	SetHookActive(ctx context.Context, in *SetHookActiveRequest, opts ...grpc.CallOption) (*Hook, error)
+}
+
+type hookServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewHookServiceClient(cc *grpc.ClientConn) HookServiceClient {
+	return &hookServiceClient{cc}
+}
+
+func (c *hookServiceClient) CreateHook(ctx context.Context, in *CreateHookRequest, opts ...grpc.CallOption) (*Hook, error) {
+	out := new(Hook)
+	err := c.cc.Invoke(ctx, "/blamewarrior.hooks.v1.HookService/CreateHook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hookServiceClient) DeleteHook(ctx context.Context, in *DeleteHookRequest, opts ...grpc.CallOption) (*DeleteHookResponse, error) {
+	out := new(DeleteHookResponse)
+	err := c.cc.Invoke(ctx, "/blamewarrior.hooks.v1.HookService/DeleteHook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hookServiceClient) UpdateHook(ctx context.Context, in *UpdateHookRequest, opts ...grpc.CallOption) (*Hook, error) {
+	out := new(Hook)
+	err := c.cc.Invoke(ctx, "/blamewarrior.hooks.v1.HookService/UpdateHook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hookServiceClient) SetHookActive(ctx context.Context, in *SetHookActiveRequest, opts ...grpc.CallOption) (*Hook, error) {
+	out := new(Hook)
+	err := c.cc.Invoke(ctx, "/blamewarrior.hooks.v1.HookService/SetHookActive", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HookServiceServer is the server API for HookService service.
+type HookServiceServer interface {
+	// CreateHook installs webhook into repository.
+	CreateHook(context.Context, *CreateHookRequest) (*Hook, error)
+	// DeleteHook removes webhook from repository.
+	DeleteHook(context.Context, *DeleteHookRequest) (*DeleteHookResponse, error)
+	// UpdateHook moves webhook along with renamed or transferred repository.
+	UpdateHook(context.Context, *UpdateHookRequest) (*Hook, error)
+	// SetHookActive enables or disables delivery of events from webhook without removing it.
+	SetHookActive(context.Context, *SetHookActiveRequest) (*Hook, error)
+}
+
+func RegisterHookServiceServer(s *grpc.Server, srv HookServiceServer) {
+	s.RegisterService(&_HookService_serviceDesc, srv)
+}
+
+func _HookService_CreateHook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateHookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HookServiceServer).CreateHook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blamewarrior.hooks.v1.HookService/CreateHook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HookServiceServer).CreateHook(ctx, req.(*CreateHookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HookService_DeleteHook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteHookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HookServiceServer).DeleteHook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blamewarrior.hooks.v1.HookService/DeleteHook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HookServiceServer).DeleteHook(ctx, req.(*DeleteHookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HookService_UpdateHook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateHookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HookServiceServer).UpdateHook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blamewarrior.hooks.v1.HookService/UpdateHook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HookServiceServer).UpdateHook(ctx, req.(*UpdateHookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HookService_SetHookActive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetHookActiveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HookServiceServer).SetHookActive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blamewarrior.hooks.v1.HookService/SetHookActive",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HookServiceServer).SetHookActive(ctx, req.(*SetHookActiveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _HookService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "blamewarrior.hooks.v1.HookService",
+	HandlerType: (*HookServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateHook",
+			Handler:    _HookService_CreateHook_Handler,
+		},
+		{
+			MethodName: "DeleteHook",
+			Handler:    _HookService_DeleteHook_Handler,
+		},
+		{
+			MethodName: "UpdateHook",
+			Handler:    _HookService_UpdateHook_Handler,
+		},
+		{
+			MethodName: "SetHookActive",
+			Handler:    _HookService_SetHookActive_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "hooks.proto",
+}
+
+func init() { proto.RegisterFile("hooks.proto", fileDescriptor_hooks_1e5ec577dea80e77) }
+
+var fileDescriptor_hooks_1e5ec577dea80e77 = []byte{
+	// 285 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x92, 0x4f, 0x4f, 0x83, 0x40,
+	0x10, 0x47, 0x03, 0x31, 0xb5, 0x0c, 0xe9, 0xa1, 0x9b, 0x6a, 0x9a, 0xf6, 0xd2, 0x70, 0xc2, 0x98,
+	0x10, 0xab, 0x47, 0x4f, 0xfe, 0x89, 0x31, 0x31, 0xf1, 0x00, 0x7a, 0xd0, 0x4b, 0xb3, 0xad, 0x63,
+	0x24, 0x05, 0x06, 0x97, 0x2d, 0x7c, 0x72, 0xef, 0x66, 0xb7, 0x1a, 0x34, 0x74, 0x49, 0x39, 0x32,
+	0xfc, 0xe6, 0xcd, 0xf0, 0x06, 0x70, 0x3f, 0x88, 0xd6, 0x45, 0x90, 0x0b, 0x92, 0xc4, 0x8e, 0x96,
+	0x09, 0x4f, 0xb1, 0xe2, 0x42, 0xc4, 0x24, 0x82, 0xed, 0x9b, 0x72, 0xee, 0x5d, 0xc2, 0xc1, 0x3d,
+	0xd1, 0x9a, 0x4d, 0xc1, 0x79, 0xdf, 0x24, 0xc9, 0x22, 0xe3, 0x29, 0x8e, 0xad, 0x99, 0xe5, 0x3b,
+	0x61, 0x5f, 0x15, 0x1e, 0x79, 0x8a, 0xec, 0x18, 0x7a, 0x7c, 0x25, 0xe3, 0x12, 0xc7, 0xf6, 0xcc,
+	0xf2, 0xfb, 0xe1, 0xcf, 0x93, 0x77, 0x06, 0xc3, 0x1b, 0x81, 0x5c, 0xa2, 0x42, 0x84, 0xf8, 0xb9,
+	0xc1, 0x42, 0xb6, 0x92, 0x54, 0xc7, 0x2d, 0x26, 0xd8, 0xa1, 0x63, 0x04, 0xec, 0x6f, 0x47, 0x91,
+	0x53, 0x56, 0xa0, 0xf7, 0x04, 0xc3, 0xe7, 0xfc, 0xad, 0xc3, 0x64, 0xe6, 0xc1, 0x20, 0xc3, 0x6a,
+	0x51, 0x07, 0x6c, 0x1d, 0x70, 0x33, 0xac, 0xee, 0x7e, 0x67, 0x3d, 0xc0, 0x28, 0x42, 0xa9, 0x90,
+	0x57, 0xfa, 0x03, 0xf7, 0x02, 0x1b, 0xe4, 0x9c, 0x7f, 0xd9, 0xe0, 0x2a, 0x54, 0x84, 0xa2, 0x8c,
+	0x57, 0xc8, 0x22, 0x80, 0x5a, 0x16, 0xf3, 0x83, 0x9d, 0xf7, 0x08, 0x1a, 0x3e, 0x27, 0x53, 0x43,
+	0x52, 0x63, 0x38, 0x40, 0x6d, 0xc7, 0x08, 0x6d, 0x28, 0x9f, 0x9c, 0xec, 0x91, 0xdc, 0xaa, 0x56,
+	0x7b, 0xd7, 0xaa, 0x8d, 0x23, 0x1a, 0xd7, 0x68, 0xdf, 0xfb, 0x05, 0x06, 0xff, 0x4c, 0xb3, 0x53,
+	0x43, 0x7a, 0xd7, 0x3d, 0x5a, 0xd1, 0xd7, 0xce, 0xeb, 0xa1, 0x2e, 0x94, 0xf3, 0x65, 0x4f, 0xff,
+	0xfa, 0x17, 0xdf, 0x03, 0x00, 0xe1, 0x27, 0x7c, 0x64, 0x09, 0x03, 0x00, 0x00,
+}
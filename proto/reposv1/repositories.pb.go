@@ -0,0 +1,1107 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: repositories.proto
+
+package reposv1
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import timestamp "github.com/golang/protobuf/ptypes/timestamp"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Repository struct {
+	Id            int64                `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Owner         string               `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Name          string               `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Private       bool                 `protobuf:"varint,4,opt,name=private,proto3" json:"private,omitempty"`
+	State         string               `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
+	DeletedAt     *timestamp.Timestamp `protobuf:"bytes,6,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	InactiveSince *timestamp.Timestamp `protobuf:"bytes,7,opt,name=inactive_since,json=inactiveSince,proto3" json:"inactive_since,omitempty"`
+	Metadata      *RepositoryMetadata  `protobuf:"bytes,8,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// settings is the JSON document of repository settings
+	SettingsJson string `protobuf:"bytes,9,opt,name=settings_json,json=settingsJson,proto3" json:"settings_json,omitempty"`
+	// provider is the code hosting service repository is stored at, "github" or "gitlab"
+	Provider             string   `protobuf:"bytes,10,opt,name=provider,proto3" json:"provider,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Repository) Reset()         { *m = Repository{} }
+func (m *Repository) String() string { return proto.CompactTextString(m) }
+func (*Repository) ProtoMessage()    {}
+func (*Repository) Descriptor() ([]byte, []int) {
+	return fileDescriptor_repositories_2c2613d5498e3413, []int{0}
+}
+func (m *Repository) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Repository.Unmarshal(m, b)
+}
+func (m *Repository) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Repository.Marshal(b, m, deterministic)
+}
+func (dst *Repository) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Repository.Merge(dst, src)
+}
+func (m *Repository) XXX_Size() int {
+	return xxx_messageInfo_Repository.Size(m)
+}
+func (m *Repository) XXX_DiscardUnknown() {
+	xxx_messageInfo_Repository.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Repository proto.InternalMessageInfo
+
+func (m *Repository) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Repository) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Repository) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Repository) GetPrivate() bool {
+	if m != nil {
+		return m.Private
+	}
+	return false
+}
+
+func (m *Repository) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *Repository) GetDeletedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.DeletedAt
+	}
+	return nil
+}
+
+func (m *Repository) GetInactiveSince() *timestamp.Timestamp {
+	if m != nil {
+		return m.InactiveSince
+	}
+	return nil
+}
+
+func (m *Repository) GetMetadata() *RepositoryMetadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *Repository) GetSettingsJson() string {
+	if m != nil {
+		return m.SettingsJson
+	}
+	return ""
+}
+
+func (m *Repository) GetProvider() string {
+	if m != nil {
+		return m.Provider
+	}
+	return ""
+}
+
+type RepositoryMetadata struct {
+	Description string               `protobuf:"bytes,1,opt,name=description,proto3" json:"description,omitempty"`
+	Language    string               `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	Topics      []string             `protobuf:"bytes,3,rep,name=topics,proto3" json:"topics,omitempty"`
+	Size        int32                `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Stars       int32                `protobuf:"varint,5,opt,name=stars,proto3" json:"stars,omitempty"`
+	PushedAt    *timestamp.Timestamp `protobuf:"bytes,6,opt,name=pushed_at,json=pushedAt,proto3" json:"pushed_at,omitempty"`
+	Archived    bool                 `protobuf:"varint,7,opt,name=archived,proto3" json:"archived,omitempty"`
+	// provider_id is the ID of repository at provider, it does not change when repository is renamed
+	ProviderId           int64    `protobuf:"varint,8,opt,name=provider_id,json=providerId,proto3" json:"provider_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RepositoryMetadata) Reset()         { *m = RepositoryMetadata{} }
+func (m *RepositoryMetadata) String() string { return proto.CompactTextString(m) }
+func (*RepositoryMetadata) ProtoMessage()    {}
+func (*RepositoryMetadata) Descriptor() ([]byte, []int) {
+	return fileDescriptor_repositories_2c2613d5498e3413, []int{1}
+}
+func (m *RepositoryMetadata) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RepositoryMetadata.Unmarshal(m, b)
+}
+func (m *RepositoryMetadata) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RepositoryMetadata.Marshal(b, m, deterministic)
+}
+func (dst *RepositoryMetadata) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RepositoryMetadata.Merge(dst, src)
+}
+func (m *RepositoryMetadata) XXX_Size() int {
+	return xxx_messageInfo_RepositoryMetadata.Size(m)
+}
+func (m *RepositoryMetadata) XXX_DiscardUnknown() {
+	xxx_messageInfo_RepositoryMetadata.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RepositoryMetadata proto.InternalMessageInfo
+
+func (m *RepositoryMetadata) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *RepositoryMetadata) GetLanguage() string {
+	if m != nil {
+		return m.Language
+	}
+	return ""
+}
+
+func (m *RepositoryMetadata) GetTopics() []string {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+func (m *RepositoryMetadata) GetSize() int32 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *RepositoryMetadata) GetStars() int32 {
+	if m != nil {
+		return m.Stars
+	}
+	return 0
+}
+
+func (m *RepositoryMetadata) GetPushedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.PushedAt
+	}
+	return nil
+}
+
+func (m *RepositoryMetadata) GetArchived() bool {
+	if m != nil {
+		return m.Archived
+	}
+	return false
+}
+
+func (m *RepositoryMetadata) GetProviderId() int64 {
+	if m != nil {
+		return m.ProviderId
+	}
+	return 0
+}
+
+type GetRequest struct {
+	// full_name is repository name in the form of "owner/name", repositories hosted at
+	// providers other than GitHub are prefixed with provider, e.g. "gitlab:owner/name"
+	FullName             string   `protobuf:"bytes,1,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	IncludeDeleted       bool     `protobuf:"varint,2,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetRequest) Reset()         { *m = GetRequest{} }
+func (m *GetRequest) String() string { return proto.CompactTextString(m) }
+func (*GetRequest) ProtoMessage()    {}
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_repositories_2c2613d5498e3413, []int{2}
+}
+func (m *GetRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetRequest.Unmarshal(m, b)
+}
+func (m *GetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetRequest.Merge(dst, src)
+}
+func (m *GetRequest) XXX_Size() int {
+	return xxx_messageInfo_GetRequest.Size(m)
+}
+func (m *GetRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetRequest proto.InternalMessageInfo
+
+func (m *GetRequest) GetFullName() string {
+	if m != nil {
+		return m.FullName
+	}
+	return ""
+}
+
+func (m *GetRequest) GetIncludeDeleted() bool {
+	if m != nil {
+		return m.IncludeDeleted
+	}
+	return false
+}
+
+type ListByOwnerRequest struct {
+	Owner          string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	IncludeDeleted bool   `protobuf:"varint,2,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted,omitempty"`
+	Language       string `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	Topic          string `protobuf:"bytes,4,opt,name=topic,proto3" json:"topic,omitempty"`
+	State          string `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
+	// sort is one of "name", "pushed" or "stars"
+	Sort        string               `protobuf:"bytes,6,opt,name=sort,proto3" json:"sort,omitempty"`
+	PushedSince *timestamp.Timestamp `protobuf:"bytes,7,opt,name=pushed_since,json=pushedSince,proto3" json:"pushed_since,omitempty"`
+	// provider defaults to "github"
+	Provider             string   `protobuf:"bytes,8,opt,name=provider,proto3" json:"provider,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListByOwnerRequest) Reset()         { *m = ListByOwnerRequest{} }
+func (m *ListByOwnerRequest) String() string { return proto.CompactTextString(m) }
+func (*ListByOwnerRequest) ProtoMessage()    {}
+func (*ListByOwnerRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_repositories_2c2613d5498e3413, []int{3}
+}
+func (m *ListByOwnerRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListByOwnerRequest.Unmarshal(m, b)
+}
+func (m *ListByOwnerRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListByOwnerRequest.Marshal(b, m, deterministic)
+}
+func (dst *ListByOwnerRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListByOwnerRequest.Merge(dst, src)
+}
+func (m *ListByOwnerRequest) XXX_Size() int {
+	return xxx_messageInfo_ListByOwnerRequest.Size(m)
+}
+func (m *ListByOwnerRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListByOwnerRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListByOwnerRequest proto.InternalMessageInfo
+
+func (m *ListByOwnerRequest) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *ListByOwnerRequest) GetIncludeDeleted() bool {
+	if m != nil {
+		return m.IncludeDeleted
+	}
+	return false
+}
+
+func (m *ListByOwnerRequest) GetLanguage() string {
+	if m != nil {
+		return m.Language
+	}
+	return ""
+}
+
+func (m *ListByOwnerRequest) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *ListByOwnerRequest) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *ListByOwnerRequest) GetSort() string {
+	if m != nil {
+		return m.Sort
+	}
+	return ""
+}
+
+func (m *ListByOwnerRequest) GetPushedSince() *timestamp.Timestamp {
+	if m != nil {
+		return m.PushedSince
+	}
+	return nil
+}
+
+func (m *ListByOwnerRequest) GetProvider() string {
+	if m != nil {
+		return m.Provider
+	}
+	return ""
+}
+
+type ListRepositoriesResponse struct {
+	Repositories         []*Repository `protobuf:"bytes,1,rep,name=repositories,proto3" json:"repositories,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ListRepositoriesResponse) Reset()         { *m = ListRepositoriesResponse{} }
+func (m *ListRepositoriesResponse) String() string { return proto.CompactTextString(m) }
+func (*ListRepositoriesResponse) ProtoMessage()    {}
+func (*ListRepositoriesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_repositories_2c2613d5498e3413, []int{4}
+}
+func (m *ListRepositoriesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListRepositoriesResponse.Unmarshal(m, b)
+}
+func (m *ListRepositoriesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListRepositoriesResponse.Marshal(b, m, deterministic)
+}
+func (dst *ListRepositoriesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListRepositoriesResponse.Merge(dst, src)
+}
+func (m *ListRepositoriesResponse) XXX_Size() int {
+	return xxx_messageInfo_ListRepositoriesResponse.Size(m)
+}
+func (m *ListRepositoriesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListRepositoriesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListRepositoriesResponse proto.InternalMessageInfo
+
+func (m *ListRepositoriesResponse) GetRepositories() []*Repository {
+	if m != nil {
+		return m.Repositories
+	}
+	return nil
+}
+
+type CreateRequest struct {
+	Owner        string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Name         string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Private      bool   `protobuf:"varint,3,opt,name=private,proto3" json:"private,omitempty"`
+	SettingsJson string `protobuf:"bytes,4,opt,name=settings_json,json=settingsJson,proto3" json:"settings_json,omitempty"`
+	// actor and request_id are recorded in the audit log
+	Actor     string `protobuf:"bytes,5,opt,name=actor,proto3" json:"actor,omitempty"`
+	RequestId string `protobuf:"bytes,6,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// provider defaults to "github"
+	Provider             string   `protobuf:"bytes,7,opt,name=provider,proto3" json:"provider,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateRequest) Reset()         { *m = CreateRequest{} }
+func (m *CreateRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateRequest) ProtoMessage()    {}
+func (*CreateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_repositories_2c2613d5498e3413, []int{5}
+}
+func (m *CreateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateRequest.Unmarshal(m, b)
+}
+func (m *CreateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateRequest.Marshal(b, m, deterministic)
+}
+func (dst *CreateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateRequest.Merge(dst, src)
+}
+func (m *CreateRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateRequest.Size(m)
+}
+func (m *CreateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateRequest proto.InternalMessageInfo
+
+func (m *CreateRequest) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *CreateRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CreateRequest) GetPrivate() bool {
+	if m != nil {
+		return m.Private
+	}
+	return false
+}
+
+func (m *CreateRequest) GetSettingsJson() string {
+	if m != nil {
+		return m.SettingsJson
+	}
+	return ""
+}
+
+func (m *CreateRequest) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *CreateRequest) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+func (m *CreateRequest) GetProvider() string {
+	if m != nil {
+		return m.Provider
+	}
+	return ""
+}
+
+type DeleteRequest struct {
+	FullName             string   `protobuf:"bytes,1,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	Actor                string   `protobuf:"bytes,2,opt,name=actor,proto3" json:"actor,omitempty"`
+	RequestId            string   `protobuf:"bytes,3,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
+func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteRequest) ProtoMessage()    {}
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_repositories_2c2613d5498e3413, []int{6}
+}
+func (m *DeleteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteRequest.Unmarshal(m, b)
+}
+func (m *DeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteRequest.Marshal(b, m, deterministic)
+}
+func (dst *DeleteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteRequest.Merge(dst, src)
+}
+func (m *DeleteRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteRequest.Size(m)
+}
+func (m *DeleteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteRequest proto.InternalMessageInfo
+
+func (m *DeleteRequest) GetFullName() string {
+	if m != nil {
+		return m.FullName
+	}
+	return ""
+}
+
+func (m *DeleteRequest) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *DeleteRequest) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+type DeleteResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteResponse) Reset()         { *m = DeleteResponse{} }
+func (m *DeleteResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteResponse) ProtoMessage()    {}
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_repositories_2c2613d5498e3413, []int{7}
+}
+func (m *DeleteResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteResponse.Unmarshal(m, b)
+}
+func (m *DeleteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteResponse.Marshal(b, m, deterministic)
+}
+func (dst *DeleteResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteResponse.Merge(dst, src)
+}
+func (m *DeleteResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteResponse.Size(m)
+}
+func (m *DeleteResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteResponse proto.InternalMessageInfo
+
+type ListGithubRequest struct {
+	Owner                string   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListGithubRequest) Reset()         { *m = ListGithubRequest{} }
+func (m *ListGithubRequest) String() string { return proto.CompactTextString(m) }
+func (*ListGithubRequest) ProtoMessage()    {}
+func (*ListGithubRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_repositories_2c2613d5498e3413, []int{8}
+}
+func (m *ListGithubRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListGithubRequest.Unmarshal(m, b)
+}
+func (m *ListGithubRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListGithubRequest.Marshal(b, m, deterministic)
+}
+func (dst *ListGithubRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListGithubRequest.Merge(dst, src)
+}
+func (m *ListGithubRequest) XXX_Size() int {
+	return xxx_messageInfo_ListGithubRequest.Size(m)
+}
+func (m *ListGithubRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListGithubRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListGithubRequest proto.InternalMessageInfo
+
+func (m *ListGithubRequest) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+type LookupRequest struct {
+	// full_names may be prefixed with provider the same way as in GetRequest
+	FullNames []string `protobuf:"bytes,1,rep,name=full_names,json=fullNames,proto3" json:"full_names,omitempty"`
+	// ids are IDs of repositories at provider, which defaults to "github"
+	Ids                  []int64  `protobuf:"varint,2,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	Provider             string   `protobuf:"bytes,3,opt,name=provider,proto3" json:"provider,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LookupRequest) Reset()         { *m = LookupRequest{} }
+func (m *LookupRequest) String() string { return proto.CompactTextString(m) }
+func (*LookupRequest) ProtoMessage()    {}
+func (*LookupRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_repositories_2c2613d5498e3413, []int{9}
+}
+func (m *LookupRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LookupRequest.Unmarshal(m, b)
+}
+func (m *LookupRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LookupRequest.Marshal(b, m, deterministic)
+}
+func (dst *LookupRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LookupRequest.Merge(dst, src)
+}
+func (m *LookupRequest) XXX_Size() int {
+	return xxx_messageInfo_LookupRequest.Size(m)
+}
+func (m *LookupRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LookupRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LookupRequest proto.InternalMessageInfo
+
+func (m *LookupRequest) GetFullNames() []string {
+	if m != nil {
+		return m.FullNames
+	}
+	return nil
+}
+
+func (m *LookupRequest) GetIds() []int64 {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+func (m *LookupRequest) GetProvider() string {
+	if m != nil {
+		return m.Provider
+	}
+	return ""
+}
+
+type LookupResult struct {
+	FullName string `protobuf:"bytes,1,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	Id       int64  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	// found is false if repository is not tracked
+	Found                bool        `protobuf:"varint,3,opt,name=found,proto3" json:"found,omitempty"`
+	Repository           *Repository `protobuf:"bytes,4,opt,name=repository,proto3" json:"repository,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *LookupResult) Reset()         { *m = LookupResult{} }
+func (m *LookupResult) String() string { return proto.CompactTextString(m) }
+func (*LookupResult) ProtoMessage()    {}
+func (*LookupResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_repositories_2c2613d5498e3413, []int{10}
+}
+func (m *LookupResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LookupResult.Unmarshal(m, b)
+}
+func (m *LookupResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LookupResult.Marshal(b, m, deterministic)
+}
+func (dst *LookupResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LookupResult.Merge(dst, src)
+}
+func (m *LookupResult) XXX_Size() int {
+	return xxx_messageInfo_LookupResult.Size(m)
+}
+func (m *LookupResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_LookupResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LookupResult proto.InternalMessageInfo
+
+func (m *LookupResult) GetFullName() string {
+	if m != nil {
+		return m.FullName
+	}
+	return ""
+}
+
+func (m *LookupResult) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *LookupResult) GetFound() bool {
+	if m != nil {
+		return m.Found
+	}
+	return false
+}
+
+func (m *LookupResult) GetRepository() *Repository {
+	if m != nil {
+		return m.Repository
+	}
+	return nil
+}
+
+type LookupResponse struct {
+	// results follow the order of full names and then IDs of the request
+	Results              []*LookupResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *LookupResponse) Reset()         { *m = LookupResponse{} }
+func (m *LookupResponse) String() string { return proto.CompactTextString(m) }
+func (*LookupResponse) ProtoMessage()    {}
+func (*LookupResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_repositories_2c2613d5498e3413, []int{11}
+}
+func (m *LookupResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LookupResponse.Unmarshal(m, b)
+}
+func (m *LookupResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LookupResponse.Marshal(b, m, deterministic)
+}
+func (dst *LookupResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LookupResponse.Merge(dst, src)
+}
+func (m *LookupResponse) XXX_Size() int {
+	return xxx_messageInfo_LookupResponse.Size(m)
+}
+func (m *LookupResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_LookupResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LookupResponse proto.InternalMessageInfo
+
+func (m *LookupResponse) GetResults() []*LookupResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Repository)(nil), "blamewarrior.repos.v1.Repository")
+	proto.RegisterType((*RepositoryMetadata)(nil), "blamewarrior.repos.v1.RepositoryMetadata")
+	proto.RegisterType((*GetRequest)(nil), "blamewarrior.repos.v1.GetRequest")
+	proto.RegisterType((*ListByOwnerRequest)(nil), "blamewarrior.repos.v1.ListByOwnerRequest")
+	proto.RegisterType((*ListRepositoriesResponse)(nil), "blamewarrior.repos.v1.ListRepositoriesResponse")
+	proto.RegisterType((*CreateRequest)(nil), "blamewarrior.repos.v1.CreateRequest")
+	proto.RegisterType((*DeleteRequest)(nil), "blamewarrior.repos.v1.DeleteRequest")
+	proto.RegisterType((*DeleteResponse)(nil), "blamewarrior.repos.v1.DeleteResponse")
+	proto.RegisterType((*ListGithubRequest)(nil), "blamewarrior.repos.v1.ListGithubRequest")
+	proto.RegisterType((*LookupRequest)(nil), "blamewarrior.repos.v1.LookupRequest")
+	proto.RegisterType((*LookupResult)(nil), "blamewarrior.repos.v1.LookupResult")
+	proto.RegisterType((*LookupResponse)(nil), "blamewarrior.repos.v1.LookupResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// RepositoryServiceClient is the client API for RepositoryService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type RepositoryServiceClient interface {
+	// Get returns tracked repository by its full name.
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Repository, error)
+	// ListByOwner returns repositories of owner tracked by BlameWarrior.
+	ListByOwner(ctx context.Context, in *ListByOwnerRequest, opts ...grpc.CallOption) (*ListRepositoriesResponse, error)
+	// Create starts tracking a repository and installs its webhook.
+	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*Repository, error)
+	// Delete stops tracking a repository and removes its webhook.
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// ListGithub returns repositories of owner available on GitHub.
+	ListGithub(ctx context.Context, in *ListGithubRequest, opts ...grpc.CallOption) (*ListRepositoriesResponse, error)
+	// Lookup returns tracked repositories by full names and IDs at provider in a single call.
+	Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupResponse, error)
+}
+
+type repositoryServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewRepositoryServiceClient(cc *grpc.ClientConn) RepositoryServiceClient {
+	return &repositoryServiceClient{cc}
+}
+
+func (c *repositoryServiceClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Repository, error) {
+	out := new(Repository)
+	err := c.cc.Invoke(ctx, "/blamewarrior.repos.v1.RepositoryService/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repositoryServiceClient) ListByOwner(ctx context.Context, in *ListByOwnerRequest, opts ...grpc.CallOption) (*ListRepositoriesResponse, error) {
+	out := new(ListRepositoriesResponse)
+	err := c.cc.Invoke(ctx, "/blamewarrior.repos.v1.RepositoryService/ListByOwner", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repositoryServiceClient) Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*Repository, error) {
+	out := new(Repository)
+	err := c.cc.Invoke(ctx, "/blamewarrior.repos.v1.RepositoryService/Create", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repositoryServiceClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/blamewarrior.repos.v1.RepositoryService/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repositoryServiceClient) ListGithub(ctx context.Context, in *ListGithubRequest, opts ...grpc.CallOption) (*ListRepositoriesResponse, error) {
+	out := new(ListRepositoriesResponse)
+	err := c.cc.Invoke(ctx, "/blamewarrior.repos.v1.RepositoryService/ListGithub", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repositoryServiceClient) Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupResponse, error) {
+	out := new(LookupResponse)
+	err := c.cc.Invoke(ctx, "/blamewarrior.repos.v1.RepositoryService/Lookup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RepositoryServiceServer is the server API for RepositoryService service.
+type RepositoryServiceServer interface {
+	// Get returns tracked repository by its full name.
+	Get(context.Context, *GetRequest) (*Repository, error)
+	// ListByOwner returns repositories of owner tracked by BlameWarrior.
+	ListByOwner(context.Context, *ListByOwnerRequest) (*ListRepositoriesResponse, error)
+	// Create starts tracking a repository and installs its webhook.
+	Create(context.Context, *CreateRequest) (*Repository, error)
+	// Delete stops tracking a repository and removes its webhook.
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// ListGithub returns repositories of owner available on GitHub.
+	ListGithub(context.Context, *ListGithubRequest) (*ListRepositoriesResponse, error)
+	// Lookup returns tracked repositories by full names and IDs at provider in a single call.
+	Lookup(context.Context, *LookupRequest) (*LookupResponse, error)
+}
+
+func RegisterRepositoryServiceServer(s *grpc.Server, srv RepositoryServiceServer) {
+	s.RegisterService(&_RepositoryService_serviceDesc, srv)
+}
+
+func _RepositoryService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepositoryServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blamewarrior.repos.v1.RepositoryService/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepositoryServiceServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepositoryService_ListByOwner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListByOwnerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepositoryServiceServer).ListByOwner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blamewarrior.repos.v1.RepositoryService/ListByOwner",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepositoryServiceServer).ListByOwner(ctx, req.(*ListByOwnerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepositoryService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepositoryServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blamewarrior.repos.v1.RepositoryService/Create",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepositoryServiceServer).Create(ctx, req.(*CreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepositoryService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepositoryServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blamewarrior.repos.v1.RepositoryService/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepositoryServiceServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepositoryService_ListGithub_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGithubRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepositoryServiceServer).ListGithub(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blamewarrior.repos.v1.RepositoryService/ListGithub",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepositoryServiceServer).ListGithub(ctx, req.(*ListGithubRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepositoryService_Lookup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepositoryServiceServer).Lookup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blamewarrior.repos.v1.RepositoryService/Lookup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepositoryServiceServer).Lookup(ctx, req.(*LookupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _RepositoryService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "blamewarrior.repos.v1.RepositoryService",
+	HandlerType: (*RepositoryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _RepositoryService_Get_Handler,
+		},
+		{
+			MethodName: "ListByOwner",
+			Handler:    _RepositoryService_ListByOwner_Handler,
+		},
+		{
+			MethodName: "Create",
+			Handler:    _RepositoryService_Create_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _RepositoryService_Delete_Handler,
+		},
+		{
+			MethodName: "ListGithub",
+			Handler:    _RepositoryService_ListGithub_Handler,
+		},
+		{
+			MethodName: "Lookup",
+			Handler:    _RepositoryService_Lookup_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "repositories.proto",
+}
+
+func init() { proto.RegisterFile("repositories.proto", fileDescriptor_repositories_2c2613d5498e3413) }
+
+var fileDescriptor_repositories_2c2613d5498e3413 = []byte{
+	// 865 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0x51, 0x6f, 0xdc, 0x44,
+	0x10, 0x96, 0xed, 0xdc, 0x9d, 0x3d, 0x97, 0x3b, 0xda, 0x55, 0x40, 0xd6, 0xa1, 0xaa, 0x87, 0x4b,
+	0x85, 0xfb, 0xe2, 0xa8, 0xe1, 0x01, 0xf1, 0xd0, 0x87, 0x14, 0x50, 0x54, 0x28, 0x54, 0x6c, 0xe9,
+	0x0b, 0x42, 0x3a, 0x6d, 0xec, 0xcd, 0x65, 0xc1, 0xe7, 0x35, 0xbb, 0xeb, 0xab, 0xc2, 0x4f, 0xe0,
+	0x99, 0xbf, 0xc2, 0x8f, 0xe0, 0x3f, 0xf1, 0x80, 0xbc, 0xeb, 0x75, 0xec, 0x24, 0xce, 0x1d, 0x6f,
+	0x3b, 0xe3, 0x99, 0xd9, 0xd9, 0xef, 0xfb, 0x66, 0x0c, 0x48, 0xd0, 0x92, 0x4b, 0xa6, 0xb8, 0x60,
+	0x54, 0x26, 0xa5, 0xe0, 0x8a, 0xa3, 0x0f, 0xcf, 0x73, 0xb2, 0xa1, 0xef, 0x89, 0x10, 0x8c, 0x8b,
+	0x44, 0x07, 0x24, 0xdb, 0xe7, 0x8b, 0xc7, 0x6b, 0xce, 0xd7, 0x39, 0x3d, 0xd6, 0x41, 0xe7, 0xd5,
+	0xc5, 0xb1, 0x62, 0x1b, 0x2a, 0x15, 0xd9, 0x94, 0x26, 0x2f, 0xfa, 0xd7, 0x05, 0xc0, 0xb6, 0xdc,
+	0x15, 0x9a, 0x83, 0xcb, 0xb2, 0xd0, 0x59, 0x3a, 0xb1, 0x87, 0x5d, 0x96, 0xa1, 0x23, 0x18, 0xf1,
+	0xf7, 0x05, 0x15, 0xa1, 0xbb, 0x74, 0xe2, 0x00, 0x1b, 0x03, 0x21, 0x38, 0x28, 0xc8, 0x86, 0x86,
+	0x9e, 0x76, 0xea, 0x33, 0x0a, 0x61, 0x52, 0x0a, 0xb6, 0x25, 0x8a, 0x86, 0x07, 0x4b, 0x27, 0xf6,
+	0xb1, 0x35, 0xeb, 0x1a, 0x52, 0xd5, 0xfe, 0x91, 0xa9, 0xa1, 0x0d, 0xf4, 0x25, 0x40, 0x46, 0x73,
+	0xaa, 0x68, 0xb6, 0x22, 0x2a, 0x1c, 0x2f, 0x9d, 0x78, 0x7a, 0xb2, 0x48, 0x4c, 0xbb, 0x89, 0x6d,
+	0x37, 0xf9, 0xc9, 0xb6, 0x8b, 0x83, 0x26, 0xfa, 0x54, 0xa1, 0x53, 0x98, 0xb3, 0x82, 0xa4, 0x8a,
+	0x6d, 0xe9, 0x4a, 0xb2, 0x22, 0xa5, 0xe1, 0x64, 0x67, 0xfa, 0xcc, 0x66, 0xbc, 0xad, 0x13, 0xd0,
+	0x37, 0xe0, 0x6f, 0xa8, 0x22, 0x19, 0x51, 0x24, 0xf4, 0x75, 0xf2, 0xb3, 0xe4, 0x4e, 0x04, 0x93,
+	0x6b, 0x70, 0xbe, 0x6f, 0x12, 0x70, 0x9b, 0x8a, 0x9e, 0xc0, 0x4c, 0x52, 0xa5, 0x58, 0xb1, 0x96,
+	0xab, 0x5f, 0x25, 0x2f, 0xc2, 0x40, 0x3f, 0xf1, 0xd0, 0x3a, 0xbf, 0x95, 0xbc, 0x40, 0x0b, 0xf0,
+	0x4b, 0xc1, 0xb7, 0x2c, 0xa3, 0x22, 0x04, 0xfd, 0xbd, 0xb5, 0xa3, 0x3f, 0x5d, 0x40, 0xb7, 0x6f,
+	0x40, 0x4b, 0x98, 0x66, 0x54, 0xa6, 0x82, 0x95, 0x8a, 0xf1, 0x42, 0xf3, 0x11, 0xe0, 0xae, 0xab,
+	0x2e, 0x9a, 0x93, 0x62, 0x5d, 0x91, 0x35, 0x6d, 0xb8, 0x69, 0x6d, 0xf4, 0x11, 0x8c, 0x15, 0x2f,
+	0x59, 0x2a, 0x43, 0x6f, 0xe9, 0xc5, 0x01, 0x6e, 0xac, 0x9a, 0x36, 0xc9, 0xfe, 0x30, 0xfc, 0x8c,
+	0xb0, 0x3e, 0x37, 0xe4, 0x08, 0xa9, 0xc9, 0x19, 0x61, 0x63, 0xa0, 0x2f, 0x20, 0x28, 0x2b, 0x79,
+	0xb9, 0x2f, 0x37, 0xbe, 0x09, 0x3e, 0x55, 0x75, 0x5b, 0x44, 0xa4, 0x97, 0x6c, 0x4b, 0x33, 0x4d,
+	0x8a, 0x8f, 0x5b, 0x1b, 0x3d, 0x86, 0xa9, 0x7d, 0xf7, 0x8a, 0x65, 0x1a, 0x76, 0x0f, 0x83, 0x75,
+	0xbd, 0xca, 0x22, 0x0c, 0x70, 0x46, 0x15, 0xa6, 0xbf, 0x57, 0x54, 0x2a, 0xf4, 0x31, 0x04, 0x17,
+	0x55, 0x9e, 0xaf, 0xb4, 0xd2, 0x0c, 0x02, 0x7e, 0xed, 0xf8, 0xa1, 0x56, 0xdb, 0x67, 0xf0, 0x01,
+	0x2b, 0xd2, 0xbc, 0xca, 0xe8, 0xaa, 0xd1, 0x85, 0x46, 0xc1, 0xc7, 0xf3, 0xc6, 0xfd, 0xb5, 0xf1,
+	0x6a, 0x80, 0x5f, 0x33, 0xa9, 0x5e, 0x5e, 0xbd, 0xa9, 0xa5, 0x6b, 0x8b, 0xb7, 0xba, 0x76, 0xba,
+	0xba, 0xde, 0xb7, 0x6a, 0x0f, 0x7d, 0xef, 0x06, 0xfa, 0x47, 0x30, 0xd2, 0x78, 0x6b, 0x98, 0x03,
+	0x6c, 0x8c, 0x81, 0x21, 0xa8, 0x19, 0xe1, 0xc2, 0x40, 0x1c, 0x60, 0x7d, 0x46, 0x2f, 0xe0, 0xb0,
+	0xc1, 0x7e, 0x5f, 0x6d, 0x4f, 0x4d, 0xbc, 0x51, 0x76, 0x57, 0x6d, 0xfe, 0x0d, 0xb5, 0x11, 0x08,
+	0x6b, 0x2c, 0x70, 0x67, 0x7d, 0x60, 0x2a, 0x4b, 0x5e, 0xc8, 0x7a, 0x22, 0x0e, 0xbb, 0x6b, 0x25,
+	0x74, 0x96, 0x5e, 0x3c, 0x3d, 0xf9, 0x64, 0xe7, 0x54, 0xe0, 0x5e, 0x5a, 0xf4, 0x8f, 0x03, 0xb3,
+	0xaf, 0x04, 0x25, 0x8a, 0xde, 0x0f, 0xb5, 0x5d, 0x21, 0xee, 0xdd, 0x2b, 0xc4, 0xeb, 0xaf, 0x90,
+	0x5b, 0x73, 0x76, 0x70, 0xc7, 0x9c, 0x1d, 0xc1, 0x88, 0xa4, 0x8a, 0x0b, 0x0b, 0xb1, 0x36, 0xd0,
+	0x23, 0x00, 0x61, 0x3a, 0xa9, 0x45, 0x67, 0x80, 0x0e, 0x1a, 0xcf, 0xab, 0xac, 0x07, 0xd7, 0xe4,
+	0x16, 0x5c, 0x33, 0x43, 0xf8, 0x5e, 0x92, 0x6c, 0xaf, 0x77, 0x87, 0xaf, 0xf7, 0x6e, 0x5c, 0x1f,
+	0x3d, 0x80, 0xb9, 0xbd, 0xc2, 0xf0, 0x10, 0x3d, 0x83, 0x87, 0x35, 0x47, 0x67, 0x4c, 0x5d, 0x56,
+	0xe7, 0xf7, 0x62, 0x18, 0xfd, 0x02, 0xb3, 0xd7, 0x9c, 0xff, 0x56, 0x95, 0x36, 0xec, 0x11, 0x40,
+	0xdb, 0x9f, 0x61, 0x30, 0xc0, 0x81, 0x6d, 0x50, 0xa2, 0x07, 0xe0, 0xb1, 0x4c, 0x86, 0xee, 0xd2,
+	0x8b, 0x3d, 0x5c, 0x1f, 0x7b, 0xaf, 0xf7, 0x6e, 0xbc, 0xfe, 0x2f, 0x07, 0x0e, 0x6d, 0x79, 0x59,
+	0xe5, 0x3b, 0x5e, 0x6f, 0x7e, 0x1c, 0x6e, 0xf7, 0xc7, 0x71, 0xc1, 0xab, 0x22, 0x6b, 0x98, 0x34,
+	0x06, 0x3a, 0x05, 0x68, 0xd5, 0x72, 0xa5, 0x49, 0xdc, 0x4b, 0x62, 0x9d, 0xa4, 0xe8, 0x0d, 0xcc,
+	0xdb, 0xae, 0x8c, 0x72, 0x5f, 0xc0, 0x44, 0xe8, 0x0e, 0xad, 0x68, 0x9f, 0x0c, 0x54, 0xec, 0xbe,
+	0x06, 0xdb, 0x9c, 0x93, 0xbf, 0x0f, 0xe0, 0xe1, 0xf5, 0x5d, 0x6f, 0xa9, 0xd8, 0xb2, 0x94, 0xa2,
+	0xef, 0xc0, 0x3b, 0xa3, 0x0a, 0x0d, 0x35, 0x77, 0xbd, 0xa7, 0x16, 0xbb, 0xfb, 0x47, 0x0c, 0xa6,
+	0x9d, 0x1d, 0x84, 0x86, 0x7e, 0x35, 0xb7, 0xf7, 0xd4, 0xe2, 0xf8, 0x9e, 0xd0, 0x3b, 0xc7, 0xf8,
+	0x47, 0x18, 0x9b, 0xf1, 0x43, 0x9f, 0x0e, 0xa4, 0xf6, 0xa6, 0x73, 0x9f, 0xee, 0xdf, 0xc1, 0xd8,
+	0x68, 0x74, 0xb0, 0x64, 0x6f, 0x4a, 0x16, 0x4f, 0x77, 0x44, 0x35, 0x9d, 0xae, 0x01, 0xae, 0x85,
+	0x8e, 0xe2, 0x7b, 0x1e, 0xda, 0x9b, 0x85, 0xff, 0x0f, 0xc9, 0x3b, 0x18, 0x1b, 0xe6, 0x07, 0xfb,
+	0xef, 0x4d, 0xd1, 0xe2, 0xe9, 0x8e, 0x28, 0x53, 0xf6, 0x65, 0xf0, 0xf3, 0x44, 0x7f, 0xda, 0x3e,
+	0x3f, 0x1f, 0xeb, 0xa5, 0xfc, 0xf9, 0x7f, 0x03, 0x00, 0x70, 0x72, 0x73, 0x0c, 0x99, 0x09, 0x00,
+	0x00,
+}
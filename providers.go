@@ -0,0 +1,176 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/hooks"
+	"github.com/blamewarrior/repos/github"
+)
+
+// errProviderNotConfigured is returned for repositories hosted at a supported provider the
+// service has no API credentials for.
+var errProviderNotConfigured = errors.New("provider is not configured")
+
+// providerFor returns API client of provider. GitHub is always available through ghClient,
+// other providers have to be registered in providers.
+func providerFor(name string, ghClient github.Client, providers map[string]blamewarrior.Provider) (blamewarrior.Provider, error) {
+	if name == "" || name == blamewarrior.ProviderGithub {
+		return github.NewProvider(ghClient), nil
+	}
+
+	if provider, ok := providers[name]; ok {
+		return provider, nil
+	}
+
+	return nil, errProviderNotConfigured
+}
+
+// provider returns API client of provider repositories are hosted at.
+func (h *Handlers) provider(name string) (blamewarrior.Provider, error) {
+	return providerFor(name, h.ghClient, h.providers)
+}
+
+// routeRepositories adapts request path to API routes:
+//
+// Routes of repositories hosted at any provider are available under /repositories/:provider
+// prefix, e.g. /repositories/gitlab/:owner/:name. The prefix is stripped and passed to
+// handlers as :provider query parameter the same way pat passes route parameters. Routes
+// without prefix serve repositories hosted at GitHub. A path segment following /repositories/
+// is always taken for a provider if it names one, so owners named after a provider, e.g.
+// GitHub user gitlab, have to be addressed with explicit prefix: /repositories/github/gitlab/:name.
+//
+// Slashes in nested namespaces are expected to be escaped, e.g. /repositories/gitlab/group%2Fsub/repo,
+// and are kept escaped so that the owner is matched as a single path segment. Use requestOwner
+// to get the owner back.
+func routeRepositories(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		const prefix = "/repositories/"
+
+		path := routePath(req.URL)
+
+		var provider string
+		if rest := strings.TrimPrefix(path, prefix); rest != path {
+			if sep := strings.IndexByte(rest, '/'); sep > 0 && sep < len(rest)-1 && blamewarrior.ValidProvider(rest[:sep]) {
+				provider, path = rest[:sep], prefix+rest[sep+1:]
+			}
+		}
+
+		if path != req.URL.Path || provider != "" {
+			r := new(http.Request)
+			*r = *req
+
+			u := *req.URL
+			u.Path, u.RawPath = path, ""
+			if provider != "" {
+				u.RawQuery = ":provider=" + provider + "&" + u.RawQuery
+			}
+			r.URL = &u
+
+			req = r
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// escapedSlash is how slashes in path segments are passed to routes.
+const escapedSlash = "%2F"
+
+// routePath returns unescaped path of u except for escaped slashes.
+func routePath(u *url.URL) string {
+	escaped := u.EscapedPath()
+	if !strings.Contains(strings.ToUpper(escaped), escapedSlash) {
+		return u.Path
+	}
+
+	// NUL can not be a part of valid path, so it is used as a placeholder
+	path, err := url.PathUnescape(strings.NewReplacer("%2F", "\x00", "%2f", "\x00").Replace(escaped))
+	if err != nil {
+		return u.Path
+	}
+
+	return strings.Replace(path, "\x00", escapedSlash, -1)
+}
+
+// requestProvider returns provider requested repositories are hosted at.
+func requestProvider(req *http.Request) string {
+	if provider := req.URL.Query().Get(":provider"); provider != "" {
+		return provider
+	}
+
+	return blamewarrior.DefaultProvider
+}
+
+// requestOwner returns owner of repositories request refers to.
+func requestOwner(req *http.Request) string {
+	return strings.Replace(req.URL.Query().Get(":owner"), escapedSlash, "/", -1)
+}
+
+// repositoryName returns qualified name of repository request refers to.
+func repositoryName(req *http.Request) string {
+	return blamewarrior.QualifiedName(requestProvider(req), requestOwner(req), req.URL.Query().Get(":name"))
+}
+
+// githubHooks manages webhooks of repositories hosted at GitHub only since hooks service
+// does not support other providers. Calls for other repositories are ignored.
+type githubHooks struct {
+	hooks.Client
+}
+
+func (c githubHooks) CreateHook(repositoryName string) error {
+	if !hostedAtGithub(repositoryName) {
+		return nil
+	}
+
+	return c.Client.CreateHook(repositoryName)
+}
+
+func (c githubHooks) DeleteHook(repositoryName string) error {
+	if !hostedAtGithub(repositoryName) {
+		return nil
+	}
+
+	return c.Client.DeleteHook(repositoryName)
+}
+
+func (c githubHooks) UpdateHook(oldRepositoryName, newRepositoryName string) error {
+	if !hostedAtGithub(oldRepositoryName) {
+		return nil
+	}
+
+	return c.Client.UpdateHook(oldRepositoryName, newRepositoryName)
+}
+
+func (c githubHooks) SetHookActive(repositoryName string, active bool) error {
+	if !hostedAtGithub(repositoryName) {
+		return nil
+	}
+
+	return c.Client.SetHookActive(repositoryName, active)
+}
+
+// hostedAtGithub reports whether qualified repository name refers to a GitHub repository.
+func hostedAtGithub(repositoryName string) bool {
+	sep := strings.IndexByte(repositoryName, ':')
+
+	return sep < 0 || repositoryName[:sep] == blamewarrior.ProviderGithub
+}
@@ -0,0 +1,247 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+type providerMock struct {
+	mock.Mock
+}
+
+func (p *providerMock) UserRepositories(ctx context.Context, owner string) ([]blamewarrior.Repository, error) {
+	args := p.Called(owner)
+
+	repositories, _ := args.Get(0).([]blamewarrior.Repository)
+	return repositories, args.Error(1)
+}
+
+func (p *providerMock) RepositoryMetadata(ctx context.Context, owner, name string) (*blamewarrior.RepositoryMetadata, error) {
+	args := p.Called(owner, name)
+
+	metadata, _ := args.Get(0).(*blamewarrior.RepositoryMetadata)
+	return metadata, args.Error(1)
+}
+
+func (p *providerMock) Collaborators(ctx context.Context, owner, name string) ([]blamewarrior.Collaborator, error) {
+	args := p.Called(owner, name)
+
+	collaborators, _ := args.Get(0).([]blamewarrior.Collaborator)
+	return collaborators, args.Error(1)
+}
+
+func (p *providerMock) Permission(ctx context.Context, owner, name, login string) (string, error) {
+	args := p.Called(owner, name, login)
+	return args.String(0), args.Error(1)
+}
+
+func TestRouteRepositories(t *testing.T) {
+	results := []struct {
+		URL              string
+		ExpectedPath     string
+		ExpectedProvider string
+		ExpectedQuery    string
+	}{
+		{"/repositories/blamewarrior/repos", "/repositories/blamewarrior/repos", "", ""},
+		{"/repositories/gitlab/blamewarrior/repos/history", "/repositories/blamewarrior/repos/history", "gitlab", ""},
+		{"/repositories/gitlab/blamewarrior?state=active", "/repositories/blamewarrior", "gitlab", "active"},
+		{"/repositories/github/gitlab/repos", "/repositories/gitlab/repos", "github", ""},
+		{"/repositories/github/hub", "/repositories/hub", "github", ""},
+		{"/repositories/gitlab/", "/repositories/gitlab/", "", ""},
+		{"/repositories/gitlab", "/repositories/gitlab", "", ""},
+		{"/repositories/bitbucket/blamewarrior", "/repositories/bitbucket/blamewarrior", "", ""},
+		{"/owners/gitlab/settings", "/owners/gitlab/settings", "", ""},
+		{"/repositories/gitlab/group%2Fsub/my.repo/history", "/repositories/group%2Fsub/my.repo/history", "gitlab", ""},
+		{"/repositories/gitlab/group%2fsub%2Fteam?state=active", "/repositories/group%2Fsub%2Fteam", "gitlab", "active"},
+		{"/repositories/my%20org/repo", "/repositories/my org/repo", "", ""},
+		{"/repositories/gitlab/lookup", "/repositories/lookup", "gitlab", ""},
+	}
+
+	for _, result := range results {
+		var passed *http.Request
+
+		handler := routeRepositories(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			passed = req
+		}))
+
+		req, err := http.NewRequest("GET", result.URL, nil)
+		require.NoError(t, err)
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		require.NotNil(t, passed, result.URL)
+		assert.Equal(t, result.ExpectedPath, passed.URL.Path, result.URL)
+		assert.Equal(t, result.ExpectedProvider, passed.URL.Query().Get(":provider"), result.URL)
+		assert.Equal(t, result.ExpectedQuery, passed.URL.Query().Get("state"), result.URL)
+		assert.Equal(t, result.URL, req.URL.String(), "original request must not be modified")
+	}
+}
+
+func TestRepositoryName(t *testing.T) {
+	results := map[string]string{
+		"/?:owner=blamewarrior&:name=repos":                   "blamewarrior/repos",
+		"/?:provider=github&:owner=blamewarrior&:name=repos":  "blamewarrior/repos",
+		"/?:provider=gitlab&:owner=blamewarrior&:name=repos":  "gitlab:blamewarrior/repos",
+		"/?:provider=gitlab&:owner=group%252Fsub&:name=repos": "gitlab:group/sub/repos",
+	}
+
+	for url, expected := range results {
+		req, err := http.NewRequest("GET", url, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, repositoryName(req), url)
+	}
+}
+
+func TestGithubHooks(t *testing.T) {
+	hooksClient := new(hooksClientMock)
+	hooksClient.On("CreateHook", "blamewarrior/repos").Return(nil)
+	hooksClient.On("SetHookActive", "github:blamewarrior/repos", false).Return(nil)
+
+	c := githubHooks{hooksClient}
+
+	require.NoError(t, c.CreateHook("blamewarrior/repos"))
+	require.NoError(t, c.SetHookActive("github:blamewarrior/repos", false))
+	require.NoError(t, c.CreateHook("gitlab:blamewarrior/repos"))
+	require.NoError(t, c.DeleteHook("gitlab:blamewarrior/repos"))
+	require.NoError(t, c.UpdateHook("gitlab:blamewarrior/repos", "gitlab:blamewarrior/hooks"))
+
+	hooksClient.AssertExpectations(t)
+}
+
+func TestProviderFor(t *testing.T) {
+	gitlabProvider := new(providerMock)
+	providers := map[string]blamewarrior.Provider{blamewarrior.ProviderGitlab: gitlabProvider}
+
+	p, err := providerFor(blamewarrior.ProviderGitlab, new(githubClientMock), providers)
+	require.NoError(t, err)
+	assert.Equal(t, gitlabProvider, p)
+
+	_, err = providerFor(blamewarrior.ProviderGithub, new(githubClientMock), nil)
+	require.NoError(t, err)
+
+	_, err = providerFor(blamewarrior.ProviderGitlab, new(githubClientMock), nil)
+	assert.Equal(t, errProviderNotConfigured, err)
+}
+
+func TestHandlers_ProviderRoutes(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Provider: blamewarrior.ProviderGitlab, Owner: "blamewarrior", Name: "repos", Private: true}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Provider: blamewarrior.ProviderGitlab, Owner: "blamewarrior/backend", Name: "my.repo"}))
+
+	gitlabProvider := new(providerMock)
+	gitlabProvider.On("UserRepositories", "blamewarrior").Return([]blamewarrior.Repository{
+		{Provider: blamewarrior.ProviderGitlab, Owner: "blamewarrior", Name: "hooks"},
+	}, nil)
+
+	router, err := newRouter(&Handlers{
+		db:          db,
+		hooksClient: githubHooks{new(hooksClientMock)},
+		ghClient:    new(githubClientMock),
+		providers:   map[string]blamewarrior.Provider{blamewarrior.ProviderGitlab: gitlabProvider},
+	})
+	require.NoError(t, err)
+
+	results := []struct {
+		Method       string
+		URL          string
+		ResponseCode int
+		ResponseBody string
+	}{
+		{
+			Method:       "GET",
+			URL:          "/repositories/blamewarrior/repos",
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"full_name":"blamewarrior/repos","owner":"blamewarrior","name":"repos","private":false,"provider":"github","state":"active"}`,
+		},
+		{
+			Method:       "GET",
+			URL:          "/repositories/gitlab/blamewarrior/repos",
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"full_name":"blamewarrior/repos","owner":"blamewarrior","name":"repos","private":true,"provider":"gitlab","state":"active"}`,
+		},
+		{
+			Method:       "GET",
+			URL:          "/repositories/gitlab/blamewarrior",
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[{"full_name":"blamewarrior/repos","owner":"blamewarrior","name":"repos","private":true,"provider":"gitlab","state":"active"}]`,
+		},
+		{
+			Method:       "GET",
+			URL:          "/repositories/gitlab/blamewarrior/github",
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[{"full_name":"blamewarrior/hooks","owner":"blamewarrior","name":"hooks","private":false,"provider":"gitlab"}]`,
+		},
+		{
+			Method:       "GET",
+			URL:          "/repositories/gitlab/BlameWarrior%2FBackend/My.Repo",
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"full_name":"blamewarrior/backend/my.repo","owner":"blamewarrior/backend","name":"my.repo","private":false,"provider":"gitlab","state":"active"}`,
+		},
+		{
+			Method:       "GET",
+			URL:          "/repositories/gitlab/blamewarrior%2Fbackend",
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[{"full_name":"blamewarrior/backend/my.repo","owner":"blamewarrior/backend","name":"my.repo","private":false,"provider":"gitlab","state":"active"}]`,
+		},
+		{
+			Method:       "DELETE",
+			URL:          "/repositories/gitlab/blamewarrior/repos",
+			ResponseCode: http.StatusNoContent,
+		},
+		{
+			Method:       "GET",
+			URL:          "/repositories/gitlab/blamewarrior/repos",
+			ResponseCode: http.StatusNotFound,
+		},
+		{
+			Method:       "GET",
+			URL:          "/repositories/blamewarrior/repos",
+			ResponseCode: http.StatusOK,
+		},
+	}
+
+	for _, result := range results {
+		req, err := http.NewRequest(result.Method, result.URL, nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, result.ResponseCode, w.Code, result.URL)
+		if result.ResponseBody != "" {
+			assert.JSONEq(t, result.ResponseBody, w.Body.String(), result.URL)
+		}
+	}
+
+	gitlabProvider.AssertExpectations(t)
+}
@@ -0,0 +1,118 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/hooks"
+	"github.com/blamewarrior/repos/github"
+)
+
+// reconcileRepositories periodically enqueues reconcile_repository jobs for all tracked
+// repositories. Every instance enqueues them, but jobs are keyed by repository, so that a
+// repository is not enqueued again while its job is pending or running.
+func reconcileRepositories(db *blamewarrior.DB, interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := enqueueReconciliation(db.Primary()); err != nil {
+			log.Printf("failed to reconcile repositories: %s", err)
+		}
+	}
+}
+
+// enqueueReconciliation enqueues reconcile_repository job for every tracked repository.
+func enqueueReconciliation(runner blamewarrior.SQLRunner) error {
+	repositories, err := blamewarrior.GetRepositories(runner)
+	if err != nil {
+		return err
+	}
+
+	change := &blamewarrior.Change{Source: blamewarrior.SourceReconciler}
+
+	for i := range repositories {
+		repo := &repositories[i]
+
+		job, err := newRepositoryJob(jobReconcileRepository, repo, change)
+		if err != nil {
+			return err
+		}
+
+		job.Key = repo.QualifiedName()
+
+		if err = blamewarrior.EnqueueJob(runner, job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reconcileRepository refreshes metadata, default branch snapshot and collaborators of repository
+// and applies staleness policy to it. Default branch is only tracked for GitHub repositories.
+func reconcileRepository(ctx context.Context, provider blamewarrior.Provider, ghClient github.Client, hooksClient hooks.Client, db blamewarrior.Conn, repo *blamewarrior.Repository) error {
+	metadata, err := provider.RepositoryMetadata(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := blamewarrior.UpdateRepositoryMetadata(db, repo, metadata); err != nil {
+		return err
+	}
+
+	if err := applyStalenessPolicy(db, hooksClient, repo, time.Now()); err != nil {
+		return err
+	}
+
+	if repo.DeletedAt != nil {
+		return nil
+	}
+
+	if repo.Provider == blamewarrior.ProviderGithub {
+		if err := refreshDefaultBranch(github.Context{Context: ctx}, ghClient, db, repo); err != nil {
+			return err
+		}
+	}
+
+	return syncCollaborators(ctx, provider, db, repo)
+}
+
+// refreshDefaultBranch fetches default branch of repository along with its protection settings
+// and stores them.
+func refreshDefaultBranch(ctx github.Context, ghClient github.Client, db blamewarrior.Conn, repo *blamewarrior.Repository) error {
+	branch, err := ghClient.DefaultBranch(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	change := &blamewarrior.Change{Source: blamewarrior.SourceReconciler}
+
+	if err = blamewarrior.UpdateRepositoryDefaultBranch(tx, repo, branch, change); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,100 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/github"
+)
+
+func TestReconcileRepository(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events, repository_collaborators CASCADE;")
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
+
+	ghClient := new(githubClientMock)
+	ghClient.On("DefaultBranch", "blamewarrior", "repos").Return(&blamewarrior.DefaultBranch{
+		Name:                    "master",
+		Protected:               true,
+		RequiredReviews:         true,
+		RequireCodeOwnerReviews: true,
+	}, nil)
+	ghClient.On("RepositoryMetadata", "blamewarrior", "repos").Return(&blamewarrior.RepositoryMetadata{
+		Language:    "Go",
+		Topics:      []string{"backend"},
+		Stars:       10,
+		RefreshedAt: time.Now(),
+	}, nil)
+	ghClient.On("Collaborators", "blamewarrior", "repos").Return([]blamewarrior.Collaborator{
+		{Login: "user1", Type: blamewarrior.CollaboratorUser, Permission: blamewarrior.PermissionAdmin},
+	}, nil)
+
+	require.NoError(t, reconcileRepository(context.Background(), github.NewProvider(ghClient), ghClient, new(hooksClientMock), db, repo))
+
+	ghClient.AssertExpectations(t)
+
+	repo, err = blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	require.NotNil(t, repo.DefaultBranch)
+	assert.Equal(t, "master", repo.DefaultBranch.Name)
+	assert.True(t, repo.DefaultBranch.RequireCodeOwnerReviews)
+	require.NotNil(t, repo.Metadata)
+	assert.Equal(t, "Go", repo.Metadata.Language)
+	assert.Equal(t, []string{"backend"}, repo.Metadata.Topics)
+
+	history, err := blamewarrior.GetRepositoryHistory(db, "blamewarrior/repos")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, blamewarrior.SourceReconciler, history[1].Source)
+
+	collaborators, err := blamewarrior.GetCollaborators(db, repo.ID, "")
+	require.NoError(t, err)
+	assert.Len(t, collaborators, 1)
+}
+
+func TestEnqueueReconciliation(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, jobs CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Provider: blamewarrior.ProviderGitlab, Owner: "blamewarrior", Name: "repos"}))
+
+	// instances enqueue reconciliation independently
+	require.NoError(t, enqueueReconciliation(db))
+	require.NoError(t, enqueueReconciliation(db))
+
+	results, err := blamewarrior.GetJobs(db, blamewarrior.JobPending, jobReconcileRepository, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 2, "repository should not be enqueued again while its job is pending")
+
+	assert.Equal(t, "blamewarrior/repos", results[0].Key)
+	assert.Equal(t, "gitlab:blamewarrior/repos", results[1].Key)
+}
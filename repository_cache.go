@@ -0,0 +1,223 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/cache"
+)
+
+const (
+	defaultCacheSize = 10000
+	defaultCacheTTL  = time.Minute
+)
+
+// cacheMetrics counts hits, misses, evictions and invalidations of repository cache entries.
+// It is published at /debug/vars.
+var cacheMetrics = expvar.NewMap("repositories_cache")
+
+// repositoryCache is a read-through cache of repositories looked up by full name and of
+// repositories listed by owner. Entries are invalidated by handlers once they have changed
+// a repository and by notifications sent by database trigger for changes made by other
+// instances or background jobs. TTL limits staleness in case a notification gets lost.
+//
+// A nil repositoryCache caches nothing.
+type repositoryCache struct {
+	// db is the primary database, replicas may lag behind notifications
+	db blamewarrior.SQLRunner
+
+	repositories *cache.LRU
+	owners       *cache.LRU
+
+	mu sync.Mutex
+	// generation is incremented on every invalidation so that results fetched before
+	// a change are not cached after it
+	generation uint64
+}
+
+func newRepositoryCache(db blamewarrior.SQLRunner, size int, ttl time.Duration) *repositoryCache {
+	return &repositoryCache{
+		db:           db,
+		repositories: cache.NewLRU(size, ttl),
+		owners:       cache.NewLRU(size, ttl),
+	}
+}
+
+// Repository returns tracked repository by its qualified full name.
+func (c *repositoryCache) Repository(fullName string) (*blamewarrior.Repository, error) {
+	key := strings.ToLower(fullName)
+
+	if v, ok := c.repositories.Get(key); ok {
+		cacheMetrics.Add("hits", 1)
+
+		repo := *v.(*blamewarrior.Repository)
+		return &repo, nil
+	}
+
+	cacheMetrics.Add("misses", 1)
+
+	generation := c.currentGeneration()
+
+	repo, err := blamewarrior.GetRepositoryByFullName(c.db, fullName)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := *repo
+	c.add(c.repositories, key, &cached, generation)
+
+	return repo, nil
+}
+
+// OwnerRepositories returns repositories of owner hosted at opts.Provider filtered and sorted
+// according to opts. Listings of the same owner are cached together so that they are
+// invalidated at once.
+func (c *repositoryCache) OwnerRepositories(owner string, opts *blamewarrior.ListOptions) ([]blamewarrior.Repository, error) {
+	key := ownerCacheKey(opts.Provider, owner)
+	listingKey := listOptionsKey(opts)
+
+	var listings map[string][]blamewarrior.Repository
+	if v, ok := c.owners.Get(key); ok {
+		listings = v.(map[string][]blamewarrior.Repository)
+
+		if repositories, ok := listings[listingKey]; ok {
+			cacheMetrics.Add("hits", 1)
+			return append([]blamewarrior.Repository(nil), repositories...), nil
+		}
+	}
+
+	cacheMetrics.Add("misses", 1)
+
+	generation := c.currentGeneration()
+
+	repositories, err := blamewarrior.GetListRepositoryByOwner(c.db, owner, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// listings may be read concurrently, so a new map is stored instead of updating it
+	updated := make(map[string][]blamewarrior.Repository, len(listings)+1)
+	for k, v := range listings {
+		updated[k] = v
+	}
+	updated[listingKey] = append([]blamewarrior.Repository(nil), repositories...)
+
+	c.add(c.owners, key, updated, generation)
+
+	return repositories, nil
+}
+
+// Invalidate removes cached entries repository may be part of.
+func (c *repositoryCache) Invalidate(provider, owner, name string) {
+	if c == nil {
+		return
+	}
+
+	if provider == "" {
+		provider = blamewarrior.DefaultProvider
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	c.repositories.Remove(strings.ToLower(blamewarrior.QualifiedName(provider, owner, name)))
+	c.owners.Remove(ownerCacheKey(provider, owner))
+
+	cacheMetrics.Add("invalidations", 1)
+}
+
+// Purge removes all cached entries.
+func (c *repositoryCache) Purge() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	c.repositories.Purge()
+	c.owners.Purge()
+
+	cacheMetrics.Add("purges", 1)
+}
+
+// listen invalidates entries of repositories notified about until notifications channel is
+// closed. A nil notification is sent by pq.Listener after it has reconnected, since changes
+// made in the meantime are unknown the whole cache is purged.
+func (c *repositoryCache) listen(notifications <-chan *pq.Notification) {
+	for n := range notifications {
+		if n == nil {
+			c.Purge()
+			continue
+		}
+
+		change, err := blamewarrior.ParseRepositoryNotification(n.Extra)
+		if err != nil {
+			log.Printf("failed to invalidate repository cache: %s", err)
+			c.Purge()
+			continue
+		}
+
+		c.Invalidate(change.Provider, change.Owner, change.Name)
+	}
+}
+
+func (c *repositoryCache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+// add stores value unless cache has been invalidated since generation.
+func (c *repositoryCache) add(lru *cache.LRU, key string, value interface{}, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generation != generation {
+		return
+	}
+
+	if lru.Add(key, value) {
+		cacheMetrics.Add("evictions", 1)
+	}
+}
+
+func ownerCacheKey(provider, owner string) string {
+	return provider + ":" + strings.ToLower(owner)
+}
+
+func listOptionsKey(opts *blamewarrior.ListOptions) string {
+	var pushedSince string
+	if !opts.PushedSince.IsZero() {
+		pushedSince = opts.PushedSince.UTC().Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprintf("%t|%s|%s|%s|%s|%s", opts.IncludeDeleted, opts.Language, opts.Topic, opts.State, opts.Sort, pushedSince)
+}
@@ -0,0 +1,229 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestRepositoryCache_Repository(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
+
+	c := newRepositoryCache(db, 10, time.Minute)
+
+	hits, misses := cacheMetric("hits"), cacheMetric("misses")
+
+	cached, err := c.Repository("blamewarrior/repos")
+	require.NoError(t, err)
+	assert.Equal(t, repo.ID, cached.ID)
+
+	cached, err = c.Repository("BlameWarrior/Repos")
+	require.NoError(t, err)
+	assert.Equal(t, repo.ID, cached.ID)
+
+	assert.Equal(t, hits+1, cacheMetric("hits"))
+	assert.Equal(t, misses+1, cacheMetric("misses"))
+
+	// changes are not seen until the entry is invalidated
+	_, err = db.Exec("UPDATE repositories SET private=true WHERE id=$1", repo.ID)
+	require.NoError(t, err)
+
+	cached, err = c.Repository("blamewarrior/repos")
+	require.NoError(t, err)
+	assert.False(t, cached.Private)
+
+	c.Invalidate(blamewarrior.ProviderGithub, "blamewarrior", "repos")
+
+	cached, err = c.Repository("blamewarrior/repos")
+	require.NoError(t, err)
+	assert.True(t, cached.Private)
+
+	// missing repositories are not cached
+	_, err = c.Repository("blamewarrior/missing")
+	assert.Equal(t, blamewarrior.ErrRepositoryNotFound, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "missing"}))
+
+	_, err = c.Repository("blamewarrior/missing")
+	assert.NoError(t, err)
+}
+
+func TestRepositoryCache_OwnerRepositories(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+
+	c := newRepositoryCache(db, 10, time.Minute)
+
+	active := &blamewarrior.ListOptions{Provider: blamewarrior.ProviderGithub, State: blamewarrior.StateActive}
+	inactive := &blamewarrior.ListOptions{Provider: blamewarrior.ProviderGithub, State: blamewarrior.StateInactive}
+
+	repositories, err := c.OwnerRepositories("blamewarrior", active)
+	require.NoError(t, err)
+	assert.Len(t, repositories, 1)
+
+	repositories, err = c.OwnerRepositories("blamewarrior", inactive)
+	require.NoError(t, err)
+	assert.Len(t, repositories, 0)
+
+	hits := cacheMetric("hits")
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"}))
+
+	repositories, err = c.OwnerRepositories("BlameWarrior", active)
+	require.NoError(t, err)
+	assert.Len(t, repositories, 1)
+
+	assert.Equal(t, hits+1, cacheMetric("hits"))
+
+	c.Invalidate(blamewarrior.ProviderGithub, "blamewarrior", "hooks")
+
+	repositories, err = c.OwnerRepositories("blamewarrior", active)
+	require.NoError(t, err)
+	assert.Len(t, repositories, 2)
+}
+
+func TestRepositoryCache_Handlers(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+
+	hooksClient := new(hooksClientMock)
+	hooksClient.On("DeleteHook", "blamewarrior/repos").Return(nil)
+
+	handlers := &Handlers{
+		db:          db,
+		hooksClient: hooksClient,
+		cache:       newRepositoryCache(db, 10, time.Minute),
+	}
+
+	urlValues := make(url.Values)
+	urlValues[":owner"] = []string{"blamewarrior"}
+	urlValues[":name"] = []string{"repos"}
+
+	get := func() int {
+		req, err := http.NewRequest("GET", "/repositories?"+urlValues.Encode(), nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handlers.GetRepositoryByFullName(w, req)
+
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get())
+
+	req, err := http.NewRequest("DELETE", "/repositories?"+urlValues.Encode(), nil)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handlers.DeleteRepository(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	assert.Equal(t, http.StatusNotFound, get())
+
+	hooksClient.AssertExpectations(t)
+}
+
+func TestRepositoryCache_Listen(t *testing.T) {
+	c := newRepositoryCache(nil, 10, time.Minute)
+
+	fill := func() {
+		c.repositories.Add("blamewarrior/repos", &blamewarrior.Repository{})
+		c.repositories.Add("gitlab:group/subgroup/repos", &blamewarrior.Repository{})
+		c.owners.Add("github:blamewarrior", map[string][]blamewarrior.Repository{})
+		c.owners.Add("gitlab:group/subgroup", map[string][]blamewarrior.Repository{})
+	}
+
+	results := []struct {
+		Notification *pq.Notification
+		Remaining    int
+	}{
+		{&pq.Notification{Extra: `{"provider":"github","owner":"BlameWarrior","name":"Repos"}`}, 2},
+		{&pq.Notification{Extra: `{"provider":"gitlab","owner":"group/subgroup","name":"repos"}`}, 2},
+		{&pq.Notification{Extra: `{"provider":"github","owner":"blamewarrior","name":"hooks"}`}, 3},
+		{&pq.Notification{Extra: `{"provider":"github"}`}, 0},
+		{nil, 0},
+	}
+
+	for _, result := range results {
+		fill()
+
+		notifications := make(chan *pq.Notification, 1)
+		notifications <- result.Notification
+		close(notifications)
+
+		c.listen(notifications)
+
+		assert.Equal(t, result.Remaining, c.repositories.Len()+c.owners.Len(), "%+v", result.Notification)
+
+		c.Purge()
+	}
+}
+
+func TestRepositoryCache_StaleResults(t *testing.T) {
+	c := newRepositoryCache(nil, 10, time.Minute)
+
+	generation := c.currentGeneration()
+	c.Invalidate(blamewarrior.ProviderGithub, "blamewarrior", "repos")
+
+	c.add(c.repositories, "blamewarrior/repos", &blamewarrior.Repository{}, generation)
+	assert.Equal(t, 0, c.repositories.Len())
+
+	c.add(c.repositories, "blamewarrior/repos", &blamewarrior.Repository{}, c.currentGeneration())
+	assert.Equal(t, 1, c.repositories.Len())
+
+	var disabled *repositoryCache
+	assert.NotPanics(t, func() {
+		disabled.Invalidate(blamewarrior.ProviderGithub, "blamewarrior", "repos")
+		disabled.Purge()
+	})
+}
+
+func cacheMetric(name string) int64 {
+	if v, ok := cacheMetrics.Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+
+	return 0
+}
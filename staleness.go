@@ -0,0 +1,116 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/hooks"
+)
+
+// applyStalenessPolicy updates state of repository according to staleness policy of its owner.
+// Depending on policy, hooks of inactive repositories are disabled, and repositories that stay
+// inactive for too long are untracked.
+func applyStalenessPolicy(db blamewarrior.Conn, hooksClient hooks.Client, repo *blamewarrior.Repository, now time.Time) error {
+	// pending repositories are activated once their webhook is created, failed ones once
+	// creation of their webhook is retried
+	if repo.State == blamewarrior.StatePending || repo.State == blamewarrior.StateFailed {
+		return nil
+	}
+
+	policy, err := blamewarrior.GetStalenessPolicy(db, repo.Provider, repo.Owner)
+	if err != nil {
+		return err
+	}
+
+	change := &blamewarrior.Change{Source: blamewarrior.SourceReconciler}
+
+	inactive := policy.Inactive(repo, now)
+
+	// repository that has been pushed to since it became inactive is activated instead
+	if inactive && policy.Untrack(repo, now) {
+		return untrackRepository(db, hooksClient, repo, change)
+	}
+
+	state := blamewarrior.StateActive
+	if inactive {
+		state = blamewarrior.StateInactive
+	}
+
+	if state == repo.State {
+		return nil
+	}
+
+	tx, err := db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if err = blamewarrior.UpdateRepositoryState(tx, repo, state, change); err != nil {
+		return err
+	}
+
+	// hook is re-enabled even if policy is to notify, since it might have been disabled
+	// before owner changed the policy
+	if policy.Action != blamewarrior.StalenessNotify || state == blamewarrior.StateActive {
+		if err = hooksClient.SetHookActive(repo.QualifiedName(), state == blamewarrior.StateActive); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("repository %s is now %s", repo.QualifiedName(), state)
+
+	return nil
+}
+
+// untrackRepository deletes repository along with its hook.
+func untrackRepository(db blamewarrior.Conn, hooksClient hooks.Client, repo *blamewarrior.Repository, change *blamewarrior.Change) error {
+	tx, err := db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if err = blamewarrior.DeleteRepository(tx, repo.QualifiedName(), change); err != nil {
+		return err
+	}
+
+	if err = hooksClient.DeleteHook(repo.QualifiedName()); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	deletedAt := time.Now()
+	repo.DeletedAt = &deletedAt
+
+	log.Printf("repository %s has been untracked after being inactive since %s", repo.QualifiedName(), repo.InactiveSince)
+
+	return nil
+}
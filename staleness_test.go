@@ -0,0 +1,127 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+)
+
+func TestApplyStalenessPolicy(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events, owner_settings CASCADE;")
+	require.NoError(t, err)
+
+	err = blamewarrior.UpdateOwnerSettings(db, blamewarrior.ProviderGithub, "blamewarrior", json.RawMessage(`{"version":1,"staleness":{"action":"untrack","inactive_after_days":30,"untrack_after_days":10}}`))
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
+
+	repo, err = blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+
+	hooksClient := new(hooksClientMock)
+	hooksClient.On("SetHookActive", "blamewarrior/repos", false).Return(nil)
+	hooksClient.On("SetHookActive", "blamewarrior/repos", true).Return(nil)
+	hooksClient.On("DeleteHook", "blamewarrior/repos").Return(nil)
+
+	now := time.Now()
+	pushedAt := now.AddDate(0, -2, 0)
+
+	repo.Metadata = &blamewarrior.RepositoryMetadata{PushedAt: &pushedAt, RefreshedAt: now}
+
+	// repository that has not been pushed to for two months becomes inactive
+	require.NoError(t, applyStalenessPolicy(db, hooksClient, repo, now))
+	assert.Equal(t, blamewarrior.StateInactive, repo.State)
+	hooksClient.AssertCalled(t, "SetHookActive", "blamewarrior/repos", false)
+
+	// ...and becomes active again once pushed to
+	repo.Metadata.PushedAt = &now
+
+	require.NoError(t, applyStalenessPolicy(db, hooksClient, repo, now))
+	assert.Equal(t, blamewarrior.StateActive, repo.State)
+	hooksClient.AssertCalled(t, "SetHookActive", "blamewarrior/repos", true)
+
+	// repository pushed to after untrack period has passed is activated rather than untracked
+	repo.Metadata.PushedAt = &pushedAt
+
+	require.NoError(t, applyStalenessPolicy(db, hooksClient, repo, now))
+	assert.Equal(t, blamewarrior.StateInactive, repo.State)
+
+	later := now.AddDate(0, 0, 11)
+	repo.Metadata.PushedAt = &later
+
+	require.NoError(t, applyStalenessPolicy(db, hooksClient, repo, later))
+	assert.Equal(t, blamewarrior.StateActive, repo.State)
+	hooksClient.AssertNotCalled(t, "DeleteHook", "blamewarrior/repos")
+
+	// repository that stays inactive for longer than policy allows is untracked
+	repo.Metadata.PushedAt = &pushedAt
+
+	require.NoError(t, applyStalenessPolicy(db, hooksClient, repo, now))
+	require.NoError(t, applyStalenessPolicy(db, hooksClient, repo, now.AddDate(0, 0, 11)))
+
+	hooksClient.AssertCalled(t, "DeleteHook", "blamewarrior/repos")
+
+	_, err = blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	assert.Equal(t, blamewarrior.ErrRepositoryNotFound, err)
+}
+
+func TestApplyStalenessPolicy_Notify(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events, owner_settings CASCADE;")
+	require.NoError(t, err)
+
+	err = blamewarrior.UpdateOwnerSettings(db, blamewarrior.ProviderGithub, "blamewarrior", json.RawMessage(`{"version":1,"staleness":{"action":"notify","inactive_after_days":30}}`))
+	require.NoError(t, err)
+
+	repo := &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}
+	require.NoError(t, blamewarrior.CreateRepository(db, repo))
+
+	repo, err = blamewarrior.GetRepositoryByFullName(db, "blamewarrior/repos")
+	require.NoError(t, err)
+
+	hooksClient := new(hooksClientMock)
+	hooksClient.On("SetHookActive", "blamewarrior/repos", true).Return(nil)
+
+	now := time.Now()
+	pushedAt := now.AddDate(0, -2, 0)
+
+	repo.Metadata = &blamewarrior.RepositoryMetadata{PushedAt: &pushedAt, RefreshedAt: now}
+
+	// hook is left intact when repository becomes inactive...
+	require.NoError(t, applyStalenessPolicy(db, hooksClient, repo, now))
+	assert.Equal(t, blamewarrior.StateInactive, repo.State)
+	hooksClient.AssertNotCalled(t, "SetHookActive", "blamewarrior/repos", false)
+
+	// ...but is enabled once it is active again in case it was disabled by previous policy
+	repo.Metadata.PushedAt = &now
+
+	require.NoError(t, applyStalenessPolicy(db, hooksClient, repo, now))
+	assert.Equal(t, blamewarrior.StateActive, repo.State)
+	hooksClient.AssertCalled(t, "SetHookActive", "blamewarrior/repos", true)
+}
@@ -0,0 +1,122 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/stream"
+)
+
+const (
+	// deliveryBatchSize is the maximum number of subscription deliveries sent at once
+	deliveryBatchSize = 50
+	// maxDeliveryAttempts is the number of attempts after which an event is dropped
+	maxDeliveryAttempts = 8
+	// maxSubscriptionFailures is the number of consecutive failed attempts after which
+	// subscription is disabled
+	maxSubscriptionFailures = 50
+	// deliveryLease is how long claimed deliveries are kept from other workers, it is long
+	// enough to send the whole batch
+	deliveryLease = 10 * time.Minute
+
+	initialDeliveryBackoff = 30 * time.Second
+	maxDeliveryBackoff     = 6 * time.Hour
+)
+
+// deliverSubscriptionEvents periodically sends queued events to subscriptions.
+func deliverSubscriptionEvents(db *blamewarrior.DB, client *stream.SubscriberClient, interval time.Duration) {
+	for range time.Tick(interval) {
+		for {
+			n, err := sendPendingDeliveries(db, client, time.Now(), deliveryBatchSize)
+			if err != nil {
+				log.Printf("failed to deliver subscription events: %s", err)
+				break
+			}
+
+			if n < deliveryBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// sendPendingDeliveries sends a batch of due deliveries and records their outcome. Failed
+// deliveries are retried with exponential backoff until maxDeliveryAttempts is reached.
+// Deliveries are leased rather than locked, so that no transaction is held while they are
+// sent, and each attempt is recorded on its own.
+func sendPendingDeliveries(db *blamewarrior.DB, client *stream.SubscriberClient, now time.Time, limit int) (int, error) {
+	pending, err := blamewarrior.ClaimPendingDeliveries(db, now, deliveryLease, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	disabled := make(map[int]bool)
+
+	for _, p := range pending {
+		if disabled[p.Subscription.ID] {
+			continue
+		}
+
+		delivery := client.Deliver(&p.Subscription, &p.Event, p.Attempts+1)
+
+		var retryAt time.Time
+		if !delivery.Succeeded() && delivery.Attempt < maxDeliveryAttempts {
+			retryAt = now.Add(deliveryBackoff(delivery.Attempt))
+		}
+
+		if disabled[p.Subscription.ID], err = recordDelivery(db, delivery, retryAt); err != nil {
+			return 0, err
+		}
+
+		if disabled[p.Subscription.ID] {
+			log.Printf("subscription %d to %s is disabled after %d failed deliveries", p.Subscription.ID, p.Subscription.URL, maxSubscriptionFailures)
+		}
+	}
+
+	return len(pending), nil
+}
+
+// recordDelivery records outcome of a delivery attempt and reports whether the subscription
+// has been disabled because of too many failures.
+func recordDelivery(db *blamewarrior.DB, delivery *blamewarrior.Delivery, retryAt time.Time) (disabled bool, err error) {
+	tx, err := db.Begin()
+
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	if err = blamewarrior.RecordDelivery(tx, delivery, retryAt); err != nil {
+		return false, err
+	}
+
+	if !delivery.Succeeded() {
+		if disabled, err = blamewarrior.FailSubscription(tx, delivery.SubscriptionID, maxSubscriptionFailures); err != nil {
+			return false, err
+		}
+	}
+
+	return disabled, tx.Commit()
+}
+
+// deliveryBackoff returns the delay before the next delivery attempt after given number of attempts.
+func deliveryBackoff(attempts int) time.Duration {
+	return blamewarrior.ExponentialBackoff(attempts, initialDeliveryBackoff, maxDeliveryBackoff)
+}
@@ -0,0 +1,109 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/stream"
+)
+
+func TestDeliveryBackoff(t *testing.T) {
+	results := []struct {
+		Attempts int
+		Backoff  time.Duration
+	}{
+		{Attempts: 1, Backoff: 30 * time.Second},
+		{Attempts: 2, Backoff: time.Minute},
+		{Attempts: 5, Backoff: 8 * time.Minute},
+		{Attempts: 20, Backoff: 6 * time.Hour},
+	}
+
+	for _, result := range results {
+		assert.Equal(t, result.Backoff, deliveryBackoff(result.Attempts), "attempts: %d", result.Attempts)
+	}
+}
+
+func TestSendPendingDeliveries(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events, event_outbox, subscriptions CASCADE;")
+	require.NoError(t, err)
+
+	var received int
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	ok := &blamewarrior.Subscription{URL: receiver.URL, Secret: "s1"}
+	require.NoError(t, blamewarrior.CreateSubscription(db, ok))
+
+	failing := &blamewarrior.Subscription{URL: broken.URL, Secret: "s2"}
+	require.NoError(t, blamewarrior.CreateSubscription(db, failing))
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+
+	_, err = publishOutboxEvents(db, nil, outboxBatchSize)
+	require.NoError(t, err)
+
+	client := stream.NewSubscriberClient()
+	now := time.Now()
+
+	n, err := sendPendingDeliveries(db, client, now, deliveryBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 1, received)
+
+	// failed delivery is retried after backoff
+	n, err = sendPendingDeliveries(db, client, now, deliveryBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = sendPendingDeliveries(db, client, now.Add(deliveryBackoff(1)), deliveryBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	deliveries, err := blamewarrior.GetDeliveries(db, failing.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, 2, deliveries[0].Attempt)
+	assert.Equal(t, http.StatusServiceUnavailable, deliveries[0].StatusCode)
+
+	sub, err := blamewarrior.GetSubscription(db, failing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sub.Failures)
+	assert.True(t, sub.Active)
+
+	sub, err = blamewarrior.GetSubscription(db, ok.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sub.Failures)
+}
@@ -0,0 +1,120 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/stream"
+)
+
+// webhookBatchSize is the maximum number of queued events sent to webhook URLs at once
+const webhookBatchSize = 100
+
+// deliverWebhookEvents periodically sends queued events to webhook URLs. Publishers are
+// keyed by the URL they send events to.
+func deliverWebhookEvents(db *blamewarrior.DB, publishers map[string]stream.Publisher, interval time.Duration) {
+	for range time.Tick(interval) {
+		for {
+			n, err := sendWebhookEvents(db, publishers, time.Now(), webhookBatchSize)
+			if err != nil {
+				log.Printf("failed to deliver webhook events: %s", err)
+				break
+			}
+
+			if n < webhookBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// sendWebhookEvents sends a batch of due webhook events, all events queued for the same URL in
+// a single request, and records the outcome. Like subscription deliveries, events are leased
+// rather than locked while they are sent, and failed ones are retried with exponential backoff
+// until maxDeliveryAttempts is reached. Events queued for URLs that are no longer configured
+// are dropped.
+func sendWebhookEvents(db *blamewarrior.DB, publishers map[string]stream.Publisher, now time.Time, limit int) (int, error) {
+	pending, err := blamewarrior.ClaimWebhookEvents(db, now, deliveryLease, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for start, end := 0, 0; start < len(pending); start = end {
+		url := pending[start].URL
+
+		var events []blamewarrior.RepositoryEvent
+		for end = start; end < len(pending) && pending[end].URL == url; end++ {
+			events = append(events, pending[end].Event)
+		}
+
+		publisher, ok := publishers[url]
+		if !ok {
+			if err = blamewarrior.DeleteWebhookEvents(db, url, events); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		if err = publisher.Publish(events); err != nil {
+			log.Printf("failed to deliver %d events to webhook: %s", len(events), err)
+
+			if err = rescheduleWebhookEvents(db, pending[start:end], now); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		if err = blamewarrior.DeleteWebhookEvents(db, url, events); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(pending), nil
+}
+
+// rescheduleWebhookEvents records failed attempt to send events to the same webhook URL. Events
+// are retried together after a backoff based on the number of attempts made to send the oldest
+// of them, and those that have been attempted maxDeliveryAttempts times are dropped.
+func rescheduleWebhookEvents(db *blamewarrior.DB, pending []blamewarrior.PendingWebhookEvent, now time.Time) error {
+	url := pending[0].URL
+	retryAt := now.Add(deliveryBackoff(pending[0].Attempts + 1))
+
+	var retried, dropped []blamewarrior.RepositoryEvent
+	for _, p := range pending {
+		if p.Attempts+1 < maxDeliveryAttempts {
+			retried = append(retried, p.Event)
+		} else {
+			dropped = append(dropped, p.Event)
+		}
+	}
+
+	if len(dropped) > 0 {
+		log.Printf("dropping %d events after %d failed attempts to deliver them to %s", len(dropped), maxDeliveryAttempts, url)
+
+		if err := blamewarrior.DeleteWebhookEvents(db, url, dropped); err != nil {
+			return err
+		}
+	}
+
+	if len(retried) > 0 {
+		return blamewarrior.RescheduleWebhookEvents(db, url, retried, retryAt)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+/*
+   Copyright (C) 2017 The BlameWarrior Authors.
+   This file is a part of BlameWarrior service.
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blamewarrior/repos/blamewarrior"
+	"github.com/blamewarrior/repos/blamewarrior/stream"
+)
+
+func TestSendWebhookEvents(t *testing.T) {
+	db, teardown := setup()
+	defer teardown()
+
+	_, err := db.Exec("TRUNCATE repositories, repository_events, event_outbox, webhook_queue CASCADE;")
+	require.NoError(t, err)
+
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "repos"}))
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "hooks"}))
+
+	ok, failing := &publisherMock{}, &publisherMock{err: assert.AnError}
+	publishers := map[string]stream.Publisher{
+		"http://hooks.example.com":     ok,
+		"http://reviewers.example.com": failing,
+	}
+
+	urls := []string{"http://hooks.example.com", "http://reviewers.example.com", "http://removed.example.com"}
+
+	_, err = publishOutboxEvents(db, urls, outboxBatchSize)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	n, err := sendWebhookEvents(db, publishers, now, webhookBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 6, n)
+	require.Len(t, ok.events, 2)
+
+	// failing URL does not hold up the others and is retried after backoff
+	require.NoError(t, blamewarrior.CreateRepository(db, &blamewarrior.Repository{Owner: "blamewarrior", Name: "tokens"}))
+
+	_, err = publishOutboxEvents(db, urls[:2], outboxBatchSize)
+	require.NoError(t, err)
+
+	n, err = sendWebhookEvents(db, publishers, now, webhookBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	require.Len(t, ok.events, 3)
+	assert.Equal(t, "tokens", ok.events[2].Name)
+
+	n, err = sendWebhookEvents(db, publishers, now, webhookBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	failing.err = nil
+
+	n, err = sendWebhookEvents(db, publishers, now.Add(deliveryBackoff(1)), webhookBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	require.Len(t, failing.events, 3)
+	for i, name := range []string{"repos", "hooks", "tokens"} {
+		assert.Equal(t, name, failing.events[i].Name)
+	}
+
+	// delivered events are removed from the queue
+	n, err = sendWebhookEvents(db, publishers, now.Add(maxDeliveryBackoff), webhookBatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}